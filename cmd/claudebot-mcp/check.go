@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/config"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+)
+
+// discordCheckTimeout bounds the Discord requests made by -check.
+const discordCheckTimeout = 15 * time.Second
+
+// checkStatus is the outcome of one self-check.
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+	checkSkip checkStatus = "SKIP"
+)
+
+// checkResult is one line of the -check report. Detail explains a failure,
+// warning or skip, or adds context to a pass.
+type checkResult struct {
+	Name   string
+	Status checkStatus
+	Detail string
+}
+
+// discordVerifier checks that token can log in to Discord over REST and
+// that the bot can see guildID, returning a short description of the bot
+// user on success.
+type discordVerifier func(ctx context.Context, token, guildID string) (string, error)
+
+// runChecks validates cfg the way startup does, without opening the gateway
+// or starting the server, and then verifies the Discord credentials with
+// verify unless the config is already known to be unusable.
+func runChecks(ctx context.Context, cfg *config.Config, verify discordVerifier) []checkResult {
+	var results []checkResult
+	add := func(name string, err error) {
+		if err != nil {
+			results = append(results, checkResult{Name: name, Status: checkFail, Detail: err.Error()})
+			return
+		}
+		results = append(results, checkResult{Name: name, Status: checkPass})
+	}
+
+	add("config", cfg.Validate())
+	if _, ok := config.LookupLogLevel(cfg.Logging.Level); !ok && cfg.Logging.Level != "" {
+		results = append(results, checkResult{Name: "logging.level", Status: checkWarn, Detail: fmt.Sprintf("unknown level %q, info will be used", cfg.Logging.Level)})
+	}
+
+	var channelErrs []error
+	if _, err := buildChannelFilter(cfg.Safety.Channels); err != nil {
+		channelErrs = append(channelErrs, err)
+	}
+	for _, guildID := range slices.Sorted(maps.Keys(cfg.Safety.Channels.Guilds)) {
+		if _, err := buildChannelFilter(cfg.Safety.Channels.Guilds[guildID]); err != nil {
+			channelErrs = append(channelErrs, fmt.Errorf("guild %s: %w", guildID, err))
+		}
+	}
+	add("safety.channels", errors.Join(channelErrs...))
+	_, err := safety.NewFilterValidated(cfg.Safety.Users.Allowlist, cfg.Safety.Users.Denylist,
+		safety.WithCaseSensitive(cfg.Safety.Users.CaseSensitive))
+	add("safety.users", err)
+	_, err = safety.NewContentFilter(cfg.Safety.Content.Allowlist, cfg.Safety.Content.Denylist,
+		safety.WithContentCaseSensitive(cfg.Safety.Content.CaseSensitive))
+	add("safety.content", err)
+	_, err = discord.ParseIntents(cfg.Discord.Intents)
+	add("discord.intents", err)
+	_, err = discord.BuildPresence(cfg.Discord.Presence.Status, cfg.Discord.Presence.ActivityType, cfg.Discord.Presence.ActivityName)
+	add("discord.presence", err)
+
+	if cfg.ValidateRequired() != nil {
+		results = append(results, checkResult{Name: "discord login", Status: checkSkip, Detail: "token or guild ID missing"})
+		return results
+	}
+	ctx, cancel := context.WithTimeout(ctx, discordCheckTimeout)
+	defer cancel()
+	bot, err := verify(ctx, cfg.Discord.Token, cfg.Discord.GuildID)
+	if err != nil {
+		results = append(results, checkResult{Name: "discord login", Status: checkFail, Detail: err.Error()})
+		return results
+	}
+	results = append(results, checkResult{Name: "discord login", Status: checkPass, Detail: bot})
+	return results
+}
+
+// writeCheckReport prints results one per line and a summary, and reports
+// whether every check passed. Warnings and skips do not fail the report.
+func writeCheckReport(w io.Writer, results []checkResult) bool {
+	failed := 0
+	for _, r := range results {
+		line := fmt.Sprintf("%-4s  %s", r.Status, r.Name)
+		if r.Detail != "" {
+			line += ": " + r.Detail
+		}
+		fmt.Fprintln(w, line)
+		if r.Status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(w, "\n%d of %d checks failed\n", failed, len(results))
+		return false
+	}
+	fmt.Fprintln(w, "\nall checks passed")
+	return true
+}
+
+// verifyDiscord returns a discordVerifier that logs in to the Discord REST
+// API with token, using the API version and user agent from dc and without
+// opening the gateway, and fetches guildID to confirm the bot is a member.
+func verifyDiscord(dc config.DiscordConfig) discordVerifier {
+	return func(ctx context.Context, token, guildID string) (string, error) {
+		dg, err := discordgo.New("Bot " + token)
+		if err != nil {
+			return "", err
+		}
+		if err := discord.ConfigureSession(dg, dc.APIVersion, dc.UserAgent); err != nil {
+			return "", err
+		}
+		return describeBot(ctx, dg, guildID)
+	}
+}
+
+// describeBot fetches the bot user and guildID with dg and describes both.
+func describeBot(ctx context.Context, dg *discordgo.Session, guildID string) (string, error) {
+	me, err := dg.User("@me", discordgo.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("logging in: %w", err)
+	}
+	guild, err := dg.Guild(guildID, discordgo.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("logged in as %s but guild %s is not accessible: %w", me.Username, guildID, err)
+	}
+	return fmt.Sprintf("logged in as %s (%s), guild %q", me.Username, me.ID, guild.Name), nil
+}
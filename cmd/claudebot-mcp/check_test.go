@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jamesprial/claudebot-mcp/internal/config"
+)
+
+// goodCheckConfig returns a config that passes every offline check.
+func goodCheckConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Discord.Token = "token"
+	cfg.Discord.GuildID = "guild-1"
+	return cfg
+}
+
+// statusByName indexes check results by name.
+func statusByName(results []checkResult) map[string]checkStatus {
+	out := make(map[string]checkStatus, len(results))
+	for _, r := range results {
+		out[r.Name] = r.Status
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// runChecks / writeCheckReport
+// ---------------------------------------------------------------------------
+
+func Test_RunChecks_GoodConfig(t *testing.T) {
+	t.Parallel()
+
+	var gotToken, gotGuild string
+	verify := func(_ context.Context, token, guildID string) (string, error) {
+		gotToken, gotGuild = token, guildID
+		return "logged in as bot", nil
+	}
+
+	results := runChecks(context.Background(), goodCheckConfig(), verify)
+	for _, r := range results {
+		if r.Status != checkPass {
+			t.Errorf("%s = %s (%s), want PASS", r.Name, r.Status, r.Detail)
+		}
+	}
+	if gotToken != "token" || gotGuild != "guild-1" {
+		t.Errorf("verify called with (%q, %q), want (token, guild-1)", gotToken, gotGuild)
+	}
+
+	var buf bytes.Buffer
+	if !writeCheckReport(&buf, results) {
+		t.Errorf("writeCheckReport() = false, want true; report:\n%s", buf.String())
+	}
+	for _, want := range []string{"PASS  discord login: logged in as bot", "all checks passed"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func Test_RunChecks_BadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		mutate     func(*config.Config)
+		wantFailed []string
+		wantLogin  checkStatus
+	}{
+		{
+			name:       "missing token skips login",
+			mutate:     func(c *config.Config) { c.Discord.Token = "" },
+			wantFailed: []string{"config"},
+			wantLogin:  checkSkip,
+		},
+		{
+			name: "several problems",
+			mutate: func(c *config.Config) {
+				c.Server.TLS.CertFile = "cert.pem"
+				c.Discord.Intents = []string{"not_an_intent"}
+				c.Discord.Presence.Status = "sleepy"
+			},
+			wantFailed: []string{"config", "discord.intents", "discord.presence"},
+			wantLogin:  checkPass,
+		},
+		{
+			name: "bad guild channel rules",
+			mutate: func(c *config.Config) {
+				c.Safety.Channels.Guilds = map[string]config.ChannelFilter{"guild-1": {Allowlist: []string{"re:("}}}
+			},
+			wantFailed: []string{"safety.channels"},
+			wantLogin:  checkPass,
+		},
+		{
+			name: "audit on stdout in stdio mode",
+			mutate: func(c *config.Config) {
+				c.Server.Stdio = true
+				c.Audit.Enabled = true
+				c.Audit.LogPath = "stdout"
+			},
+			wantFailed: []string{"config"},
+			wantLogin:  checkPass,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := goodCheckConfig()
+			tt.mutate(cfg)
+			verify := func(context.Context, string, string) (string, error) { return "bot", nil }
+
+			results := runChecks(context.Background(), cfg, verify)
+			statuses := statusByName(results)
+			for _, name := range tt.wantFailed {
+				if statuses[name] != checkFail {
+					t.Errorf("%s = %s, want FAIL", name, statuses[name])
+				}
+			}
+			if statuses["discord login"] != tt.wantLogin {
+				t.Errorf("discord login = %s, want %s", statuses["discord login"], tt.wantLogin)
+			}
+
+			var buf bytes.Buffer
+			if writeCheckReport(&buf, results) {
+				t.Errorf("writeCheckReport() = true, want false; report:\n%s", buf.String())
+			}
+			if !strings.Contains(buf.String(), "checks failed") {
+				t.Errorf("report missing failure summary:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func Test_RunChecks_LoginFails(t *testing.T) {
+	t.Parallel()
+
+	verify := func(context.Context, string, string) (string, error) {
+		return "", errors.New("logging in: HTTP 401 Unauthorized")
+	}
+	results := runChecks(context.Background(), goodCheckConfig(), verify)
+
+	last := results[len(results)-1]
+	if last.Name != "discord login" || last.Status != checkFail || !strings.Contains(last.Detail, "401") {
+		t.Errorf("last result = %+v, want a failed discord login mentioning 401", last)
+	}
+	if writeCheckReport(&bytes.Buffer{}, results) {
+		t.Error("writeCheckReport() = true, want false")
+	}
+}
+
+func Test_RunChecks_UnknownLogLevelWarns(t *testing.T) {
+	t.Parallel()
+
+	cfg := goodCheckConfig()
+	cfg.Logging.Level = "verbose"
+	results := runChecks(context.Background(), cfg, func(context.Context, string, string) (string, error) { return "bot", nil })
+
+	if got := statusByName(results)["logging.level"]; got != checkWarn {
+		t.Errorf("logging.level = %s, want WARN", got)
+	}
+	if !writeCheckReport(&bytes.Buffer{}, results) {
+		t.Error("a warning should not fail the report")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -13,16 +14,19 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/admin"
 	"github.com/jamesprial/claudebot-mcp/internal/auth"
 	"github.com/jamesprial/claudebot-mcp/internal/channel"
 	"github.com/jamesprial/claudebot-mcp/internal/config"
 	"github.com/jamesprial/claudebot-mcp/internal/discord"
 	"github.com/jamesprial/claudebot-mcp/internal/guild"
 	"github.com/jamesprial/claudebot-mcp/internal/message"
+	"github.com/jamesprial/claudebot-mcp/internal/metrics"
 	"github.com/jamesprial/claudebot-mcp/internal/queue"
 	"github.com/jamesprial/claudebot-mcp/internal/reaction"
 	"github.com/jamesprial/claudebot-mcp/internal/resolve"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/telemetry"
 	"github.com/jamesprial/claudebot-mcp/internal/tools"
 	"github.com/jamesprial/claudebot-mcp/internal/user"
 	"github.com/mark3labs/mcp-go/server"
@@ -30,49 +34,164 @@ import (
 
 const defaultConfigPath = "config.yaml"
 
-var stdioFlag = flag.Bool("stdio", false, "use stdio transport instead of HTTP")
+var (
+	stdioFlag = flag.Bool("stdio", false, "use stdio transport instead of HTTP")
+	checkFlag = flag.Bool("check", false, "validate the config and Discord login, print a report and exit")
+)
 
 func main() {
 	flag.Parse()
 
-	// 1. Load config (before structured logger exists, uses stderr for errors).
-	cfg := loadConfig()
+	// 1-2. Load config and apply environment variable overrides (before the
+	// structured logger exists, so errors go to stderr).
+	configPath, cfg, source, envOverrides := loadConfig()
+	cfg.Server.Stdio = *stdioFlag
 
-	// 2. Apply environment variable overrides.
-	config.ApplyEnvOverrides(cfg)
+	// -check reports on the config and Discord credentials without opening
+	// the gateway or starting the server.
+	if *checkFlag {
+		fmt.Printf("config: %s (%s), env overrides: %v\n\n", configPath, source, envOverrides)
+		if !writeCheckReport(os.Stdout, runChecks(context.Background(), cfg, verifyDiscord(cfg.Discord))) {
+			os.Exit(1)
+		}
+		return
+	}
 
-	// 3. Build structured logger from config.
-	logLevel := config.ParseLogLevel(cfg.Logging.Level)
-	slogHandler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+	// 3. Build structured logger from config. The level is a LevelVar so
+	// discord_set_log_level can change it at runtime.
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(config.ParseLogLevel(cfg.Logging.Level))
+	// Log lines written with a tool call's context carry its request_id.
+	slogHandler := tools.NewContextHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: logLevel,
-	})
+	}))
 	logger := slog.New(slogHandler)
 
 	// Create a *log.Logger bridge for mcp-go compatibility.
 	stdLogger := slog.NewLogLogger(slogHandler, slog.LevelError)
 
-	// 4. Open audit log file if enabled.
-	var auditLogger *safety.AuditLogger
+	logger.Info("config loaded", "source", source, "path", configPath, "env_overrides", envOverrides)
+	if _, ok := config.LookupLogLevel(cfg.Logging.Level); !ok && cfg.Logging.Level != "" {
+		logger.Warn("unknown logging.level, using info", "level", cfg.Logging.Level, "valid", "debug, info, warn, error")
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Error("invalid config", "error", err)
+		os.Exit(1)
+	}
+	addr, err := cfg.Server.ListenAddr()
+	if err != nil {
+		logger.Error("invalid listen address", "error", err)
+		os.Exit(1)
+	}
+
+	// 4. Set up span export (no-op unless an OTLP endpoint is configured).
+	spanSink, shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetry.Config{
+		OTLPEndpoint: cfg.Telemetry.OTLPEndpoint,
+		Insecure:     cfg.Telemetry.Insecure,
+		ServiceName:  cfg.Telemetry.ServiceName,
+	})
+	if err != nil {
+		logger.Error("failed to set up telemetry", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTelemetry(ctx); err != nil {
+			logger.Warn("telemetry shutdown error", "error", err)
+		}
+	}()
+
+	// 4a. Open audit log destination (file, stdout/stderr, or syslog) if
+	// enabled. Spans are exported even when the NDJSON log is disabled.
+	var auditWriter io.Writer
 	if cfg.Audit.Enabled {
-		f, err := os.OpenFile(cfg.Audit.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		f, err := safety.OpenAuditWriter(cfg.Audit.LogPath)
 		if err != nil {
 			logger.Warn("could not open audit log, audit logging disabled",
 				"path", cfg.Audit.LogPath, "error", err)
 		} else {
-			auditLogger = safety.NewAuditLogger(f)
+			auditWriter = f
 			defer func() { _ = f.Close() }()
 		}
 	}
+	auditFormat, err := safety.ParseAuditFormat(cfg.Audit.Format)
+	if err != nil {
+		logger.Error("invalid audit config", "error", err)
+		os.Exit(1)
+	}
+	var auditLogger *safety.AuditLogger
+	if cfg.Telemetry.OTLPEndpoint != "" {
+		auditLogger = safety.NewAuditLoggerWithFormat(auditWriter, auditFormat, spanSink)
+	} else {
+		auditLogger = safety.NewAuditLoggerWithFormat(auditWriter, auditFormat)
+	}
 
 	// 5. Build safety components.
-	channelFilter := safety.NewFilter(
-		cfg.Safety.Channels.Allowlist,
-		cfg.Safety.Channels.Denylist,
+	guildChannelFilters := make(map[string]*safety.Filter, len(cfg.Safety.Channels.Guilds))
+	for guildID, rules := range cfg.Safety.Channels.Guilds {
+		f, err := buildChannelFilter(rules)
+		if err != nil {
+			logger.Error("invalid channel filter", "guild_id", guildID, "error", err)
+			os.Exit(1)
+		}
+		guildChannelFilters[guildID] = f
+	}
+	defaultChannelFilter, err := buildChannelFilter(cfg.Safety.Channels)
+	if err != nil {
+		logger.Error("invalid channel filter", "error", err)
+		os.Exit(1)
+	}
+	// Tools that take a channel name act in discord.guild_id, whose channels
+	// the resolver caches, so they use that guild's filter; ingestion and
+	// tools that take a guild_id pick the filter for each event or call.
+	guildFilters := safety.NewGuildFilters(defaultChannelFilter, guildChannelFilters)
+	channelFilter := guildFilters.For(cfg.Discord.GuildID)
+	userFilter, err := safety.NewFilterValidated(
+		cfg.Safety.Users.Allowlist,
+		cfg.Safety.Users.Denylist,
+		safety.WithCaseSensitive(cfg.Safety.Users.CaseSensitive),
+	)
+	if err != nil {
+		logger.Error("invalid user filter", "error", err)
+		os.Exit(1)
+	}
+	contentFilter, err := safety.NewContentFilter(
+		cfg.Safety.Content.Allowlist,
+		cfg.Safety.Content.Denylist,
+		safety.WithAllowMentions(cfg.Safety.Content.AllowMentions),
+		safety.WithContentCaseSensitive(cfg.Safety.Content.CaseSensitive),
+	)
+	if err != nil {
+		logger.Error("invalid content filter", "error", err)
+		os.Exit(1)
+	}
+	// The confirmation mode was checked by Validate.
+	confirmMode, _ := safety.ParseConfirmationMode(cfg.Confirmation.Mode)
+	destructive := message.DestructiveToolNames()
+	if cfg.Safety.AllowInvites {
+		// When invites are disabled discord_create_invite only reports so,
+		// and should not ask for confirmation first.
+		destructive = append(destructive, channel.DestructiveToolNames()...)
+	}
+	confirm := safety.NewConfirmationTracker(
+		append(destructive, cfg.Safety.DestructiveTools...),
+		safety.WithConfirmationMode(confirmMode),
 	)
-	confirm := safety.NewConfirmationTracker(message.DestructiveToolNames())
+	if confirmMode != safety.ConfirmToken {
+		logger.Warn("destructive tools do not require a confirmation token", "confirmation_mode", confirmMode)
+	}
 
-	// 6. Build queue.
-	q := queue.New(queue.WithMaxSize(cfg.Queue.MaxSize))
+	// 6. Build queue (the overflow policy was checked by Validate).
+	q := queue.New(
+		queue.WithMaxSize(cfg.Queue.MaxSize),
+		queue.WithOverflowPolicy(queue.OverflowPolicy(cfg.Queue.OverflowPolicy)),
+		queue.WithBlockTimeout(time.Duration(cfg.Queue.BlockTimeoutSec)*time.Second),
+		queue.WithDedup(cfg.Queue.DedupWindow),
+		queue.WithPriorityLanes(cfg.Queue.PriorityLanes),
+		queue.WithAckTimeout(time.Duration(cfg.Queue.AckTimeoutSec)*time.Second),
+		queue.WithPerChannelMax(cfg.Queue.PerChannelMax),
+	)
 
 	// 7. Create raw discordgo session.
 	rawDG, err := discordgo.New("Bot " + cfg.Discord.Token)
@@ -80,22 +199,53 @@ func main() {
 		logger.Error("failed to create Discord session", "error", err)
 		os.Exit(1)
 	}
+	if err := discord.ConfigureSession(rawDG, cfg.Discord.APIVersion, cfg.Discord.UserAgent); err != nil {
+		logger.Error("failed to configure Discord session", "error", err)
+		os.Exit(1)
+	}
 
 	// 8. Create resolver.
-	resolver := resolve.New(rawDG, cfg.Discord.GuildID)
+	resolver := resolve.New(rawDG, cfg.Discord.GuildID,
+		resolve.WithVerifyNumericIDs(cfg.Discord.VerifyNumericChannelIDs),
+		resolve.WithRefreshOnMiss(resolve.DefaultMissTTL),
+	)
+
+	// 8a. Idle disconnect: closes the gateway when nothing happens for a while
+	// and reopens it on the next tool call. Nil when disabled.
+	idle := discord.NewIdleMonitor(rawDG, time.Duration(cfg.Discord.IdleDisconnectSec)*time.Second, logger)
+
+	// 8b. Shutdown context cancels long-running work (e.g. polls and channel
+	// cache retries) so the server can stop without waiting out timeouts.
+	shutdownCtx, cancelShutdown := context.WithCancel(context.Background())
+	defer cancelShutdown()
 
 	// 9. Create discord.Session (registers event handlers and intents).
-	discordSession := discord.NewFromSession(rawDG, q, resolver, logger)
-	_ = discordSession // event handlers registered; Close called on shutdown
+	intents, err := discord.ParseIntents(cfg.Discord.Intents)
+	if err != nil {
+		logger.Error("invalid discord intents", "error", err)
+		os.Exit(1)
+	}
+	discordSession := discord.NewFromSession(rawDG, q, resolver, logger,
+		discord.WithIntents(intents),
+		discord.WithUserFilter(userFilter),
+		discord.WithContentFilter(contentFilter),
+		discord.WithReplyContext(cfg.Queue.ReplyContextLength),
+		discord.WithTypingEvents(cfg.Queue.TypingEvents),
+		discord.WithMentionExpansion(cfg.Queue.ExpandMentions),
+		discord.WithCommandPrefix(cfg.Queue.CommandPrefix),
+		discord.WithIdleMonitor(idle),
+		discord.WithShutdown(shutdownCtx),
+	)
+	// Drop messages from denied channels before they reach the queue.
+	discordSession.SetGuildFilters(guildFilters)
 
-	// 9a. Set initial presence (online from first connect).
-	rawDG.Identify.Presence = discordgo.GatewayStatusUpdate{
-		Status: "online",
-		Game: discordgo.Activity{
-			Name: "the server",
-			Type: discordgo.ActivityTypeWatching,
-		},
+	// 9a. Set initial presence (from first connect).
+	presence, err := discord.BuildPresence(cfg.Discord.Presence.Status, cfg.Discord.Presence.ActivityType, cfg.Discord.Presence.ActivityName)
+	if err != nil {
+		logger.Error("invalid discord presence", "error", err)
+		os.Exit(1)
 	}
+	rawDG.Identify.Presence = presence
 
 	// 10. Open Discord connection.
 	if err := rawDG.Open(); err != nil {
@@ -103,6 +253,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if idle != nil {
+		logger.Info("idle disconnect enabled", "timeout", idle.Timeout())
+		go idle.Run(shutdownCtx)
+	}
+
 	// 11. Build MCP server.
 	mcpServer := server.NewMCPServer(
 		"claudebot-mcp",
@@ -111,24 +266,91 @@ func main() {
 	)
 
 	// 12. Register all tools.
+	pollCfg := message.PollConfig{
+		DefaultTimeoutSec: cfg.Queue.PollTimeoutSec,
+		MaxTimeoutSec:     cfg.Queue.MaxPollTimeoutSec,
+	}
+
+	// REST client used by tool handlers; retries transient Discord failures
+	// and fails fast while Discord is down.
+	dg := discord.NewRetryClient(rawDG, discord.RetryPolicy{
+		MaxAttempts: cfg.Discord.Retry.MaxAttempts,
+		BaseDelay:   time.Duration(cfg.Discord.Retry.BaseDelayMs) * time.Millisecond,
+	}, discord.WithCircuitBreaker(discord.BreakerPolicy{
+		Threshold: cfg.Discord.CircuitBreaker.FailureThreshold,
+		Cooldown:  time.Duration(cfg.Discord.CircuitBreaker.CooldownSec) * time.Second,
+	}))
+
 	var registrations []tools.Registration
 	registrations = append(registrations,
-		message.MessageTools(rawDG, q, resolver, channelFilter, confirm, auditLogger, logger)...,
+		message.MessageTools(shutdownCtx, dg, q, pollCfg, resolver, channelFilter, confirm, auditLogger, logger,
+			message.WithMentionPolicy(message.MentionPolicy{
+				DenyEveryone:    cfg.Safety.Mentions.DenyEveryone,
+				AllowedRoles:    cfg.Safety.Mentions.AllowedRoles,
+				MaxUserMentions: cfg.Safety.Mentions.MaxUserMentions,
+			}),
+			message.WithAttachmentConfig(message.AttachmentConfig{MaxBytes: cfg.Tools.MaxAttachmentBytes}),
+			message.WithPollKeepalive(pollKeepaliveNotifier(mcpServer, *stdioFlag), time.Duration(cfg.Queue.PollKeepaliveSec)*time.Second),
+			message.WithMaxResultItems(cfg.Tools.MaxResultItems),
+		)...,
+	)
+	registrations = append(registrations,
+		reaction.ReactionTools(dg, resolver, cfg.Discord.GuildID, channelFilter, auditLogger, logger,
+			reaction.WithModeration(cfg.Safety.AllowModeration),
+		)...,
 	)
+	channelOpts := []channel.Option{
+		channel.WithMaxResultItems(cfg.Tools.MaxResultItems),
+		channel.WithGuildFilters(guildFilters),
+		channel.WithShutdown(shutdownCtx),
+	}
+	if cfg.Safety.AllowInvites {
+		channelOpts = append(channelOpts, channel.WithInviteCreation(confirm))
+	}
 	registrations = append(registrations,
-		reaction.ReactionTools(rawDG, resolver, channelFilter, auditLogger, logger)...,
+		channel.ChannelTools(dg, resolver, cfg.Discord.GuildID, channelFilter, auditLogger, logger, channelOpts...)...,
 	)
 	registrations = append(registrations,
-		channel.ChannelTools(rawDG, resolver, cfg.Discord.GuildID, channelFilter, auditLogger, logger)...,
+		user.UserTools(dg, auditLogger, logger)...,
 	)
 	registrations = append(registrations,
-		user.UserTools(rawDG, auditLogger, logger)...,
+		guild.GuildTools(dg, cfg.Discord.GuildID, auditLogger, logger,
+			guild.WithMaxResultItems(cfg.Tools.MaxResultItems),
+		)...,
 	)
 	registrations = append(registrations,
-		guild.GuildTools(rawDG, cfg.Discord.GuildID, auditLogger, logger)...,
+		admin.AdminTools(logLevel, auditLogger, logger, admin.WithLogLevelChange(cfg.Safety.AllowLogLevelChange))...,
 	)
 
-	tools.RegisterAll(mcpServer, registrations)
+	for _, name := range tools.UnknownToolNames(registrations, cfg.Safety.DestructiveTools) {
+		logger.Warn("unknown tool in safety.destructive_tools, ignoring", "tool", name)
+	}
+	for _, name := range tools.UnknownToolNames(registrations, cfg.Tools.Enabled) {
+		logger.Warn("unknown tool in tools.enabled, ignoring", "tool", name)
+	}
+	for _, name := range tools.UnknownToolNames(registrations, cfg.Tools.Disabled) {
+		logger.Warn("unknown tool in tools.disabled, ignoring", "tool", name)
+	}
+	registrations = tools.SelectTools(registrations, cfg.Tools.Enabled, cfg.Tools.Disabled)
+	if cfg.Discord.DefaultChannel != "" {
+		registrations = tools.OptionalChannel(registrations, tools.DefaultChannelTools, cfg.Discord.DefaultChannel)
+	}
+	registrations = tools.WithConfirmation(confirm, registrations)
+	if cfg.Server.StrictArguments {
+		registrations = tools.WithStrictArguments(auditLogger, registrations)
+	}
+
+	toolMetrics := metrics.New(q)
+	registrations = tools.WithIdleReconnect(idle, logger, registrations)
+	registrations = metrics.Instrument(toolMetrics, registrations)
+	if cfg.Tools.TimingMeta {
+		registrations = tools.WithTimingMeta(registrations)
+	}
+
+	if err := tools.RegisterAll(mcpServer, registrations, auditLogger, logger); err != nil {
+		logger.Error("failed to register tools", "error", err)
+		os.Exit(1)
+	}
 
 	// 13. Start in stdio or HTTP mode.
 	if *stdioFlag {
@@ -136,22 +358,31 @@ func main() {
 		if err := server.ServeStdio(mcpServer, server.WithErrorLogger(stdLogger)); err != nil {
 			logger.Error("stdio server error", "error", err)
 		}
+		cancelShutdown()
 	} else {
 		httpHandler := server.NewStreamableHTTPServer(mcpServer)
-		authMiddleware := auth.NewAuthMiddleware(cfg.Server.AuthToken, logger)
-		wrappedHandler := authMiddleware(httpHandler)
-
-		addr := fmt.Sprintf(":%d", cfg.Server.Port)
-		httpSrv := &http.Server{
-			Addr:              addr,
-			Handler:           wrappedHandler,
-			ReadHeaderTimeout: 10 * time.Second,
-			IdleTimeout:       120 * time.Second,
-		}
+		authMiddleware := auth.NewClientAuthMiddleware(authClients(cfg.Server), logger)
+		// CORS wraps auth so browser preflights, which carry no token, succeed.
+		corsMiddleware := auth.NewCORSMiddleware(auth.CORSOptions{
+			AllowedOrigins: cfg.Server.CORS.AllowedOrigins,
+			AllowedMethods: cfg.Server.CORS.AllowedMethods,
+			AllowedHeaders: cfg.Server.CORS.AllowedHeaders,
+		})
+		// /metrics and /readyz are served outside the auth middleware so
+		// scrapers and probes need no token. /readyz reports ready once the
+		// channel cache has loaded, so channel names resolve.
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", toolMetrics.Handler())
+		mux.Handle("/readyz", readyHandler(func() bool {
+			return !resolver.RefreshedAt().IsZero()
+		}))
+		mux.Handle("/", corsMiddleware(authMiddleware(httpHandler)))
+
+		httpSrv := newHTTPServer(addr, mux, cfg.Server)
 
 		go func() {
-			logger.Info("listening", "addr", addr)
-			if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Info("listening", "addr", addr, "tls", cfg.Server.TLS.Enabled())
+			if err := listenAndServe(httpSrv, cfg.Server.TLS); err != nil && err != http.ErrServerClosed {
 				logger.Error("HTTP server error", "error", err)
 				os.Exit(1)
 			}
@@ -161,6 +392,7 @@ func main() {
 		signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 		<-stop
 		logger.Info("shutting down")
+		cancelShutdown()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
@@ -178,21 +410,66 @@ func main() {
 	logger.Info("server stopped")
 }
 
-// loadConfig attempts to read the config file from the path specified by
-// CLAUDEBOT_CONFIG_PATH or the default "config.yaml". If the file cannot be
-// read, DefaultConfig is returned. Uses fmt.Fprintf to stderr because the
-// structured logger has not been constructed yet (it depends on config).
-func loadConfig() *config.Config {
+// pollKeepaliveNotifier returns the notifier for long-poll keepalives: the
+// MCP server over HTTP, and nil over stdio, where no proxy sits between the
+// client and the server.
+func pollKeepaliveNotifier(s *server.MCPServer, stdio bool) tools.Notifier {
+	if stdio {
+		return nil
+	}
+	return s
+}
+
+// authClients lists the bearer tokens accepted over HTTP: server.auth_token,
+// labelled by its hash, followed by server.clients.
+func authClients(c config.ServerConfig) []auth.Client {
+	var clients []auth.Client
+	if c.AuthToken != "" {
+		clients = append(clients, auth.Client{Token: c.AuthToken})
+	}
+	for _, client := range c.Clients {
+		clients = append(clients, auth.Client{Label: client.Label, Token: client.Token})
+	}
+	return clients
+}
+
+// buildChannelFilter compiles one set of channel rules, including its read
+// and write lists, into a Filter.
+func buildChannelFilter(c config.ChannelFilter) (*safety.Filter, error) {
+	channelCase := safety.WithCaseSensitive(c.CaseSensitive)
+	readFilter, err := safety.NewFilterValidated(c.Read.Allowlist, c.Read.Denylist, channelCase)
+	if err != nil {
+		return nil, fmt.Errorf("read lists: %w", err)
+	}
+	writeFilter, err := safety.NewFilterValidated(c.Write.Allowlist, c.Write.Denylist, channelCase)
+	if err != nil {
+		return nil, fmt.Errorf("write lists: %w", err)
+	}
+	return safety.NewFilterValidated(
+		c.Allowlist,
+		c.Denylist,
+		channelCase,
+		safety.WithOperationFilter(safety.OpRead, readFilter),
+		safety.WithOperationFilter(safety.OpWrite, writeFilter),
+	)
+}
+
+// loadConfig loads the config file from the path specified by
+// CLAUDEBOT_CONFIG_PATH or the default "config.yaml" and applies environment
+// overrides. A missing file falls back to defaults; any other load error
+// exits. Uses fmt.Fprintf to stderr because the structured logger has not
+// been constructed yet (it depends on config).
+func loadConfig() (string, *config.Config, config.Source, []string) {
 	path := os.Getenv("CLAUDEBOT_CONFIG_PATH")
 	if path == "" {
 		path = defaultConfigPath
 	}
 
-	cfg, err := config.LoadConfig(path)
+	cfg, source, applied, err := config.Load(path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "claudebot-mcp: could not load config from %q (%v), using defaults\n", path, err)
-		return config.DefaultConfig()
+		fmt.Fprintf(os.Stderr, "claudebot-mcp: could not load config from %q: %v\n", path, err)
+		os.Exit(1)
 	}
 
-	return cfg
+	return path, cfg, source, applied
 }
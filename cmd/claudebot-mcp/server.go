@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/config"
+)
+
+// Default HTTP server timeouts used when ServerConfig fields are unset.
+const (
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+)
+
+// newHTTPServer returns an http.Server for handler listening on addr, with
+// timeouts taken from cfg.
+func newHTTPServer(addr string, handler http.Handler, cfg config.ServerConfig) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: secondsOr(cfg.ReadHeaderTimeoutSec, defaultReadHeaderTimeout),
+		IdleTimeout:       secondsOr(cfg.IdleTimeoutSec, defaultIdleTimeout),
+	}
+}
+
+// listenAndServe serves srv over HTTPS when tls is enabled and plain HTTP
+// otherwise. Like http.Server.ListenAndServe it returns http.ErrServerClosed
+// after Shutdown.
+func listenAndServe(srv *http.Server, tls config.TLSConfig) error {
+	if tls.Enabled() {
+		return srv.ListenAndServeTLS(tls.CertFile, tls.KeyFile)
+	}
+	return srv.ListenAndServe()
+}
+
+// readyHandler serves a readiness probe: 200 once ready reports true, and
+// 503 until then.
+func readyHandler(ready func() bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if !ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("channel cache not loaded\n"))
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
+
+// secondsOr converts n seconds to a Duration, or returns def when n is zero
+// or negative.
+func secondsOr(n int, def time.Duration) time.Duration {
+	if n <= 0 {
+		return def
+	}
+	return time.Duration(n) * time.Second
+}
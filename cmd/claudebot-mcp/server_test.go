@@ -0,0 +1,181 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/config"
+)
+
+// writeSelfSignedCert writes a self-signed certificate for 127.0.0.1 and its
+// key to dir and returns their paths.
+func writeSelfSignedCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "claudebot-test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "tls.crt")
+	keyFile = filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+// freeAddr returns a loopback address with a port that was free at the time
+// of the call.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+// ---------------------------------------------------------------------------
+// newHTTPServer
+// ---------------------------------------------------------------------------
+
+func Test_NewHTTPServer_Timeouts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		cfg             config.ServerConfig
+		wantReadHeader  time.Duration
+		wantIdleTimeout time.Duration
+	}{
+		{name: "defaults", cfg: config.ServerConfig{}, wantReadHeader: 10 * time.Second, wantIdleTimeout: 120 * time.Second},
+		{name: "configured", cfg: config.ServerConfig{ReadHeaderTimeoutSec: 3, IdleTimeoutSec: 30}, wantReadHeader: 3 * time.Second, wantIdleTimeout: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := newHTTPServer(":0", http.NotFoundHandler(), tt.cfg)
+			if srv.ReadHeaderTimeout != tt.wantReadHeader {
+				t.Errorf("ReadHeaderTimeout = %v, want %v", srv.ReadHeaderTimeout, tt.wantReadHeader)
+			}
+			if srv.IdleTimeout != tt.wantIdleTimeout {
+				t.Errorf("IdleTimeout = %v, want %v", srv.IdleTimeout, tt.wantIdleTimeout)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// listenAndServe
+// ---------------------------------------------------------------------------
+
+func Test_ListenAndServe_TLS(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := writeSelfSignedCert(t, t.TempDir())
+	addr := freeAddr(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	srv := newHTTPServer(addr, handler, config.ServerConfig{})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- listenAndServe(srv, config.TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	}()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	client := &http.Client{
+		Timeout:   time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := client.Get("https://" + addr + "/")
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.TLS == nil {
+				t.Fatal("response was not served over TLS")
+			}
+			break
+		}
+		select {
+		case serveErr := <-errCh:
+			t.Fatalf("listenAndServe returned early: %v", serveErr)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not accept HTTPS connections: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	_ = srv.Close()
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("listenAndServe after Close = %v, want http.ErrServerClosed", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// readyHandler
+// ---------------------------------------------------------------------------
+
+func Test_ReadyHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		ready      bool
+		wantStatus int
+	}{
+		{name: "not ready", ready: false, wantStatus: http.StatusServiceUnavailable},
+		{name: "ready", ready: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := httptest.NewRecorder()
+			readyHandler(func() bool { return tt.ready }).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Package admin provides MCP tool handlers for operating the server itself,
+// as opposed to Discord.
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/config"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Option configures optional behaviour of the tools returned by AdminTools.
+type Option func(*options)
+
+// options holds the settings applied by Option values.
+type options struct {
+	allowLevelChange bool
+}
+
+// WithLogLevelChange lets discord_set_log_level change the log level. It is
+// off by default, since debug level logs every tool call's arguments and
+// results, so the tool only reports that safety.allow_log_level_change is
+// required.
+func WithLogLevelChange(enabled bool) Option {
+	return func(o *options) {
+		o.allowLevelChange = enabled
+	}
+}
+
+// AdminTools returns all tool registrations for server administration.
+// level is the LevelVar that the server's log handler reads its minimum level
+// from.
+func AdminTools(
+	level *slog.LevelVar,
+	audit *safety.AuditLogger,
+	logger *slog.Logger,
+	opts ...Option,
+) []tools.Registration {
+	logger = tools.DefaultLogger(logger)
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return []tools.Registration{
+		toolSetLogLevel(level, o.allowLevelChange, audit, logger),
+	}
+}
+
+func toolSetLogLevel(level *slog.LevelVar, allowed bool, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_set_log_level"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Change the server's log level at runtime, e.g. to capture debug logs during an incident without restarting. Must be enabled with safety.allow_log_level_change."),
+		mcp.WithString("level",
+			mcp.Required(),
+			mcp.Description("New log level: debug, info, warn or error"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		input := req.GetString("level", "")
+		params := map[string]any{"level": input}
+
+		if !allowed {
+			err := tools.WithCode(tools.CodeInvalidArgument, errors.New("changing the log level requires safety.allow_log_level_change"))
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+		newLevel, ok := config.LookupLogLevel(input)
+		if !ok {
+			err := fmt.Errorf("invalid log level %q (want debug, info, warn or error)", input)
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		old := level.Level()
+		level.Set(newLevel)
+		logger.InfoContext(ctx, "log level changed", "from", old, "to", newLevel)
+
+		tools.LogAudit(ctx, audit, toolName, params, "ok: "+strings.ToLower(newLevel.String()), start)
+		return mcp.NewToolResultText(fmt.Sprintf("Log level changed from %s to %s", old, newLevel)), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
@@ -0,0 +1,86 @@
+package admin_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/jamesprial/claudebot-mcp/internal/admin"
+	"github.com/jamesprial/claudebot-mcp/internal/testutil"
+)
+
+// ---------------------------------------------------------------------------
+// Tool Registration
+// ---------------------------------------------------------------------------
+
+func Test_AdminTools_Registration(t *testing.T) {
+	t.Parallel()
+	regs := admin.AdminTools(new(slog.LevelVar), nil, nil)
+
+	testutil.AssertRegistrations(t, regs, []string{
+		"discord_set_log_level",
+	})
+}
+
+// ---------------------------------------------------------------------------
+// discord_set_log_level handler
+// ---------------------------------------------------------------------------
+
+func Test_SetLogLevel_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		input     string
+		want      slog.Level
+		wantError bool
+	}{
+		{name: "debug", input: "debug", want: slog.LevelDebug},
+		{name: "mixed case", input: "Warn", want: slog.LevelWarn},
+		{name: "error", input: "error", want: slog.LevelError},
+		{name: "invalid level leaves level unchanged", input: "verbose", want: slog.LevelInfo, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			level := new(slog.LevelVar)
+			regs := admin.AdminTools(level, nil, nil, admin.WithLogLevelChange(true))
+			handler := testutil.FindHandler(t, regs, "discord_set_log_level")
+
+			req := testutil.NewCallToolRequest("discord_set_log_level", map[string]any{
+				"level": tt.input,
+			})
+
+			result, err := handler(context.Background(), req)
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if result.IsError != tt.wantError {
+				t.Errorf("IsError = %v, want %v (%s)", result.IsError, tt.wantError, testutil.ExtractText(t, result))
+			}
+			if got := level.Level(); got != tt.want {
+				t.Errorf("level = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SetLogLevel_DisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	level := new(slog.LevelVar)
+	regs := admin.AdminTools(level, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_set_log_level")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_set_log_level", map[string]any{
+		"level": "debug",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertTextContains(t, result, "safety.allow_log_level_change")
+	if got := level.Level(); got != slog.LevelInfo {
+		t.Errorf("level = %v, want it unchanged at INFO", got)
+	}
+}
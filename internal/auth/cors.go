@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Default CORS settings used when CORSOptions fields are empty. The headers
+// cover bearer auth and the MCP streamable HTTP transport.
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodOptions}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", "Mcp-Session-Id", "Mcp-Protocol-Version", "Last-Event-ID"}
+)
+
+// corsExposedHeaders lists response headers browser clients must be able to
+// read; the MCP transport returns the session ID in Mcp-Session-Id.
+const corsExposedHeaders = "Mcp-Session-Id"
+
+// CORSOptions configures NewCORSMiddleware. AllowedOrigins lists the exact
+// origins (e.g. "https://dashboard.example.com") permitted to call the
+// endpoint from a browser, or "*" for any origin. Empty AllowedMethods and
+// AllowedHeaders fall back to the methods and headers the MCP transport uses.
+type CORSOptions struct {
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+}
+
+// NewCORSMiddleware returns an HTTP middleware that adds CORS headers to
+// responses for requests from an allowed origin. Preflight OPTIONS requests
+// from an allowed origin are answered directly with 204 No Content, so it
+// must wrap the auth middleware: browsers send preflights without the
+// Authorization header.
+//
+// With no allowed origins the middleware is disabled and returns next
+// unchanged. Requests from other origins pass through without CORS headers,
+// which makes the browser block them.
+func NewCORSMiddleware(opts CORSOptions) func(http.Handler) http.Handler {
+	if len(opts.AllowedOrigins) == 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := opts.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			w.Header().Add("Vary", "Origin")
+			if origin == "" || !(anyOrigin || slices.Contains(opts.AllowedOrigins, origin)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+				w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_NewCORSMiddleware_Cases(t *testing.T) {
+	t.Parallel()
+
+	// okHandler stands in for the auth-protected MCP handler.
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		opts           CORSOptions
+		method         string
+		origin         string
+		preflight      bool
+		wantStatusCode int
+		wantAllow      string
+		wantMethods    string
+	}{
+		{
+			name:           "disabled adds no headers",
+			opts:           CORSOptions{},
+			method:         http.MethodPost,
+			origin:         "https://dash.example.com",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "allowed origin gets allow header",
+			opts:           CORSOptions{AllowedOrigins: []string{"https://dash.example.com"}},
+			method:         http.MethodPost,
+			origin:         "https://dash.example.com",
+			wantStatusCode: http.StatusOK,
+			wantAllow:      "https://dash.example.com",
+		},
+		{
+			name:           "other origin gets no allow header",
+			opts:           CORSOptions{AllowedOrigins: []string{"https://dash.example.com"}},
+			method:         http.MethodPost,
+			origin:         "https://evil.example.com",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "wildcard allows any origin",
+			opts:           CORSOptions{AllowedOrigins: []string{"*"}},
+			method:         http.MethodGet,
+			origin:         "https://other.example.com",
+			wantStatusCode: http.StatusOK,
+			wantAllow:      "https://other.example.com",
+		},
+		{
+			name:           "preflight answered before handler",
+			opts:           CORSOptions{AllowedOrigins: []string{"https://dash.example.com"}},
+			method:         http.MethodOptions,
+			origin:         "https://dash.example.com",
+			preflight:      true,
+			wantStatusCode: http.StatusNoContent,
+			wantAllow:      "https://dash.example.com",
+			wantMethods:    "GET, POST, DELETE, OPTIONS",
+		},
+		{
+			name:           "preflight uses configured methods",
+			opts:           CORSOptions{AllowedOrigins: []string{"https://dash.example.com"}, AllowedMethods: []string{"POST"}},
+			method:         http.MethodOptions,
+			origin:         "https://dash.example.com",
+			preflight:      true,
+			wantStatusCode: http.StatusNoContent,
+			wantAllow:      "https://dash.example.com",
+			wantMethods:    "POST",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// Auth runs inside CORS, as wired in main.go.
+			handler := NewCORSMiddleware(tt.opts)(NewAuthMiddleware("secret", nil)(okHandler))
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+				req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+			} else {
+				req.Header.Set("Authorization", "Bearer secret")
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatusCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatusCode)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllow {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantAllow)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+		})
+	}
+}
@@ -1,12 +1,48 @@
-// Package auth provides HTTP middleware for bearer token authentication.
+// Package auth provides HTTP middleware for bearer token authentication and
+// CORS.
 package auth
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"log/slog"
 	"net/http"
 	"strings"
 )
 
+// Client is a bearer token accepted by NewClientAuthMiddleware and the label
+// that identifies whoever holds it, e.g. in the audit log.
+type Client struct {
+	Label string
+	Token string
+}
+
+// clientKey is the context key under which the authenticated client's label
+// is stored.
+type clientKey struct{}
+
+// ContextWithClient returns a copy of ctx carrying the client label.
+func ContextWithClient(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, clientKey{}, label)
+}
+
+// ClientFromContext returns the label of the client that authenticated the
+// request ctx belongs to, or "" when authentication is disabled or the call
+// did not come over HTTP.
+func ClientFromContext(ctx context.Context) string {
+	label, _ := ctx.Value(clientKey{}).(string)
+	return label
+}
+
+// TokenLabel returns the label used for a token configured without one: a
+// short hash of it, so the token itself never reaches the logs.
+func TokenLabel(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "token-" + hex.EncodeToString(sum[:6])
+}
+
 // NewAuthMiddleware returns an HTTP middleware that enforces bearer token
 // authentication. If the configured token is empty, authentication is disabled
 // and all requests pass through to the next handler unconditionally.
@@ -19,18 +55,42 @@ import (
 // The "Bearer" prefix is case-sensitive and must be followed by exactly one
 // space before the token value. Any deviation — missing header, wrong token,
 // lowercase prefix, extra spaces, or an empty token value — results in a 401
-// Unauthorized response and the next handler is never called.
+// Unauthorized response and the next handler is never called. Accepted
+// requests carry the client label TokenLabel(token); see ClientFromContext.
 //
 // logger is used to emit DEBUG-level messages on rejected requests. If nil,
 // slog.Default() is used.
 func NewAuthMiddleware(token string, logger *slog.Logger) func(http.Handler) http.Handler {
+	var clients []Client
+	if token != "" {
+		clients = []Client{{Token: token}}
+	}
+	return NewClientAuthMiddleware(clients, logger)
+}
+
+// NewClientAuthMiddleware is like NewAuthMiddleware but accepts any of
+// several tokens, one per client, and stores the matched client's label in
+// the request context for ClientFromContext. A client with an empty Label
+// is labelled TokenLabel(Token); clients with an empty Token are ignored.
+// With no clients, authentication is disabled.
+func NewClientAuthMiddleware(clients []Client, logger *slog.Logger) func(http.Handler) http.Handler {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	var accepted []Client
+	for _, c := range clients {
+		if c.Token == "" {
+			continue
+		}
+		if c.Label == "" {
+			c.Label = TokenLabel(c.Token)
+		}
+		accepted = append(accepted, c)
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Auth disabled when no token is configured.
-			if token == "" {
+			if len(accepted) == 0 {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -49,13 +109,27 @@ func NewAuthMiddleware(token string, logger *slog.Logger) func(http.Handler) htt
 			provided := authHeader[len(prefix):]
 
 			// The extracted portion must be non-empty and match exactly.
-			if provided == "" || provided != token {
+			label, ok := matchClient(accepted, provided)
+			if provided == "" || !ok {
 				logger.Debug("auth rejected: invalid token", "remote", r.RemoteAddr)
 				http.Error(w, "unauthorized", http.StatusUnauthorized)
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(ContextWithClient(r.Context(), label)))
 		})
 	}
 }
+
+// matchClient returns the label of the client whose token is provided. Every
+// token is compared in constant time so the match position leaks nothing.
+func matchClient(clients []Client, provided string) (string, bool) {
+	var label string
+	found := false
+	for _, c := range clients {
+		if subtle.ConstantTimeCompare([]byte(c.Token), []byte(provided)) == 1 && !found {
+			label, found = c.Label, true
+		}
+	}
+	return label, found
+}
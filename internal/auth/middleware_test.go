@@ -3,6 +3,7 @@ package auth
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -140,3 +141,76 @@ func Test_NewAuthMiddleware_BlocksInnerHandler(t *testing.T) {
 		t.Error("inner handler was called despite invalid auth")
 	}
 }
+
+func Test_NewClientAuthMiddleware_Labels(t *testing.T) {
+	t.Parallel()
+
+	clients := []Client{
+		{Label: "alice", Token: "token-a"},
+		{Token: "token-b"},
+		{Label: "ignored", Token: ""},
+	}
+
+	tests := []struct {
+		name       string
+		authHeader string
+		wantStatus int
+		wantClient string
+	}{
+		{"labelled client", "Bearer token-a", http.StatusOK, "alice"},
+		{"unlabelled client uses token hash", "Bearer token-b", http.StatusOK, TokenLabel("token-b")},
+		{"unknown token", "Bearer token-c", http.StatusUnauthorized, ""},
+		{"empty token entry never matches", "Bearer ", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotClient string
+			handler := NewClientAuthMiddleware(clients, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotClient = ClientFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", tt.authHeader)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotClient != tt.wantClient {
+				t.Errorf("client = %q, want %q", gotClient, tt.wantClient)
+			}
+		})
+	}
+}
+
+func Test_NewClientAuthMiddleware_NoClientsDisablesAuth(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	handler := NewClientAuthMiddleware([]Client{{Label: "empty"}}, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if c := ClientFromContext(r.Context()); c != "" {
+			t.Errorf("client = %q, want empty with auth disabled", c)
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !called {
+		t.Error("inner handler was not called with auth disabled")
+	}
+}
+
+func Test_TokenLabel_Stable(t *testing.T) {
+	t.Parallel()
+
+	a, b := TokenLabel("secret"), TokenLabel("other")
+	if a != TokenLabel("secret") || a == b {
+		t.Errorf("TokenLabel should be stable and distinct: %q, %q", a, b)
+	}
+	if !strings.HasPrefix(a, "token-") || strings.Contains(a, "secret") {
+		t.Errorf("TokenLabel(secret) = %q, want a token- hash", a)
+	}
+}
@@ -0,0 +1,236 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Limits Discord places on invite settings. max_age is in seconds; zero
+// max_age or max_uses means no limit. defaultInviteMaxAge matches Discord's
+// own default of one day.
+const (
+	defaultInviteMaxAge = 86400
+	maxInviteMaxAge     = 604800
+	maxInviteMaxUses    = 100
+)
+
+// inviteURLPrefix is prepended to an invite code to form its URL.
+const inviteURLPrefix = "https://discord.gg/"
+
+// InviteSummary is the response shape for a single invite, returned by
+// discord_create_invite and as the entries of discord_get_invites. MaxAge is
+// in seconds; zero MaxAge or MaxUses means the invite never expires or has
+// no use limit. ExpiresAt is omitted for invites that never expire.
+type InviteSummary struct {
+	Code      string     `json:"code"`
+	URL       string     `json:"url"`
+	ChannelID string     `json:"channel_id"`
+	Channel   string     `json:"channel,omitempty"`
+	Inviter   string     `json:"inviter,omitempty"`
+	Uses      int        `json:"uses"`
+	MaxUses   int        `json:"max_uses"`
+	MaxAge    int        `json:"max_age"`
+	Temporary bool       `json:"temporary,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// summarizeInvite converts inv to an InviteSummary. The channel name comes
+// from the invite, falling back to the resolver's cache.
+func summarizeInvite(inv *discordgo.Invite, r resolve.ChannelResolver) InviteSummary {
+	out := InviteSummary{
+		Code:      inv.Code,
+		URL:       inviteURLPrefix + inv.Code,
+		Uses:      inv.Uses,
+		MaxUses:   inv.MaxUses,
+		MaxAge:    inv.MaxAge,
+		Temporary: inv.Temporary,
+	}
+	if inv.Channel != nil {
+		out.ChannelID = inv.Channel.ID
+		out.Channel = inv.Channel.Name
+		if out.Channel == "" {
+			out.Channel = r.ChannelName(inv.Channel.ID)
+		}
+	}
+	if inv.Inviter != nil {
+		out.Inviter = inv.Inviter.Username
+	}
+	if !inv.CreatedAt.IsZero() {
+		created := inv.CreatedAt.UTC()
+		out.CreatedAt = &created
+	}
+	if inv.ExpiresAt != nil {
+		expires := inv.ExpiresAt.UTC()
+		out.ExpiresAt = &expires
+	}
+	return out
+}
+
+func toolCreateInvite(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, confirm *safety.ConfirmationTracker, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_create_invite"
+
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Create an invite link to a Discord channel. Anyone with the link can join the server, so this requires confirmation and must be enabled with safety.allow_invites."),
+		mcp.WithString("channel",
+			mcp.Required(),
+			mcp.Description("Channel name or ID the invite leads to"),
+		),
+		mcp.WithNumber("max_age",
+			mcp.Description(fmt.Sprintf("Seconds until the invite expires, 0 for never (default: %d, max: %d)", defaultInviteMaxAge, maxInviteMaxAge)),
+		),
+		mcp.WithNumber("max_uses",
+			mcp.Description(fmt.Sprintf("Number of times the invite can be used, 0 for unlimited (default: 0, max: %d)", maxInviteMaxUses)),
+		),
+	}
+	if confirm != nil {
+		opts = append(opts, tools.ConfirmationParam(confirm))
+	}
+	tool := mcp.NewTool(toolName, opts...)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		channel := req.GetString("channel", "")
+		maxAge := req.GetInt("max_age", defaultInviteMaxAge)
+		maxUses := req.GetInt("max_uses", 0)
+		params := map[string]any{
+			"channel":  channel,
+			"max_age":  maxAge,
+			"max_uses": maxUses,
+		}
+
+		if confirm == nil {
+			err := tools.WithCode(tools.CodeInvalidArgument, errors.New("creating invites requires safety.allow_invites"))
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+		if maxAge < 0 || maxAge > maxInviteMaxAge {
+			err := tools.WithCode(tools.CodeInvalidArgument, fmt.Errorf("max_age must be between 0 and %d", maxInviteMaxAge))
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+		if maxUses < 0 || maxUses > maxInviteMaxUses {
+			err := tools.WithCode(tools.CodeInvalidArgument, fmt.Errorf("max_uses must be between 0 and %d", maxInviteMaxUses))
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		channelID, channelName, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, channel, params, start)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		// Bind the confirmation to the limits too, so a token issued for a
+		// short-lived invite cannot confirm an unlimited one.
+		resource := fmt.Sprintf("%s max_age=%d max_uses=%d", channelID, maxAge, maxUses)
+		if ok, token, reason := tools.Approve(confirm, req, toolName, resource); !ok {
+			logger.DebugContext(ctx, "confirmation required", "tool", toolName, "reason", reason)
+			desc := fmt.Sprintf("This will create an invite to channel %q that %s and %s. Anyone with the link can join the server.",
+				channelName, describeInviteAge(maxAge), describeInviteUses(maxUses))
+			return tools.ConfirmPrompt(confirm, toolName, resource, tools.WithRejection(desc, token, reason)), nil
+		}
+
+		logger.DebugContext(ctx, "creating invite", "channelID", channelID, "max_age", maxAge, "max_uses", maxUses)
+
+		inv, err := dg.ChannelInviteCreate(channelID, discordgo.Invite{MaxAge: maxAge, MaxUses: maxUses}, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		summary := summarizeInvite(inv, r)
+		if summary.ChannelID == "" {
+			summary.ChannelID = channelID
+		}
+		if summary.Channel == "" {
+			summary.Channel = channelName
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, "ok: "+inv.Code, start)
+		return tools.JSONResultWithText("Created invite "+summary.URL, summary), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// inviteAllowed reports whether filter lets the invite's channel be read,
+// matching both its ID and name. A channel whose name is unknown is refused
+// when an allowlist applies, since it cannot be shown to be on it.
+func inviteAllowed(filter *safety.Filter, s InviteSummary) bool {
+	if s.Channel == "" || s.Channel == s.ChannelID {
+		// The resolver returns the ID itself for a channel it does not know.
+		if filter.HasAllowlist(safety.OpRead) {
+			return false
+		}
+		return filter.IsAllowedForAny(safety.OpRead, s.ChannelID)
+	}
+	return filter.IsAllowedForAny(safety.OpRead, s.ChannelID, s.Channel)
+}
+
+// describeInviteAge renders maxAge for a confirmation prompt.
+func describeInviteAge(maxAge int) string {
+	if maxAge == 0 {
+		return "never expires"
+	}
+	return "expires after " + (time.Duration(maxAge) * time.Second).String()
+}
+
+// describeInviteUses renders maxUses for a confirmation prompt.
+func describeInviteUses(maxUses int) string {
+	if maxUses == 0 {
+		return "can be used any number of times"
+	}
+	return fmt.Sprintf("can be used %d times", maxUses)
+}
+
+func toolGetInvites(dg discord.DiscordClient, r resolve.ChannelResolver, defaultGuildID string, filters *safety.GuildFilters, results tools.ResultLimit, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_get_invites"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("List a guild's active invites with their code, URL, channel, creator, uses and expiry. Invites to channels the bot may not read are omitted, as are invites to unknown channels when a channel allowlist is configured."),
+		mcp.WithString("guild_id",
+			mcp.Description("Guild (server) ID (optional, uses default guild if omitted)"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		guildID := req.GetString("guild_id", "")
+		if guildID == "" {
+			guildID = defaultGuildID
+		}
+		params := map[string]any{"guild_id": guildID}
+
+		logger.DebugContext(ctx, "listing invites", "guildID", guildID)
+
+		invites, err := dg.GuildInvites(guildID, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		filter := filters.For(guildID)
+		out := make([]InviteSummary, 0, len(invites))
+		for _, inv := range invites {
+			if inv == nil {
+				continue
+			}
+			summary := summarizeInvite(inv, r)
+			if filter != nil && !inviteAllowed(filter, summary) {
+				continue
+			}
+			out = append(out, summary)
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d invites", len(out)), start)
+		return results.JSONResult(out), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
@@ -0,0 +1,72 @@
+package channel
+
+import "github.com/bwmarrin/discordgo"
+
+// permissionNames maps each Discord permission bit to the name reported by
+// discord_get_channel_permissions, in bit order. Bits with several discordgo
+// aliases use the current name.
+var permissionNames = []struct {
+	bit  int64
+	name string
+}{
+	{discordgo.PermissionCreateInstantInvite, "CreateInstantInvite"},
+	{discordgo.PermissionKickMembers, "KickMembers"},
+	{discordgo.PermissionBanMembers, "BanMembers"},
+	{discordgo.PermissionAdministrator, "Administrator"},
+	{discordgo.PermissionManageChannels, "ManageChannels"},
+	{discordgo.PermissionManageGuild, "ManageGuild"},
+	{discordgo.PermissionAddReactions, "AddReactions"},
+	{discordgo.PermissionViewAuditLogs, "ViewAuditLogs"},
+	{discordgo.PermissionVoicePrioritySpeaker, "PrioritySpeaker"},
+	{discordgo.PermissionVoiceStreamVideo, "Stream"},
+	{discordgo.PermissionViewChannel, "ViewChannel"},
+	{discordgo.PermissionSendMessages, "SendMessages"},
+	{discordgo.PermissionSendTTSMessages, "SendTTSMessages"},
+	{discordgo.PermissionManageMessages, "ManageMessages"},
+	{discordgo.PermissionEmbedLinks, "EmbedLinks"},
+	{discordgo.PermissionAttachFiles, "AttachFiles"},
+	{discordgo.PermissionReadMessageHistory, "ReadMessageHistory"},
+	{discordgo.PermissionMentionEveryone, "MentionEveryone"},
+	{discordgo.PermissionUseExternalEmojis, "UseExternalEmojis"},
+	{discordgo.PermissionViewGuildInsights, "ViewGuildInsights"},
+	{discordgo.PermissionVoiceConnect, "Connect"},
+	{discordgo.PermissionVoiceSpeak, "Speak"},
+	{discordgo.PermissionVoiceMuteMembers, "MuteMembers"},
+	{discordgo.PermissionVoiceDeafenMembers, "DeafenMembers"},
+	{discordgo.PermissionVoiceMoveMembers, "MoveMembers"},
+	{discordgo.PermissionVoiceUseVAD, "UseVAD"},
+	{discordgo.PermissionChangeNickname, "ChangeNickname"},
+	{discordgo.PermissionManageNicknames, "ManageNicknames"},
+	{discordgo.PermissionManageRoles, "ManageRoles"},
+	{discordgo.PermissionManageWebhooks, "ManageWebhooks"},
+	{discordgo.PermissionManageGuildExpressions, "ManageGuildExpressions"},
+	{discordgo.PermissionUseApplicationCommands, "UseApplicationCommands"},
+	{discordgo.PermissionVoiceRequestToSpeak, "RequestToSpeak"},
+	{discordgo.PermissionManageEvents, "ManageEvents"},
+	{discordgo.PermissionManageThreads, "ManageThreads"},
+	{discordgo.PermissionCreatePublicThreads, "CreatePublicThreads"},
+	{discordgo.PermissionCreatePrivateThreads, "CreatePrivateThreads"},
+	{discordgo.PermissionUseExternalStickers, "UseExternalStickers"},
+	{discordgo.PermissionSendMessagesInThreads, "SendMessagesInThreads"},
+	{discordgo.PermissionUseEmbeddedActivities, "UseEmbeddedActivities"},
+	{discordgo.PermissionModerateMembers, "ModerateMembers"},
+	{discordgo.PermissionViewCreatorMonetizationAnalytics, "ViewCreatorMonetizationAnalytics"},
+	{discordgo.PermissionUseSoundboard, "UseSoundboard"},
+	{discordgo.PermissionCreateGuildExpressions, "CreateGuildExpressions"},
+	{discordgo.PermissionCreateEvents, "CreateEvents"},
+	{discordgo.PermissionUseExternalSounds, "UseExternalSounds"},
+	{discordgo.PermissionSendVoiceMessages, "SendVoiceMessages"},
+	{discordgo.PermissionSendPolls, "SendPolls"},
+	{discordgo.PermissionUseExternalApps, "UseExternalApps"},
+}
+
+// permissionList returns the names of the permissions set in perms.
+func permissionList(perms int64) []string {
+	out := []string{}
+	for _, p := range permissionNames {
+		if perms&p.bit == p.bit {
+			out = append(out, p.name)
+		}
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Bounds on the number of archived threads discord_get_active_threads
+// fetches; Discord returns at most 100 per request.
+const (
+	defaultArchivedThreadLimit = 50
+	maxArchivedThreadLimit     = 100
+)
+
+// ThreadSummary is one entry in the response of discord_get_active_threads.
+// Parent is the name of the channel the thread belongs to. MessageCount is
+// Discord's approximate count and stops at 50 for threads created before
+// July 2022. ArchivedAt is set for archived threads only.
+type ThreadSummary struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	ParentID     string     `json:"parent_id"`
+	Parent       string     `json:"parent"`
+	MessageCount int        `json:"message_count"`
+	MemberCount  int        `json:"member_count"`
+	Archived     bool       `json:"archived"`
+	Locked       bool       `json:"locked,omitempty"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+}
+
+func toolGetActiveThreads(dg discord.DiscordClient, r resolve.ChannelResolver, defaultGuildID string, filter *safety.Filter, results tools.ResultLimit, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_get_active_threads"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("List active threads in the guild with their parent channel, name and message count, e.g. open support tickets. Threads in channels the bot may not read are omitted."),
+		mcp.WithString("channel",
+			mcp.Description("Only list threads in this parent channel (name or ID); required with include_archived"),
+		),
+		mcp.WithBoolean("include_archived",
+			mcp.Description("Also list the channel's public archived threads, newest first (default: false)"),
+		),
+		mcp.WithNumber("archived_limit",
+			mcp.Description(fmt.Sprintf("Maximum number of archived threads to list (default: %d, max: %d)", defaultArchivedThreadLimit, maxArchivedThreadLimit)),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		channel := req.GetString("channel", "")
+		includeArchived := req.GetBool("include_archived", false)
+		archivedLimit := req.GetInt("archived_limit", defaultArchivedThreadLimit)
+		params := map[string]any{
+			"channel":          channel,
+			"include_archived": includeArchived,
+			"archived_limit":   archivedLimit,
+		}
+
+		var parentID string
+		if channel != "" {
+			id, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpRead, audit, logger, toolName, channel, params, start)
+			if errResult != nil {
+				return errResult, nil
+			}
+			parentID = id
+		}
+		if includeArchived && parentID == "" {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("include_archived requires channel"), start), nil
+		}
+		if archivedLimit < 1 || archivedLimit > maxArchivedThreadLimit {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("archived_limit must be between 1 and %d", maxArchivedThreadLimit), start), nil
+		}
+
+		logger.DebugContext(ctx, "listing active threads", "guildID", defaultGuildID, "parentID", parentID)
+
+		active, err := dg.GuildThreadsActive(defaultGuildID, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+		threads := active.Threads
+
+		if includeArchived {
+			archived, err := dg.ThreadsArchived(parentID, nil, archivedLimit, discordgo.WithContext(ctx))
+			if err != nil {
+				return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("listing archived threads: %w", err), start), nil
+			}
+			threads = append(threads, archived.Threads...)
+		}
+
+		out := make([]ThreadSummary, 0, len(threads))
+		for _, th := range threads {
+			if th == nil || (parentID != "" && th.ParentID != parentID) {
+				continue
+			}
+			parent := r.ChannelName(th.ParentID)
+			if !filter.IsAllowedFor(safety.OpRead, parent) {
+				continue
+			}
+			out = append(out, summarizeThread(th, parent))
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d threads", len(out)), start)
+		return results.JSONResult(out), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// summarizeThread converts a thread channel into a ThreadSummary.
+func summarizeThread(th *discordgo.Channel, parent string) ThreadSummary {
+	s := ThreadSummary{
+		ID:           th.ID,
+		Name:         th.Name,
+		ParentID:     th.ParentID,
+		Parent:       parent,
+		MessageCount: th.MessageCount,
+		MemberCount:  th.MemberCount,
+	}
+	if md := th.ThreadMetadata; md != nil {
+		s.Archived = md.Archived
+		s.Locked = md.Locked
+		if md.Archived && !md.ArchiveTimestamp.IsZero() {
+			at := md.ArchiveTimestamp.UTC()
+			s.ArchivedAt = &at
+		}
+	}
+	return s
+}
@@ -25,6 +25,71 @@ type ChannelSummary struct {
 	Position int    `json:"position"`
 }
 
+// ChannelPermissions is the response shape for discord_get_channel_permissions.
+type ChannelPermissions struct {
+	ChannelID   string   `json:"channel_id"`
+	Channel     string   `json:"channel"`
+	Permissions []string `json:"permissions"`
+}
+
+// destructiveTools lists the tool names in this package that require
+// confirmation before executing.
+var destructiveTools = []string{"discord_create_invite"}
+
+// DestructiveToolNames returns a copy of the destructive tool names list.
+func DestructiveToolNames() []string {
+	out := make([]string, len(destructiveTools))
+	copy(out, destructiveTools)
+	return out
+}
+
+// Option configures optional behaviour of the tools returned by ChannelTools.
+type Option func(*options)
+
+// options holds the settings applied by Option values.
+type options struct {
+	inviteConfirm *safety.ConfirmationTracker
+	results       tools.ResultLimit
+	guildFilters  *safety.GuildFilters
+	shutdown      context.Context
+}
+
+// WithInviteCreation lets discord_create_invite create invites, confirmed
+// through confirm. It is off by default, and a nil tracker leaves it off, so
+// the tool only reports that safety.allow_invites is required.
+func WithInviteCreation(confirm *safety.ConfirmationTracker) Option {
+	return func(o *options) {
+		o.inviteConfirm = confirm
+	}
+}
+
+// WithMaxResultItems caps how many entries the list tools return; see
+// tools.ResultLimit.
+func WithMaxResultItems(n int) Option {
+	return func(o *options) {
+		o.results = tools.ResultLimit(n)
+	}
+}
+
+// WithGuildFilters sets per-guild channel filters for the tools that take a
+// guild_id, so each call is filtered by the rules of the guild it names. The
+// filter passed to ChannelTools should be g.For(defaultGuildID). Without
+// it, every guild uses that filter.
+func WithGuildFilters(g *safety.GuildFilters) Option {
+	return func(o *options) {
+		o.guildFilters = g
+	}
+}
+
+// WithShutdown stops discord_typing's background refreshes when ctx is
+// cancelled, so none outlive the server. Without it they run for their full
+// duration.
+func WithShutdown(ctx context.Context) Option {
+	return func(o *options) {
+		o.shutdown = ctx
+	}
+}
+
 // ChannelTools returns all tool registrations for Discord channel operations.
 func ChannelTools(
 	dg discord.DiscordClient,
@@ -33,15 +98,33 @@ func ChannelTools(
 	filter *safety.Filter,
 	audit *safety.AuditLogger,
 	logger *slog.Logger,
+	opts ...Option,
 ) []tools.Registration {
 	logger = tools.DefaultLogger(logger)
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	guildFilters := o.guildFilters
+	if guildFilters == nil {
+		guildFilters = safety.NewGuildFilters(filter, nil)
+	}
+	if o.shutdown == nil {
+		o.shutdown = context.Background()
+	}
 	return []tools.Registration{
-		toolGetChannels(dg, defaultGuildID, audit, logger),
-		toolTyping(dg, r, filter, audit, logger),
+		toolGetChannels(dg, defaultGuildID, o.results, audit, logger),
+		toolGetChannelTree(dg, defaultGuildID, guildFilters, o.results, audit, logger),
+		toolTyping(o.shutdown, dg, r, filter, audit, logger),
+		toolGetChannelPermissions(dg, r, filter, audit, logger),
+		toolGetActiveThreads(dg, r, defaultGuildID, filter, o.results, audit, logger),
+		toolCreateInvite(dg, r, filter, o.inviteConfirm, audit, logger),
+		toolGetInvites(dg, r, defaultGuildID, guildFilters, o.results, audit, logger),
+		toolResolverDump(r, audit, logger),
 	}
 }
 
-func toolGetChannels(dg discord.DiscordClient, defaultGuildID string, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+func toolGetChannels(dg discord.DiscordClient, defaultGuildID string, results tools.ResultLimit, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
 	const toolName = "discord_get_channels"
 
 	tool := mcp.NewTool(toolName,
@@ -59,11 +142,11 @@ func toolGetChannels(dg discord.DiscordClient, defaultGuildID string, audit *saf
 		}
 		params := map[string]any{"guild_id": guildID}
 
-		logger.Debug("listing channels", "guildID", guildID)
+		logger.DebugContext(ctx, "listing channels", "guildID", guildID)
 
-		rawChannels, err := dg.GuildChannels(guildID)
+		rawChannels, err := dg.GuildChannels(guildID, discordgo.WithContext(ctx))
 		if err != nil {
-			return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
 		}
 
 		summaries := make([]ChannelSummary, 0, len(rawChannels))
@@ -81,14 +164,14 @@ func toolGetChannels(dg discord.DiscordClient, defaultGuildID string, audit *saf
 			})
 		}
 
-		tools.LogAudit(audit, toolName, params, fmt.Sprintf("ok: %d channels", len(summaries)), start)
-		return tools.JSONResult(summaries), nil
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d channels", len(summaries)), start)
+		return results.JSONResult(summaries), nil
 	}
 
 	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
 }
 
-func toolTyping(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+func toolTyping(shutdown context.Context, dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
 	const toolName = "discord_typing"
 
 	tool := mcp.NewTool(toolName,
@@ -97,27 +180,106 @@ func toolTyping(dg discord.DiscordClient, r resolve.ChannelResolver, filter *saf
 			mcp.Required(),
 			mcp.Description("Channel name or ID"),
 		),
+		mcp.WithNumber("duration_seconds",
+			mcp.Description(fmt.Sprintf("Keep the indicator active for this many seconds, e.g. while composing a long reply (default: one indicator of about 10 seconds, max: %d). A later call for the same channel replaces it.", maxTypingDurationS)),
+		),
 	)
 
+	typing := newTyper(shutdown, dg, typingInterval, logger)
+
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		start := time.Now()
 		channel := req.GetString("channel", "")
-		params := map[string]any{"channel": channel}
+		duration := req.GetInt("duration_seconds", 0)
+		if duration < 0 {
+			duration = 0
+		}
+		if duration > maxTypingDurationS {
+			duration = maxTypingDurationS
+		}
+		params := map[string]any{"channel": channel, "duration_seconds": duration}
 
-		channelID, _, errResult := tools.ResolveAndFilterChannel(r, filter, audit, logger, toolName, channel, params, start)
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, channel, params, start)
 		if errResult != nil {
 			return errResult, nil
 		}
 
-		logger.Debug("sending typing indicator", "channelID", channelID)
+		logger.DebugContext(ctx, "sending typing indicator", "channelID", channelID, "duration_seconds", duration)
 
-		if err := dg.ChannelTyping(channelID); err != nil {
-			return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+		if err := typing.start(ctx, channelID, time.Duration(duration)*time.Second); err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
 		}
 
-		tools.LogAudit(audit, toolName, params, "ok", start)
+		tools.LogAudit(ctx, audit, toolName, params, "ok", start)
+		if duration > 0 {
+			return mcp.NewToolResultText(fmt.Sprintf("Typing indicator active for %d seconds", duration)), nil
+		}
 		return mcp.NewToolResultText("Typing indicator sent"), nil
 	}
 
 	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
 }
+
+func toolGetChannelPermissions(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_get_channel_permissions"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("List the bot's effective permissions in a Discord channel, e.g. to check it can send messages or add reactions before trying."),
+		mcp.WithString("channel",
+			mcp.Required(),
+			mcp.Description("Channel name or ID"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		channel := req.GetString("channel", "")
+		params := map[string]any{"channel": channel}
+
+		channelID, channelName, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpRead, audit, logger, toolName, channel, params, start)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		bot, err := dg.User("@me", discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		perms, err := dg.UserChannelPermissions(bot.ID, channelID, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		names := permissionList(perms)
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d permissions", len(names)), start)
+		return tools.JSONResult(ChannelPermissions{
+			ChannelID:   channelID,
+			Channel:     channelName,
+			Permissions: names,
+		}), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+func toolResolverDump(r resolve.ChannelResolver, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_resolver_dump"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Dump the channel name/ID resolution cache and when it was last refreshed, for debugging channels that fail to resolve."),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		params := map[string]any{}
+
+		snap := r.Snapshot()
+		logger.DebugContext(ctx, "dumping resolver cache", "guildID", snap.GuildID, "channels", len(snap.ByID))
+
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d channels", len(snap.ByID)), start)
+		return tools.JSONResult(snap), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
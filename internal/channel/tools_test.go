@@ -2,12 +2,19 @@ package channel_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/channel"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
 	"github.com/jamesprial/claudebot-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // ---------------------------------------------------------------------------
@@ -24,7 +31,13 @@ func Test_ChannelTools_Registration(t *testing.T) {
 
 	testutil.AssertRegistrations(t, regs, []string{
 		"discord_get_channels",
+		"discord_get_channel_tree",
 		"discord_typing",
+		"discord_get_channel_permissions",
+		"discord_get_active_threads",
+		"discord_create_invite",
+		"discord_get_invites",
+		"discord_resolver_dump",
 	})
 }
 
@@ -81,6 +94,196 @@ func Test_GetChannels_JSONFormat(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// discord_get_channel_tree handler
+// ---------------------------------------------------------------------------
+
+func Test_GetChannelTree_NestsAndOrders(t *testing.T) {
+	t.Parallel()
+
+	var gotGuild string
+	client := &testutil.MockDiscordClient{
+		GuildChannelsFunc: func(guildID string, _ ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+			gotGuild = guildID
+			return []*discordgo.Channel{
+				{ID: "ch-10", Name: "voice-lounge", Type: discordgo.ChannelTypeGuildVoice, ParentID: "cat-2", Position: 1},
+				{ID: "cat-2", Name: "Voice", Type: discordgo.ChannelTypeGuildCategory, Position: 2},
+				{ID: "ch-11", Name: "announcements", Type: discordgo.ChannelTypeGuildNews, ParentID: "cat-1", Position: 0},
+				{ID: "ch-12", Name: "chat", Type: discordgo.ChannelTypeGuildText, ParentID: "cat-1", Position: 3},
+				{ID: "cat-1", Name: "Text", Type: discordgo.ChannelTypeGuildCategory, Position: 0},
+				{ID: "ch-13", Name: "help", Type: discordgo.ChannelTypeGuildText, ParentID: "cat-1", Position: 1},
+				{ID: "ch-14", Name: "welcome", Type: discordgo.ChannelTypeGuildText, Position: 0},
+				{ID: "th-1", Name: "a-thread", Type: discordgo.ChannelTypeGuildPublicThread, ParentID: "ch-12"},
+				{ID: "ch-15", Name: "orphan", Type: discordgo.ChannelTypeGuildText, ParentID: "cat-gone", Position: 5},
+				{ID: "cat-3", Name: "Empty", Type: discordgo.ChannelTypeGuildCategory, Position: 1},
+			}, nil
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	regs := channel.ChannelTools(client, r, "guild-1", safety.NewFilter(nil, nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_channel_tree")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_channel_tree", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+	if gotGuild != "guild-1" {
+		t.Errorf("GuildChannels guildID = %q, want guild-1", gotGuild)
+	}
+
+	var got channel.ChannelTree
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	want := channel.ChannelTree{
+		Categories: []channel.ChannelCategory{
+			{ID: "cat-1", Name: "Text", Position: 0, Children: []channel.ChannelNode{
+				{ID: "ch-11", Name: "announcements", Type: "announcement", Position: 0},
+				{ID: "ch-13", Name: "help", Type: "text", Position: 1},
+				{ID: "ch-12", Name: "chat", Type: "text", Position: 3},
+			}},
+			{ID: "cat-3", Name: "Empty", Position: 1, Children: []channel.ChannelNode{}},
+			{ID: "cat-2", Name: "Voice", Position: 2, Children: []channel.ChannelNode{
+				{ID: "ch-10", Name: "voice-lounge", Type: "voice", Position: 1},
+			}},
+		},
+		Uncategorized: []channel.ChannelNode{
+			{ID: "ch-14", Name: "welcome", Type: "text", Position: 0},
+			{ID: "ch-15", Name: "orphan", Type: "text", Position: 5},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tree = %+v, want %+v", got, want)
+	}
+}
+
+func Test_GetChannelTree_OmitsDeniedChannels(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		GuildChannelsFunc: func(string, ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+			return []*discordgo.Channel{
+				{ID: "cat-1", Name: "Text", Type: discordgo.ChannelTypeGuildCategory},
+				{ID: "ch-001", Name: "general", Type: discordgo.ChannelTypeGuildText, ParentID: "cat-1"},
+				{ID: "ch-002", Name: "random", Type: discordgo.ChannelTypeGuildText, ParentID: "cat-1", Position: 1},
+			}, nil
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	regs := channel.ChannelTools(client, r, "guild-1", safety.NewFilter(nil, []string{"random"}), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_channel_tree")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_channel_tree", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	text := testutil.ExtractText(t, result)
+	if !strings.Contains(text, "general") || strings.Contains(text, "random") {
+		t.Errorf("expected general but not random in tree, got: %s", text)
+	}
+}
+
+func Test_GetChannelTree_OmitsDeniedCategories(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		GuildChannelsFunc: func(string, ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+			return []*discordgo.Channel{
+				{ID: "cat-1", Name: "Text", Type: discordgo.ChannelTypeGuildCategory},
+				{ID: "cat-2", Name: "staff", Type: discordgo.ChannelTypeGuildCategory, Position: 1},
+				{ID: "ch-001", Name: "general", Type: discordgo.ChannelTypeGuildText, ParentID: "cat-1"},
+				{ID: "ch-003", Name: "help", Type: discordgo.ChannelTypeGuildText, ParentID: "cat-2"},
+			}, nil
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	regs := channel.ChannelTools(client, r, "guild-1", safety.NewFilter(nil, []string{"staff"}), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_channel_tree")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_channel_tree", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	var got channel.ChannelTree
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	want := channel.ChannelTree{
+		Categories: []channel.ChannelCategory{
+			{ID: "cat-1", Name: "Text", Children: []channel.ChannelNode{
+				{ID: "ch-001", Name: "general", Type: "text"},
+			}},
+		},
+		Uncategorized: []channel.ChannelNode{
+			{ID: "ch-003", Name: "help", Type: "text"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tree = %+v, want %+v", got, want)
+	}
+}
+
+func Test_GetChannelTree_UsesRequestedGuildRules(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		GuildChannelsFunc: func(string, ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+			return []*discordgo.Channel{
+				{ID: "ch-001", Name: "general", Type: discordgo.ChannelTypeGuildText},
+				{ID: "ch-002", Name: "random", Type: discordgo.ChannelTypeGuildText, Position: 1},
+			}, nil
+		},
+	}
+	filters := safety.NewGuildFilters(safety.NewFilter(nil, []string{"random"}), map[string]*safety.Filter{
+		"guild-2": safety.NewFilter(nil, []string{"general"}),
+	})
+	regs := channel.ChannelTools(client, testutil.NewMockChannelResolver(), "guild-1", filters.For("guild-1"), nil, nil,
+		channel.WithGuildFilters(filters))
+	handler := testutil.FindHandler(t, regs, "discord_get_channel_tree")
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		wantName string
+		denied   string
+	}{
+		{name: "default guild", args: map[string]any{}, wantName: "general", denied: "random"},
+		{name: "other guild", args: map[string]any{"guild_id": "guild-2"}, wantName: "random", denied: "general"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_channel_tree", tt.args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			text := testutil.ExtractText(t, result)
+			if !strings.Contains(text, tt.wantName) || strings.Contains(text, tt.denied) {
+				t.Errorf("expected %s but not %s in tree, got: %s", tt.wantName, tt.denied, text)
+			}
+		})
+	}
+}
+
+func Test_GetChannelTree_DiscordError(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		GuildChannelsFunc: func(string, ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	regs := channel.ChannelTools(client, testutil.NewMockChannelResolver(), "guild-1", safety.NewFilter(nil, nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_channel_tree")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_channel_tree", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertTextContains(t, result, "boom")
+}
+
 // ---------------------------------------------------------------------------
 // discord_typing handler
 // ---------------------------------------------------------------------------
@@ -134,3 +337,449 @@ func Test_Typing_DeniedChannel(t *testing.T) {
 		t.Errorf("expected channel denied error, got: %s", text)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// discord_get_channel_permissions handler
+// ---------------------------------------------------------------------------
+
+func Test_GetChannelPermissions_Valid(t *testing.T) {
+	t.Parallel()
+	var gotUser, gotChannel string
+	client := &testutil.MockDiscordClient{
+		UserFunc: func(userID string, options ...discordgo.RequestOption) (*discordgo.User, error) {
+			return &discordgo.User{ID: "bot-001", Username: "claudebot"}, nil
+		},
+		UserChannelPermissionsFunc: func(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error) {
+			gotUser, gotChannel = userID, channelID
+			return discordgo.PermissionViewChannel | discordgo.PermissionSendMessages, nil
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+
+	regs := channel.ChannelTools(client, r, "test-guild-id", filter, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_channel_permissions")
+
+	req := testutil.NewCallToolRequest("discord_get_channel_permissions", map[string]any{
+		"channel": "general",
+	})
+
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	if gotUser != "bot-001" || gotChannel != "ch-001" {
+		t.Errorf("UserChannelPermissions(%q, %q), want (bot-001, ch-001)", gotUser, gotChannel)
+	}
+
+	var perms channel.ChannelPermissions
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &perms); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	want := []string{"ViewChannel", "SendMessages"}
+	if !reflect.DeepEqual(perms.Permissions, want) {
+		t.Errorf("Permissions = %v, want %v", perms.Permissions, want)
+	}
+	if perms.Channel != "general" {
+		t.Errorf("Channel = %q, want %q", perms.Channel, "general")
+	}
+}
+
+func Test_GetChannelPermissions_DeniedChannel(t *testing.T) {
+	t.Parallel()
+	client := &testutil.MockDiscordClient{}
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, []string{"general"})
+
+	regs := channel.ChannelTools(client, r, "test-guild-id", filter, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_channel_permissions")
+
+	req := testutil.NewCallToolRequest("discord_get_channel_permissions", map[string]any{
+		"channel": "general",
+	})
+
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected error result for denied channel, got: %s", testutil.ExtractText(t, result))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_resolver_dump handler
+// ---------------------------------------------------------------------------
+
+func Test_ResolverDump_ReturnsCache(t *testing.T) {
+	t.Parallel()
+	client := &testutil.MockDiscordClient{}
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+
+	regs := channel.ChannelTools(client, r, "test-guild-id", filter, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_resolver_dump")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_resolver_dump", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	var snap resolve.Snapshot
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &snap); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	if snap.GuildID != "mock-guild" {
+		t.Errorf("GuildID = %q, want %q", snap.GuildID, "mock-guild")
+	}
+	if !reflect.DeepEqual(snap.ByID, r.IDToName) || !reflect.DeepEqual(snap.ByName, r.NameToID) {
+		t.Errorf("snapshot = %+v, want the resolver's maps", snap)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_get_active_threads handler
+// ---------------------------------------------------------------------------
+
+func Test_GetActiveThreads_ListsAndFilters(t *testing.T) {
+	t.Parallel()
+
+	var gotGuild string
+	client := &testutil.MockDiscordClient{
+		GuildThreadsActiveFunc: func(guildID string, _ ...discordgo.RequestOption) (*discordgo.ThreadsList, error) {
+			gotGuild = guildID
+			return &discordgo.ThreadsList{Threads: []*discordgo.Channel{
+				{ID: "th-1", Name: "ticket-1", ParentID: "ch-001", MessageCount: 12, MemberCount: 3, ThreadMetadata: &discordgo.ThreadMetadata{}},
+				{ID: "th-2", Name: "ticket-2", ParentID: "ch-002", MessageCount: 4, MemberCount: 2},
+			}}, nil
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	// Threads under a channel the bot may not read are hidden.
+	filter := safety.NewFilter(nil, []string{"random"})
+	regs := channel.ChannelTools(client, r, "guild-1", filter, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_active_threads")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_active_threads", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+	if gotGuild != "guild-1" {
+		t.Errorf("GuildThreadsActive guildID = %q, want guild-1", gotGuild)
+	}
+
+	var got []channel.ThreadSummary
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	want := []channel.ThreadSummary{
+		{ID: "th-1", Name: "ticket-1", ParentID: "ch-001", Parent: "general", MessageCount: 12, MemberCount: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("threads = %+v, want %+v", got, want)
+	}
+}
+
+func Test_GetActiveThreads_IncludeArchived(t *testing.T) {
+	t.Parallel()
+
+	archivedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var gotChannel string
+	var gotLimit int
+	client := &testutil.MockDiscordClient{
+		GuildThreadsActiveFunc: func(string, ...discordgo.RequestOption) (*discordgo.ThreadsList, error) {
+			return &discordgo.ThreadsList{Threads: []*discordgo.Channel{
+				{ID: "th-1", Name: "open", ParentID: "ch-001"},
+				{ID: "th-2", Name: "elsewhere", ParentID: "ch-002"},
+			}}, nil
+		},
+		ThreadsArchivedFunc: func(channelID string, before *time.Time, limit int, _ ...discordgo.RequestOption) (*discordgo.ThreadsList, error) {
+			gotChannel, gotLimit = channelID, limit
+			return &discordgo.ThreadsList{Threads: []*discordgo.Channel{
+				{ID: "th-3", Name: "closed", ParentID: "ch-001", ThreadMetadata: &discordgo.ThreadMetadata{Archived: true, Locked: true, ArchiveTimestamp: archivedAt}},
+			}}, nil
+		},
+	}
+	regs := channel.ChannelTools(client, testutil.NewMockChannelResolver(), "guild-1", safety.NewFilter(nil, nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_active_threads")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_active_threads", map[string]any{
+		"channel":          "general",
+		"include_archived": true,
+		"archived_limit":   float64(10),
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+	if gotChannel != "ch-001" || gotLimit != 10 {
+		t.Errorf("ThreadsArchived(%q, %d), want (ch-001, 10)", gotChannel, gotLimit)
+	}
+
+	var got []channel.ThreadSummary
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	want := []channel.ThreadSummary{
+		{ID: "th-1", Name: "open", ParentID: "ch-001", Parent: "general"},
+		{ID: "th-3", Name: "closed", ParentID: "ch-001", Parent: "general", Archived: true, Locked: true, ArchivedAt: &archivedAt},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("threads = %+v, want %+v", got, want)
+	}
+}
+
+func Test_GetActiveThreads_InvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		args      map[string]any
+		wantInErr string
+	}{
+		{name: "archived without channel", args: map[string]any{"include_archived": true}, wantInErr: "include_archived requires channel"},
+		{name: "archived limit too large", args: map[string]any{"channel": "general", "include_archived": true, "archived_limit": float64(101)}, wantInErr: "archived_limit"},
+		{name: "denied channel", args: map[string]any{"channel": "random"}, wantInErr: "not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := &testutil.MockDiscordClient{
+				GuildThreadsActiveFunc: func(string, ...discordgo.RequestOption) (*discordgo.ThreadsList, error) {
+					t.Error("GuildThreadsActive should not be called")
+					return nil, errors.New("unexpected")
+				},
+			}
+			regs := channel.ChannelTools(client, testutil.NewMockChannelResolver(), "guild-1", safety.NewFilter(nil, []string{"random"}), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_get_active_threads")
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_active_threads", tt.args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+			testutil.AssertTextContains(t, result, tt.wantInErr)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_create_invite handler
+// ---------------------------------------------------------------------------
+
+func Test_CreateInvite_RequiresAllowInvites(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		ChannelInviteCreateFunc: func(string, discordgo.Invite, ...discordgo.RequestOption) (*discordgo.Invite, error) {
+			t.Error("ChannelInviteCreate should not be called")
+			return nil, errors.New("unexpected")
+		},
+	}
+	regs := channel.ChannelTools(client, testutil.NewMockChannelResolver(), "guild-1", safety.NewFilter(nil, nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_create_invite")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_create_invite", map[string]any{"channel": "general"}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if te := testutil.ToolError(t, result); te.Code != "INVALID_ARGUMENT" || !strings.Contains(te.Message, "safety.allow_invites") {
+		t.Errorf("error = %+v, want INVALID_ARGUMENT naming safety.allow_invites", te)
+	}
+}
+
+func Test_CreateInvite_ConfirmThenCreate(t *testing.T) {
+	t.Parallel()
+
+	var gotChannel string
+	var gotInvite discordgo.Invite
+	client := &testutil.MockDiscordClient{
+		ChannelInviteCreateFunc: func(channelID string, i discordgo.Invite, _ ...discordgo.RequestOption) (*discordgo.Invite, error) {
+			gotChannel, gotInvite = channelID, i
+			return &discordgo.Invite{Code: "abc123", Channel: &discordgo.Channel{ID: channelID}, MaxAge: i.MaxAge, MaxUses: i.MaxUses}, nil
+		},
+	}
+	confirm := safety.NewConfirmationTracker(channel.DestructiveToolNames())
+	regs := channel.ChannelTools(client, testutil.NewMockChannelResolver(), "guild-1", safety.NewFilter(nil, nil), nil, nil,
+		channel.WithInviteCreation(confirm),
+	)
+	handler := testutil.FindHandler(t, regs, "discord_create_invite")
+
+	args := map[string]any{"channel": "general", "max_age": float64(3600), "max_uses": float64(5)}
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_create_invite", args))
+	if err != nil {
+		t.Fatalf("first call error: %v", err)
+	}
+	prompt := testutil.ConfirmationRequest(t, result)
+	if prompt.Resource != "ch-001 max_age=3600 max_uses=5" {
+		t.Errorf("resource = %q, want the channel and limits", prompt.Resource)
+	}
+	if gotChannel != "" {
+		t.Fatal("invite created before confirmation")
+	}
+
+	// A token issued for these limits does not confirm different ones.
+	other := map[string]any{"channel": "general", "max_age": float64(0), "confirmation_token": prompt.ConfirmationToken}
+	result, err = handler(context.Background(), testutil.NewCallToolRequest("discord_create_invite", other))
+	if err != nil {
+		t.Fatalf("mismatched call error: %v", err)
+	}
+	if gotChannel != "" {
+		t.Fatal("invite created with a token for other limits")
+	}
+
+	args["confirmation_token"] = testutil.ConfirmationRequest(t, result).ConfirmationToken
+	args["max_age"], args["max_uses"] = float64(0), float64(0)
+	result, err = handler(context.Background(), testutil.NewCallToolRequest("discord_create_invite", args))
+	if err != nil {
+		t.Fatalf("confirmed call error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+	if gotChannel != "ch-001" || gotInvite.MaxAge != 0 || gotInvite.MaxUses != 0 {
+		t.Errorf("created invite on %q with %+v, want ch-001 with no limits", gotChannel, gotInvite)
+	}
+
+	var got channel.InviteSummary
+	if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &got); err != nil {
+		t.Fatalf("unmarshal invite: %v", err)
+	}
+	want := channel.InviteSummary{Code: "abc123", URL: "https://discord.gg/abc123", ChannelID: "ch-001", Channel: "general"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invite = %+v, want %+v", got, want)
+	}
+}
+
+func Test_CreateInvite_InvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		args      map[string]any
+		wantInErr string
+	}{
+		{name: "negative max_age", args: map[string]any{"channel": "general", "max_age": float64(-1)}, wantInErr: "max_age"},
+		{name: "max_age over a week", args: map[string]any{"channel": "general", "max_age": float64(604801)}, wantInErr: "max_age"},
+		{name: "max_uses over 100", args: map[string]any{"channel": "general", "max_uses": float64(101)}, wantInErr: "max_uses"},
+		{name: "denied channel", args: map[string]any{"channel": "random"}, wantInErr: "not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := &testutil.MockDiscordClient{
+				ChannelInviteCreateFunc: func(string, discordgo.Invite, ...discordgo.RequestOption) (*discordgo.Invite, error) {
+					t.Error("ChannelInviteCreate should not be called")
+					return nil, errors.New("unexpected")
+				},
+			}
+			confirm := safety.NewConfirmationTracker(nil, safety.WithConfirmationMode(safety.ConfirmOff))
+			regs := channel.ChannelTools(client, testutil.NewMockChannelResolver(), "guild-1", safety.NewFilter(nil, []string{"random"}), nil, nil,
+				channel.WithInviteCreation(confirm),
+			)
+			handler := testutil.FindHandler(t, regs, "discord_create_invite")
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_create_invite", tt.args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+			testutil.AssertTextContains(t, result, tt.wantInErr)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_get_invites handler
+// ---------------------------------------------------------------------------
+
+func Test_GetInvites_ListsAndFilters(t *testing.T) {
+	t.Parallel()
+
+	expires := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	var gotGuild string
+	client := &testutil.MockDiscordClient{
+		GuildInvitesFunc: func(guildID string, _ ...discordgo.RequestOption) ([]*discordgo.Invite, error) {
+			gotGuild = guildID
+			return []*discordgo.Invite{
+				{Code: "gen", Channel: &discordgo.Channel{ID: "ch-001"}, Inviter: &discordgo.User{Username: "alice"}, Uses: 3, MaxUses: 10, MaxAge: 86400, ExpiresAt: &expires},
+				{Code: "rnd", Channel: &discordgo.Channel{ID: "ch-002", Name: "random"}},
+			}, nil
+		},
+	}
+	regs := channel.ChannelTools(client, testutil.NewMockChannelResolver(), "guild-1", safety.NewFilter(nil, []string{"random"}), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_invites")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_invites", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+	if gotGuild != "guild-1" {
+		t.Errorf("guild = %q, want the default guild-1", gotGuild)
+	}
+
+	var got []channel.InviteSummary
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+		t.Fatalf("unmarshal invites: %v", err)
+	}
+	want := []channel.InviteSummary{{
+		Code: "gen", URL: "https://discord.gg/gen", ChannelID: "ch-001", Channel: "general",
+		Inviter: "alice", Uses: 3, MaxUses: 10, MaxAge: 86400, ExpiresAt: &expires,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invites = %+v, want %+v", got, want)
+	}
+}
+
+func Test_GetInvites_FiltersByIDAndName(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		GuildInvitesFunc: func(string, ...discordgo.RequestOption) ([]*discordgo.Invite, error) {
+			return []*discordgo.Invite{
+				{Code: "gen", Channel: &discordgo.Channel{ID: "ch-001"}},
+				{Code: "rnd", Channel: &discordgo.Channel{ID: "ch-002"}},
+				{Code: "unk", Channel: &discordgo.Channel{ID: "ch-999"}},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter *safety.Filter
+		want   []string
+	}{
+		{name: "denied by ID", filter: safety.NewFilter(nil, []string{"ch-002"}), want: []string{"gen", "unk"}},
+		{name: "allowed by ID", filter: safety.NewFilter([]string{"ch-002"}, nil), want: []string{"rnd"}},
+		{name: "allowlist drops unknown channel", filter: safety.NewFilter([]string{"*"}, nil), want: []string{"gen", "rnd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			regs := channel.ChannelTools(client, testutil.NewMockChannelResolver(), "guild-1", tt.filter, nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_get_invites")
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_invites", map[string]any{}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			var got []channel.InviteSummary
+			if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+				t.Fatalf("unmarshal invites: %v", err)
+			}
+			codes := make([]string, len(got))
+			for i, inv := range got {
+				codes[i] = inv.Code
+			}
+			if !reflect.DeepEqual(codes, tt.want) {
+				t.Errorf("invite codes = %v, want %v", codes, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package channel
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// treeChannelTypes names the channel types discord_get_channel_tree lists
+// under their category. Threads and categories themselves are not children.
+var treeChannelTypes = map[discordgo.ChannelType]string{
+	discordgo.ChannelTypeGuildText:       "text",
+	discordgo.ChannelTypeGuildNews:       "announcement",
+	discordgo.ChannelTypeGuildForum:      "forum",
+	discordgo.ChannelTypeGuildVoice:      "voice",
+	discordgo.ChannelTypeGuildStageVoice: "stage",
+}
+
+// ChannelTree is the response of discord_get_channel_tree. Categories and
+// each category's children are in Discord's display order; Uncategorized
+// holds channels with no parent category.
+type ChannelTree struct {
+	Categories    []ChannelCategory `json:"categories"`
+	Uncategorized []ChannelNode     `json:"uncategorized"`
+}
+
+// ChannelCategory is a guild category and the channels grouped under it.
+type ChannelCategory struct {
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Position int           `json:"position"`
+	Children []ChannelNode `json:"children"`
+}
+
+// ChannelNode is a text or voice channel within a ChannelTree.
+type ChannelNode struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Position int    `json:"position"`
+}
+
+func toolGetChannelTree(dg discord.DiscordClient, defaultGuildID string, filters *safety.GuildFilters, results tools.ResultLimit, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_get_channel_tree"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("List a guild's channels grouped under their categories, in display order, as a nested tree (category -> children). Includes text, announcement, forum, voice and stage channels; categories and channels the bot may not read are omitted."),
+		mcp.WithString("guild_id",
+			mcp.Description("Guild (server) ID (optional, uses default guild if omitted)"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		guildID := req.GetString("guild_id", "")
+		if guildID == "" {
+			guildID = defaultGuildID
+		}
+		params := map[string]any{"guild_id": guildID}
+
+		logger.DebugContext(ctx, "building channel tree", "guildID", guildID)
+
+		rawChannels, err := dg.GuildChannels(guildID, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		tree := buildChannelTree(rawChannels, func(ch *discordgo.Channel) bool {
+			return filters.IsAllowedFor(guildID, safety.OpRead, ch.Name)
+		})
+		count := len(tree.Uncategorized)
+		for _, cat := range tree.Categories {
+			count += len(cat.Children)
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d categories, %d channels", len(tree.Categories), count), start)
+		return results.JSONResult(tree), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// buildChannelTree groups channels under their ParentID category, keeping
+// only categories and the types in treeChannelTypes for which allowed
+// reports true. A channel whose parent is missing from channels or not
+// allowed counts as uncategorized.
+// Categories and children are sorted by position, then ID.
+func buildChannelTree(channels []*discordgo.Channel, allowed func(*discordgo.Channel) bool) ChannelTree {
+	tree := ChannelTree{
+		Categories:    []ChannelCategory{},
+		Uncategorized: []ChannelNode{},
+	}
+	index := make(map[string]int)
+	for _, ch := range channels {
+		if ch == nil || ch.Type != discordgo.ChannelTypeGuildCategory || !allowed(ch) {
+			continue
+		}
+		index[ch.ID] = len(tree.Categories)
+		tree.Categories = append(tree.Categories, ChannelCategory{
+			ID:       ch.ID,
+			Name:     ch.Name,
+			Position: ch.Position,
+			Children: []ChannelNode{},
+		})
+	}
+
+	for _, ch := range channels {
+		if ch == nil {
+			continue
+		}
+		typeName, ok := treeChannelTypes[ch.Type]
+		if !ok || !allowed(ch) {
+			continue
+		}
+		node := ChannelNode{ID: ch.ID, Name: ch.Name, Type: typeName, Position: ch.Position}
+		if i, ok := index[ch.ParentID]; ok {
+			tree.Categories[i].Children = append(tree.Categories[i].Children, node)
+			continue
+		}
+		tree.Uncategorized = append(tree.Uncategorized, node)
+	}
+
+	slices.SortFunc(tree.Categories, func(a, b ChannelCategory) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.ID, b.ID))
+	})
+	for i := range tree.Categories {
+		sortChannelNodes(tree.Categories[i].Children)
+	}
+	sortChannelNodes(tree.Uncategorized)
+	return tree
+}
+
+// sortChannelNodes orders nodes by position, then ID.
+func sortChannelNodes(nodes []ChannelNode) {
+	slices.SortFunc(nodes, func(a, b ChannelNode) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.ID, b.ID))
+	})
+}
@@ -0,0 +1,127 @@
+package channel
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+)
+
+// Typing indicator timings. Discord shows "typing…" for about ten seconds
+// after each ChannelTyping call, so a sustained indicator is refreshed a
+// little more often than that.
+const (
+	typingInterval     = 8 * time.Second
+	maxTypingDurationS = 120
+)
+
+// typer keeps typing indicators alive for a requested duration. Each channel
+// has at most one active loop; starting a new one replaces it.
+type typer struct {
+	shutdown context.Context
+	dg       discord.DiscordClient
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	active map[string]*typingLoop
+}
+
+// typingLoop is the handle for one background refresh loop.
+type typingLoop struct {
+	cancel context.CancelFunc
+}
+
+// newTyper returns a typer that refreshes indicators every interval until
+// shutdown is cancelled.
+func newTyper(shutdown context.Context, dg discord.DiscordClient, interval time.Duration, logger *slog.Logger) *typer {
+	return &typer{
+		shutdown: shutdown,
+		dg:       dg,
+		interval: interval,
+		logger:   logger,
+		active:   make(map[string]*typingLoop),
+	}
+}
+
+// start sends a typing indicator to channelID and, when d exceeds the
+// interval, keeps refreshing it in the background until d has elapsed. Any
+// loop already running for channelID is stopped first. The background loop
+// keeps ctx's values but not its cancellation, since the request that started
+// it returns immediately; it ends early when the typer's shutdown context is
+// cancelled.
+func (t *typer) start(ctx context.Context, channelID string, d time.Duration) error {
+	t.stop(channelID)
+
+	if err := t.dg.ChannelTyping(channelID, discordgo.WithContext(ctx)); err != nil {
+		return err
+	}
+	if d <= t.interval {
+		return nil
+	}
+
+	loopCtx, cancelTimeout := context.WithTimeout(context.WithoutCancel(ctx), d)
+	stopAfter := context.AfterFunc(t.shutdown, cancelTimeout)
+	l := &typingLoop{cancel: func() {
+		stopAfter()
+		cancelTimeout()
+	}}
+	t.mu.Lock()
+	prev := t.active[channelID]
+	t.active[channelID] = l
+	t.mu.Unlock()
+	// A concurrent start may have stored its loop since the stop above.
+	if prev != nil {
+		prev.cancel()
+	}
+
+	go t.loop(loopCtx, l, channelID)
+	return nil
+}
+
+// loop refreshes the indicator every interval until ctx ends or a refresh
+// fails, then releases its slot in t.active.
+func (t *typer) loop(ctx context.Context, l *typingLoop, channelID string) {
+	defer t.release(channelID, l)
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.dg.ChannelTyping(channelID, discordgo.WithContext(ctx)); err != nil {
+				if ctx.Err() == nil {
+					t.logger.Warn("typing indicator refresh failed", "channelID", channelID, "error", err)
+				}
+				return
+			}
+		}
+	}
+}
+
+// stop cancels the loop running for channelID, if any.
+func (t *typer) stop(channelID string) {
+	t.mu.Lock()
+	l := t.active[channelID]
+	delete(t.active, channelID)
+	t.mu.Unlock()
+	if l != nil {
+		l.cancel()
+	}
+}
+
+// release cancels a finished loop's context and removes it from t.active
+// unless a newer loop has already replaced it.
+func (t *typer) release(channelID string, l *typingLoop) {
+	l.cancel()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active[channelID] == l {
+		delete(t.active, channelID)
+	}
+}
@@ -0,0 +1,198 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/testutil"
+)
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return cond()
+}
+
+// activeLoops returns the number of running refresh loops.
+func activeLoops(ty *typer) int {
+	ty.mu.Lock()
+	defer ty.mu.Unlock()
+	return len(ty.active)
+}
+
+func Test_Typer_RefreshesUntilDuration(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	client := &testutil.MockDiscordClient{
+		ChannelTypingFunc: func(channelID string, options ...discordgo.RequestOption) error {
+			calls.Add(1)
+			return nil
+		},
+	}
+	ty := newTyper(context.Background(), client, 10*time.Millisecond, slog.Default())
+
+	if err := ty.start(context.Background(), "ch-001", 100*time.Millisecond); err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	if !waitFor(t, time.Second, func() bool { return activeLoops(ty) == 0 }) {
+		t.Fatal("typing loop still active after its duration")
+	}
+
+	got := calls.Load()
+	if got < 3 {
+		t.Errorf("ChannelTyping called %d times, want several", got)
+	}
+
+	// No further refreshes once the loop has ended.
+	time.Sleep(50 * time.Millisecond)
+	if after := calls.Load(); after != got {
+		t.Errorf("ChannelTyping called %d more times after the loop ended", after-got)
+	}
+}
+
+func Test_Typer_OutlivesRequestContext(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	client := &testutil.MockDiscordClient{
+		ChannelTypingFunc: func(channelID string, options ...discordgo.RequestOption) error {
+			calls.Add(1)
+			return nil
+		},
+	}
+	ty := newTyper(context.Background(), client, 10*time.Millisecond, slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := ty.start(ctx, "ch-001", 80*time.Millisecond); err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	cancel()
+
+	if !waitFor(t, time.Second, func() bool { return calls.Load() >= 3 }) {
+		t.Errorf("ChannelTyping called %d times after the request ended, want refreshes to continue", calls.Load())
+	}
+}
+
+func Test_Typer_StopsOnShutdown(t *testing.T) {
+	t.Parallel()
+
+	shutdown, cancel := context.WithCancel(context.Background())
+	ty := newTyper(shutdown, &testutil.MockDiscordClient{}, 10*time.Millisecond, slog.Default())
+
+	if err := ty.start(context.Background(), "ch-001", time.Minute); err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	cancel()
+	if !waitFor(t, time.Second, func() bool { return activeLoops(ty) == 0 }) {
+		t.Fatal("typing loop still active after shutdown")
+	}
+}
+
+func Test_Typer_NewCallReplacesLoop(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	ty := newTyper(context.Background(), client, 10*time.Millisecond, slog.Default())
+
+	if err := ty.start(context.Background(), "ch-001", time.Minute); err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	if n := activeLoops(ty); n != 1 {
+		t.Fatalf("active loops = %d, want 1", n)
+	}
+
+	// A plain indicator stops the long-running loop.
+	if err := ty.start(context.Background(), "ch-001", 0); err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	if n := activeLoops(ty); n != 0 {
+		t.Errorf("active loops = %d after replacement, want 0", n)
+	}
+}
+
+func Test_Typer_ConcurrentStartsLeaveOneLoop(t *testing.T) {
+	t.Parallel()
+
+	// The first indicator of each start waits until every start has sent
+	// one, so all of them have passed their initial stop before any stores
+	// its loop.
+	const starts = 8
+	var calls atomic.Int32
+	allSent := make(chan struct{})
+	client := &testutil.MockDiscordClient{
+		ChannelTypingFunc: func(channelID string, options ...discordgo.RequestOption) error {
+			n := calls.Add(1)
+			if n == starts {
+				close(allSent)
+			}
+			if n <= starts {
+				<-allSent
+			}
+			return nil
+		},
+	}
+	ty := newTyper(context.Background(), client, 10*time.Millisecond, slog.Default())
+
+	var wg sync.WaitGroup
+	for range starts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ty.start(context.Background(), "ch-001", time.Minute); err != nil {
+				t.Errorf("start error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := activeLoops(ty); n != 1 {
+		t.Fatalf("active loops = %d, want 1", n)
+	}
+
+	// Stopping the surviving loop must leave nothing refreshing.
+	ty.stop("ch-001")
+	time.Sleep(30 * time.Millisecond)
+	before := calls.Load()
+	time.Sleep(50 * time.Millisecond)
+	if after := calls.Load(); after != before {
+		t.Errorf("ChannelTyping called %d more times after stop, want replaced loops cancelled", after-before)
+	}
+}
+
+func Test_Typer_StopsOnError(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	client := &testutil.MockDiscordClient{
+		ChannelTypingFunc: func(channelID string, options ...discordgo.RequestOption) error {
+			if calls.Add(1) > 1 {
+				return errors.New("missing access")
+			}
+			return nil
+		},
+	}
+	ty := newTyper(context.Background(), client, 10*time.Millisecond, slog.Default())
+
+	if err := ty.start(context.Background(), "ch-001", time.Minute); err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	if !waitFor(t, time.Second, func() bool { return activeLoops(ty) == 0 }) {
+		t.Fatal("typing loop still active after a failed refresh")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("ChannelTyping called %d times, want 2", got)
+	}
+}
@@ -2,46 +2,302 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"net"
 	"os"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
-// ServerConfig holds network and authentication settings.
+// ServerConfig holds network and authentication settings. StrictArguments
+// rejects tool calls that pass parameters the tool does not declare.
+// ReadHeaderTimeoutSec and IdleTimeoutSec tune the HTTP server; zero values
+// fall back to 10 and 120 seconds. TLS serves HTTPS in-process when set.
+// CORS lets browser-based clients call the endpoint. Host restricts the
+// HTTP listener to one interface, e.g. "127.0.0.1"; empty listens on all.
+// Clients adds labelled bearer tokens next to AuthToken, one per client, so
+// the audit log records which client made each call. Stdio is not read from
+// the file; it is set from the --stdio flag so Validate can reject settings
+// that would write to the protocol stream.
 type ServerConfig struct {
-	Port      int    `yaml:"port"`
-	AuthToken string `yaml:"auth_token"`
+	Host                 string         `yaml:"host"`
+	Port                 int            `yaml:"port"`
+	AuthToken            string         `yaml:"auth_token"`
+	Clients              []ClientConfig `yaml:"clients"`
+	StrictArguments      bool           `yaml:"strict_arguments"`
+	ReadHeaderTimeoutSec int            `yaml:"read_header_timeout_sec"`
+	IdleTimeoutSec       int            `yaml:"idle_timeout_sec"`
+	TLS                  TLSConfig      `yaml:"tls"`
+	CORS                 CORSConfig     `yaml:"cors"`
+	Stdio                bool           `yaml:"-"`
+}
+
+// ClientConfig is a bearer token accepted over HTTP and the label recorded
+// in audit entries for calls made with it. An empty Label records a short
+// hash of the token instead.
+type ClientConfig struct {
+	Label string `yaml:"label"`
+	Token string `yaml:"token"`
+}
+
+// ListenAddr returns the address the HTTP server listens on, built from Host
+// and Port. It returns an error if Port is outside 0-65535 or Host is not a
+// bare hostname or IP address (e.g. it includes a port or scheme).
+func (c ServerConfig) ListenAddr() (string, error) {
+	if c.Port < 0 || c.Port > 65535 {
+		return "", fmt.Errorf("server.port %d out of range 0-65535", c.Port)
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(c.Host, "["), "]")
+	if strings.ContainsAny(host, "/ ") || (strings.Contains(host, ":") && net.ParseIP(host) == nil) {
+		return "", fmt.Errorf("server.host %q must be a hostname or IP address without a port", c.Host)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(c.Port)), nil
+}
+
+// CORSConfig controls CORS headers on the MCP endpoint. CORS is disabled
+// when AllowedOrigins is empty. Empty AllowedMethods and AllowedHeaders use
+// the methods and headers the MCP transport needs.
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	AllowedMethods []string `yaml:"allowed_methods"`
+	AllowedHeaders []string `yaml:"allowed_headers"`
+}
+
+// TLSConfig holds the certificate and private key paths for serving HTTPS.
+// Both must be set to enable TLS; both empty serves plain HTTP.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+// Enabled reports whether TLS is configured.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// Validate returns an error if only one of CertFile and KeyFile is set.
+func (c TLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("server.tls: cert_file and key_file must be set together")
+	}
+	return nil
 }
 
 // DiscordConfig holds Discord bot credentials and guild targeting.
+// VerifyNumericChannelIDs resolves an all-digit channel parameter as a
+// channel name when it is not a known channel ID. Intents lists the gateway
+// intents to request by name (e.g. "message_content"); empty uses the
+// built-in default set. DefaultChannel, when set, is the channel name or ID
+// used by the send, get-messages, typing and reaction tools when their
+// channel parameter is omitted. Presence sets the status and activity shown
+// from the first connect. APIVersion pins the Discord API version (e.g.
+// "10") and UserAgent replaces the REST User-Agent header; empty values keep
+// discordgo's defaults. IdleDisconnectSec closes the gateway after that many
+// seconds with no incoming events and no tool calls, reopening it on the next
+// tool call; zero keeps it always connected.
 type DiscordConfig struct {
-	Token   string `yaml:"token"`
-	GuildID string `yaml:"guild_id"`
+	Token                   string         `yaml:"token"`
+	GuildID                 string         `yaml:"guild_id"`
+	Retry                   RetryConfig    `yaml:"retry"`
+	CircuitBreaker          BreakerConfig  `yaml:"circuit_breaker"`
+	VerifyNumericChannelIDs bool           `yaml:"verify_numeric_channel_ids"`
+	Intents                 []string       `yaml:"intents"`
+	DefaultChannel          string         `yaml:"default_channel"`
+	Presence                PresenceConfig `yaml:"presence"`
+	APIVersion              string         `yaml:"api_version"`
+	UserAgent               string         `yaml:"user_agent"`
+	IdleDisconnectSec       int            `yaml:"idle_disconnect_sec"`
+}
+
+// PresenceConfig is the bot's presence at startup. Status is online, idle,
+// dnd or invisible (empty means online). ActivityType is playing, streaming,
+// listening, watching, custom or competing (empty means watching) and
+// ActivityName is its text; an empty ActivityName shows no activity.
+type PresenceConfig struct {
+	Status       string `yaml:"status"`
+	ActivityType string `yaml:"activity_type"`
+	ActivityName string `yaml:"activity_name"`
+}
+
+// RetryConfig controls retries of Discord REST calls that fail with a 5xx
+// response or a network error. MaxAttempts counts the first attempt; set it
+// to 1 to disable retries. Zero values fall back to 3 attempts and 200ms.
+type RetryConfig struct {
+	MaxAttempts int `yaml:"max_attempts"`
+	BaseDelayMs int `yaml:"base_delay_ms"`
+}
+
+// BreakerConfig controls the circuit breaker around Discord REST calls.
+// After FailureThreshold consecutive calls fail with a 5xx response, a
+// network error or a timeout, tool calls fail immediately for CooldownSec
+// before one trial call is let through. Zero values fall back to 5 failures
+// and 30 seconds; a negative FailureThreshold disables the breaker.
+type BreakerConfig struct {
+	FailureThreshold int `yaml:"failure_threshold"`
+	CooldownSec      int `yaml:"cooldown_sec"`
 }
 
 // QueueConfig controls the internal message queue behaviour.
+// PollTimeoutSec is the long-poll duration used when a client omits
+// timeout_seconds; MaxPollTimeoutSec caps any requested duration. Zero
+// values fall back to the built-in defaults. DedupWindow is the number of
+// recent message IDs remembered to drop duplicate deliveries; zero disables
+// deduplication. PriorityLanes delivers messages that mention the bot ahead
+// of ordinary chatter. OverflowPolicy is "drop" (default) or "block";
+// BlockTimeoutSec bounds how long a full queue stalls ingestion under "block".
+// ReplyContextLength, when positive, attaches the author and up to that many
+// characters of a replied-to message to each queued reply.
+// TypingEvents enqueues a "typing" event whenever a user starts typing.
+// ExpandMentions rewrites mention tokens in queued content as readable names.
+// PerChannelMax, when positive, caps how many queued messages one channel
+// may hold so a busy channel cannot evict the others' messages.
+// CommandPrefix, when set, parses messages starting with it (e.g. "!") into
+// a command name and arguments on each queued message.
+// AckTimeoutSec, when positive, enables acknowledgment mode: polled messages
+// carry a delivery token and are redelivered unless acknowledged within that
+// many seconds. PollKeepaliveSec, when positive, sends a keepalive
+// notification that often while an HTTP long poll waits, so proxies with
+// idle timeouts do not cut it.
 type QueueConfig struct {
-	MaxSize int `yaml:"max_size"`
+	MaxSize            int    `yaml:"max_size"`
+	PollTimeoutSec     int    `yaml:"poll_timeout_sec"`
+	MaxPollTimeoutSec  int    `yaml:"max_poll_timeout_sec"`
+	PollKeepaliveSec   int    `yaml:"poll_keepalive_sec"`
+	DedupWindow        int    `yaml:"dedup_window"`
+	PriorityLanes      bool   `yaml:"priority_lanes"`
+	OverflowPolicy     string `yaml:"overflow_policy"`
+	BlockTimeoutSec    int    `yaml:"block_timeout_sec"`
+	ReplyContextLength int    `yaml:"reply_context_length"`
+	TypingEvents       bool   `yaml:"typing_events"`
+	ExpandMentions     bool   `yaml:"expand_mentions"`
+	CommandPrefix      string `yaml:"command_prefix"`
+	PerChannelMax      int    `yaml:"per_channel_max"`
+	AckTimeoutSec      int    `yaml:"ack_timeout_sec"`
+}
+
+// ToolsConfig selects which MCP tools are registered. When Enabled is
+// non-empty only the listed tools are registered; tools in Disabled are
+// never registered. TimingMeta adds a _meta block with the tool name and
+// duration_ms to every tool result. MaxResultItems caps how many elements of
+// a list result are returned; zero uses the default of 500 and a negative
+// value disables the cap. MaxAttachmentBytes caps the file size
+// discord_download_attachment returns; zero uses the default of 8 MiB.
+type ToolsConfig struct {
+	Enabled            []string `yaml:"enabled"`
+	Disabled           []string `yaml:"disabled"`
+	TimingMeta         bool     `yaml:"timing_meta"`
+	MaxResultItems     int      `yaml:"max_result_items"`
+	MaxAttachmentBytes int64    `yaml:"max_attachment_bytes"`
 }
 
 // ChannelFilter holds allowlist and denylist entries for Discord channel filtering.
+// Matching is case-insensitive unless CaseSensitive is set. Read and Write
+// add lists that apply only to reading or writing tools, on top of the
+// shared lists, so a channel can be readable but not writable. Guilds maps a
+// guild ID to rules that replace these for that guild; nested Guilds entries
+// are ignored.
 type ChannelFilter struct {
+	Allowlist     []string                 `yaml:"allowlist"`
+	Denylist      []string                 `yaml:"denylist"`
+	CaseSensitive bool                     `yaml:"case_sensitive"`
+	Read          ChannelAccess            `yaml:"read"`
+	Write         ChannelAccess            `yaml:"write"`
+	Guilds        map[string]ChannelFilter `yaml:"guilds"`
+}
+
+// ChannelAccess holds allowlist and denylist entries for one kind of channel
+// operation.
+type ChannelAccess struct {
 	Allowlist []string `yaml:"allowlist"`
 	Denylist  []string `yaml:"denylist"`
 }
 
-// SafetyConfig groups channel filters and destructive tool declarations.
+// UserFilter holds allowlist and denylist entries for message authors. Each
+// entry is matched against both the author's user ID and username.
+type UserFilter struct {
+	Allowlist     []string `yaml:"allowlist"`
+	Denylist      []string `yaml:"denylist"`
+	CaseSensitive bool     `yaml:"case_sensitive"`
+}
+
+// ContentFilter holds keyword and regex rules applied to incoming message
+// content. Plain entries are substring matches and "re:" entries are regular
+// expressions. When AllowMentions is set, messages that mention the bot pass
+// the allowlist regardless of content.
+type ContentFilter struct {
+	Allowlist     []string `yaml:"allowlist"`
+	Denylist      []string `yaml:"denylist"`
+	AllowMentions bool     `yaml:"allow_mentions"`
+	CaseSensitive bool     `yaml:"case_sensitive"`
+}
+
+// MentionConfig limits who outgoing messages may ping, regardless of the
+// per-call options. DenyEveryone blocks @everyone and @here; a non-empty
+// AllowedRoles lists the only role IDs that may be pinged; a positive
+// MaxUserMentions caps the users pinged per message.
+type MentionConfig struct {
+	DenyEveryone    bool     `yaml:"deny_everyone"`
+	AllowedRoles    []string `yaml:"allowed_roles"`
+	MaxUserMentions int      `yaml:"max_user_mentions"`
+}
+
+// SafetyConfig groups channel, user and content filters, the outbound mention
+// policy and destructive tool declarations.
+// DestructiveTools lists additional tool names that require a confirmation
+// token; they are merged with the built-in destructive tools at startup.
+// AllowModeration enables tools that change other users' content, such as
+// removing another user's reaction. AllowInvites enables
+// discord_create_invite, which lets anyone with the link join the server.
+// AllowLogLevelChange enables discord_set_log_level; at debug level the
+// server logs every tool call's arguments and results.
 type SafetyConfig struct {
-	Channels ChannelFilter `yaml:"channels"`
+	Channels            ChannelFilter `yaml:"channels"`
+	Users               UserFilter    `yaml:"users"`
+	Content             ContentFilter `yaml:"content"`
+	Mentions            MentionConfig `yaml:"mentions"`
+	DestructiveTools    []string      `yaml:"destructive_tools"`
+	AllowModeration     bool          `yaml:"allow_moderation"`
+	AllowInvites        bool          `yaml:"allow_invites"`
+	AllowLogLevelChange bool          `yaml:"allow_log_level_change"`
 }
 
-// AuditConfig controls audit logging behaviour.
+// ConfirmationConfig selects how destructive tool calls are confirmed. Mode
+// is "token" (the default two-step confirmation_token flow), "boolean"
+// (accept confirm: true, trusting the client to have the user's approval)
+// or "off" (no confirmation).
+type ConfirmationConfig struct {
+	Mode string `yaml:"mode"`
+}
+
+// AuditConfig controls audit logging behaviour. Format is "json" (the
+// default, one JSON object per line) or "logfmt".
 type AuditConfig struct {
 	Enabled bool   `yaml:"enabled"`
 	LogPath string `yaml:"log_path"`
+	Format  string `yaml:"format"`
+}
+
+// WritesStdout reports whether audit entries go to standard output, which
+// LogPath selects with "-" or "stdout".
+func (c AuditConfig) WritesStdout() bool {
+	switch strings.ToLower(c.LogPath) {
+	case "-", "stdout":
+		return c.Enabled
+	}
+	return false
+}
+
+// TelemetryConfig controls OpenTelemetry span export. Leaving OTLPEndpoint
+// empty disables export.
+type TelemetryConfig struct {
+	OTLPEndpoint string `yaml:"otlp_endpoint"`
+	Insecure     bool   `yaml:"insecure"`
+	ServiceName  string `yaml:"service_name"`
 }
 
 // LoggingConfig controls structured log output.
@@ -51,12 +307,15 @@ type LoggingConfig struct {
 
 // Config is the top-level configuration structure for the claudebot-mcp server.
 type Config struct {
-	Server  ServerConfig  `yaml:"server"`
-	Discord DiscordConfig `yaml:"discord"`
-	Queue   QueueConfig   `yaml:"queue"`
-	Safety  SafetyConfig  `yaml:"safety"`
-	Audit   AuditConfig   `yaml:"audit"`
-	Logging LoggingConfig `yaml:"logging"`
+	Server       ServerConfig       `yaml:"server"`
+	Discord      DiscordConfig      `yaml:"discord"`
+	Queue        QueueConfig        `yaml:"queue"`
+	Safety       SafetyConfig       `yaml:"safety"`
+	Tools        ToolsConfig        `yaml:"tools"`
+	Audit        AuditConfig        `yaml:"audit"`
+	Confirmation ConfirmationConfig `yaml:"confirmation"`
+	Telemetry    TelemetryConfig    `yaml:"telemetry"`
+	Logging      LoggingConfig      `yaml:"logging"`
 }
 
 // LoadConfig reads and parses a YAML configuration file from the given path.
@@ -82,6 +341,8 @@ func LoadConfig(path string) (*Config, error) {
 // Defaults:
 //   - Server.Port = 8080
 //   - Queue.MaxSize = 1000
+//   - Queue.PollTimeoutSec = 30
+//   - Queue.MaxPollTimeoutSec = 300
 //   - Audit.Enabled = true
 //   - Audit.LogPath = "audit.log"
 //   - Logging.Level = "info"
@@ -91,7 +352,9 @@ func DefaultConfig() *Config {
 			Port: 8080,
 		},
 		Queue: QueueConfig{
-			MaxSize: 1000,
+			MaxSize:           1000,
+			PollTimeoutSec:    30,
+			MaxPollTimeoutSec: 300,
 		},
 		Audit: AuditConfig{
 			Enabled: true,
@@ -105,40 +368,164 @@ func DefaultConfig() *Config {
 
 // ApplyEnvOverrides updates cfg in place with values from environment variables.
 // Only non-empty environment variable values override existing config values.
+// It returns the names of the variables that were applied.
 //
 // Recognized variables:
 //   - CLAUDEBOT_DISCORD_TOKEN  -> cfg.Discord.Token
 //   - CLAUDEBOT_DISCORD_GUILD_ID -> cfg.Discord.GuildID
 //   - CLAUDEBOT_AUTH_TOKEN -> cfg.Server.AuthToken
-func ApplyEnvOverrides(cfg *Config) {
-	if token := os.Getenv("CLAUDEBOT_DISCORD_TOKEN"); token != "" {
-		cfg.Discord.Token = token
+//   - CLAUDEBOT_LOG_LEVEL -> cfg.Logging.Level
+func ApplyEnvOverrides(cfg *Config) []string {
+	overrides := []struct {
+		env   string
+		field *string
+	}{
+		{"CLAUDEBOT_DISCORD_TOKEN", &cfg.Discord.Token},
+		{"CLAUDEBOT_DISCORD_GUILD_ID", &cfg.Discord.GuildID},
+		{"CLAUDEBOT_AUTH_TOKEN", &cfg.Server.AuthToken},
+		{"CLAUDEBOT_LOG_LEVEL", &cfg.Logging.Level},
+	}
+	var applied []string
+	for _, o := range overrides {
+		if v := os.Getenv(o.env); v != "" {
+			*o.field = v
+			applied = append(applied, o.env)
+		}
+	}
+	return applied
+}
+
+// Source describes where Load took its configuration from.
+type Source string
+
+// Source values returned by Load.
+const (
+	// SourceFile means the config file was read; environment variables may
+	// still have overridden some of its values.
+	SourceFile Source = "file"
+	// SourceEnv means there was no config file and environment variables
+	// supplied values on top of the defaults.
+	SourceEnv Source = "env"
+	// SourceDefaults means there was no config file and no environment
+	// overrides.
+	SourceDefaults Source = "defaults"
+)
+
+// Load reads the config file at path and applies environment overrides. A
+// missing file is not an error: DefaultConfig is used instead, so the server
+// can be configured from environment variables alone. A file that exists but
+// cannot be read or parsed is an error. Load returns the config, where it
+// came from, and the names of the environment variables applied.
+//
+// Load does not check required fields; call ValidateRequired once the result
+// has been logged.
+func Load(path string) (*Config, Source, []string, error) {
+	source := SourceFile
+	cfg, err := LoadConfig(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		cfg, source = DefaultConfig(), SourceDefaults
+	} else if err != nil {
+		return nil, "", nil, err
+	}
+
+	applied := ApplyEnvOverrides(cfg)
+	if source == SourceDefaults && len(applied) > 0 {
+		source = SourceEnv
+	}
+	return cfg, source, applied, nil
+}
+
+// ValidateRequired returns an error naming every required setting that is
+// still empty, together with the environment variable that can supply it.
+func (c *Config) ValidateRequired() error {
+	var missing []string
+	if c.Discord.Token == "" {
+		missing = append(missing, "discord.token (CLAUDEBOT_DISCORD_TOKEN)")
+	}
+	if c.Discord.GuildID == "" {
+		missing = append(missing, "discord.guild_id (CLAUDEBOT_DISCORD_GUILD_ID)")
 	}
-	if guildID := os.Getenv("CLAUDEBOT_DISCORD_GUILD_ID"); guildID != "" {
-		cfg.Discord.GuildID = guildID
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required config: %s", strings.Join(missing, ", "))
 	}
-	if authToken := os.Getenv("CLAUDEBOT_AUTH_TOKEN"); authToken != "" {
-		cfg.Server.AuthToken = authToken
+	return nil
+}
+
+// Validate checks the settings the server cannot start without: the
+// required fields, the TLS pair, the listen address, the HTTP clients, the
+// Discord API version, the queue overflow policy, the audit format and
+// destination, and the confirmation mode. It returns every problem found,
+// joined, or nil.
+//
+// Settings compiled by other packages, such as filters and intents, are
+// checked where they are built.
+func (c *Config) Validate() error {
+	errs := []error{c.ValidateRequired(), c.Server.TLS.Validate()}
+	if _, err := c.Server.ListenAddr(); err != nil {
+		errs = append(errs, err)
+	}
+	if v := c.Discord.APIVersion; v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+			errs = append(errs, fmt.Errorf("discord.api_version %q must be a positive number", v))
+		}
+	}
+	switch c.Queue.OverflowPolicy {
+	case "", "drop", "block":
+	default:
+		errs = append(errs, fmt.Errorf("queue.overflow_policy %q must be drop or block", c.Queue.OverflowPolicy))
+	}
+	seenLabels := make(map[string]bool, len(c.Server.Clients))
+	for i, client := range c.Server.Clients {
+		if client.Token == "" {
+			errs = append(errs, fmt.Errorf("server.clients[%d]: token must not be empty", i))
+		}
+		if client.Label != "" && seenLabels[client.Label] {
+			errs = append(errs, fmt.Errorf("server.clients[%d]: label %q is used twice", i, client.Label))
+		}
+		seenLabels[client.Label] = true
+	}
+	switch c.Audit.Format {
+	case "", "json", "logfmt":
+	default:
+		errs = append(errs, fmt.Errorf("audit.format %q must be json or logfmt", c.Audit.Format))
 	}
-	if level := os.Getenv("CLAUDEBOT_LOG_LEVEL"); level != "" {
-		cfg.Logging.Level = level
+	if c.Server.Stdio && c.Audit.WritesStdout() {
+		// In stdio mode stdout carries the MCP JSON-RPC stream.
+		errs = append(errs, fmt.Errorf("audit.log_path %q writes to stdout, which carries the protocol in stdio mode; use stderr or a file", c.Audit.LogPath))
 	}
+	switch c.Confirmation.Mode {
+	case "", "token", "boolean", "off":
+	default:
+		errs = append(errs, fmt.Errorf("confirmation.mode %q must be token, boolean or off", c.Confirmation.Mode))
+	}
+	return errors.Join(errs...)
 }
 
 // ParseLogLevel converts a logging level string to the corresponding slog.Level.
-// Recognized values (case-insensitive): "debug", "info", "warn"/"warning", "error".
-// Unrecognized values default to slog.LevelInfo.
+// Recognized values (case-insensitive, surrounding spaces ignored): "debug",
+// "info", "warn"/"warning", "error". Unrecognized values default to
+// slog.LevelInfo; use LookupLogLevel to detect them.
 func ParseLogLevel(level string) slog.Level {
-	switch strings.ToLower(level) {
+	l, ok := LookupLogLevel(level)
+	if !ok {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+// LookupLogLevel is like ParseLogLevel but reports whether level was
+// recognized instead of defaulting.
+func LookupLogLevel(level string) (slog.Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
 	case "debug":
-		return slog.LevelDebug
+		return slog.LevelDebug, true
 	case "info":
-		return slog.LevelInfo
+		return slog.LevelInfo, true
 	case "warn", "warning":
-		return slog.LevelWarn
+		return slog.LevelWarn, true
 	case "error":
-		return slog.LevelError
+		return slog.LevelError, true
 	default:
-		return slog.LevelInfo
+		return slog.LevelInfo, false
 	}
 }
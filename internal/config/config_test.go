@@ -3,7 +3,9 @@ package config
 import (
 	"log/slog"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -55,6 +57,12 @@ func Test_LoadConfig_ValidYAML(t *testing.T) {
 	if cfg.Queue.MaxSize != 500 {
 		t.Errorf("Queue.MaxSize = %d, want 500", cfg.Queue.MaxSize)
 	}
+	if cfg.Queue.PollTimeoutSec != 45 {
+		t.Errorf("Queue.PollTimeoutSec = %d, want 45", cfg.Queue.PollTimeoutSec)
+	}
+	if cfg.Queue.MaxPollTimeoutSec != 120 {
+		t.Errorf("Queue.MaxPollTimeoutSec = %d, want 120", cfg.Queue.MaxPollTimeoutSec)
+	}
 	// Verify audit section
 	if !cfg.Audit.Enabled {
 		t.Error("Audit.Enabled = false, want true")
@@ -140,6 +148,170 @@ func Test_LoadConfig_MinimalYAML(t *testing.T) {
 	}
 }
 
+func Test_LoadConfig_DestructiveTools(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(testdataDir(t), "destructive_tools.yaml")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig(%q) unexpected error: %v", path, err)
+	}
+	want := []string{"discord_edit_message"}
+	if len(cfg.Safety.DestructiveTools) != len(want) {
+		t.Fatalf("Safety.DestructiveTools = %v, want %v", cfg.Safety.DestructiveTools, want)
+	}
+	for i := range want {
+		if cfg.Safety.DestructiveTools[i] != want[i] {
+			t.Errorf("Safety.DestructiveTools[%d] = %q, want %q", i, cfg.Safety.DestructiveTools[i], want[i])
+		}
+	}
+}
+
+func Test_LoadConfig_ServerTLS(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(testdataDir(t), "server_tls.yaml")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig(%q) unexpected error: %v", path, err)
+	}
+	if cfg.Server.ReadHeaderTimeoutSec != 5 {
+		t.Errorf("Server.ReadHeaderTimeoutSec = %d, want 5", cfg.Server.ReadHeaderTimeoutSec)
+	}
+	if cfg.Server.IdleTimeoutSec != 60 {
+		t.Errorf("Server.IdleTimeoutSec = %d, want 60", cfg.Server.IdleTimeoutSec)
+	}
+	want := TLSConfig{CertFile: "/etc/claudebot/tls.crt", KeyFile: "/etc/claudebot/tls.key"}
+	if cfg.Server.TLS != want {
+		t.Errorf("Server.TLS = %+v, want %+v", cfg.Server.TLS, want)
+	}
+	if !cfg.Server.TLS.Enabled() {
+		t.Error("Server.TLS.Enabled() = false, want true")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TLSConfig.Validate
+// ---------------------------------------------------------------------------
+
+func Test_TLSConfig_Validate_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tls     TLSConfig
+		wantErr bool
+	}{
+		{name: "neither set", tls: TLSConfig{}, wantErr: false},
+		{name: "both set", tls: TLSConfig{CertFile: "c.pem", KeyFile: "k.pem"}, wantErr: false},
+		{name: "cert only", tls: TLSConfig{CertFile: "c.pem"}, wantErr: true},
+		{name: "key only", tls: TLSConfig{KeyFile: "k.pem"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.tls.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_Config_Validate_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		mutate   func(*Config)
+		wantErrs []string
+	}{
+		{name: "valid", mutate: func(*Config) {}},
+		{name: "block overflow", mutate: func(c *Config) { c.Queue.OverflowPolicy = "block" }},
+		{name: "logfmt audit", mutate: func(c *Config) { c.Audit.Format = "logfmt" }},
+		{name: "pinned api version", mutate: func(c *Config) { c.Discord.APIVersion = "10" }},
+		{name: "boolean confirmation", mutate: func(c *Config) { c.Confirmation.Mode = "boolean" }},
+		{name: "missing token", mutate: func(c *Config) { c.Discord.Token = "" }, wantErrs: []string{"discord.token"}},
+		{name: "stdout audit over http", mutate: func(c *Config) { c.Audit.LogPath = "stdout" }},
+		{name: "stderr audit over stdio", mutate: func(c *Config) { c.Server.Stdio = true; c.Audit.LogPath = "stderr" }},
+		{name: "disabled stdout audit over stdio", mutate: func(c *Config) { c.Server.Stdio = true; c.Audit.Enabled = false; c.Audit.LogPath = "-" }},
+		{name: "stdout audit over stdio", mutate: func(c *Config) { c.Server.Stdio = true; c.Audit.LogPath = "-" }, wantErrs: []string{"audit.log_path", "stdio mode"}},
+		{
+			name: "every problem reported",
+			mutate: func(c *Config) {
+				c.Discord.GuildID = ""
+				c.Server.TLS.KeyFile = "k.pem"
+				c.Server.Port = 70000
+				c.Queue.OverflowPolicy = "spill"
+				c.Audit.Format = "xml"
+				c.Server.Clients = []ClientConfig{{Label: "a", Token: "t1"}, {Label: "a"}}
+				c.Discord.APIVersion = "v10"
+				c.Confirmation.Mode = "ask"
+			},
+			wantErrs: []string{"confirmation.mode", "discord.api_version", "discord.guild_id", "server.tls", "server.port", "queue.overflow_policy", "audit.format", "server.clients[1]: token", `label "a" is used twice`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := DefaultConfig()
+			cfg.Discord.Token = "token"
+			cfg.Discord.GuildID = "guild"
+			tt.mutate(cfg)
+
+			err := cfg.Validate()
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate() = nil, want an error")
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %q, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_ServerConfig_ListenAddr_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     ServerConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "no host listens on all interfaces", cfg: ServerConfig{Port: 8080}, want: ":8080"},
+		{name: "loopback", cfg: ServerConfig{Host: "127.0.0.1", Port: 8080}, want: "127.0.0.1:8080"},
+		{name: "hostname", cfg: ServerConfig{Host: "localhost", Port: 9000}, want: "localhost:9000"},
+		{name: "ipv6", cfg: ServerConfig{Host: "::1", Port: 8080}, want: "[::1]:8080"},
+		{name: "bracketed ipv6", cfg: ServerConfig{Host: "[::1]", Port: 8080}, want: "[::1]:8080"},
+		{name: "host with port", cfg: ServerConfig{Host: "127.0.0.1:9000", Port: 8080}, wantErr: true},
+		{name: "host with scheme", cfg: ServerConfig{Host: "http://localhost", Port: 8080}, wantErr: true},
+		{name: "port too large", cfg: ServerConfig{Port: 70000}, wantErr: true},
+		{name: "negative port", cfg: ServerConfig{Host: "127.0.0.1", Port: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.cfg.ListenAddr()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListenAddr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ListenAddr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_LoadConfig_UnknownKeys(t *testing.T) {
 	t.Parallel()
 	path := filepath.Join(testdataDir(t), "unknown_keys.yaml")
@@ -191,6 +363,16 @@ func Test_DefaultConfig_Fields(t *testing.T) {
 			check: func(cfg *Config) bool { return cfg.Queue.MaxSize == 1000 },
 			want:  "Queue.MaxSize == 1000",
 		},
+		{
+			name:  "Queue.PollTimeoutSec is 30",
+			check: func(cfg *Config) bool { return cfg.Queue.PollTimeoutSec == 30 },
+			want:  "Queue.PollTimeoutSec == 30",
+		},
+		{
+			name:  "Queue.MaxPollTimeoutSec is 300",
+			check: func(cfg *Config) bool { return cfg.Queue.MaxPollTimeoutSec == 300 },
+			want:  "Queue.MaxPollTimeoutSec == 300",
+		},
 		{
 			name:  "Audit.Enabled is true",
 			check: func(cfg *Config) bool { return cfg.Audit.Enabled },
@@ -329,6 +511,100 @@ func Test_ApplyEnvOverrides_AllThreeSet(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Load
+// ---------------------------------------------------------------------------
+
+// clearRequiredEnv blanks the environment variables Load reads so ambient
+// values don't leak into a test.
+func clearRequiredEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"CLAUDEBOT_DISCORD_TOKEN", "CLAUDEBOT_DISCORD_GUILD_ID", "CLAUDEBOT_AUTH_TOKEN", "CLAUDEBOT_LOG_LEVEL"} {
+		t.Setenv(name, "")
+	}
+}
+
+func Test_Load_EnvOnly(t *testing.T) {
+	clearRequiredEnv(t)
+	t.Setenv("CLAUDEBOT_DISCORD_TOKEN", "env-token")
+	t.Setenv("CLAUDEBOT_DISCORD_GUILD_ID", "env-guild")
+
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+	cfg, source, applied, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	if source != SourceEnv {
+		t.Errorf("source = %q, want %q", source, SourceEnv)
+	}
+	want := []string{"CLAUDEBOT_DISCORD_TOKEN", "CLAUDEBOT_DISCORD_GUILD_ID"}
+	if !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied = %v, want %v", applied, want)
+	}
+	if cfg.Discord.Token != "env-token" || cfg.Discord.GuildID != "env-guild" {
+		t.Errorf("Discord = %+v, want token/guild from env", cfg.Discord)
+	}
+	if cfg.Server.Port != DefaultConfig().Server.Port {
+		t.Errorf("Server.Port = %d, want default %d", cfg.Server.Port, DefaultConfig().Server.Port)
+	}
+	if err := cfg.ValidateRequired(); err != nil {
+		t.Errorf("ValidateRequired() unexpected error: %v", err)
+	}
+}
+
+func Test_Load_NoFileNoEnv(t *testing.T) {
+	clearRequiredEnv(t)
+
+	cfg, source, applied, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Load unexpected error: %v", err)
+	}
+	if source != SourceDefaults {
+		t.Errorf("source = %q, want %q", source, SourceDefaults)
+	}
+	if len(applied) != 0 {
+		t.Errorf("applied = %v, want none", applied)
+	}
+
+	err = cfg.ValidateRequired()
+	if err == nil {
+		t.Fatal("ValidateRequired() = nil, want error for missing token and guild")
+	}
+	for _, want := range []string{"CLAUDEBOT_DISCORD_TOKEN", "CLAUDEBOT_DISCORD_GUILD_ID"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func Test_Load_FileSource(t *testing.T) {
+	clearRequiredEnv(t)
+	t.Setenv("CLAUDEBOT_AUTH_TOKEN", "env-auth")
+
+	cfg, source, applied, err := Load(filepath.Join(testdataDir(t), "valid.yaml"))
+	if err != nil {
+		t.Fatalf("Load unexpected error: %v", err)
+	}
+	if source != SourceFile {
+		t.Errorf("source = %q, want %q", source, SourceFile)
+	}
+	if !reflect.DeepEqual(applied, []string{"CLAUDEBOT_AUTH_TOKEN"}) {
+		t.Errorf("applied = %v, want [CLAUDEBOT_AUTH_TOKEN]", applied)
+	}
+	if cfg.Server.AuthToken != "env-auth" {
+		t.Errorf("Server.AuthToken = %q, want %q", cfg.Server.AuthToken, "env-auth")
+	}
+}
+
+func Test_Load_InvalidYAML(t *testing.T) {
+	clearRequiredEnv(t)
+	t.Setenv("CLAUDEBOT_DISCORD_TOKEN", "env-token")
+
+	if _, _, _, err := Load(filepath.Join(testdataDir(t), "invalid.yaml")); err == nil {
+		t.Error("Load(invalid.yaml) = nil error, want parse error even with env set")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ParseLogLevel
 // ---------------------------------------------------------------------------
@@ -381,11 +657,26 @@ func Test_ParseLogLevel_Cases(t *testing.T) {
 			input: "",
 			want:  slog.LevelInfo,
 		},
+		{
+			name:  "Warn mixed case",
+			input: "Warn",
+			want:  slog.LevelWarn,
+		},
+		{
+			name:  "surrounding spaces ignored",
+			input: " error ",
+			want:  slog.LevelError,
+		},
 		{
 			name:  "unknown string defaults to info",
 			input: "garbage",
 			want:  slog.LevelInfo,
 		},
+		{
+			name:  "verbose is not a level",
+			input: "verbose",
+			want:  slog.LevelInfo,
+		},
 	}
 
 	for _, tt := range tests {
@@ -399,6 +690,33 @@ func Test_ParseLogLevel_Cases(t *testing.T) {
 	}
 }
 
+func Test_LookupLogLevel_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input  string
+		want   slog.Level
+		wantOK bool
+	}{
+		{input: "debug", want: slog.LevelDebug, wantOK: true},
+		{input: "WARNING", want: slog.LevelWarn, wantOK: true},
+		{input: "eRRor", want: slog.LevelError, wantOK: true},
+		{input: "verbose", want: slog.LevelInfo, wantOK: false},
+		{input: "trace", want: slog.LevelInfo, wantOK: false},
+		{input: "", want: slog.LevelInfo, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			got, ok := LookupLogLevel(tt.input)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("LookupLogLevel(%q) = (%v, %v), want (%v, %v)", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ApplyEnvOverrides — CLAUDEBOT_LOG_LEVEL
 // ---------------------------------------------------------------------------
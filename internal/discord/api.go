@@ -0,0 +1,55 @@
+package discord
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ConfigureSession applies a custom REST user agent and Discord API version
+// to dg before it is opened. Empty values keep discordgo's defaults. The API
+// version is process-wide: discordgo builds every REST endpoint and the
+// gateway URL from package variables, so changing it affects all sessions.
+func ConfigureSession(dg *discordgo.Session, apiVersion, userAgent string) error {
+	if apiVersion != "" {
+		if err := setAPIVersion(apiVersion); err != nil {
+			return err
+		}
+	}
+	if userAgent != "" {
+		dg.UserAgent = userAgent
+	}
+	return nil
+}
+
+// setAPIVersion points discordgo at version v of the Discord API by
+// rebuilding the endpoint variables derived from APIVersion at package
+// initialization. Endpoint functions read these variables when called, so
+// they follow automatically.
+func setAPIVersion(v string) error {
+	if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+		return fmt.Errorf("invalid Discord API version %q: want a positive number such as 10", v)
+	}
+	discordgo.APIVersion = v
+	discordgo.EndpointAPI = discordgo.EndpointDiscord + "api/v" + v + "/"
+	discordgo.EndpointGuilds = discordgo.EndpointAPI + "guilds/"
+	discordgo.EndpointChannels = discordgo.EndpointAPI + "channels/"
+	discordgo.EndpointUsers = discordgo.EndpointAPI + "users/"
+	discordgo.EndpointGateway = discordgo.EndpointAPI + "gateway"
+	discordgo.EndpointGatewayBot = discordgo.EndpointGateway + "/bot"
+	discordgo.EndpointWebhooks = discordgo.EndpointAPI + "webhooks/"
+	discordgo.EndpointStickers = discordgo.EndpointAPI + "stickers/"
+	discordgo.EndpointStageInstances = discordgo.EndpointAPI + "stage-instances"
+	discordgo.EndpointSKUs = discordgo.EndpointAPI + "skus"
+	discordgo.EndpointVoice = discordgo.EndpointAPI + "/voice/"
+	discordgo.EndpointVoiceRegions = discordgo.EndpointVoice + "regions"
+	discordgo.EndpointNitroStickersPacks = discordgo.EndpointAPI + "/sticker-packs"
+	discordgo.EndpointGuildCreate = discordgo.EndpointAPI + "guilds"
+	discordgo.EndpointApplications = discordgo.EndpointAPI + "applications"
+	discordgo.EndpointOAuth2 = discordgo.EndpointAPI + "oauth2/"
+	discordgo.EndpointOAuth2Applications = discordgo.EndpointOAuth2 + "applications"
+	discordgo.EndpointOauth2 = discordgo.EndpointOAuth2
+	discordgo.EndpointOauth2Applications = discordgo.EndpointOAuth2Applications
+	return nil
+}
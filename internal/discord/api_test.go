@@ -0,0 +1,89 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ---------------------------------------------------------------------------
+// ConfigureSession
+// ---------------------------------------------------------------------------
+
+// Test_ConfigureSession_APIVersion is not parallel: the API version lives in
+// discordgo package variables. Parallel tests only start once sequential
+// ones have finished, so they never see the temporary version.
+func Test_ConfigureSession_APIVersion(t *testing.T) {
+	original := discordgo.APIVersion
+	t.Cleanup(func() {
+		if err := setAPIVersion(original); err != nil {
+			t.Fatalf("restoring API version: %v", err)
+		}
+	})
+
+	dg, err := discordgo.New("Bot fake-token")
+	if err != nil {
+		t.Fatalf("discordgo.New() error = %v", err)
+	}
+	defaultAgent := dg.UserAgent
+
+	if err := ConfigureSession(dg, "10", ""); err != nil {
+		t.Fatalf("ConfigureSession() error = %v", err)
+	}
+	if discordgo.APIVersion != "10" {
+		t.Errorf("APIVersion = %q, want 10", discordgo.APIVersion)
+	}
+	for name, got := range map[string]string{
+		"EndpointGuilds":  discordgo.EndpointGuilds,
+		"EndpointGateway": discordgo.EndpointGateway,
+		"EndpointChannel": discordgo.EndpointChannelMessages("123"),
+		"EndpointUser":    discordgo.EndpointUser("@me"),
+	} {
+		if !strings.HasPrefix(got, "https://discord.com/api/v10/") {
+			t.Errorf("%s = %q, want the v10 API base", name, got)
+		}
+	}
+	if dg.UserAgent != defaultAgent {
+		t.Errorf("UserAgent = %q, want the default %q when unset", dg.UserAgent, defaultAgent)
+	}
+}
+
+func Test_ConfigureSession_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		apiVersion string
+		userAgent  string
+		wantErr    bool
+	}{
+		{name: "user agent only", userAgent: "DiscordBot (https://example.com, 1.2.3)"},
+		{name: "nothing set"},
+		{name: "non-numeric version", apiVersion: "v10", wantErr: true},
+		{name: "zero version", apiVersion: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dg, err := discordgo.New("Bot fake-token")
+			if err != nil {
+				t.Fatalf("discordgo.New() error = %v", err)
+			}
+			defaultAgent := dg.UserAgent
+
+			err = ConfigureSession(dg, tt.apiVersion, tt.userAgent)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConfigureSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			want := tt.userAgent
+			if want == "" || tt.wantErr {
+				want = defaultAgent
+			}
+			if dg.UserAgent != want {
+				t.Errorf("UserAgent = %q, want %q", dg.UserAgent, want)
+			}
+		})
+	}
+}
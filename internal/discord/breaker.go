@@ -0,0 +1,158 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ErrCircuitOpen is returned without contacting Discord while the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("Discord temporarily unavailable: too many consecutive failures, try again shortly")
+
+// Default circuit breaker settings used when BreakerPolicy fields are unset.
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// BreakerPolicy controls the circuit breaker. After Threshold consecutive
+// calls fail with a Discord 5xx response, a network error or a timeout, calls
+// fail fast with ErrCircuitOpen for Cooldown; then a single trial call is let
+// through, and its outcome closes the breaker or opens it for another
+// Cooldown. Zero fields fall back to 5 failures and 30s; a negative Threshold
+// disables the breaker.
+type BreakerPolicy struct {
+	Threshold int
+	Cooldown  time.Duration
+}
+
+// withDefaults returns a copy of p with unset fields filled in.
+func (p BreakerPolicy) withDefaults() BreakerPolicy {
+	if p.Threshold == 0 {
+		p.Threshold = defaultBreakerThreshold
+	}
+	if p.Cooldown <= 0 {
+		p.Cooldown = defaultBreakerCooldown
+	}
+	return p
+}
+
+// breakerState is the state of a circuitBreaker.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker tracks consecutive call failures; see BreakerPolicy. It is
+// safe for concurrent use.
+type circuitBreaker struct {
+	policy BreakerPolicy
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool // a half-open trial call is in flight
+}
+
+// newCircuitBreaker returns a closed breaker, or nil when policy disables it.
+func newCircuitBreaker(policy BreakerPolicy) *circuitBreaker {
+	policy = policy.withDefaults()
+	if policy.Threshold < 0 {
+		return nil
+	}
+	return &circuitBreaker{policy: policy, now: time.Now}
+}
+
+// allow reports whether a call may proceed, returning ErrCircuitOpen if not,
+// and whether the call is the half-open trial. A nil breaker allows every
+// call.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	if b == nil {
+		return false, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.policy.Cooldown {
+			return false, ErrCircuitOpen
+		}
+		b.state = breakerHalfOpen
+	case breakerHalfOpen:
+		if b.probing {
+			return false, ErrCircuitOpen
+		}
+	}
+	if b.state == breakerHalfOpen {
+		b.probing = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// record updates the breaker with the outcome of a call that allow let
+// through; probe is the flag allow returned for it. Calls cancelled by their
+// caller say nothing about Discord's health and leave the failure count
+// unchanged. Only the trial call decides a half-open breaker: a call that
+// started before the breaker opened is ignored once it is no longer closed.
+func (b *circuitBreaker) record(ctx context.Context, probe bool, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	} else if b.state != breakerClosed {
+		return
+	}
+	switch {
+	case isOutage(ctx, err):
+		b.failures++
+		if probe || b.failures >= b.policy.Threshold {
+			b.state = breakerOpen
+			b.openedAt = b.now()
+		}
+	case err != nil && (errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled)):
+		// Neutral; a half-open breaker lets the next call try instead.
+	default:
+		b.state = breakerClosed
+		b.failures = 0
+	}
+}
+
+// isOutage reports whether err suggests Discord is down rather than that the
+// call itself was bad: a 5xx response, a network error or a timeout. A 4xx
+// response, including a rate limit, means Discord answered.
+func isOutage(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) {
+		return restErr.Response != nil && restErr.Response.StatusCode >= http.StatusInternalServerError
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr)
+}
@@ -0,0 +1,57 @@
+package discord
+
+import (
+	"strings"
+	"unicode"
+)
+
+// parseCommand splits a bot-style command such as `!say hello world` into
+// its lowercased name and arguments. It reports false when content does not
+// start with prefix immediately followed by a name. Arguments are separated
+// by whitespace; a double-quoted argument may contain spaces, and an
+// unterminated quote runs to the end of the content.
+func parseCommand(content, prefix string) (string, []string, bool) {
+	if prefix == "" {
+		return "", nil, false
+	}
+	rest, ok := strings.CutPrefix(content, prefix)
+	if !ok || rest == "" || unicode.IsSpace(rune(rest[0])) {
+		return "", nil, false
+	}
+	fields := splitArgs(rest)
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+	return strings.ToLower(fields[0]), fields[1:], true
+}
+
+// splitArgs splits s on whitespace, keeping double-quoted runs together
+// without their quotes.
+func splitArgs(s string) []string {
+	var (
+		args    []string
+		cur     strings.Builder
+		inQuote bool
+		inArg   bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			inArg = true
+		case unicode.IsSpace(r) && !inQuote:
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args
+}
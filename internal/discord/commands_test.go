@@ -0,0 +1,50 @@
+package discord
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// parseCommand
+// ---------------------------------------------------------------------------
+
+func Test_parseCommand_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		prefix   string
+		wantName string
+		wantArgs []string
+		wantOK   bool
+	}{
+		{name: "command with args", content: "!say hello world", prefix: "!", wantName: "say", wantArgs: []string{"hello", "world"}, wantOK: true},
+		{name: "no args", content: "!ping", prefix: "!", wantName: "ping", wantArgs: []string{}, wantOK: true},
+		{name: "name lowercased, args kept", content: "!Say Hello", prefix: "!", wantName: "say", wantArgs: []string{"Hello"}, wantOK: true},
+		{name: "extra whitespace", content: "!roll   2d6 \t +3 ", prefix: "!", wantName: "roll", wantArgs: []string{"2d6", "+3"}, wantOK: true},
+		{name: "quoted argument", content: `!remind "buy milk" 5m`, prefix: "!", wantName: "remind", wantArgs: []string{"buy milk", "5m"}, wantOK: true},
+		{name: "empty quoted argument", content: `!set topic ""`, prefix: "!", wantName: "set", wantArgs: []string{"topic", ""}, wantOK: true},
+		{name: "unterminated quote runs to end", content: `!note "half done`, prefix: "!", wantName: "note", wantArgs: []string{"half done"}, wantOK: true},
+		{name: "multi-character prefix", content: "bot:status now", prefix: "bot:", wantName: "status", wantArgs: []string{"now"}, wantOK: true},
+		{name: "plain message", content: "hello there", prefix: "!"},
+		{name: "prefix alone", content: "!", prefix: "!"},
+		{name: "space after prefix", content: "! say hi", prefix: "!"},
+		{name: "prefix not at start", content: "hey !say hi", prefix: "!"},
+		{name: "no prefix configured", content: "!say hi", prefix: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			name, args, ok := parseCommand(tt.content, tt.prefix)
+			if ok != tt.wantOK || name != tt.wantName {
+				t.Fatalf("parseCommand(%q, %q) = %q, %v, want %q, %v", tt.content, tt.prefix, name, ok, tt.wantName, tt.wantOK)
+			}
+			if ok && !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
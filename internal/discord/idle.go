@@ -0,0 +1,251 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Idle disconnect settings. Timeouts below minIdleTimeout are raised to it so
+// a short setting cannot make the gateway flap between calls. A failed
+// reconnect is not retried for idleReconnectBackoff, doubling after each
+// further failure up to maxIdleReconnectBackoff, so tool calls during an
+// outage do not each trigger a new gateway handshake.
+const (
+	minIdleTimeout          = time.Minute
+	idleReconnectBackoff    = 5 * time.Second
+	maxIdleReconnectBackoff = 5 * time.Minute
+)
+
+// Gateway is a gateway connection that can be closed and reopened, such as
+// *discordgo.Session or *Session.
+type Gateway interface {
+	Open() error
+	Close() error
+}
+
+// IdleMonitor closes the gateway after a period with no activity and reopens
+// it when a tool call begins. Activity is an enqueued event (Touch) or a tool
+// call (Begin/End); the gateway is never closed while a call is running. A
+// nil *IdleMonitor is valid and does nothing, so callers need not check
+// whether idle disconnect is enabled. It is safe for concurrent use.
+type IdleMonitor struct {
+	gw      Gateway
+	timeout time.Duration
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu           sync.Mutex
+	connected    bool
+	stopped      bool
+	active       int // tool calls between Begin and End
+	lastActivity time.Time
+	failures     int // consecutive failed reconnects
+	retryAt      time.Time
+	// reconnecting is the reconnect in progress, if any. Begin calls that
+	// find one wait for it instead of opening the gateway again.
+	reconnecting *reconnectAttempt
+	// closing is closed when an idle close in progress finishes. Begin calls
+	// that find one wait for it before reopening the gateway.
+	closing chan struct{}
+}
+
+// reconnectAttempt is a gateway reopen shared by the Begin calls waiting on
+// it. err is set before done is closed.
+type reconnectAttempt struct {
+	done chan struct{}
+	err  error
+}
+
+// NewIdleMonitor returns a monitor for gw, which must already be open, that
+// closes it after timeout without activity. Timeouts under a minute are
+// raised to one minute. It returns nil when timeout is zero or negative,
+// which disables idle disconnect. A nil logger defaults to slog.Default().
+func NewIdleMonitor(gw Gateway, timeout time.Duration, logger *slog.Logger) *IdleMonitor {
+	if timeout <= 0 {
+		return nil
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	timeout = max(timeout, minIdleTimeout)
+	m := &IdleMonitor{gw: gw, timeout: timeout, logger: logger, now: time.Now, connected: true}
+	m.lastActivity = m.now()
+	return m
+}
+
+// Timeout returns the idle period after which the gateway is closed, or zero
+// for a nil monitor.
+func (m *IdleMonitor) Timeout() time.Duration {
+	if m == nil {
+		return 0
+	}
+	return m.timeout
+}
+
+// Connected reports whether the monitor believes the gateway is open. A nil
+// monitor reports true.
+func (m *IdleMonitor) Connected() bool {
+	if m == nil {
+		return true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.connected
+}
+
+// Touch records activity, postponing the idle disconnect.
+func (m *IdleMonitor) Touch() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.lastActivity = m.now()
+	m.mu.Unlock()
+}
+
+// Begin marks the start of a tool call and reopens the gateway if it was
+// closed for being idle. Concurrent calls share a single reconnect, and each
+// stops waiting for it when its ctx is done. Every call to Begin must be
+// paired with End, even when Begin returns an error. A call arriving while
+// an idle close is in progress waits for the close to finish and then
+// reconnects. While a failed reconnect is backing off, Begin returns an
+// error without contacting Discord.
+func (m *IdleMonitor) Begin(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	m.active++
+	m.lastActivity = m.now()
+	for m.closing != nil {
+		closing := m.closing
+		m.mu.Unlock()
+		select {
+		case <-closing:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		m.mu.Lock()
+	}
+	if m.connected || m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	attempt := m.reconnecting
+	if attempt == nil {
+		now := m.now()
+		if now.Before(m.retryAt) {
+			m.mu.Unlock()
+			return fmt.Errorf("Discord gateway reconnect failed recently; retrying in %s", m.retryAt.Sub(now).Round(time.Second))
+		}
+		attempt = &reconnectAttempt{done: make(chan struct{})}
+		m.reconnecting = attempt
+		go m.reconnect(attempt)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-attempt.done:
+		return attempt.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// End marks the end of a tool call started with Begin.
+func (m *IdleMonitor) End() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	if m.active > 0 {
+		m.active--
+	}
+	m.lastActivity = m.now()
+	m.mu.Unlock()
+}
+
+// reconnect opens the gateway for attempt and records the outcome. It runs
+// without m.mu held, so the handshake does not block Touch, End or the idle
+// check; it runs to completion even if every waiter has given up.
+func (m *IdleMonitor) reconnect(attempt *reconnectAttempt) {
+	err := m.gw.Open()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil && !errors.Is(err, discordgo.ErrWSAlreadyOpen) {
+		m.failures++
+		backoff := min(idleReconnectBackoff<<(m.failures-1), maxIdleReconnectBackoff)
+		m.retryAt = m.now().Add(backoff)
+		m.logger.Warn("gateway reconnect failed", "error", err, "retry_in", backoff)
+		attempt.err = fmt.Errorf("reconnecting Discord gateway: %w", err)
+	} else {
+		m.connected = true
+		m.failures = 0
+		m.retryAt = time.Time{}
+		m.logger.Info("gateway reconnected after idle disconnect")
+	}
+	m.reconnecting = nil
+	close(attempt.done)
+}
+
+// idle reports whether the gateway is open and has seen no activity, and no
+// tool call has been running, for the full timeout as of now. The caller
+// must hold m.mu.
+func (m *IdleMonitor) idle(now time.Time) bool {
+	return m.connected && !m.stopped && m.active == 0 && now.Sub(m.lastActivity) >= m.timeout
+}
+
+// closeIfIdle closes the gateway if it is idle, reporting whether it did.
+// The close runs without m.mu held, since discordgo waits on the gateway
+// while closing; Begin calls arriving meanwhile wait for it to finish.
+func (m *IdleMonitor) closeIfIdle() bool {
+	m.mu.Lock()
+	if !m.idle(m.now()) {
+		m.mu.Unlock()
+		return false
+	}
+	m.connected = false
+	closing := make(chan struct{})
+	m.closing = closing
+	m.mu.Unlock()
+
+	if err := m.gw.Close(); err != nil {
+		m.logger.Warn("idle gateway close failed", "error", err)
+	}
+	m.logger.Info("gateway closed after idle period", "idle", m.timeout)
+
+	m.mu.Lock()
+	m.closing = nil
+	m.mu.Unlock()
+	close(closing)
+	return true
+}
+
+// Run checks for an idle gateway every quarter of the timeout until ctx is
+// done. After Run returns, Begin no longer reopens the gateway, so a call
+// arriving during shutdown cannot reconnect it.
+func (m *IdleMonitor) Run(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	ticker := time.NewTicker(m.timeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.mu.Lock()
+			m.stopped = true
+			m.mu.Unlock()
+			return
+		case <-ticker.C:
+			m.closeIfIdle()
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeGateway counts Open and Close calls; Open fails with openErr when set
+// and, when block is set, waits for it to be closed first. Close likewise
+// waits for closeBlock when set.
+type fakeGateway struct {
+	opens, closes int
+	openErr       error
+	block         chan struct{}
+	closeBlock    chan struct{}
+}
+
+func (g *fakeGateway) Open() error {
+	g.opens++
+	if g.block != nil {
+		<-g.block
+	}
+	return g.openErr
+}
+
+func (g *fakeGateway) Close() error {
+	g.closes++
+	if g.closeBlock != nil {
+		<-g.closeBlock
+	}
+	return nil
+}
+
+// newTestIdleMonitor returns a monitor over a fake gateway whose clock is
+// advanced by the returned function.
+func newTestIdleMonitor(t *testing.T, timeout time.Duration) (*IdleMonitor, *fakeGateway, func(time.Duration)) {
+	t.Helper()
+	gw := &fakeGateway{}
+	m := NewIdleMonitor(gw, timeout, nil)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	m.lastActivity = now
+	return m, gw, func(d time.Duration) { now = now.Add(d) }
+}
+
+// ---------------------------------------------------------------------------
+// Idle detection
+// ---------------------------------------------------------------------------
+
+func Test_IdleMonitor_ClosesAfterTimeout(t *testing.T) {
+	t.Parallel()
+
+	m, gw, advance := newTestIdleMonitor(t, 5*time.Minute)
+
+	advance(4 * time.Minute)
+	if m.closeIfIdle() {
+		t.Fatal("closed before the idle timeout")
+	}
+	advance(time.Minute)
+	if !m.closeIfIdle() {
+		t.Fatal("did not close after the idle timeout")
+	}
+	if gw.closes != 1 || m.Connected() {
+		t.Errorf("closes = %d, connected = %v; want 1, false", gw.closes, m.Connected())
+	}
+	if m.closeIfIdle() {
+		t.Error("closed an already closed gateway")
+	}
+}
+
+func Test_IdleMonitor_TouchPostponesClose(t *testing.T) {
+	t.Parallel()
+
+	m, gw, advance := newTestIdleMonitor(t, 5*time.Minute)
+
+	for range 3 {
+		advance(4 * time.Minute)
+		m.Touch()
+		if m.closeIfIdle() {
+			t.Fatal("closed despite recent activity")
+		}
+	}
+	advance(5 * time.Minute)
+	if !m.closeIfIdle() || gw.closes != 1 {
+		t.Errorf("closes = %d, want 1 after a quiet timeout", gw.closes)
+	}
+}
+
+func Test_IdleMonitor_ActiveCallKeepsOpen(t *testing.T) {
+	t.Parallel()
+
+	m, _, advance := newTestIdleMonitor(t, 5*time.Minute)
+
+	if err := m.Begin(context.Background()); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	advance(10 * time.Minute)
+	if m.closeIfIdle() {
+		t.Fatal("closed while a call was running")
+	}
+	m.End()
+	advance(4 * time.Minute)
+	if m.closeIfIdle() {
+		t.Fatal("End should count as activity")
+	}
+	advance(time.Minute)
+	if !m.closeIfIdle() {
+		t.Error("did not close once the call ended and the timeout passed")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Reconnect
+// ---------------------------------------------------------------------------
+
+func Test_IdleMonitor_BeginReconnects(t *testing.T) {
+	t.Parallel()
+
+	m, gw, advance := newTestIdleMonitor(t, 5*time.Minute)
+
+	advance(5 * time.Minute)
+	m.closeIfIdle()
+
+	if err := m.Begin(context.Background()); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	m.End()
+	if err := m.Begin(context.Background()); err != nil {
+		t.Fatalf("second Begin: %v", err)
+	}
+	m.End()
+	if gw.opens != 1 || !m.Connected() {
+		t.Errorf("opens = %d, connected = %v; want 1, true", gw.opens, m.Connected())
+	}
+}
+
+func Test_IdleMonitor_ReconnectBackoff(t *testing.T) {
+	t.Parallel()
+
+	m, gw, advance := newTestIdleMonitor(t, 5*time.Minute)
+	advance(5 * time.Minute)
+	m.closeIfIdle()
+	gw.openErr = errors.New("gateway down")
+
+	if err := m.Begin(context.Background()); err == nil {
+		t.Fatal("Begin should report the failed reconnect")
+	}
+	m.End()
+	// Calls within the backoff fail without another handshake.
+	for range 5 {
+		if err := m.Begin(context.Background()); err == nil {
+			t.Fatal("Begin should fail while backing off")
+		}
+		m.End()
+	}
+	if gw.opens != 1 {
+		t.Fatalf("opens = %d during backoff, want 1", gw.opens)
+	}
+
+	// The second failure doubles the backoff.
+	advance(idleReconnectBackoff)
+	_ = m.Begin(context.Background())
+	m.End()
+	advance(idleReconnectBackoff)
+	_ = m.Begin(context.Background())
+	m.End()
+	if gw.opens != 2 {
+		t.Fatalf("opens = %d, want 2 before the doubled backoff expires", gw.opens)
+	}
+
+	gw.openErr = nil
+	advance(idleReconnectBackoff)
+	if err := m.Begin(context.Background()); err != nil {
+		t.Fatalf("Begin after backoff: %v", err)
+	}
+	m.End()
+	if gw.opens != 3 || !m.Connected() {
+		t.Errorf("opens = %d, connected = %v; want 3, true", gw.opens, m.Connected())
+	}
+}
+
+func Test_IdleMonitor_ConcurrentBeginSharesReconnect(t *testing.T) {
+	t.Parallel()
+
+	m, gw, advance := newTestIdleMonitor(t, 5*time.Minute)
+	advance(5 * time.Minute)
+	m.closeIfIdle()
+	gw.block = make(chan struct{})
+
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			errs <- m.Begin(context.Background())
+		}()
+	}
+
+	// While the handshake is blocked, activity is still recorded and a
+	// caller whose context ends stops waiting.
+	m.Touch()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.Begin(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Begin with cancelled ctx = %v, want context.Canceled", err)
+	}
+	m.End()
+
+	close(gw.block)
+	for range 3 {
+		if err := <-errs; err != nil {
+			t.Errorf("Begin: %v", err)
+		}
+		m.End()
+	}
+	if gw.opens != 1 || !m.Connected() {
+		t.Errorf("opens = %d, connected = %v; want 1, true", gw.opens, m.Connected())
+	}
+}
+
+func Test_IdleMonitor_BeginWaitsForClose(t *testing.T) {
+	t.Parallel()
+
+	m, gw, advance := newTestIdleMonitor(t, 5*time.Minute)
+	advance(5 * time.Minute)
+	gw.closeBlock = make(chan struct{})
+
+	closed := make(chan bool)
+	go func() { closed <- m.closeIfIdle() }()
+	for {
+		m.mu.Lock()
+		closing := m.closing != nil
+		m.mu.Unlock()
+		if closing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// While Close is blocked, activity is still recorded and Begin waits
+	// rather than reopening the gateway underneath the close.
+	m.Touch()
+	begun := make(chan error)
+	go func() { begun <- m.Begin(context.Background()) }()
+	select {
+	case err := <-begun:
+		t.Fatalf("Begin returned %v while the close was in progress", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(gw.closeBlock)
+	if !<-closed {
+		t.Error("closeIfIdle = false, want true")
+	}
+	if err := <-begun; err != nil {
+		t.Errorf("Begin: %v", err)
+	}
+	m.End()
+	if gw.closes != 1 || gw.opens != 1 || !m.Connected() {
+		t.Errorf("closes = %d, opens = %d, connected = %v; want 1, 1, true", gw.closes, gw.opens, m.Connected())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Configuration
+// ---------------------------------------------------------------------------
+
+func Test_NewIdleMonitor_Settings(t *testing.T) {
+	t.Parallel()
+
+	if m := NewIdleMonitor(&fakeGateway{}, 0, nil); m != nil {
+		t.Error("zero timeout should disable the monitor")
+	}
+	if got := NewIdleMonitor(&fakeGateway{}, time.Second, nil).Timeout(); got != minIdleTimeout {
+		t.Errorf("Timeout() = %s, want the %s minimum", got, minIdleTimeout)
+	}
+
+	// A nil monitor is a no-op.
+	var m *IdleMonitor
+	m.Touch()
+	if err := m.Begin(context.Background()); err != nil {
+		t.Errorf("nil Begin: %v", err)
+	}
+	m.End()
+	if !m.Connected() {
+		t.Error("nil monitor should report connected")
+	}
+}
@@ -0,0 +1,64 @@
+package discord
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultIntents are the gateway intents requested when none are configured.
+const DefaultIntents = discordgo.IntentGuilds |
+	discordgo.IntentGuildMessages |
+	discordgo.IntentMessageContent |
+	discordgo.IntentGuildMessageReactions
+
+// intentNames maps the configuration name of each gateway intent to its
+// value. Names follow Discord's documentation in snake case.
+var intentNames = map[string]discordgo.Intent{
+	"guilds":                        discordgo.IntentGuilds,
+	"guild_members":                 discordgo.IntentGuildMembers,
+	"guild_moderation":              discordgo.IntentGuildModeration,
+	"guild_emojis":                  discordgo.IntentGuildEmojis,
+	"guild_integrations":            discordgo.IntentGuildIntegrations,
+	"guild_webhooks":                discordgo.IntentGuildWebhooks,
+	"guild_invites":                 discordgo.IntentGuildInvites,
+	"guild_voice_states":            discordgo.IntentGuildVoiceStates,
+	"guild_presences":               discordgo.IntentGuildPresences,
+	"guild_messages":                discordgo.IntentGuildMessages,
+	"guild_message_reactions":       discordgo.IntentGuildMessageReactions,
+	"guild_message_typing":          discordgo.IntentGuildMessageTyping,
+	"direct_messages":               discordgo.IntentDirectMessages,
+	"direct_message_reactions":      discordgo.IntentDirectMessageReactions,
+	"direct_message_typing":         discordgo.IntentDirectMessageTyping,
+	"message_content":               discordgo.IntentMessageContent,
+	"guild_scheduled_events":        discordgo.IntentGuildScheduledEvents,
+	"auto_moderation_configuration": discordgo.IntentAutoModerationConfiguration,
+	"auto_moderation_execution":     discordgo.IntentAutoModerationExecution,
+}
+
+// ParseIntents combines the named gateway intents, such as "guilds" or
+// "message_content", into a single value. Names are case-insensitive. An
+// empty list returns DefaultIntents; an unknown name is an error listing the
+// valid names, so a misspelled privileged intent is caught at startup rather
+// than silently not requested.
+func ParseIntents(names []string) (discordgo.Intent, error) {
+	if len(names) == 0 {
+		return DefaultIntents, nil
+	}
+	var intents discordgo.Intent
+	for _, name := range names {
+		v, ok := intentNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			valid := make([]string, 0, len(intentNames))
+			for n := range intentNames {
+				valid = append(valid, n)
+			}
+			sort.Strings(valid)
+			return 0, fmt.Errorf("unknown gateway intent %q (valid: %s)", name, strings.Join(valid, ", "))
+		}
+		intents |= v
+	}
+	return intents, nil
+}
@@ -0,0 +1,82 @@
+package discord
+
+import (
+	"regexp"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// mentionToken matches Discord's user (<@id>, <@!id>), role (<@&id>) and
+// channel (<#id>) mention tokens.
+var mentionToken = regexp.MustCompile(`<(@!?|@&|#)(\d+)>`)
+
+// mentionNames looks up display names for mention tokens. Each function
+// reports false when the name is unknown, leaving the token unchanged. A nil
+// function never resolves.
+type mentionNames struct {
+	user    func(id string) (string, bool)
+	role    func(id string) (string, bool)
+	channel func(id string) (string, bool)
+}
+
+// expandMentions rewrites the mention tokens in content as @username,
+// @role-name and #channel-name using names.
+func expandMentions(content string, names mentionNames) string {
+	return mentionToken.ReplaceAllStringFunc(content, func(tok string) string {
+		m := mentionToken.FindStringSubmatch(tok)
+		kind, id := m[1], m[2]
+
+		lookup, prefix := names.user, "@"
+		switch kind {
+		case "@&":
+			lookup = names.role
+		case "#":
+			lookup, prefix = names.channel, "#"
+		}
+		if lookup == nil {
+			return tok
+		}
+		if name, ok := lookup(id); ok && name != "" {
+			return prefix + name
+		}
+		return tok
+	})
+}
+
+// mentionNamesFor returns the lookups used to expand mentions in m: users
+// from the message's own mention list and then the member cache, roles from
+// the state cache, and channels from the resolver.
+func (s *Session) mentionNamesFor(m *discordgo.Message) mentionNames {
+	return mentionNames{
+		user: func(id string) (string, bool) {
+			for _, u := range m.Mentions {
+				if u != nil && u.ID == id {
+					return u.Username, true
+				}
+			}
+			if s.dg.State == nil {
+				return "", false
+			}
+			member, err := s.dg.State.Member(m.GuildID, id)
+			if err != nil || member.User == nil {
+				return "", false
+			}
+			return member.User.Username, true
+		},
+		role: func(id string) (string, bool) {
+			if s.dg.State == nil {
+				return "", false
+			}
+			role, err := s.dg.State.Role(m.GuildID, id)
+			if err != nil {
+				return "", false
+			}
+			return role.Name, true
+		},
+		channel: func(id string) (string, bool) {
+			// ChannelName returns the ID itself for unknown channels.
+			name := s.resolver.ChannelName(id)
+			return name, name != id
+		},
+	}
+}
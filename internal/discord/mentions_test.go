@@ -0,0 +1,111 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ---------------------------------------------------------------------------
+// expandMentions
+// ---------------------------------------------------------------------------
+
+func Test_expandMentions_Cases(t *testing.T) {
+	t.Parallel()
+
+	known := func(names map[string]string) func(string) (string, bool) {
+		return func(id string) (string, bool) {
+			name, ok := names[id]
+			return name, ok
+		}
+	}
+	names := mentionNames{
+		user:    known(map[string]string{"111": "alice"}),
+		role:    known(map[string]string{"222": "moderators"}),
+		channel: known(map[string]string{"333": "support"}),
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"channel mention", "see <#333> for help", "see #support for help"},
+		{"user and nickname forms", "<@111> and <@!111>", "@alice and @alice"},
+		{"role mention", "ping <@&222>", "ping @moderators"},
+		{"unknown IDs kept", "<#999> <@999> <@&999>", "<#999> <@999> <@&999>"},
+		{"no mentions", "plain text", "plain text"},
+		{"not a mention", "<#abc> <@>", "<#abc> <@>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := expandMentions(tt.content, names); got != tt.want {
+				t.Errorf("expandMentions(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_expandMentions_NilLookups(t *testing.T) {
+	t.Parallel()
+	if got := expandMentions("<@1> <#2>", mentionNames{}); got != "<@1> <#2>" {
+		t.Errorf("expandMentions() with no lookups = %q, want the content unchanged", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// onMessageCreate - mention expansion
+// ---------------------------------------------------------------------------
+
+func Test_onMessageCreate_MentionExpansion(t *testing.T) {
+	t.Parallel()
+
+	newEvent := func() *discordgo.MessageCreate {
+		return &discordgo.MessageCreate{Message: &discordgo.Message{
+			ID:        "msg-1",
+			ChannelID: "chan-1",
+			GuildID:   "guild-1",
+			Content:   "<@7>: ask <@&42> in <#99>",
+			Author:    &discordgo.User{ID: "user-1", Username: "alice"},
+			Mentions:  []*discordgo.User{{ID: "7", Username: "bob"}},
+		}}
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Parallel()
+		s, q := newTestSession(t, "guild-1", nil, WithMentionExpansion(true))
+		if err := s.dg.State.GuildAdd(&discordgo.Guild{ID: "guild-1"}); err != nil {
+			t.Fatalf("GuildAdd() error = %v", err)
+		}
+		if err := s.dg.State.RoleAdd("guild-1", &discordgo.Role{ID: "42", Name: "helpers"}); err != nil {
+			t.Fatalf("RoleAdd() error = %v", err)
+		}
+		event := newEvent()
+		s.onMessageCreate(s.dg, event)
+
+		msgs := drainQueue(q, 10)
+		if len(msgs) != 1 {
+			t.Fatalf("expected 1 queued message, got %d", len(msgs))
+		}
+		// Channel 99 is not in the resolver cache, so its token stays.
+		if want := "@bob: ask @helpers in <#99>"; msgs[0].Content != want {
+			t.Errorf("Content = %q, want %q", msgs[0].Content, want)
+		}
+		if msgs[0].RawContent != event.Content {
+			t.Errorf("RawContent = %q, want the original %q", msgs[0].RawContent, event.Content)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+		s, q := newTestSession(t, "guild-1", nil)
+		s.onMessageCreate(s.dg, newEvent())
+
+		msgs := drainQueue(q, 10)
+		if len(msgs) != 1 || msgs[0].Content != "<@7>: ask <@&42> in <#99>" || msgs[0].RawContent != "" {
+			t.Errorf("queued %+v, want the content untouched", msgs)
+		}
+	})
+}
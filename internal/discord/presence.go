@@ -0,0 +1,72 @@
+package discord
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// presenceStatuses are the statuses a bot may set at startup.
+var presenceStatuses = map[string]discordgo.Status{
+	"online":    discordgo.StatusOnline,
+	"idle":      discordgo.StatusIdle,
+	"dnd":       discordgo.StatusDoNotDisturb,
+	"invisible": discordgo.StatusInvisible,
+}
+
+// activityTypes maps the configuration name of each activity type to its
+// value.
+var activityTypes = map[string]discordgo.ActivityType{
+	"playing":   discordgo.ActivityTypeGame,
+	"streaming": discordgo.ActivityTypeStreaming,
+	"listening": discordgo.ActivityTypeListening,
+	"watching":  discordgo.ActivityTypeWatching,
+	"custom":    discordgo.ActivityTypeCustom,
+	"competing": discordgo.ActivityTypeCompeting,
+}
+
+// BuildPresence returns the presence sent when the bot identifies to the
+// gateway. status is one of online (the default when empty), idle, dnd or
+// invisible. activityType names the activity (playing, streaming, listening,
+// watching, custom or competing; watching when empty) and activityName its
+// text. An empty activityName sets no activity at all, so an unconfigured bot
+// shows only its status. Names are case-insensitive; an unknown one is an
+// error listing the valid names.
+func BuildPresence(status, activityType, activityName string) (discordgo.GatewayStatusUpdate, error) {
+	presence := discordgo.GatewayStatusUpdate{Status: string(discordgo.StatusOnline)}
+
+	if s := strings.ToLower(strings.TrimSpace(status)); s != "" {
+		v, ok := presenceStatuses[s]
+		if !ok {
+			return discordgo.GatewayStatusUpdate{}, fmt.Errorf("unknown presence status %q (valid: %s)", status, strings.Join(slices.Sorted(maps.Keys(presenceStatuses)), ", "))
+		}
+		presence.Status = string(v)
+	}
+
+	typ := discordgo.ActivityTypeWatching
+	if t := strings.ToLower(strings.TrimSpace(activityType)); t != "" {
+		v, ok := activityTypes[t]
+		if !ok {
+			return discordgo.GatewayStatusUpdate{}, fmt.Errorf("unknown activity type %q (valid: %s)", activityType, strings.Join(slices.Sorted(maps.Keys(activityTypes)), ", "))
+		}
+		typ = v
+	}
+
+	name := strings.TrimSpace(activityName)
+	if name == "" {
+		if strings.TrimSpace(activityType) != "" {
+			return discordgo.GatewayStatusUpdate{}, fmt.Errorf("presence activity_type %q needs an activity_name", activityType)
+		}
+		return presence, nil
+	}
+	presence.Game = discordgo.Activity{Name: name, Type: typ}
+	if typ == discordgo.ActivityTypeCustom {
+		// Custom statuses show the state text; the name is ignored.
+		presence.Game.Name = "Custom Status"
+		presence.Game.State = name
+	}
+	return presence, nil
+}
@@ -0,0 +1,80 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ---------------------------------------------------------------------------
+// BuildPresence
+// ---------------------------------------------------------------------------
+
+func Test_BuildPresence_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		status       string
+		activityType string
+		activityName string
+		want         discordgo.GatewayStatusUpdate
+		wantErr      bool
+	}{
+		{
+			name: "unconfigured is online with no activity",
+			want: discordgo.GatewayStatusUpdate{Status: "online"},
+		},
+		{
+			name:   "status only",
+			status: "DND",
+			want:   discordgo.GatewayStatusUpdate{Status: "dnd"},
+		},
+		{
+			name:         "name defaults to watching",
+			activityName: "the queue",
+			want: discordgo.GatewayStatusUpdate{Status: "online",
+				Game: discordgo.Activity{Name: "the queue", Type: discordgo.ActivityTypeWatching}},
+		},
+		{
+			name:         "listening with idle status",
+			status:       "idle",
+			activityType: "Listening",
+			activityName: "#support",
+			want: discordgo.GatewayStatusUpdate{Status: "idle",
+				Game: discordgo.Activity{Name: "#support", Type: discordgo.ActivityTypeListening}},
+		},
+		{
+			name:         "custom status uses state",
+			activityType: "custom",
+			activityName: "Answering questions",
+			want: discordgo.GatewayStatusUpdate{Status: "online",
+				Game: discordgo.Activity{Name: "Custom Status", State: "Answering questions", Type: discordgo.ActivityTypeCustom}},
+		},
+		{name: "unknown status", status: "away", wantErr: true},
+		{name: "unknown activity type", activityType: "reading", activityName: "docs", wantErr: true},
+		{name: "activity type without name", activityType: "playing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := BuildPresence(tt.status, tt.activityType, tt.activityName)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("BuildPresence() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildPresence() error = %v", err)
+			}
+			if got.Status != tt.want.Status {
+				t.Errorf("Status = %q, want %q", got.Status, tt.want.Status)
+			}
+			if got.Game.Name != tt.want.Game.Name || got.Game.Type != tt.want.Game.Type || got.Game.State != tt.want.Game.State {
+				t.Errorf("Game = %+v, want %+v", got.Game, tt.want.Game)
+			}
+		})
+	}
+}
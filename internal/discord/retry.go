@@ -0,0 +1,309 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Default retry settings used when RetryPolicy fields are unset.
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 200 * time.Millisecond
+)
+
+// RetryPolicy controls how RetryClient retries transient failures.
+// MaxAttempts is the total number of attempts per call, including the first;
+// BaseDelay is the wait before the first retry and doubles on each further
+// retry. Zero or negative fields fall back to 3 attempts and 200ms.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+// withDefaults returns a copy of p with unset fields filled in.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = defaultRetryBaseDelay
+	}
+	return p
+}
+
+// RetryClient is a DiscordClient that retries calls failing with a Discord
+// 5xx response or a network error, backing off exponentially between
+// attempts. 4xx responses (including rate limits) are returned immediately.
+// Only idempotent calls (reads, edits, deletes, reactions and typing) are
+// retried: calls that create something, such as sending a message or
+// creating an invite, are made once, since a request that timed out may
+// still have succeeded and repeating it would create a duplicate.
+// Waits honour the context supplied via discordgo.WithContext, so a cancelled
+// request stops retrying. With WithCircuitBreaker, calls fail fast with
+// ErrCircuitOpen while Discord appears to be down.
+type RetryClient struct {
+	next    DiscordClient
+	policy  RetryPolicy
+	breaker *circuitBreaker
+}
+
+// RetryOption configures a RetryClient.
+type RetryOption func(*RetryClient)
+
+// WithCircuitBreaker guards every call with a circuit breaker configured by
+// policy, so that an outage fails calls immediately instead of making each
+// one wait out its retries. The breaker sees a call once, after its retries.
+func WithCircuitBreaker(policy BreakerPolicy) RetryOption {
+	return func(c *RetryClient) {
+		c.breaker = newCircuitBreaker(policy)
+	}
+}
+
+// Compile-time assertion: *RetryClient satisfies DiscordClient.
+var _ DiscordClient = (*RetryClient)(nil)
+
+// NewRetryClient wraps next so that transient failures are retried according
+// to policy.
+func NewRetryClient(next DiscordClient, policy RetryPolicy, opts ...RetryOption) *RetryClient {
+	c := &RetryClient{next: next, policy: policy.withDefaults()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// RequestContext returns the context that options attach to a REST request,
+// or context.Background() when none is set.
+func RequestContext(options ...discordgo.RequestOption) context.Context {
+	req, _ := http.NewRequest(http.MethodGet, "http://discord.invalid/", nil)
+	cfg := &discordgo.RequestConfig{Request: req}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	return cfg.Request.Context()
+}
+
+// retry calls fn until it succeeds, fails with a non-transient error, or the
+// attempts are exhausted. An open circuit breaker fails the call before fn
+// is called.
+func retry[T any](c *RetryClient, options []discordgo.RequestOption, fn func() (T, error)) (T, error) {
+	probe, err := c.breaker.allow()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	ctx := RequestContext(options...)
+	v, err := attempt(ctx, c, fn)
+	c.breaker.record(ctx, probe, err)
+	return v, err
+}
+
+// attempt runs fn with retries and backoff according to c's policy.
+func attempt[T any](ctx context.Context, c *RetryClient, fn func() (T, error)) (T, error) {
+	delay := c.policy.BaseDelay
+	for n := 1; ; n++ {
+		v, err := fn()
+		if err == nil || n >= c.policy.MaxAttempts || !isTransient(ctx, err) {
+			return v, err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return v, err
+		}
+		delay *= 2
+	}
+}
+
+// once makes a single attempt at a non-idempotent call, still guarded by the
+// circuit breaker.
+func once[T any](c *RetryClient, options []discordgo.RequestOption, fn func() (T, error)) (T, error) {
+	probe, err := c.breaker.allow()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	v, err := fn()
+	c.breaker.record(RequestContext(options...), probe, err)
+	return v, err
+}
+
+// retryErr adapts an error-only call for retry.
+func retryErr(c *RetryClient, options []discordgo.RequestOption, fn func() error) error {
+	_, err := retry(c, options, func() (struct{}, error) { return struct{}{}, fn() })
+	return err
+}
+
+// isTransient reports whether err is worth retrying: a Discord 5xx response
+// or a network error not caused by ctx ending.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) {
+		return restErr.Response != nil && restErr.Response.StatusCode >= http.StatusInternalServerError
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr)
+}
+
+func (c *RetryClient) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return once(c, options, func() (*discordgo.Message, error) {
+		return c.next.ChannelMessageSendComplex(channelID, data, options...)
+	})
+}
+
+func (c *RetryClient) ChannelMessage(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return retry(c, options, func() (*discordgo.Message, error) {
+		return c.next.ChannelMessage(channelID, messageID, options...)
+	})
+}
+
+func (c *RetryClient) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+	return retry(c, options, func() ([]*discordgo.Message, error) {
+		return c.next.ChannelMessages(channelID, limit, beforeID, afterID, aroundID, options...)
+	})
+}
+
+func (c *RetryClient) ChannelMessagesPinned(channelID string, before *time.Time, limit int, options ...discordgo.RequestOption) (*discordgo.ChannelMessagePinsList, error) {
+	return retry(c, options, func() (*discordgo.ChannelMessagePinsList, error) {
+		return c.next.ChannelMessagesPinned(channelID, before, limit, options...)
+	})
+}
+
+func (c *RetryClient) ChannelMessageEdit(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return retry(c, options, func() (*discordgo.Message, error) {
+		return c.next.ChannelMessageEdit(channelID, messageID, content, options...)
+	})
+}
+
+func (c *RetryClient) ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return retry(c, options, func() (*discordgo.Message, error) {
+		return c.next.ChannelMessageEditComplex(m, options...)
+	})
+}
+
+func (c *RetryClient) ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error {
+	return retryErr(c, options, func() error {
+		return c.next.ChannelMessageDelete(channelID, messageID, options...)
+	})
+}
+
+func (c *RetryClient) MessageReactionAdd(channelID, messageID, emojiID string, options ...discordgo.RequestOption) error {
+	return retryErr(c, options, func() error {
+		return c.next.MessageReactionAdd(channelID, messageID, emojiID, options...)
+	})
+}
+
+func (c *RetryClient) MessageReactionRemove(channelID, messageID, emojiID, userID string, options ...discordgo.RequestOption) error {
+	return retryErr(c, options, func() error {
+		return c.next.MessageReactionRemove(channelID, messageID, emojiID, userID, options...)
+	})
+}
+
+func (c *RetryClient) MessageReactions(channelID, messageID, emojiID string, limit int, beforeID, afterID string, options ...discordgo.RequestOption) ([]*discordgo.User, error) {
+	return retry(c, options, func() ([]*discordgo.User, error) {
+		return c.next.MessageReactions(channelID, messageID, emojiID, limit, beforeID, afterID, options...)
+	})
+}
+
+func (c *RetryClient) GuildChannels(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+	return retry(c, options, func() ([]*discordgo.Channel, error) {
+		return c.next.GuildChannels(guildID, options...)
+	})
+}
+
+func (c *RetryClient) Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	return retry(c, options, func() (*discordgo.Guild, error) {
+		return c.next.Guild(guildID, options...)
+	})
+}
+
+func (c *RetryClient) GuildEmojis(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Emoji, error) {
+	return retry(c, options, func() ([]*discordgo.Emoji, error) {
+		return c.next.GuildEmojis(guildID, options...)
+	})
+}
+
+func (c *RetryClient) GuildAuditLog(guildID, userID, beforeID string, actionType, limit int, options ...discordgo.RequestOption) (*discordgo.GuildAuditLog, error) {
+	return retry(c, options, func() (*discordgo.GuildAuditLog, error) {
+		return c.next.GuildAuditLog(guildID, userID, beforeID, actionType, limit, options...)
+	})
+}
+
+func (c *RetryClient) GuildInvites(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Invite, error) {
+	return retry(c, options, func() ([]*discordgo.Invite, error) {
+		return c.next.GuildInvites(guildID, options...)
+	})
+}
+
+func (c *RetryClient) GuildThreadsActive(guildID string, options ...discordgo.RequestOption) (*discordgo.ThreadsList, error) {
+	return retry(c, options, func() (*discordgo.ThreadsList, error) {
+		return c.next.GuildThreadsActive(guildID, options...)
+	})
+}
+
+func (c *RetryClient) ThreadsArchived(channelID string, before *time.Time, limit int, options ...discordgo.RequestOption) (*discordgo.ThreadsList, error) {
+	return retry(c, options, func() (*discordgo.ThreadsList, error) {
+		return c.next.ThreadsArchived(channelID, before, limit, options...)
+	})
+}
+
+func (c *RetryClient) MessageThreadStartComplex(channelID, messageID string, data *discordgo.ThreadStart, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return once(c, options, func() (*discordgo.Channel, error) {
+		return c.next.MessageThreadStartComplex(channelID, messageID, data, options...)
+	})
+}
+
+func (c *RetryClient) ChannelTyping(channelID string, options ...discordgo.RequestOption) error {
+	return retryErr(c, options, func() error {
+		return c.next.ChannelTyping(channelID, options...)
+	})
+}
+
+func (c *RetryClient) ChannelInviteCreate(channelID string, i discordgo.Invite, options ...discordgo.RequestOption) (*discordgo.Invite, error) {
+	return once(c, options, func() (*discordgo.Invite, error) {
+		return c.next.ChannelInviteCreate(channelID, i, options...)
+	})
+}
+
+func (c *RetryClient) User(userID string, options ...discordgo.RequestOption) (*discordgo.User, error) {
+	return retry(c, options, func() (*discordgo.User, error) {
+		return c.next.User(userID, options...)
+	})
+}
+
+func (c *RetryClient) UserChannelPermissions(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error) {
+	return retry(c, fetchOptions, func() (int64, error) {
+		return c.next.UserChannelPermissions(userID, channelID, fetchOptions...)
+	})
+}
+
+func (c *RetryClient) ChannelWebhooks(channelID string, options ...discordgo.RequestOption) ([]*discordgo.Webhook, error) {
+	return retry(c, options, func() ([]*discordgo.Webhook, error) {
+		return c.next.ChannelWebhooks(channelID, options...)
+	})
+}
+
+func (c *RetryClient) WebhookCreate(channelID, name, avatar string, options ...discordgo.RequestOption) (*discordgo.Webhook, error) {
+	return once(c, options, func() (*discordgo.Webhook, error) {
+		return c.next.WebhookCreate(channelID, name, avatar, options...)
+	})
+}
+
+func (c *RetryClient) WebhookExecute(webhookID, token string, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return once(c, options, func() (*discordgo.Message, error) {
+		return c.next.WebhookExecute(webhookID, token, wait, data, options...)
+	})
+}
@@ -0,0 +1,375 @@
+package discord_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/testutil"
+)
+
+// statusError returns a discordgo REST error carrying the given HTTP status.
+func statusError(code int) error {
+	return &discordgo.RESTError{Response: &http.Response{StatusCode: code}}
+}
+
+// ---------------------------------------------------------------------------
+// RetryClient
+// ---------------------------------------------------------------------------
+
+func Test_RetryClient_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		errs      []error // returned by successive calls; nil thereafter
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "503 twice then success", errs: []error{statusError(503), statusError(503)}, wantCalls: 3, wantErr: false},
+		{name: "gives up after max attempts", errs: []error{statusError(502), statusError(502), statusError(502), statusError(502)}, wantCalls: 3, wantErr: true},
+		{name: "4xx not retried", errs: []error{statusError(404)}, wantCalls: 1, wantErr: true},
+		{name: "429 not retried", errs: []error{statusError(429)}, wantCalls: 1, wantErr: true},
+		{name: "network error retried", errs: []error{&netError{}}, wantCalls: 2, wantErr: false},
+		{name: "non-transient error not retried", errs: []error{errors.New("boom")}, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			mock := &testutil.MockDiscordClient{
+				ChannelMessageFunc: func(channelID, messageID string, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+					calls++
+					if calls <= len(tt.errs) {
+						return nil, tt.errs[calls-1]
+					}
+					return &discordgo.Message{ID: "sent"}, nil
+				},
+			}
+			client := discord.NewRetryClient(mock, discord.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+			msg, err := client.ChannelMessage("ch-1", "m-1")
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && msg.ID != "sent" {
+				t.Errorf("msg.ID = %q, want sent", msg.ID)
+			}
+		})
+	}
+}
+
+func Test_RetryClient_CreatesNotRetried(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	mock := &testutil.MockDiscordClient{
+		ChannelMessageSendComplexFunc: func(string, *discordgo.MessageSend, ...discordgo.RequestOption) (*discordgo.Message, error) {
+			calls.Add(1)
+			return nil, statusError(503)
+		},
+		ChannelInviteCreateFunc: func(string, discordgo.Invite, ...discordgo.RequestOption) (*discordgo.Invite, error) {
+			calls.Add(1)
+			return nil, &netError{}
+		},
+		WebhookCreateFunc: func(string, string, string, ...discordgo.RequestOption) (*discordgo.Webhook, error) {
+			calls.Add(1)
+			return nil, statusError(502)
+		},
+		WebhookExecuteFunc: func(string, string, bool, *discordgo.WebhookParams, ...discordgo.RequestOption) (*discordgo.Message, error) {
+			calls.Add(1)
+			return nil, statusError(503)
+		},
+		MessageThreadStartComplexFunc: func(string, string, *discordgo.ThreadStart, ...discordgo.RequestOption) (*discordgo.Channel, error) {
+			calls.Add(1)
+			return nil, statusError(503)
+		},
+	}
+	client := discord.NewRetryClient(mock, discord.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	creates := map[string]func() error{
+		"ChannelMessageSendComplex": func() error {
+			_, err := client.ChannelMessageSendComplex("ch-1", &discordgo.MessageSend{Content: "hi"})
+			return err
+		},
+		"ChannelInviteCreate": func() error {
+			_, err := client.ChannelInviteCreate("ch-1", discordgo.Invite{})
+			return err
+		},
+		"WebhookCreate": func() error {
+			_, err := client.WebhookCreate("ch-1", "bot", "")
+			return err
+		},
+		"WebhookExecute": func() error {
+			_, err := client.WebhookExecute("wh-1", "tok", true, &discordgo.WebhookParams{Content: "hi"})
+			return err
+		},
+		"MessageThreadStartComplex": func() error {
+			_, err := client.MessageThreadStartComplex("ch-1", "m-1", &discordgo.ThreadStart{Name: "t"})
+			return err
+		},
+	}
+	for name, call := range creates {
+		calls.Store(0)
+		if err := call(); err == nil {
+			t.Errorf("%s: expected the error to be returned", name)
+		}
+		if n := calls.Load(); n != 1 {
+			t.Errorf("%s: calls = %d, want 1", name, n)
+		}
+	}
+}
+
+func Test_RetryClient_CancelledContextStopsRetrying(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	mock := &testutil.MockDiscordClient{
+		ChannelMessageDeleteFunc: func(channelID, messageID string, _ ...discordgo.RequestOption) error {
+			calls++
+			return statusError(503)
+		},
+	}
+	client := discord.NewRetryClient(mock, discord.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	err := client.ChannelMessageDelete("ch-1", "m-1", discordgo.WithContext(ctx))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("retry wait ignored cancellation, took %v", elapsed)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Circuit breaker
+// ---------------------------------------------------------------------------
+
+func Test_RetryClient_CircuitBreaker_TripsAndRecovers(t *testing.T) {
+	t.Parallel()
+
+	const cooldown = 50 * time.Millisecond
+	var calls atomic.Int32
+	var down atomic.Bool
+	down.Store(true)
+	mock := &testutil.MockDiscordClient{
+		ChannelMessageFunc: func(channelID, messageID string, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+			calls.Add(1)
+			if down.Load() {
+				return nil, statusError(503)
+			}
+			return &discordgo.Message{ID: messageID}, nil
+		},
+	}
+	client := discord.NewRetryClient(mock, discord.RetryPolicy{MaxAttempts: 1},
+		discord.WithCircuitBreaker(discord.BreakerPolicy{Threshold: 3, Cooldown: cooldown}))
+
+	for i := range 3 {
+		if _, err := client.ChannelMessage("ch-1", "m-1"); err == nil || errors.Is(err, discord.ErrCircuitOpen) {
+			t.Fatalf("call %d: err = %v, want the 503", i+1, err)
+		}
+	}
+	if _, err := client.ChannelMessage("ch-1", "m-1"); !errors.Is(err, discord.ErrCircuitOpen) {
+		t.Fatalf("after 3 failures: err = %v, want ErrCircuitOpen", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("calls = %d, want 3; an open breaker must not reach Discord", got)
+	}
+
+	// A failed trial call after the cooldown reopens the breaker at once.
+	time.Sleep(cooldown)
+	if _, err := client.ChannelMessage("ch-1", "m-1"); err == nil || errors.Is(err, discord.ErrCircuitOpen) {
+		t.Fatalf("trial call: err = %v, want the 503", err)
+	}
+	if _, err := client.ChannelMessage("ch-1", "m-1"); !errors.Is(err, discord.ErrCircuitOpen) {
+		t.Fatalf("after failed trial: err = %v, want ErrCircuitOpen", err)
+	}
+
+	// Once Discord recovers, a successful trial closes the breaker.
+	down.Store(false)
+	time.Sleep(cooldown)
+	for i := range 2 {
+		if _, err := client.ChannelMessage("ch-1", "m-1"); err != nil {
+			t.Fatalf("call %d after recovery: %v", i+1, err)
+		}
+	}
+	if got := calls.Load(); got != 6 {
+		t.Errorf("calls = %d, want 6", got)
+	}
+}
+
+func Test_RetryClient_CircuitBreaker_StaleCallDoesNotDecideProbe(t *testing.T) {
+	t.Parallel()
+
+	const cooldown = 50 * time.Millisecond
+	started := make(chan string, 2)
+	staleRelease := make(chan struct{})
+	probeRelease := make(chan struct{})
+	mock := &testutil.MockDiscordClient{
+		ChannelMessageFunc: func(channelID, messageID string, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+			switch messageID {
+			case "stale":
+				started <- messageID
+				<-staleRelease
+				return &discordgo.Message{ID: messageID}, nil
+			case "probe":
+				started <- messageID
+				<-probeRelease
+			}
+			return nil, statusError(503)
+		},
+	}
+	client := discord.NewRetryClient(mock, discord.RetryPolicy{MaxAttempts: 1},
+		discord.WithCircuitBreaker(discord.BreakerPolicy{Threshold: 3, Cooldown: cooldown}))
+
+	// A call starts while the breaker is closed and outlives its opening.
+	staleDone := make(chan error)
+	go func() {
+		_, err := client.ChannelMessage("ch-1", "stale")
+		staleDone <- err
+	}()
+	<-started
+	for i := range 3 {
+		if _, err := client.ChannelMessage("ch-1", "m-1"); err == nil || errors.Is(err, discord.ErrCircuitOpen) {
+			t.Fatalf("call %d: err = %v, want the 503", i+1, err)
+		}
+	}
+
+	time.Sleep(cooldown)
+	probeDone := make(chan error)
+	go func() {
+		_, err := client.ChannelMessage("ch-1", "probe")
+		probeDone <- err
+	}()
+	<-started
+
+	// The stale call's success neither closes the breaker nor frees the
+	// trial slot while the probe is still in flight.
+	close(staleRelease)
+	if err := <-staleDone; err != nil {
+		t.Fatalf("stale call: %v", err)
+	}
+	if _, err := client.ChannelMessage("ch-1", "m-1"); !errors.Is(err, discord.ErrCircuitOpen) {
+		t.Fatalf("during probe: err = %v, want ErrCircuitOpen", err)
+	}
+
+	// The probe's failure reopens it.
+	close(probeRelease)
+	if err := <-probeDone; err == nil || errors.Is(err, discord.ErrCircuitOpen) {
+		t.Fatalf("probe: err = %v, want the 503", err)
+	}
+	if _, err := client.ChannelMessage("ch-1", "m-1"); !errors.Is(err, discord.ErrCircuitOpen) {
+		t.Errorf("after failed probe: err = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func Test_RetryClient_CircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"404", statusError(404)},
+		{"429", statusError(429)},
+		{"plain error", errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mock := &testutil.MockDiscordClient{
+				ChannelMessageDeleteFunc: func(channelID, messageID string, _ ...discordgo.RequestOption) error {
+					return tt.err
+				},
+			}
+			client := discord.NewRetryClient(mock, discord.RetryPolicy{MaxAttempts: 1},
+				discord.WithCircuitBreaker(discord.BreakerPolicy{Threshold: 2, Cooldown: time.Hour}))
+
+			for i := range 5 {
+				if err := client.ChannelMessageDelete("ch-1", "m-1"); errors.Is(err, discord.ErrCircuitOpen) {
+					t.Fatalf("call %d: breaker opened on %v", i+1, tt.err)
+				}
+			}
+		})
+	}
+}
+
+func Test_RetryClient_CircuitBreaker_SuccessResetsCount(t *testing.T) {
+	t.Parallel()
+
+	errs := []error{statusError(500), statusError(500), nil, statusError(500), statusError(500)}
+	calls := 0
+	mock := &testutil.MockDiscordClient{
+		ChannelMessageDeleteFunc: func(channelID, messageID string, _ ...discordgo.RequestOption) error {
+			calls++
+			return errs[(calls-1)%len(errs)]
+		},
+	}
+	client := discord.NewRetryClient(mock, discord.RetryPolicy{MaxAttempts: 1},
+		discord.WithCircuitBreaker(discord.BreakerPolicy{Threshold: 3, Cooldown: time.Hour}))
+
+	for i := range errs {
+		if err := client.ChannelMessageDelete("ch-1", "m-1"); errors.Is(err, discord.ErrCircuitOpen) {
+			t.Fatalf("call %d: breaker opened although failures were not consecutive", i+1)
+		}
+	}
+	// The third consecutive failure trips it.
+	if err := client.ChannelMessageDelete("ch-1", "m-1"); errors.Is(err, discord.ErrCircuitOpen) {
+		t.Fatal("breaker opened before the third consecutive failure")
+	}
+	if err := client.ChannelMessageDelete("ch-1", "m-1"); !errors.Is(err, discord.ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func Test_RetryClient_CircuitBreaker_Disabled(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	mock := &testutil.MockDiscordClient{
+		ChannelMessageDeleteFunc: func(channelID, messageID string, _ ...discordgo.RequestOption) error {
+			calls++
+			return statusError(503)
+		},
+	}
+	client := discord.NewRetryClient(mock, discord.RetryPolicy{MaxAttempts: 1},
+		discord.WithCircuitBreaker(discord.BreakerPolicy{Threshold: -1}))
+
+	for range 10 {
+		if err := client.ChannelMessageDelete("ch-1", "m-1"); errors.Is(err, discord.ErrCircuitOpen) {
+			t.Fatal("disabled breaker opened")
+		}
+	}
+	if calls != 10 {
+		t.Errorf("calls = %d, want 10", calls)
+	}
+}
+
+// netError is a minimal net.Error for simulating connection failures.
+type netError struct{}
+
+func (*netError) Error() string   { return "connection reset" }
+func (*netError) Timeout() bool   { return false }
+func (*netError) Temporary() bool { return true }
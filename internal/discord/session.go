@@ -3,7 +3,11 @@
 package discord
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/queue"
@@ -11,6 +15,14 @@ import (
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
 )
 
+// Retry schedule for the channel cache refresh run when the gateway becomes
+// ready: up to refreshAttempts tries, waiting defaultRefreshBackoff before the
+// first retry and doubling the wait after each one.
+const (
+	refreshAttempts       = 5
+	defaultRefreshBackoff = time.Second
+)
+
 // Session wraps a discordgo.Session and routes incoming guild messages through
 // the safety filter before pushing them onto the message queue.
 type Session struct {
@@ -18,22 +30,145 @@ type Session struct {
 	guildID  string
 	queue    *queue.Queue
 	resolver *resolve.Resolver
-	// filter applies channel filtering at the ingestion level, preventing
-	// messages from denied channels from entering the queue. When nil, all
-	// messages from the configured guild are enqueued. Currently,
-	// NewFromSession passes nil for this field; channel filtering is
-	// enforced at the tool handler level instead. The field is exercised
-	// by tests via the internal newFromSessionFull constructor.
-	filter *safety.Filter
-	logger *slog.Logger
+	// filters applies channel filtering at the ingestion level, preventing
+	// messages from channels denied for reading from entering the queue.
+	// Each event is checked against the filter for its own guild. When nil,
+	// all messages from the configured guild are enqueued. It is set with
+	// SetGuildFilters or SetFilter and may be swapped while events are being
+	// handled.
+	filters atomic.Pointer[safety.GuildFilters]
+	// userFilter drops messages from denied users before they reach the
+	// queue. Entries are matched against both the author ID and username.
+	// When nil, messages from every user are enqueued.
+	userFilter *safety.Filter
+	// contentFilter drops messages whose content does not pass the keyword
+	// and regex rules, such as requiring a command prefix. When nil, all
+	// content is enqueued.
+	contentFilter *safety.ContentFilter
+	// replyContextLen is the maximum number of characters of a replied-to
+	// message's content copied into QueuedMessage.ReplyToContent. Zero
+	// disables reply context.
+	replyContextLen int
+	// typingEvents enqueues an EventTyping entry whenever a user starts
+	// typing; see WithTypingEvents.
+	typingEvents bool
+	// expandMentions rewrites mention tokens in queued content as readable
+	// names; see WithMentionExpansion.
+	expandMentions bool
+	// commandPrefix, when set, parses messages starting with it into
+	// QueuedMessage.Command and Args; see WithCommandPrefix.
+	commandPrefix string
+	// idle, when set, is told about each enqueued event so the gateway is
+	// not closed while events are arriving; see WithIdleMonitor.
+	idle *IdleMonitor
+	// intents are the gateway intents requested when the session opens.
+	intents discordgo.Intent
+	// shutdown ends onReady's refresh retries early when cancelled; see
+	// WithShutdown.
+	shutdown context.Context
+	// refreshBackoff is the wait before the first retry of a failed channel
+	// cache refresh in onReady.
+	refreshBackoff time.Duration
+	logger         *slog.Logger
+}
+
+// SessionOption is a functional option for configuring a Session.
+type SessionOption func(*Session)
+
+// WithUserFilter sets a filter applied to each message author's ID and
+// username at ingestion. Messages from denied users never enter the queue.
+func WithUserFilter(f *safety.Filter) SessionOption {
+	return func(s *Session) {
+		s.userFilter = f
+	}
+}
+
+// WithContentFilter sets a filter applied to each message's content after
+// the channel filter. Messages it rejects never enter the queue.
+func WithContentFilter(f *safety.ContentFilter) SessionOption {
+	return func(s *Session) {
+		s.contentFilter = f
+	}
+}
+
+// WithReplyContext copies the author and up to maxLen characters of the
+// content of a replied-to message into each reply's QueuedMessage. The
+// referenced message comes from the gateway event or the state cache, so no
+// extra REST calls are made. Values of zero or less disable reply context.
+func WithReplyContext(maxLen int) SessionOption {
+	return func(s *Session) {
+		if maxLen > 0 {
+			s.replyContextLen = maxLen
+		}
+	}
+}
+
+// WithIntents sets the gateway intents requested when the session opens,
+// replacing DefaultIntents. Without IntentMessageContent Discord delivers
+// guild messages with empty content unless they mention the bot.
+func WithIntents(intents discordgo.Intent) SessionOption {
+	return func(s *Session) {
+		s.intents = intents
+	}
+}
+
+// WithTypingEvents enqueues a typing event (queue.EventTyping) each time a
+// user starts typing in a channel that passes the channel and user filters,
+// so agents can hold off replying. It adds IntentGuildMessageTyping to the
+// requested intents.
+func WithTypingEvents(enabled bool) SessionOption {
+	return func(s *Session) {
+		s.typingEvents = enabled
+	}
+}
+
+// WithMentionExpansion rewrites user, role and channel mention tokens such
+// as <@123> and <#456> in each queued message's content as @username,
+// @role-name and #channel-name. The original content is kept in
+// QueuedMessage.RawContent. Names come from the gateway event, the state
+// cache and the channel resolver; tokens whose name is unknown are left as
+// they are. Content filtering still sees the original content.
+func WithMentionExpansion(enabled bool) SessionOption {
+	return func(s *Session) {
+		s.expandMentions = enabled
+	}
+}
+
+// WithCommandPrefix parses each message whose content starts with prefix,
+// such as "!", as a bot command: `!say hello world` is queued with Command
+// "say" and Args ["hello", "world"]. Command names are lowercased and a
+// double-quoted argument may contain spaces. The original content is parsed,
+// before any mention expansion. Other messages are queued with no command,
+// and an empty prefix disables parsing.
+func WithCommandPrefix(prefix string) SessionOption {
+	return func(s *Session) {
+		s.commandPrefix = prefix
+	}
+}
+
+// WithIdleMonitor records each enqueued message and event as activity on m,
+// postponing its idle disconnect. A nil monitor is ignored.
+func WithIdleMonitor(m *IdleMonitor) SessionOption {
+	return func(s *Session) {
+		s.idle = m
+	}
+}
+
+// WithShutdown stops the channel cache refresh retries made on connect when
+// ctx is cancelled, so they do not delay shutdown. A nil ctx is ignored.
+func WithShutdown(ctx context.Context) SessionOption {
+	return func(s *Session) {
+		if ctx != nil {
+			s.shutdown = ctx
+		}
+	}
 }
 
 // NewFromSession wraps an existing *discordgo.Session, registering message and
-// ready event handlers and configuring the required gateway intents. The guild
-// ID is read from the resolver. A nil filter allows all channels; a nil logger
-// defaults to slog.Default().
+// ready event handlers and configuring the gateway intents. The guild ID is
+// read from the resolver. A nil logger defaults to slog.Default().
 //
-// Intents enabled:
+// Intents enabled unless overridden with WithIntents (DefaultIntents):
 //   - IntentGuilds
 //   - IntentGuildMessages
 //   - IntentMessageContent
@@ -43,8 +178,9 @@ func NewFromSession(
 	q *queue.Queue,
 	r *resolve.Resolver,
 	logger *slog.Logger,
+	opts ...SessionOption,
 ) *Session {
-	return newFromSessionFull(dg, q, r, nil, logger)
+	return newFromSessionFull(dg, q, r, nil, logger, opts...)
 }
 
 // newFromSessionFull is the internal constructor used by NewFromSession and by
@@ -56,6 +192,7 @@ func newFromSessionFull(
 	r *resolve.Resolver,
 	filter *safety.Filter,
 	logger *slog.Logger,
+	opts ...SessionOption,
 ) *Session {
 	if logger == nil {
 		logger = slog.Default()
@@ -66,21 +203,61 @@ func newFromSessionFull(
 		guildID:  r.GuildID(),
 		queue:    q,
 		resolver: r,
-		filter:   filter,
+		intents:  DefaultIntents,
 		logger:   logger,
+
+		refreshBackoff: defaultRefreshBackoff,
+		shutdown:       context.Background(),
+	}
+	s.SetFilter(filter)
+	for _, opt := range opts {
+		opt(s)
 	}
 
-	dg.Identify.Intents = discordgo.IntentGuilds |
-		discordgo.IntentGuildMessages |
-		discordgo.IntentMessageContent |
-		discordgo.IntentGuildMessageReactions
+	if s.typingEvents {
+		s.intents |= discordgo.IntentGuildMessageTyping
+	}
+	dg.Identify.Intents = s.intents
+	if s.intents&discordgo.IntentMessageContent == 0 {
+		logger.Warn("message_content intent disabled: queued messages will have empty content unless they mention the bot")
+	}
 
 	dg.AddHandler(s.onReady)
 	dg.AddHandler(s.onMessageCreate)
+	if s.typingEvents {
+		dg.AddHandler(s.onTypingStart)
+	}
 
 	return s
 }
 
+// SetFilter replaces the channel filter applied at ingestion with f for
+// every guild; see SetGuildFilters. A nil filter enqueues messages from
+// every channel.
+func (s *Session) SetFilter(f *safety.Filter) {
+	if f == nil {
+		s.filters.Store(nil)
+		return
+	}
+	s.filters.Store(safety.NewGuildFilters(f, nil))
+}
+
+// SetGuildFilters replaces the channel filters applied at ingestion. Each
+// message is checked against the filter for its event's guild, and messages
+// in channels it denies for reading are dropped before they reach the queue.
+// A nil g enqueues messages from every channel. It is safe to call while the
+// session is open, e.g. when reloading configuration.
+func (s *Session) SetGuildFilters(g *safety.GuildFilters) {
+	s.filters.Store(g)
+}
+
+// channelAllowed reports whether the channel name in guildID passes the
+// ingestion filters for reading.
+func (s *Session) channelAllowed(guildID, channelName string) bool {
+	g := s.filters.Load()
+	return g == nil || g.IsAllowedFor(guildID, safety.OpRead, channelName)
+}
+
 // Open establishes the WebSocket connection to the Discord gateway.
 // It must be called after NewFromSession to begin receiving events.
 func (s *Session) Open() error {
@@ -100,20 +277,56 @@ func (s *Session) DiscordSession() *discordgo.Session {
 }
 
 // onReady is called when the Discord gateway confirms the bot is connected.
-// It logs the bot's username and triggers an initial channel cache refresh.
+// It logs the bot's username and refreshes the channel cache, retrying with
+// backoff so a transient failure does not leave channel names unresolvable.
 func (s *Session) onReady(dg *discordgo.Session, event *discordgo.Ready) {
 	s.logger.Info("discord connected",
 		"username", event.User.Username,
 		"discriminator", event.User.Discriminator,
 	)
-	if err := s.resolver.Refresh(); err != nil {
-		s.logger.Warn("channel cache refresh failed", "error", err)
+	if err := s.refreshChannels(); err != nil {
+		s.logger.Error("channel cache refresh failed; channel names will not resolve until the next reconnect",
+			"attempts", refreshAttempts,
+			"error", err,
+		)
+	}
+}
+
+// refreshChannels refreshes the resolver's channel cache, making up to
+// refreshAttempts attempts with exponential backoff between them. It returns
+// the last error if every attempt fails, or as soon as the shutdown context
+// is cancelled during a wait.
+func (s *Session) refreshChannels() error {
+	delay := s.refreshBackoff
+	for attempt := 1; ; attempt++ {
+		err := s.resolver.Refresh()
+		if err == nil {
+			if attempt > 1 {
+				s.logger.Info("channel cache refreshed", "attempts", attempt)
+			}
+			return nil
+		}
+		if attempt == refreshAttempts {
+			return err
+		}
+		s.logger.Warn("channel cache refresh failed, retrying",
+			"attempt", attempt,
+			"retry_in", delay,
+			"error", err,
+		)
+		select {
+		case <-time.After(delay):
+		case <-s.shutdown.Done():
+			return fmt.Errorf("%w (retries stopped: %w)", err, s.shutdown.Err())
+		}
+		delay *= 2
 	}
 }
 
 // onMessageCreate handles incoming Discord message events. It filters out bot
-// messages, messages from other guilds, and messages in denied channels before
-// resolving the channel name and enqueueing the message.
+// messages, messages from other guilds or denied users, messages in denied
+// channels, and messages rejected by the content filter, then enqueues the
+// rest with their resolved channel name.
 func (s *Session) onMessageCreate(dg *discordgo.Session, event *discordgo.MessageCreate) {
 	if event.Author == nil {
 		return
@@ -129,15 +342,28 @@ func (s *Session) onMessageCreate(dg *discordgo.Session, event *discordgo.Messag
 		return
 	}
 
+	// Ignore messages from denied users, matched by ID or username.
+	if s.userFilter != nil && !s.userFilter.IsAllowedAny(event.Author.ID, event.Author.Username) {
+		s.logger.Debug("message filtered by user deny", "author_id", event.Author.ID, "author", event.Author.Username)
+		return
+	}
+
 	// Resolve the channel name for filter and display purposes.
 	channelName := s.resolver.ChannelName(event.ChannelID)
 
 	// Apply channel filter using the resolved name.
-	if s.filter != nil && !s.filter.IsAllowed(channelName) {
+	if !s.channelAllowed(event.GuildID, channelName) {
 		s.logger.Debug("message filtered by channel deny", "channel", channelName, "author", event.Author.Username)
 		return
 	}
 
+	// Apply the content filter (keywords, regexes, bot mentions).
+	mentioned := s.mentionsBot(event.Message)
+	if s.contentFilter != nil && !s.contentFilter.IsAllowed(event.Content, mentioned) {
+		s.logger.Debug("message filtered by content", "channel", channelName, "author", event.Author.Username)
+		return
+	}
+
 	// Build the message reference string if this is a reply.
 	var msgRef string
 	if event.MessageReference != nil {
@@ -146,15 +372,136 @@ func (s *Session) onMessageCreate(dg *discordgo.Session, event *discordgo.Messag
 
 	msg := queue.QueuedMessage{
 		ID:               event.ID,
+		GuildID:          event.GuildID,
 		ChannelID:        event.ChannelID,
 		ChannelName:      channelName,
 		AuthorID:         event.Author.ID,
 		AuthorUsername:   event.Author.Username,
 		Content:          event.Content,
-		Timestamp:        event.Timestamp,
+		Timestamp:        event.Timestamp.UTC(),
 		MessageReference: msgRef,
 	}
+	if mentioned {
+		msg.Priority = queue.PriorityHigh
+	}
+	if name, args, ok := parseCommand(event.Content, s.commandPrefix); ok {
+		msg.Command = name
+		msg.Args = args
+	}
+	if s.expandMentions {
+		if rendered := expandMentions(event.Content, s.mentionNamesFor(event.Message)); rendered != event.Content {
+			msg.RawContent = event.Content
+			msg.Content = rendered
+		}
+	}
+	if msgRef != "" && s.replyContextLen > 0 {
+		if ref := s.referencedMessage(event.Message); ref != nil {
+			if ref.Author != nil {
+				msg.ReplyToAuthor = ref.Author.Username
+			}
+			msg.ReplyToContent = truncate(ref.Content, s.replyContextLen)
+		}
+	}
 
-	s.queue.Enqueue(msg)
+	if err := s.queue.Enqueue(msg); err != nil {
+		s.logger.Warn("message dropped", "id", event.ID, "channel", channelName, "error", err)
+		return
+	}
+	s.idle.Touch()
 	s.logger.Debug("message enqueued", "id", event.ID, "channel", channelName, "author", event.Author.Username)
 }
+
+// onTypingStart handles gateway typing events when typing events are
+// enabled. Events from other guilds, from bots (including ourselves) and in
+// channels or from users the filters deny are ignored; the rest are enqueued
+// as queue.EventTyping entries. The username comes from the state cache and
+// is empty if the member is not cached.
+func (s *Session) onTypingStart(dg *discordgo.Session, event *discordgo.TypingStart) {
+	if event.GuildID != s.guildID {
+		return
+	}
+
+	var username string
+	if s.dg.State != nil {
+		if member, err := s.dg.State.Member(event.GuildID, event.UserID); err == nil && member.User != nil {
+			if member.User.Bot {
+				return
+			}
+			username = member.User.Username
+		}
+		if s.dg.State.User != nil && s.dg.State.User.ID == event.UserID {
+			return
+		}
+	}
+
+	if s.userFilter != nil && !s.userFilter.IsAllowedAny(event.UserID, username) {
+		return
+	}
+	channelName := s.resolver.ChannelName(event.ChannelID)
+	if !s.channelAllowed(event.GuildID, channelName) {
+		return
+	}
+
+	msg := queue.QueuedMessage{
+		Type:           queue.EventTyping,
+		GuildID:        event.GuildID,
+		ChannelID:      event.ChannelID,
+		ChannelName:    channelName,
+		AuthorID:       event.UserID,
+		AuthorUsername: username,
+		Timestamp:      time.Unix(int64(event.Timestamp), 0).UTC(),
+	}
+	if err := s.queue.Enqueue(msg); err != nil {
+		s.logger.Warn("typing event dropped", "channel", channelName, "user_id", event.UserID, "error", err)
+		return
+	}
+	s.idle.Touch()
+	s.logger.Debug("typing event enqueued", "channel", channelName, "user_id", event.UserID)
+}
+
+// mentionsBot reports whether m mentions the connected bot user. It returns
+// false before the gateway has identified the bot.
+func (s *Session) mentionsBot(m *discordgo.Message) bool {
+	if s.dg.State == nil || s.dg.State.User == nil {
+		return false
+	}
+	botID := s.dg.State.User.ID
+	for _, u := range m.Mentions {
+		if u != nil && u.ID == botID {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedMessage returns the message m replies to, taken from the gateway
+// event or, failing that, the state cache. It returns nil when the message
+// was deleted or is not cached; no REST call is made, so ingestion never
+// blocks on Discord.
+func (s *Session) referencedMessage(m *discordgo.Message) *discordgo.Message {
+	if m.ReferencedMessage != nil {
+		return m.ReferencedMessage
+	}
+	if s.dg.State == nil || m.MessageReference == nil {
+		return nil
+	}
+	ref := m.MessageReference
+	channelID := ref.ChannelID
+	if channelID == "" {
+		channelID = m.ChannelID
+	}
+	cached, err := s.dg.State.Message(channelID, ref.MessageID)
+	if err != nil {
+		return nil
+	}
+	return cached
+}
+
+// truncate shortens text to at most n runes, marking a cut with an ellipsis.
+func truncate(text string, n int) string {
+	r := []rune(text)
+	if len(r) <= n {
+		return text
+	}
+	return string(r[:n]) + "…"
+}
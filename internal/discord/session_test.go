@@ -2,8 +2,15 @@ package discord
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -21,7 +28,7 @@ import (
 // newTestSession constructs a *Session using newFromSessionFull so that a
 // custom safety.Filter and a silent logger can be injected. The returned queue
 // can be inspected after handler invocations to verify what was enqueued.
-func newTestSession(t *testing.T, guildID string, filter *safety.Filter) (*Session, *queue.Queue) {
+func newTestSession(t *testing.T, guildID string, filter *safety.Filter, opts ...SessionOption) (*Session, *queue.Queue) {
 	t.Helper()
 
 	dg, err := discordgo.New("Bot fake-token")
@@ -34,7 +41,10 @@ func newTestSession(t *testing.T, guildID string, filter *safety.Filter) (*Sessi
 
 	// Use a silent logger so tests don't spam stderr.
 	silent := slog.New(slog.NewTextHandler(io.Discard, nil))
-	s := newFromSessionFull(dg, q, r, filter, silent)
+	s := newFromSessionFull(dg, q, r, filter, silent, opts...)
+	// Keep onReady's refresh retries fast; the test session cannot reach
+	// Discord.
+	s.refreshBackoff = time.Millisecond
 
 	return s, q
 }
@@ -226,6 +236,156 @@ func Test_onMessageCreate_DeniedChannel_NotEnqueued(t *testing.T) {
 	}
 }
 
+func Test_onMessageCreate_DeniedUser_NotEnqueued(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		deny    []string
+		allow   []string
+		author  *discordgo.User
+		wantLen int
+	}{
+		{
+			name:    "denied user ID",
+			deny:    []string{"user-noisy"},
+			author:  &discordgo.User{ID: "user-noisy", Username: "Bob"},
+			wantLen: 0,
+		},
+		{
+			name:    "denied username",
+			deny:    []string{"spambot*"},
+			author:  &discordgo.User{ID: "user-2", Username: "SpamBot3000"},
+			wantLen: 0,
+		},
+		{
+			name:    "other user still enqueued",
+			deny:    []string{"user-noisy", "spambot*"},
+			author:  &discordgo.User{ID: "user-1", Username: "Alice"},
+			wantLen: 1,
+		},
+		{
+			name:    "allowlist matches username",
+			allow:   []string{"alice"},
+			author:  &discordgo.User{ID: "user-1", Username: "Alice"},
+			wantLen: 1,
+		},
+		{
+			name:    "allowlist excludes other users",
+			allow:   []string{"alice"},
+			author:  &discordgo.User{ID: "user-2", Username: "Bob"},
+			wantLen: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			users := safety.NewFilter(tt.allow, tt.deny)
+			s, q := newTestSession(t, "guild-1", nil, WithUserFilter(users))
+
+			event := &discordgo.MessageCreate{
+				Message: &discordgo.Message{
+					ID:        "msg-user",
+					ChannelID: "chan-1",
+					GuildID:   "guild-1",
+					Content:   "hello",
+					Author:    tt.author,
+				},
+			}
+
+			s.onMessageCreate(s.dg, event)
+
+			if q.Len() != tt.wantLen {
+				t.Errorf("queue Len() = %d, want %d", q.Len(), tt.wantLen)
+			}
+		})
+	}
+}
+
+func Test_onMessageCreate_ContentFilter_PrefixOnly(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		mentions []*discordgo.User
+		wantLen  int
+	}{
+		{name: "command prefix enqueued", content: "!bot status", wantLen: 1},
+		{name: "chatter dropped", content: "lunch anyone?", wantLen: 0},
+		{name: "prefix mid-sentence dropped", content: "what does !bot do", wantLen: 0},
+		{name: "bot mention enqueued", content: "hey you", mentions: []*discordgo.User{{ID: "bot-self"}}, wantLen: 1},
+		{name: "other mention dropped", content: "hey you", mentions: []*discordgo.User{{ID: "user-9"}}, wantLen: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := safety.NewContentFilter([]string{`re:^!bot\b`}, nil, safety.WithAllowMentions(true))
+			if err != nil {
+				t.Fatalf("NewContentFilter() error = %v", err)
+			}
+			s, q := newTestSession(t, "guild-1", nil, WithContentFilter(content))
+			s.dg.State.User = &discordgo.User{ID: "bot-self"}
+
+			event := &discordgo.MessageCreate{
+				Message: &discordgo.Message{
+					ID:        "msg-content",
+					ChannelID: "chan-1",
+					GuildID:   "guild-1",
+					Content:   tt.content,
+					Mentions:  tt.mentions,
+					Author:    &discordgo.User{ID: "user-1", Username: "Alice"},
+				},
+			}
+
+			s.onMessageCreate(s.dg, event)
+
+			if q.Len() != tt.wantLen {
+				t.Errorf("queue Len() = %d, want %d", q.Len(), tt.wantLen)
+			}
+		})
+	}
+}
+
+func Test_onMessageCreate_BotMention_JumpsAhead(t *testing.T) {
+	t.Parallel()
+
+	dg, err := discordgo.New("Bot fake-token")
+	if err != nil {
+		t.Fatalf("discordgo.New() error = %v", err)
+	}
+	dg.State.User = &discordgo.User{ID: "bot-self"}
+	q := queue.New(queue.WithPriorityLanes(true))
+	silent := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := newFromSessionFull(dg, q, resolve.New(dg, "guild-1"), nil, silent)
+
+	for _, m := range []*discordgo.Message{
+		{ID: "chatter-1", Content: "lunch?"},
+		{ID: "chatter-2", Content: "sure"},
+		{ID: "mention", Content: "<@bot-self> help", Mentions: []*discordgo.User{{ID: "bot-self"}}},
+	} {
+		m.ChannelID = "chan-1"
+		m.GuildID = "guild-1"
+		m.Author = &discordgo.User{ID: "user-1", Username: "Alice"}
+		s.onMessageCreate(dg, &discordgo.MessageCreate{Message: m})
+	}
+
+	msgs := drainQueue(q, 0)
+	if len(msgs) != 3 {
+		t.Fatalf("got %d messages, want 3", len(msgs))
+	}
+	if msgs[0].ID != "mention" || msgs[0].Priority != queue.PriorityHigh {
+		t.Errorf("first message = %q (priority %d), want mention with high priority", msgs[0].ID, msgs[0].Priority)
+	}
+	if msgs[1].ID != "chatter-1" || msgs[2].ID != "chatter-2" {
+		t.Errorf("normal lane order = [%s %s], want [chatter-1 chatter-2]", msgs[1].ID, msgs[2].ID)
+	}
+}
+
 func Test_onMessageCreate_NormalMessage_Enqueued(t *testing.T) {
 	t.Parallel()
 
@@ -286,6 +446,37 @@ func Test_onMessageCreate_NormalMessage_Enqueued(t *testing.T) {
 	}
 }
 
+func Test_onMessageCreate_CommandPrefix_ParsesCommand(t *testing.T) {
+	t.Parallel()
+
+	s, q := newTestSession(t, "guild-1", nil, WithCommandPrefix("!"))
+	for i, content := range []string{"!say hello world", "just chatting"} {
+		s.onMessageCreate(s.dg, &discordgo.MessageCreate{
+			Message: &discordgo.Message{
+				ID:        fmt.Sprintf("msg-%d", i),
+				ChannelID: "chan-1",
+				GuildID:   "guild-1",
+				Content:   content,
+				Author:    &discordgo.User{ID: "user-1", Username: "alice"},
+			},
+		})
+	}
+
+	msgs := drainQueue(q, 0)
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2", len(msgs))
+	}
+	if msgs[0].Command != "say" || !reflect.DeepEqual(msgs[0].Args, []string{"hello", "world"}) {
+		t.Errorf("command = %q %q, want say [hello world]", msgs[0].Command, msgs[0].Args)
+	}
+	if msgs[0].Content != "!say hello world" {
+		t.Errorf("Content = %q, want the original text", msgs[0].Content)
+	}
+	if msgs[1].Command != "" || msgs[1].Args != nil {
+		t.Errorf("non-command message has command %q %q, want none", msgs[1].Command, msgs[1].Args)
+	}
+}
+
 func Test_onMessageCreate_NilAuthor_NoPanic(t *testing.T) {
 	t.Parallel()
 
@@ -349,6 +540,200 @@ func Test_onMessageCreate_WithMessageReference_EnqueuedWithRef(t *testing.T) {
 	}
 }
 
+func Test_onMessageCreate_ReplyContext_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		opts        []SessionOption
+		referenced  *discordgo.Message
+		cached      *discordgo.Message
+		wantAuthor  string
+		wantContent string
+	}{
+		{
+			name:       "disabled by default",
+			referenced: &discordgo.Message{ID: "orig-1", Content: "hello", Author: &discordgo.User{Username: "Alice"}},
+		},
+		{
+			name:        "from gateway event",
+			opts:        []SessionOption{WithReplyContext(100)},
+			referenced:  &discordgo.Message{ID: "orig-1", Content: "hello", Author: &discordgo.User{Username: "Alice"}},
+			wantAuthor:  "Alice",
+			wantContent: "hello",
+		},
+		{
+			name:        "truncated",
+			opts:        []SessionOption{WithReplyContext(5)},
+			referenced:  &discordgo.Message{ID: "orig-1", Content: "hello world", Author: &discordgo.User{Username: "Alice"}},
+			wantAuthor:  "Alice",
+			wantContent: "hello…",
+		},
+		{
+			name:        "from state cache",
+			opts:        []SessionOption{WithReplyContext(100)},
+			cached:      &discordgo.Message{ID: "orig-1", ChannelID: "chan-1", Content: "cached", Author: &discordgo.User{Username: "Bob"}},
+			wantAuthor:  "Bob",
+			wantContent: "cached",
+		},
+		{
+			name: "deleted original",
+			opts: []SessionOption{WithReplyContext(100)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, q := newTestSession(t, "guild-1", nil, tc.opts...)
+			if tc.cached != nil {
+				s.dg.State.MaxMessageCount = 10
+				if err := s.dg.State.GuildAdd(&discordgo.Guild{ID: "guild-1"}); err != nil {
+					t.Fatalf("GuildAdd() error = %v", err)
+				}
+				if err := s.dg.State.ChannelAdd(&discordgo.Channel{ID: "chan-1", GuildID: "guild-1"}); err != nil {
+					t.Fatalf("ChannelAdd() error = %v", err)
+				}
+				if err := s.dg.State.MessageAdd(tc.cached); err != nil {
+					t.Fatalf("MessageAdd() error = %v", err)
+				}
+			}
+
+			s.onMessageCreate(s.dg, &discordgo.MessageCreate{
+				Message: &discordgo.Message{
+					ID:                "msg-reply",
+					ChannelID:         "chan-1",
+					GuildID:           "guild-1",
+					Content:           "replying",
+					Author:            &discordgo.User{ID: "user-3", Username: "Carol"},
+					MessageReference:  &discordgo.MessageReference{MessageID: "orig-1", ChannelID: "chan-1"},
+					ReferencedMessage: tc.referenced,
+				},
+			})
+
+			msgs := drainQueue(q, 1)
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 message from Poll, got %d", len(msgs))
+			}
+			if msgs[0].ReplyToAuthor != tc.wantAuthor {
+				t.Errorf("ReplyToAuthor = %q, want %q", msgs[0].ReplyToAuthor, tc.wantAuthor)
+			}
+			if msgs[0].ReplyToContent != tc.wantContent {
+				t.Errorf("ReplyToContent = %q, want %q", msgs[0].ReplyToContent, tc.wantContent)
+			}
+		})
+	}
+}
+
+func Test_onMessageCreate_Timestamp_NormalizedToUTC(t *testing.T) {
+	t.Parallel()
+
+	s, q := newTestSession(t, "guild-1", nil)
+	local := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+
+	s.onMessageCreate(s.dg, &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        "msg-tz",
+			ChannelID: "chan-1",
+			GuildID:   "guild-1",
+			Content:   "hi",
+			Timestamp: local,
+			Author:    &discordgo.User{ID: "user-1", Username: "Alice"},
+		},
+	})
+
+	msgs := drainQueue(q, 1)
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message from Poll, got %d", len(msgs))
+	}
+	data, err := json.Marshal(msgs[0])
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `"timestamp":"2024-03-01T14:30:00Z"`; !strings.Contains(string(data), want) {
+		t.Errorf("JSON = %s, want it to contain %s", data, want)
+	}
+}
+
+func Test_SetFilter_BlocksPreviouslyAllowedChannel(t *testing.T) {
+	t.Parallel()
+
+	s, q := newTestSession(t, "guild-1", nil)
+	event := func(id string) *discordgo.MessageCreate {
+		return &discordgo.MessageCreate{
+			Message: &discordgo.Message{
+				ID:        id,
+				ChannelID: "chan-1",
+				GuildID:   "guild-1",
+				Content:   "hello",
+				Author:    &discordgo.User{ID: "user-1", Username: "Alice"},
+			},
+		}
+	}
+
+	s.onMessageCreate(s.dg, event("before"))
+	if q.Len() != 1 {
+		t.Fatalf("expected message enqueued before SetFilter, queue Len() = %d", q.Len())
+	}
+
+	// The resolver has no cache, so the channel name is its ID.
+	s.SetFilter(safety.NewFilter(nil, []string{"chan-1"}))
+	s.onMessageCreate(s.dg, event("after"))
+	if q.Len() != 1 {
+		t.Errorf("expected message dropped after SetFilter, queue Len() = %d", q.Len())
+	}
+
+	s.SetFilter(nil)
+	s.onMessageCreate(s.dg, event("cleared"))
+	if q.Len() != 2 {
+		t.Errorf("expected message enqueued after clearing filter, queue Len() = %d", q.Len())
+	}
+}
+
+func Test_SetGuildFilters_UsesEventGuildRules(t *testing.T) {
+	t.Parallel()
+
+	s, q := newTestSession(t, "guild-1", nil)
+	// The fallback rules allow chan-1, but guild-1's own rules deny it.
+	s.SetGuildFilters(safety.NewGuildFilters(safety.NewFilter(nil, nil), map[string]*safety.Filter{
+		"guild-1": safety.NewFilter(nil, []string{"chan-1"}),
+	}))
+	s.onMessageCreate(s.dg, &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        "msg-1",
+			ChannelID: "chan-1",
+			GuildID:   "guild-1",
+			Content:   "hello",
+			Author:    &discordgo.User{ID: "user-1", Username: "Alice"},
+		},
+	})
+	if q.Len() != 0 {
+		t.Errorf("expected message dropped by guild-1 rules, queue Len() = %d", q.Len())
+	}
+}
+
+func Test_SetFilter_WriteOnlyRestrictionStillIngests(t *testing.T) {
+	t.Parallel()
+
+	s, q := newTestSession(t, "guild-1", nil)
+	s.SetFilter(safety.NewFilter(nil, nil,
+		safety.WithOperationFilter(safety.OpWrite, safety.NewFilter([]string{"bot-commands"}, nil)),
+	))
+	s.onMessageCreate(s.dg, &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        "msg-1",
+			ChannelID: "chan-1",
+			GuildID:   "guild-1",
+			Content:   "hello",
+			Author:    &discordgo.User{ID: "user-1", Username: "Alice"},
+		},
+	})
+	if q.Len() != 1 {
+		t.Errorf("readable channel should be ingested, queue Len() = %d", q.Len())
+	}
+}
+
 func Test_onMessageCreate_EmptyContent_StillEnqueued(t *testing.T) {
 	t.Parallel()
 
@@ -570,6 +955,7 @@ func Test_onMessageCreate_FieldMapping(t *testing.T) {
 		want  string
 	}{
 		{"ID", msg.ID, "field-msg-1"},
+		{"GuildID", msg.GuildID, "guild-1"},
 		{"ChannelID", msg.ChannelID, "field-chan-1"},
 		{"ChannelName", msg.ChannelName, "field-chan-1"}, // resolver cache empty, falls back to ID
 		{"AuthorID", msg.AuthorID, "field-user-1"},
@@ -695,6 +1081,91 @@ func Test_onReady_WithValidUser_NoPanic(t *testing.T) {
 	s.onReady(s.dg, event)
 }
 
+func Test_onReady_RefreshRetriesUntilSuccess(t *testing.T) {
+	// Not parallel: overrides discordgo's package-level endpoints.
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v9/guilds/guild-1/channels", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			http.Error(w, `{"message": "upstream unavailable"}`, http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]*discordgo.Channel{
+			{ID: "ch-001", Name: "general", Type: discordgo.ChannelTypeGuildText},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	origAPI, origGuilds := discordgo.EndpointAPI, discordgo.EndpointGuilds
+	discordgo.EndpointAPI = server.URL + "/api/v9/"
+	discordgo.EndpointGuilds = discordgo.EndpointAPI + "guilds/"
+	t.Cleanup(func() {
+		discordgo.EndpointAPI = origAPI
+		discordgo.EndpointGuilds = origGuilds
+	})
+
+	s, _ := newTestSession(t, "guild-1", nil)
+	// discordgo retries 502s itself; disable that so each failure reaches
+	// the session's own retry loop.
+	s.dg.ShouldRetryOnRateLimit = false
+	s.dg.MaxRestRetries = 0
+
+	s.onReady(s.dg, &discordgo.Ready{User: &discordgo.User{Username: "TestBot"}})
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("GuildChannels calls = %d, want 3", got)
+	}
+	if s.resolver.RefreshedAt().IsZero() {
+		t.Fatal("RefreshedAt is zero after onReady, want the cache loaded")
+	}
+	if id, err := s.resolver.ChannelID("general"); err != nil || id != "ch-001" {
+		t.Errorf("ChannelID(general) = %q, %v; want ch-001", id, err)
+	}
+}
+
+func Test_onReady_RefreshRetriesStopOnShutdown(t *testing.T) {
+	// Not parallel: overrides discordgo's package-level endpoints.
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v9/guilds/guild-1/channels", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, `{"message": "upstream unavailable"}`, http.StatusBadGateway)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	origAPI, origGuilds := discordgo.EndpointAPI, discordgo.EndpointGuilds
+	discordgo.EndpointAPI = server.URL + "/api/v9/"
+	discordgo.EndpointGuilds = discordgo.EndpointAPI + "guilds/"
+	t.Cleanup(func() {
+		discordgo.EndpointAPI = origAPI
+		discordgo.EndpointGuilds = origGuilds
+	})
+
+	shutdown, cancel := context.WithCancel(context.Background())
+	cancel()
+	s, _ := newTestSession(t, "guild-1", nil, WithShutdown(shutdown))
+	s.dg.ShouldRetryOnRateLimit = false
+	s.dg.MaxRestRetries = 0
+	s.refreshBackoff = time.Hour
+
+	done := make(chan struct{})
+	go func() {
+		s.onReady(s.dg, &discordgo.Ready{User: &discordgo.User{Username: "TestBot"}})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("onReady still waiting to retry after shutdown")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("GuildChannels calls = %d, want 1", got)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // onMessageCreate - denylist with glob pattern
 // ---------------------------------------------------------------------------
@@ -758,3 +1229,171 @@ func Test_onMessageCreate_DenylistOverridesAllowlist(t *testing.T) {
 		t.Errorf("expected denylist to override allowlist, got Len() = %d", q.Len())
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Gateway intents
+// ---------------------------------------------------------------------------
+
+func Test_NewFromSession_DefaultIntents(t *testing.T) {
+	t.Parallel()
+	s, _ := newTestSession(t, "guild-1", nil)
+	if got := s.dg.Identify.Intents; got != DefaultIntents {
+		t.Errorf("Identify.Intents = %d, want %d", got, DefaultIntents)
+	}
+}
+
+func Test_NewFromSession_CustomIntents(t *testing.T) {
+	t.Parallel()
+	intents, err := ParseIntents([]string{"guilds", "Guild_Messages"})
+	if err != nil {
+		t.Fatalf("ParseIntents() error = %v", err)
+	}
+	s, _ := newTestSession(t, "guild-1", nil, WithIntents(intents))
+
+	want := discordgo.IntentGuilds | discordgo.IntentGuildMessages
+	if got := s.dg.Identify.Intents; got != want {
+		t.Errorf("Identify.Intents = %d, want %d", got, want)
+	}
+	if s.dg.Identify.Intents&discordgo.IntentMessageContent != 0 {
+		t.Error("message_content should not be requested")
+	}
+}
+
+func Test_ParseIntents_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    discordgo.Intent
+		wantErr bool
+	}{
+		{name: "empty uses default", names: nil, want: DefaultIntents},
+		{name: "privileged intents", names: []string{"message_content", "guild_members", "guild_presences"},
+			want: discordgo.IntentMessageContent | discordgo.IntentGuildMembers | discordgo.IntentGuildPresences},
+		{name: "misspelled privileged intent", names: []string{"guilds", "message_contents"}, wantErr: true},
+		{name: "unknown intent", names: []string{"bogus"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseIntents(tt.names)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseIntents(%v) error = nil, want error", tt.names)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseIntents(%v) error = %v", tt.names, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseIntents(%v) = %d, want %d", tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// onTypingStart
+// ---------------------------------------------------------------------------
+
+func Test_NewFromSession_TypingEventsAddsIntent(t *testing.T) {
+	t.Parallel()
+	s, _ := newTestSession(t, "guild-1", nil, WithTypingEvents(true))
+	if s.dg.Identify.Intents&discordgo.IntentGuildMessageTyping == 0 {
+		t.Error("guild_message_typing intent should be requested when typing events are enabled")
+	}
+
+	s, _ = newTestSession(t, "guild-1", nil)
+	if s.dg.Identify.Intents&discordgo.IntentGuildMessageTyping != 0 {
+		t.Error("guild_message_typing intent should not be requested by default")
+	}
+}
+
+func Test_onTypingStart_Enqueued(t *testing.T) {
+	t.Parallel()
+	s, q := newTestSession(t, "guild-1", nil, WithTypingEvents(true))
+	if err := s.dg.State.GuildAdd(&discordgo.Guild{ID: "guild-1"}); err != nil {
+		t.Fatalf("GuildAdd() error = %v", err)
+	}
+	if err := s.dg.State.MemberAdd(&discordgo.Member{GuildID: "guild-1", User: &discordgo.User{ID: "user-1", Username: "alice"}}); err != nil {
+		t.Fatalf("MemberAdd() error = %v", err)
+	}
+
+	s.onTypingStart(s.dg, &discordgo.TypingStart{UserID: "user-1", ChannelID: "chan-1", GuildID: "guild-1", Timestamp: 1700000000})
+
+	msgs := drainQueue(q, 10)
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 queued event, got %d", len(msgs))
+	}
+	got := msgs[0]
+	if got.Type != queue.EventTyping {
+		t.Errorf("Type = %q, want %q", got.Type, queue.EventTyping)
+	}
+	if got.ID != "" || got.Content != "" {
+		t.Errorf("typing event should have no ID or content, got ID=%q Content=%q", got.ID, got.Content)
+	}
+	if got.GuildID != "guild-1" || got.ChannelID != "chan-1" || got.AuthorID != "user-1" || got.AuthorUsername != "alice" {
+		t.Errorf("unexpected fields: %+v", got)
+	}
+	if want := time.Unix(1700000000, 0).UTC(); !got.Timestamp.Equal(want) || got.Timestamp.Location() != time.UTC {
+		t.Errorf("Timestamp = %v, want %v", got.Timestamp, want)
+	}
+}
+
+func Test_onTypingStart_Ignored_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		opts  []SessionOption
+		bot   bool
+		event discordgo.TypingStart
+	}{
+		{
+			name:  "wrong guild",
+			event: discordgo.TypingStart{UserID: "user-1", ChannelID: "chan-1", GuildID: "guild-2"},
+		},
+		{
+			name:  "bot user",
+			bot:   true,
+			event: discordgo.TypingStart{UserID: "user-1", ChannelID: "chan-1", GuildID: "guild-1"},
+		},
+		{
+			name:  "self",
+			event: discordgo.TypingStart{UserID: "bot-self", ChannelID: "chan-1", GuildID: "guild-1"},
+		},
+		{
+			name:  "denied channel",
+			event: discordgo.TypingStart{UserID: "user-1", ChannelID: "secret-channel", GuildID: "guild-1"},
+		},
+		{
+			name:  "denied user",
+			opts:  []SessionOption{WithUserFilter(safety.NewFilter(nil, []string{"user-1"}))},
+			event: discordgo.TypingStart{UserID: "user-1", ChannelID: "chan-1", GuildID: "guild-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			filter := safety.NewFilter(nil, []string{"secret-channel"})
+			s, q := newTestSession(t, "guild-1", filter, append([]SessionOption{WithTypingEvents(true)}, tt.opts...)...)
+			s.dg.State.User = &discordgo.User{ID: "bot-self"}
+			if err := s.dg.State.GuildAdd(&discordgo.Guild{ID: "guild-1"}); err != nil {
+				t.Fatalf("GuildAdd() error = %v", err)
+			}
+			if err := s.dg.State.MemberAdd(&discordgo.Member{GuildID: "guild-1", User: &discordgo.User{ID: "user-1", Username: "alice", Bot: tt.bot}}); err != nil {
+				t.Fatalf("MemberAdd() error = %v", err)
+			}
+
+			s.onTypingStart(s.dg, &tt.event)
+
+			if q.Len() != 0 {
+				t.Errorf("expected queue to be empty, got Len() = %d", q.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package guild
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Bounds on the number of entries discord_get_audit_log returns; Discord
+// returns at most 100 per request.
+const (
+	defaultAuditLogLimit = 50
+	maxAuditLogLimit     = 100
+)
+
+// auditLogActions maps the action_type names accepted by
+// discord_get_audit_log to Discord's audit log action types. The same names
+// appear in each entry's "action" field.
+var auditLogActions = map[string]discordgo.AuditLogAction{
+	"guild_update":              discordgo.AuditLogActionGuildUpdate,
+	"channel_create":            discordgo.AuditLogActionChannelCreate,
+	"channel_update":            discordgo.AuditLogActionChannelUpdate,
+	"channel_delete":            discordgo.AuditLogActionChannelDelete,
+	"channel_overwrite_create":  discordgo.AuditLogActionChannelOverwriteCreate,
+	"channel_overwrite_update":  discordgo.AuditLogActionChannelOverwriteUpdate,
+	"channel_overwrite_delete":  discordgo.AuditLogActionChannelOverwriteDelete,
+	"member_kick":               discordgo.AuditLogActionMemberKick,
+	"member_prune":              discordgo.AuditLogActionMemberPrune,
+	"member_ban_add":            discordgo.AuditLogActionMemberBanAdd,
+	"member_ban_remove":         discordgo.AuditLogActionMemberBanRemove,
+	"member_update":             discordgo.AuditLogActionMemberUpdate,
+	"member_role_update":        discordgo.AuditLogActionMemberRoleUpdate,
+	"member_move":               discordgo.AuditLogActionMemberMove,
+	"member_disconnect":         discordgo.AuditLogActionMemberDisconnect,
+	"bot_add":                   discordgo.AuditLogActionBotAdd,
+	"role_create":               discordgo.AuditLogActionRoleCreate,
+	"role_update":               discordgo.AuditLogActionRoleUpdate,
+	"role_delete":               discordgo.AuditLogActionRoleDelete,
+	"invite_create":             discordgo.AuditLogActionInviteCreate,
+	"invite_update":             discordgo.AuditLogActionInviteUpdate,
+	"invite_delete":             discordgo.AuditLogActionInviteDelete,
+	"webhook_create":            discordgo.AuditLogActionWebhookCreate,
+	"webhook_update":            discordgo.AuditLogActionWebhookUpdate,
+	"webhook_delete":            discordgo.AuditLogActionWebhookDelete,
+	"emoji_create":              discordgo.AuditLogActionEmojiCreate,
+	"emoji_update":              discordgo.AuditLogActionEmojiUpdate,
+	"emoji_delete":              discordgo.AuditLogActionEmojiDelete,
+	"message_delete":            discordgo.AuditLogActionMessageDelete,
+	"message_bulk_delete":       discordgo.AuditLogActionMessageBulkDelete,
+	"message_pin":               discordgo.AuditLogActionMessagePin,
+	"message_unpin":             discordgo.AuditLogActionMessageUnpin,
+	"thread_create":             discordgo.AuditLogActionThreadCreate,
+	"thread_update":             discordgo.AuditLogActionThreadUpdate,
+	"thread_delete":             discordgo.AuditLogActionThreadDelete,
+	"automod_rule_create":       discordgo.AuditLogActionAutoModerationRuleCreate,
+	"automod_rule_update":       discordgo.AuditLogActionAutoModerationRuleUpdate,
+	"automod_rule_delete":       discordgo.AuditLogActionAutoModerationRuleDelete,
+	"automod_block_message":     discordgo.AuditLogActionAutoModerationBlockMessage,
+	"automod_flag_to_channel":   discordgo.AuditLogActionAutoModerationFlagToChannel,
+	"automod_timeout_member":    discordgo.AuditLogActionAutoModerationUserCommunicationDisabled,
+	"scheduled_event_create":    discordgo.AuditLogGuildScheduledEventCreate,
+	"scheduled_event_update":    discordgo.AuditLogGuildScheduledEventUpdate,
+	"scheduled_event_delete":    discordgo.AuditLogGuildScheduledEventDelete,
+	"integration_create":        discordgo.AuditLogActionIntegrationCreate,
+	"integration_update":        discordgo.AuditLogActionIntegrationUpdate,
+	"integration_delete":        discordgo.AuditLogActionIntegrationDelete,
+	"sticker_create":            discordgo.AuditLogActionStickerCreate,
+	"sticker_update":            discordgo.AuditLogActionStickerUpdate,
+	"sticker_delete":            discordgo.AuditLogActionStickerDelete,
+	"command_permission_update": discordgo.AuditLogActionApplicationCommandPermissionUpdate,
+}
+
+// auditLogActionNames is the reverse of auditLogActions.
+var auditLogActionNames = func() map[discordgo.AuditLogAction]string {
+	out := make(map[discordgo.AuditLogAction]string, len(auditLogActions))
+	for name, action := range auditLogActions {
+		out[action] = name
+	}
+	return out
+}()
+
+// AuditLogEntrySummary is one entry in the response of discord_get_audit_log.
+// Action is the action type's name, or "unknown" for types this server does
+// not name; ActionType always carries Discord's numeric value. UserID is the
+// moderator who performed the action and TargetID the affected user,
+// channel, role or other object. Timestamp is derived from the entry ID and
+// is in UTC.
+type AuditLogEntrySummary struct {
+	ID         string                  `json:"id"`
+	Action     string                  `json:"action"`
+	ActionType int                     `json:"action_type"`
+	UserID     string                  `json:"user_id,omitempty"`
+	Username   string                  `json:"username,omitempty"`
+	TargetID   string                  `json:"target_id,omitempty"`
+	Reason     string                  `json:"reason,omitempty"`
+	Timestamp  time.Time               `json:"timestamp"`
+	ChannelID  string                  `json:"channel_id,omitempty"`
+	Count      string                  `json:"count,omitempty"`
+	Changes    []AuditLogChangeSummary `json:"changes,omitempty"`
+}
+
+// AuditLogChangeSummary describes one field changed by an audit log entry.
+type AuditLogChangeSummary struct {
+	Key string `json:"key"`
+	Old any    `json:"old,omitempty"`
+	New any    `json:"new,omitempty"`
+}
+
+func toolGetAuditLog(dg discord.DiscordClient, defaultGuildID string, results tools.ResultLimit, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_get_audit_log"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Fetch recent entries from the Discord server's own audit log (bans, kicks, message deletes, role changes and other moderation events), newest first. Requires the View Audit Log permission."),
+		mcp.WithString("guild_id",
+			mcp.Description("Guild (server) ID (optional, uses default guild if omitted)"),
+		),
+		mcp.WithString("action_type",
+			mcp.Description("Only return entries of this type, by name (e.g. \"member_ban_add\", \"message_delete\", \"member_role_update\") or Discord's numeric value (optional)"),
+		),
+		mcp.WithString("user_id",
+			mcp.Description("Only return entries performed by this user ID (optional)"),
+		),
+		mcp.WithString("before",
+			mcp.Description("Only return entries older than this entry ID, for paging (optional)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of entries to return (default: %d, max: %d)", defaultAuditLogLimit, maxAuditLogLimit)),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		guildID := req.GetString("guild_id", "")
+		if guildID == "" {
+			guildID = defaultGuildID
+		}
+		actionName := req.GetString("action_type", "")
+		userID := req.GetString("user_id", "")
+		before := req.GetString("before", "")
+		limit := req.GetInt("limit", defaultAuditLogLimit)
+		params := map[string]any{
+			"guild_id":    guildID,
+			"action_type": actionName,
+			"user_id":     userID,
+			"before":      before,
+			"limit":       limit,
+		}
+
+		if limit < 1 || limit > maxAuditLogLimit {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("limit must be between 1 and %d", maxAuditLogLimit), start), nil
+		}
+		actionType, err := parseAuditLogAction(actionName)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		logger.DebugContext(ctx, "fetching guild audit log", "guildID", guildID, "action_type", actionType, "limit", limit)
+
+		log, err := dg.GuildAuditLog(guildID, userID, before, int(actionType), limit, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		out := summarizeAuditLog(log)
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d entries", len(out)), start)
+		return results.JSONResult(out), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// parseAuditLogAction converts an action_type argument, given by name or
+// number, to an AuditLogAction. An empty value returns 0, which Discord
+// treats as "all actions".
+func parseAuditLogAction(s string) (discordgo.AuditLogAction, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return discordgo.AuditLogAction(n), nil
+	}
+	if action, ok := auditLogActions[strings.ToLower(s)]; ok {
+		return action, nil
+	}
+	return 0, fmt.Errorf("unknown action_type %q (valid: %s)", s, strings.Join(slices.Sorted(maps.Keys(auditLogActions)), ", "))
+}
+
+// summarizeAuditLog converts log's entries to summaries, filling in each
+// moderator's username from the users Discord includes with the log.
+func summarizeAuditLog(log *discordgo.GuildAuditLog) []AuditLogEntrySummary {
+	if log == nil {
+		return []AuditLogEntrySummary{}
+	}
+	usernames := make(map[string]string, len(log.Users))
+	for _, u := range log.Users {
+		if u != nil {
+			usernames[u.ID] = u.Username
+		}
+	}
+
+	out := make([]AuditLogEntrySummary, 0, len(log.AuditLogEntries))
+	for _, e := range log.AuditLogEntries {
+		if e == nil {
+			continue
+		}
+		s := AuditLogEntrySummary{
+			ID:       e.ID,
+			Action:   "unknown",
+			UserID:   e.UserID,
+			Username: usernames[e.UserID],
+			TargetID: e.TargetID,
+			Reason:   e.Reason,
+		}
+		if ts, err := discordgo.SnowflakeTimestamp(e.ID); err == nil {
+			s.Timestamp = ts.UTC()
+		}
+		if e.ActionType != nil {
+			s.ActionType = int(*e.ActionType)
+			if name, ok := auditLogActionNames[*e.ActionType]; ok {
+				s.Action = name
+			}
+		}
+		if e.Options != nil {
+			s.ChannelID = e.Options.ChannelID
+			s.Count = e.Options.Count
+		}
+		for _, c := range e.Changes {
+			if c == nil || c.Key == nil {
+				continue
+			}
+			s.Changes = append(s.Changes, AuditLogChangeSummary{Key: string(*c.Key), Old: c.OldValue, New: c.NewValue})
+		}
+		out = append(out, s)
+	}
+	return out
+}
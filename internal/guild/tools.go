@@ -3,9 +3,11 @@ package guild
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/discord"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
 	"github.com/jamesprial/claudebot-mcp/internal/tools"
@@ -22,16 +24,48 @@ type GuildSummary struct {
 	Description string `json:"description,omitempty"`
 }
 
+// EmojiSummary is one entry in the response of discord_get_guild_emojis.
+// Reaction is the "name:id" form accepted by discord_add_reaction.
+type EmojiSummary struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Animated bool   `json:"animated"`
+	Reaction string `json:"reaction"`
+}
+
+// Option configures optional behaviour of the tools returned by GuildTools.
+type Option func(*options)
+
+// options holds the settings applied by Option values.
+type options struct {
+	results tools.ResultLimit
+}
+
+// WithMaxResultItems caps how many entries the list tools return; see
+// tools.ResultLimit.
+func WithMaxResultItems(n int) Option {
+	return func(o *options) {
+		o.results = tools.ResultLimit(n)
+	}
+}
+
 // GuildTools returns all tool registrations for Discord guild operations.
 func GuildTools(
 	dg discord.DiscordClient,
 	defaultGuildID string,
 	audit *safety.AuditLogger,
 	logger *slog.Logger,
+	opts ...Option,
 ) []tools.Registration {
 	logger = tools.DefaultLogger(logger)
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return []tools.Registration{
 		toolGetGuild(dg, defaultGuildID, audit, logger),
+		toolGetGuildEmojis(dg, defaultGuildID, o.results, audit, logger),
+		toolGetAuditLog(dg, defaultGuildID, o.results, audit, logger),
 	}
 }
 
@@ -53,11 +87,11 @@ func toolGetGuild(dg discord.DiscordClient, defaultGuildID string, audit *safety
 		}
 		params := map[string]any{"guild_id": guildID}
 
-		logger.Debug("fetching guild info", "guildID", guildID)
+		logger.DebugContext(ctx, "fetching guild info", "guildID", guildID)
 
-		g, err := dg.Guild(guildID)
+		g, err := dg.Guild(guildID, discordgo.WithContext(ctx))
 		if err != nil {
-			return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
 		}
 
 		summary := GuildSummary{
@@ -68,9 +102,51 @@ func toolGetGuild(dg discord.DiscordClient, defaultGuildID string, audit *safety
 			Description: g.Description,
 		}
 
-		tools.LogAudit(audit, toolName, params, "ok", start)
+		tools.LogAudit(ctx, audit, toolName, params, "ok", start)
 		return tools.JSONResult(summary), nil
 	}
 
 	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
 }
+
+func toolGetGuildEmojis(dg discord.DiscordClient, defaultGuildID string, results tools.ResultLimit, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_get_guild_emojis"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("List a guild's custom emojis with the name:id string to use when reacting."),
+		mcp.WithString("guild_id",
+			mcp.Description("Guild (server) ID (optional, uses default guild if omitted)"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		guildID := req.GetString("guild_id", "")
+		if guildID == "" {
+			guildID = defaultGuildID
+		}
+		params := map[string]any{"guild_id": guildID}
+
+		logger.DebugContext(ctx, "fetching guild emojis", "guildID", guildID)
+
+		emojis, err := dg.GuildEmojis(guildID, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		out := make([]EmojiSummary, 0, len(emojis))
+		for _, e := range emojis {
+			out = append(out, EmojiSummary{
+				ID:       e.ID,
+				Name:     e.Name,
+				Animated: e.Animated,
+				Reaction: e.APIName(),
+			})
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d emojis", len(out)), start)
+		return results.JSONResult(out), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
@@ -2,9 +2,14 @@ package guild_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/guild"
 	"github.com/jamesprial/claudebot-mcp/internal/testutil"
 )
@@ -20,6 +25,8 @@ func Test_GuildTools_Registration(t *testing.T) {
 
 	testutil.AssertRegistrations(t, regs, []string{
 		"discord_get_guild",
+		"discord_get_guild_emojis",
+		"discord_get_audit_log",
 	})
 }
 
@@ -89,3 +96,191 @@ func Test_GetGuild_ContainsMemberCount(t *testing.T) {
 		t.Errorf("expected result to contain member count '42', got: %s", text)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// discord_get_guild_emojis handler
+// ---------------------------------------------------------------------------
+
+func Test_GetGuildEmojis_Valid(t *testing.T) {
+	t.Parallel()
+	var gotGuild string
+	client := &testutil.MockDiscordClient{
+		GuildEmojisFunc: func(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Emoji, error) {
+			gotGuild = guildID
+			return []*discordgo.Emoji{
+				{ID: "111", Name: "party"},
+				{ID: "222", Name: "dance", Animated: true},
+			}, nil
+		},
+	}
+	regs := guild.GuildTools(client, "guild-1", nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_guild_emojis")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_guild_emojis", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if gotGuild != "guild-1" {
+		t.Errorf("GuildEmojis guildID = %q, want %q", gotGuild, "guild-1")
+	}
+
+	var got []guild.EmojiSummary
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	want := []guild.EmojiSummary{
+		{ID: "111", Name: "party", Reaction: "party:111"},
+		{ID: "222", Name: "dance", Animated: true, Reaction: "dance:222"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("emojis = %+v, want %+v", got, want)
+	}
+}
+
+func Test_GetGuildEmojis_Error(t *testing.T) {
+	t.Parallel()
+	client := &testutil.MockDiscordClient{
+		GuildEmojisFunc: func(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Emoji, error) {
+			return nil, errors.New("missing access")
+		},
+	}
+	regs := guild.GuildTools(client, "guild-1", nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_guild_emojis")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_guild_emojis", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected error result, got: %s", testutil.ExtractText(t, result))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_get_audit_log handler
+// ---------------------------------------------------------------------------
+
+func Test_GetAuditLog_Summarizes(t *testing.T) {
+	t.Parallel()
+
+	ban := discordgo.AuditLogActionMemberBanAdd
+	del := discordgo.AuditLogActionMessageDelete
+	nick := discordgo.AuditLogChangeKeyNick
+	var gotGuild, gotUser, gotBefore string
+	var gotAction, gotLimit int
+	client := &testutil.MockDiscordClient{
+		GuildAuditLogFunc: func(guildID, userID, beforeID string, actionType, limit int, _ ...discordgo.RequestOption) (*discordgo.GuildAuditLog, error) {
+			gotGuild, gotUser, gotBefore, gotAction, gotLimit = guildID, userID, beforeID, actionType, limit
+			return &discordgo.GuildAuditLog{
+				Users: []*discordgo.User{{ID: "mod-1", Username: "moderator"}},
+				AuditLogEntries: []*discordgo.AuditLogEntry{
+					{ID: "1213093380096000000", ActionType: &ban, UserID: "mod-1", TargetID: "user-9", Reason: "spam"},
+					{
+						ID: "1213093380096000001", ActionType: &del, UserID: "mod-1", TargetID: "user-8",
+						Options: &discordgo.AuditLogOptions{ChannelID: "ch-001", Count: "3"},
+						Changes: []*discordgo.AuditLogChange{{Key: &nick, OldValue: "a", NewValue: "b"}},
+					},
+				},
+			}, nil
+		},
+	}
+	regs := guild.GuildTools(client, "guild-1", nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_audit_log")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_audit_log", map[string]any{
+		"action_type": "Member_Ban_Add",
+		"user_id":     "mod-1",
+		"before":      "999",
+		"limit":       float64(10),
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", testutil.ExtractText(t, result))
+	}
+	if gotGuild != "guild-1" || gotUser != "mod-1" || gotBefore != "999" || gotAction != 22 || gotLimit != 10 {
+		t.Errorf("GuildAuditLog(%q, %q, %q, %d, %d), want (guild-1, mod-1, 999, 22, 10)", gotGuild, gotUser, gotBefore, gotAction, gotLimit)
+	}
+
+	var got []guild.AuditLogEntrySummary
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got))
+	}
+	if e := got[0]; e.Action != "member_ban_add" || e.ActionType != 22 || e.Username != "moderator" || e.TargetID != "user-9" || e.Reason != "spam" {
+		t.Errorf("entry 0 = %+v", e)
+	}
+	if want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC); !got[0].Timestamp.Equal(want) {
+		t.Errorf("entry 0 timestamp = %v, want %v", got[0].Timestamp, want)
+	}
+	if e := got[1]; e.Action != "message_delete" || e.ChannelID != "ch-001" || e.Count != "3" || len(e.Changes) != 1 || e.Changes[0].Key != "nick" {
+		t.Errorf("entry 1 = %+v", e)
+	}
+}
+
+func Test_GetAuditLog_InvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		args      map[string]any
+		wantInErr string
+	}{
+		{name: "unknown action name", args: map[string]any{"action_type": "member_yeet"}, wantInErr: "unknown action_type"},
+		{name: "limit too large", args: map[string]any{"limit": float64(101)}, wantInErr: "limit"},
+		{name: "limit zero", args: map[string]any{"limit": float64(0)}, wantInErr: "limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := &testutil.MockDiscordClient{
+				GuildAuditLogFunc: func(string, string, string, int, int, ...discordgo.RequestOption) (*discordgo.GuildAuditLog, error) {
+					t.Error("GuildAuditLog should not be called")
+					return nil, errors.New("unexpected")
+				},
+			}
+			regs := guild.GuildTools(client, "guild-1", nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_get_audit_log")
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_audit_log", tt.args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+			testutil.AssertTextContains(t, result, tt.wantInErr)
+		})
+	}
+}
+
+func Test_GetAuditLog_NumericActionType(t *testing.T) {
+	t.Parallel()
+	var gotAction int
+	client := &testutil.MockDiscordClient{
+		GuildAuditLogFunc: func(_, _, _ string, actionType, _ int, _ ...discordgo.RequestOption) (*discordgo.GuildAuditLog, error) {
+			gotAction = actionType
+			return &discordgo.GuildAuditLog{}, nil
+		},
+	}
+	regs := guild.GuildTools(client, "guild-1", nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_audit_log")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_audit_log", map[string]any{"action_type": "72"}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", testutil.ExtractText(t, result))
+	}
+	if gotAction != 72 {
+		t.Errorf("actionType = %d, want 72", gotAction)
+	}
+	if text := testutil.ExtractText(t, result); text != "[]" {
+		t.Errorf("result = %q, want []", text)
+	}
+}
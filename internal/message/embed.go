@@ -0,0 +1,101 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord's embed limits, in characters unless noted.
+const (
+	maxEmbedTitle       = 256
+	maxEmbedDescription = 4096
+	maxEmbedFields      = 25 // count
+	maxEmbedFieldName   = 256
+	maxEmbedFieldValue  = 1024
+	maxEmbedFooter      = 2048
+	maxEmbedAuthor      = 256
+	maxEmbedTotal       = 6000
+)
+
+// parseEmbed converts a tool's embed argument, a JSON object using Discord's
+// embed field names (title, description, url, color, fields, footer, image,
+// thumbnail, author, timestamp), into a MessageEmbed and checks it against
+// Discord's limits. A nil value or empty object returns a nil embed, which
+// callers treat as "remove the embed".
+func parseEmbed(v any) (*discordgo.MessageEmbed, error) {
+	if v == nil {
+		return nil, nil
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("embed must be an object")
+	}
+	if len(obj) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embed: %w", err)
+	}
+	var embed discordgo.MessageEmbed
+	if err := json.Unmarshal(data, &embed); err != nil {
+		return nil, fmt.Errorf("invalid embed: %w", err)
+	}
+	if err := validateEmbed(&embed); err != nil {
+		return nil, err
+	}
+	return &embed, nil
+}
+
+// validateEmbed returns an error naming the first Discord embed limit that e
+// exceeds.
+func validateEmbed(e *discordgo.MessageEmbed) error {
+	total := 0
+	check := func(field, value string, max int) error {
+		n := utf8.RuneCountInString(value)
+		total += n
+		if n > max {
+			return fmt.Errorf("embed %s is %d characters, max %d", field, n, max)
+		}
+		return nil
+	}
+
+	if err := check("title", e.Title, maxEmbedTitle); err != nil {
+		return err
+	}
+	if err := check("description", e.Description, maxEmbedDescription); err != nil {
+		return err
+	}
+	if len(e.Fields) > maxEmbedFields {
+		return fmt.Errorf("embed has %d fields, max %d", len(e.Fields), maxEmbedFields)
+	}
+	for i, f := range e.Fields {
+		if f == nil {
+			continue
+		}
+		if err := check(fmt.Sprintf("fields[%d].name", i), f.Name, maxEmbedFieldName); err != nil {
+			return err
+		}
+		if err := check(fmt.Sprintf("fields[%d].value", i), f.Value, maxEmbedFieldValue); err != nil {
+			return err
+		}
+	}
+	if e.Footer != nil {
+		if err := check("footer.text", e.Footer.Text, maxEmbedFooter); err != nil {
+			return err
+		}
+	}
+	if e.Author != nil {
+		if err := check("author.name", e.Author.Name, maxEmbedAuthor); err != nil {
+			return err
+		}
+	}
+	if total > maxEmbedTotal {
+		return fmt.Errorf("embed text totals %d characters, max %d", total, maxEmbedTotal)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package message
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	// userMentionRe matches a user mention such as "<@123>" or "<@!123>".
+	userMentionRe = regexp.MustCompile(`<@!?(\d+)>`)
+	// roleMentionRe matches a role mention such as "<@&123>".
+	roleMentionRe = regexp.MustCompile(`<@&(\d+)>`)
+)
+
+// MentionPolicy is a server-wide limit on who outgoing messages may ping,
+// applied on top of the per-call mention options of every send and edit. The
+// text of a blocked mention is left in place; it just does not notify anyone.
+//
+// The zero value allows everything.
+type MentionPolicy struct {
+	// DenyEveryone stops @everyone and @here from pinging.
+	DenyEveryone bool
+	// AllowedRoles, when non-empty, lists the only role IDs that may be
+	// pinged.
+	AllowedRoles []string
+	// MaxUserMentions, when positive, caps how many users a single message
+	// may ping; users beyond the cap are not notified.
+	MaxUserMentions int
+}
+
+// enabled reports whether p restricts anything.
+func (p MentionPolicy) enabled() bool {
+	return p.DenyEveryone || len(p.AllowedRoles) > 0 || p.MaxUserMentions > 0
+}
+
+// apply returns the AllowedMentions to send with content once p is applied
+// to am. A nil am stands for Discord's default of parsing every mention
+// type. am itself is never modified.
+func (p MentionPolicy) apply(content string, am *discordgo.MessageAllowedMentions) *discordgo.MessageAllowedMentions {
+	if !p.enabled() {
+		return am
+	}
+
+	out := &discordgo.MessageAllowedMentions{
+		Parse: []discordgo.AllowedMentionType{
+			discordgo.AllowedMentionTypeUsers,
+			discordgo.AllowedMentionTypeRoles,
+			discordgo.AllowedMentionTypeEveryone,
+		},
+	}
+	if am != nil {
+		out.Parse = slices.Clone(am.Parse)
+		out.Roles = slices.Clone(am.Roles)
+		out.Users = slices.Clone(am.Users)
+		out.RepliedUser = am.RepliedUser
+	}
+
+	if p.DenyEveryone {
+		out.Parse = slices.DeleteFunc(out.Parse, func(t discordgo.AllowedMentionType) bool {
+			return t == discordgo.AllowedMentionTypeEveryone
+		})
+	}
+
+	if len(p.AllowedRoles) > 0 {
+		roles := out.Roles
+		if slices.Contains(out.Parse, discordgo.AllowedMentionTypeRoles) {
+			roles = mentionIDs(roleMentionRe, content)
+			out.Parse = slices.DeleteFunc(out.Parse, func(t discordgo.AllowedMentionType) bool {
+				return t == discordgo.AllowedMentionTypeRoles
+			})
+		}
+		out.Roles = slices.DeleteFunc(roles, func(id string) bool {
+			return !slices.Contains(p.AllowedRoles, id)
+		})
+	}
+
+	if p.MaxUserMentions > 0 {
+		users := out.Users
+		if slices.Contains(out.Parse, discordgo.AllowedMentionTypeUsers) {
+			users = mentionIDs(userMentionRe, content)
+		}
+		if len(users) > p.MaxUserMentions {
+			out.Parse = slices.DeleteFunc(out.Parse, func(t discordgo.AllowedMentionType) bool {
+				return t == discordgo.AllowedMentionTypeUsers
+			})
+			out.Users = users[:p.MaxUserMentions]
+		}
+	}
+
+	return out
+}
+
+// mentionIDs returns the distinct IDs captured by re in content, in order of
+// first appearance.
+func mentionIDs(re *regexp.Regexp, content string) []string {
+	var ids []string
+	for _, m := range re.FindAllStringSubmatch(content, -1) {
+		if !slices.Contains(ids, m[1]) {
+			ids = append(ids, m[1])
+		}
+	}
+	return ids
+}
@@ -0,0 +1,185 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+)
+
+// Limits on scheduled sends, bounding memory and how far ahead a send can be
+// scheduled.
+const (
+	maxScheduled      = 100
+	maxScheduleDelay  = 7 * 24 * time.Hour
+	scheduledToolName = "discord_schedule_message"
+)
+
+// ScheduledMessage is the response shape returned by discord_schedule_message
+// and discord_list_scheduled for a pending send.
+type ScheduledMessage struct {
+	ID        string    `json:"id"`
+	ChannelID string    `json:"channel_id"`
+	Channel   string    `json:"channel"`
+	Content   string    `json:"content"`
+	SendAt    time.Time `json:"send_at"`
+}
+
+// pendingSend is a ScheduledMessage with the timer that fires it and the
+// context of the request that scheduled it, kept for its values only.
+type pendingSend struct {
+	ScheduledMessage
+	timer *time.Timer
+	ctx   context.Context
+}
+
+// scheduler holds scheduled sends in memory and fires each one with its own
+// timer. Pending sends are dropped when the shutdown context is cancelled and
+// are not persisted across restarts.
+type scheduler struct {
+	shutdown context.Context
+	dg       discord.DiscordClient
+	mentions MentionPolicy
+	audit    *safety.AuditLogger
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	nextID  int
+	pending map[string]*pendingSend
+}
+
+// newScheduler returns a scheduler that stops all pending sends once
+// shutdown is cancelled.
+func newScheduler(shutdown context.Context, dg discord.DiscordClient, mentions MentionPolicy, audit *safety.AuditLogger, logger *slog.Logger) *scheduler {
+	s := &scheduler{
+		shutdown: shutdown,
+		dg:       dg,
+		mentions: mentions,
+		audit:    audit,
+		logger:   logger,
+		pending:  make(map[string]*pendingSend),
+	}
+	context.AfterFunc(shutdown, s.stopAll)
+	return s
+}
+
+// schedule queues content for channelID at sendAt. ctx supplies the values,
+// such as the request ID, carried into the audit entry written when the send
+// fires.
+func (s *scheduler) schedule(ctx context.Context, channelID, channelName, content string, sendAt time.Time) (ScheduledMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shutdown.Err() != nil {
+		return ScheduledMessage{}, fmt.Errorf("server shutting down")
+	}
+	if len(s.pending) >= maxScheduled {
+		return ScheduledMessage{}, fmt.Errorf("too many scheduled messages (max %d)", maxScheduled)
+	}
+
+	s.nextID++
+	p := &pendingSend{
+		ScheduledMessage: ScheduledMessage{
+			ID:        fmt.Sprintf("sched-%d", s.nextID),
+			ChannelID: channelID,
+			Channel:   channelName,
+			Content:   content,
+			SendAt:    sendAt.UTC(),
+		},
+		ctx: context.WithoutCancel(ctx),
+	}
+	s.pending[p.ID] = p
+	p.timer = time.AfterFunc(time.Until(sendAt), func() { s.fire(p.ID) })
+	return p.ScheduledMessage, nil
+}
+
+// list returns the pending sends ordered by send time.
+func (s *scheduler) list() []ScheduledMessage {
+	s.mu.Lock()
+	out := make([]ScheduledMessage, 0, len(s.pending))
+	for _, p := range s.pending {
+		out = append(out, p.ScheduledMessage)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].SendAt.Equal(out[j].SendAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].SendAt.Before(out[j].SendAt)
+	})
+	return out
+}
+
+// cancel removes the pending send with id, reporting whether it was found
+// before it fired.
+func (s *scheduler) cancel(id string) (ScheduledMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pending[id]
+	if !ok {
+		return ScheduledMessage{}, false
+	}
+	delete(s.pending, id)
+	p.timer.Stop()
+	return p.ScheduledMessage, true
+}
+
+// fire sends the pending message with id, splitting content that exceeds
+// Discord's length limit, and records the outcome in the audit log.
+func (s *scheduler) fire(id string) {
+	s.mu.Lock()
+	p, ok := s.pending[id]
+	delete(s.pending, id)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithCancel(p.ctx)
+	defer cancel()
+	stop := context.AfterFunc(s.shutdown, cancel)
+	defer stop()
+
+	params := map[string]any{"id": p.ID, "channel": p.Channel, "content": p.Content}
+	var sent []*discordgo.Message
+	for _, chunk := range splitContent(p.Content, maxMessageLength) {
+		data := &discordgo.MessageSend{
+			Content:         chunk,
+			AllowedMentions: s.mentions.apply(chunk, nil),
+		}
+		msg, _, err := sendWithRetry(ctx, s.dg, p.ChannelID, data, s.logger)
+		if err != nil {
+			s.logger.WarnContext(ctx, "scheduled send failed", "id", p.ID, "channelID", p.ChannelID, "error", err)
+			tools.LogAuditOutcome(ctx, s.audit, scheduledToolName, params, safety.OutcomeError, "error: "+err.Error(), start)
+			return
+		}
+		sent = append(sent, msg)
+	}
+
+	ids := strings.Join(messageIDs(sent), ", ")
+	s.logger.InfoContext(ctx, "scheduled message sent", "id", p.ID, "channelID", p.ChannelID, "messageIDs", ids)
+	tools.LogAudit(ctx, s.audit, scheduledToolName, params, "sent: "+ids, start)
+}
+
+// stopAll stops every pending timer and drops the sends.
+func (s *scheduler) stopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := len(s.pending); n > 0 {
+		s.logger.Warn("dropping scheduled messages on shutdown", "count", n)
+	}
+	for id, p := range s.pending {
+		p.timer.Stop()
+		delete(s.pending, id)
+	}
+}
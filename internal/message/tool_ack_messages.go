@@ -0,0 +1,79 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/queue"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// AckResult is the response shape returned by discord_ack_messages. Unknown
+// lists tokens that were not in flight, typically because their visibility
+// timeout expired and the message was already made visible again.
+type AckResult struct {
+	Acked    int      `json:"acked,omitempty"`
+	Requeued int      `json:"requeued,omitempty"`
+	Unknown  []string `json:"unknown,omitempty"`
+}
+
+func toolAckMessages(q *queue.Queue, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_ack_messages"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Acknowledge messages returned by discord_poll_messages or discord_drain_messages once they are processed, so they are not delivered again. Only available when the queue is in acknowledgment mode; unacknowledged messages are redelivered after the visibility timeout."),
+		mcp.WithArray("delivery_tokens",
+			mcp.Required(),
+			mcp.Description("delivery_token values from polled messages"),
+			mcp.WithStringItems(),
+			mcp.MinItems(1),
+		),
+		mcp.WithBoolean("requeue",
+			mcp.Description("Return the messages to the queue for immediate redelivery instead of acknowledging them (default: false)"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		tokens := req.GetStringSlice("delivery_tokens", nil)
+		requeue := req.GetBool("requeue", false)
+		params := map[string]any{"count": len(tokens), "requeue": requeue}
+
+		if q.AckTimeout() <= 0 {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, tools.WithCode(tools.CodeInvalidArgument, errors.New("the queue is not in acknowledgment mode; set queue.ack_timeout_sec")), start), nil
+		}
+		if len(tokens) == 0 {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, tools.WithCode(tools.CodeInvalidArgument, errors.New("delivery_tokens must list at least one token")), start), nil
+		}
+
+		settle, verb := q.Ack, "Acknowledged"
+		if requeue {
+			settle, verb = q.Nack, "Requeued"
+		}
+		var out AckResult
+		for _, token := range tokens {
+			if err := settle(token); err != nil {
+				out.Unknown = append(out.Unknown, token)
+				continue
+			}
+			if requeue {
+				out.Requeued++
+			} else {
+				out.Acked++
+			}
+		}
+		settled := len(tokens) - len(out.Unknown)
+		logger.DebugContext(ctx, "settled deliveries", "settled", settled, "unknown", len(out.Unknown), "requeue", requeue)
+
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d settled, %d unknown", settled, len(out.Unknown)), start)
+		return tools.JSONResultWithText(fmt.Sprintf("%s %d of %d messages", verb, settled, len(tokens)), out), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
@@ -0,0 +1,45 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/queue"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ClearQueueResult is the response shape returned by discord_clear_queue.
+type ClearQueueResult struct {
+	Discarded int `json:"discarded"`
+}
+
+func toolClearQueue(q *queue.Queue, confirm *safety.ConfirmationTracker, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_clear_queue"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Discard every message waiting in the queue without delivering it. Requires confirmation."),
+		tools.ConfirmationParam(confirm),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		if ok, token, reason := tools.Approve(confirm, req, toolName, "queue"); !ok {
+			logger.DebugContext(ctx, "confirmation required", "tool", toolName, "reason", reason)
+			desc := fmt.Sprintf("This will discard all %d queued messages; they will not be delivered.", q.Len())
+			return tools.ConfirmPrompt(confirm, toolName, "queue", tools.WithRejection(desc, token, reason)), nil
+		}
+
+		n := q.Clear()
+		logger.InfoContext(ctx, "queue cleared", "discarded", n)
+		tools.LogAudit(ctx, audit, toolName, map[string]any{}, fmt.Sprintf("ok: %d discarded", n), start)
+		return tools.JSONResultWithText(fmt.Sprintf("Queue cleared (%d messages discarded)", n), ClearQueueResult{Discarded: n}), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
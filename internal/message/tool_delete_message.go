@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/discord"
 	"github.com/jamesprial/claudebot-mcp/internal/resolve"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
@@ -27,37 +28,34 @@ func toolDeleteMessage(dg discord.DiscordClient, r resolve.ChannelResolver, filt
 			mcp.Required(),
 			mcp.Description("ID of the message to delete"),
 		),
-		mcp.WithString("confirmation_token",
-			mcp.Description("Confirmation token returned by a prior call to this tool"),
-		),
+		tools.ConfirmationParam(confirm),
 	)
 
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		start := time.Now()
 		channel := req.GetString("channel", "")
 		messageID := req.GetString("message_id", "")
-		token := req.GetString("confirmation_token", "")
 		params := map[string]any{
 			"channel":    channel,
 			"message_id": messageID,
 		}
 
-		channelID, channelName, errResult := tools.ResolveAndFilterChannel(r, filter, audit, logger, toolName, channel, params, start)
+		channelID, channelName, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, channel, params, start)
 		if errResult != nil {
 			return errResult, nil
 		}
 
-		if !confirm.Confirm(token) {
-			logger.Debug("confirmation required", "tool", toolName)
+		if ok, token, reason := tools.Approve(confirm, req, toolName, messageID); !ok {
+			logger.DebugContext(ctx, "confirmation required", "tool", toolName, "reason", reason)
 			desc := fmt.Sprintf("This will permanently delete message %q from channel %q.", messageID, channelName)
-			return tools.ConfirmPrompt(confirm, toolName, messageID, desc), nil
+			return tools.ConfirmPrompt(confirm, toolName, messageID, tools.WithRejection(desc, token, reason)), nil
 		}
 
-		if err := dg.ChannelMessageDelete(channelID, messageID); err != nil {
-			return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+		if err := dg.ChannelMessageDelete(channelID, messageID, discordgo.WithContext(ctx)); err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
 		}
 
-		tools.LogAudit(audit, toolName, params, "ok", start)
+		tools.LogAudit(ctx, audit, toolName, params, "ok", start)
 		return mcp.NewToolResultText("Message deleted successfully"), nil
 	}
 
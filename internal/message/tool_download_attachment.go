@@ -0,0 +1,218 @@
+package message
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultMaxAttachmentBytes is the download size cap used when
+// AttachmentConfig.MaxBytes is unset.
+const defaultMaxAttachmentBytes = 8 << 20
+
+// discordCDNHosts are the hosts Discord serves attachments from.
+var discordCDNHosts = []string{"cdn.discordapp.com", "media.discordapp.net"}
+
+// AttachmentConfig tunes discord_download_attachment and the downloads
+// discord_move_message makes to re-upload attachments. MaxBytes caps the size
+// of a download; zero or negative uses 8 MiB. Hosts lists the hosts (with a
+// port, if not the https default) URLs may point at and defaults to
+// Discord's CDN. Client makes the requests and defaults to one with a 30s
+// timeout; tests point Hosts and Client at a local server.
+type AttachmentConfig struct {
+	MaxBytes int64
+	Hosts    []string
+	Client   *http.Client
+}
+
+// withDefaults returns a copy of ac with unset fields filled in.
+func (ac AttachmentConfig) withDefaults() AttachmentConfig {
+	if ac.MaxBytes <= 0 {
+		ac.MaxBytes = defaultMaxAttachmentBytes
+	}
+	if len(ac.Hosts) == 0 {
+		ac.Hosts = discordCDNHosts
+	}
+	if ac.Client == nil {
+		ac.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return ac
+}
+
+// httpClient returns a copy of ac.Client that only follows redirects to
+// ac.Hosts over https. ac must have its defaults filled in.
+func (ac AttachmentConfig) httpClient() *http.Client {
+	client := *ac.Client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		if !slices.Contains(ac.Hosts, req.URL.Host) || req.URL.Scheme != "https" {
+			return fmt.Errorf("redirected to %s, which is not a Discord CDN host", req.URL.Host)
+		}
+		return nil
+	}
+	return &client
+}
+
+// AttachmentContent is the response of discord_download_attachment. Data is
+// the file's bytes, base64 encoded.
+type AttachmentContent struct {
+	URL         string `json:"url"`
+	ChannelID   string `json:"channel_id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	Data        string `json:"data"`
+}
+
+func toolDownloadAttachment(cfg AttachmentConfig, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_download_attachment"
+
+	cfg = cfg.withDefaults()
+	client := cfg.httpClient()
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription(fmt.Sprintf("Download a message attachment from Discord's CDN and return its bytes base64 encoded with its content type. Takes an attachment URL as returned in a message's attachments; files over %d bytes are refused.", cfg.MaxBytes)),
+		mcp.WithString("url",
+			mcp.Required(),
+			mcp.Description("Attachment URL, e.g. https://cdn.discordapp.com/attachments/<channel>/<attachment>/<filename>"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		rawURL := req.GetString("url", "")
+		params := map[string]any{"url": rawURL}
+
+		u, channelID, filename, err := parseAttachmentURL(rawURL, cfg.Hosts)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, tools.WithCode(tools.CodeInvalidArgument, err), start), nil
+		}
+		// The URL names the channel the file was posted in; downloading it
+		// reads that channel.
+		if _, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpRead, audit, logger, toolName, channelID, params, start); errResult != nil {
+			return errResult, nil
+		}
+
+		out, err := fetchAttachment(ctx, client, u, cfg.MaxBytes)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+		out.ChannelID = channelID
+		out.Filename = filename
+		logger.DebugContext(ctx, "attachment downloaded", "channelID", channelID, "filename", filename, "size", out.Size)
+
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d bytes", out.Size), start)
+		return tools.JSONResultWithText(fmt.Sprintf("Downloaded %s (%s, %d bytes)", filename, out.ContentType, out.Size), out), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// parseAttachmentURL checks that rawURL is an https attachment URL on one of
+// hosts and returns it parsed, with the channel ID and filename from its
+// /attachments/<channel>/<attachment>/<filename> path.
+func parseAttachmentURL(rawURL string, hosts []string) (*url.URL, string, string, error) {
+	if rawURL == "" {
+		return nil, "", "", errors.New("url must not be empty")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "https" || !slices.Contains(hosts, u.Host) || u.User != nil {
+		return nil, "", "", fmt.Errorf("url must be an https URL on %s", strings.Join(hosts, " or "))
+	}
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "attachments" || !isSnowflake(parts[1]) || !isSnowflake(parts[2]) || parts[3] == "" {
+		return nil, "", "", errors.New("url is not a Discord attachment URL (/attachments/<channel>/<attachment>/<filename>)")
+	}
+	return u, parts[1], parts[3], nil
+}
+
+// isSnowflake reports whether s looks like a Discord ID.
+func isSnowflake(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchAttachment downloads u with client, refusing bodies over maxBytes,
+// and returns it base64 encoded.
+func fetchAttachment(ctx context.Context, client *http.Client, u *url.URL, maxBytes int64) (AttachmentContent, error) {
+	data, contentType, err := downloadAttachment(ctx, client, u, maxBytes)
+	if err != nil {
+		return AttachmentContent{}, err
+	}
+	return AttachmentContent{
+		URL:         u.String(),
+		ContentType: contentType,
+		Size:        len(data),
+		Data:        base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+// downloadAttachment downloads u with client, refusing bodies over maxBytes,
+// and returns its bytes and content type. The content type comes from the
+// response, or is sniffed when absent.
+func downloadAttachment(ctx context.Context, client *http.Client, u *url.URL, maxBytes int64) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", tools.WithCode(tools.CodeDiscordUnavailable, fmt.Errorf("downloading attachment: %w", err))
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden:
+		// Discord answers 404 for deleted files and 403 for expired signed
+		// URLs; either way the file is gone from the client's view.
+		return nil, "", tools.WithCode(tools.CodeNotFound, fmt.Errorf("attachment not found (HTTP %d); the URL may have expired", resp.StatusCode))
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, "", tools.WithCode(tools.CodeDiscordUnavailable, fmt.Errorf("downloading attachment: HTTP %d", resp.StatusCode))
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("downloading attachment: HTTP %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, "", tools.WithCode(tools.CodeInvalidArgument, fmt.Errorf("attachment is %d bytes; the maximum is %d", resp.ContentLength, maxBytes))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", tools.WithCode(tools.CodeDiscordUnavailable, fmt.Errorf("reading attachment: %w", err))
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", tools.WithCode(tools.CodeInvalidArgument, fmt.Errorf("attachment is over the maximum of %d bytes", maxBytes))
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if _, _, err := mime.ParseMediaType(contentType); err != nil {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
@@ -0,0 +1,79 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/queue"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DrainResult is the JSON shape returned by discord_drain_messages. Every
+// drained message is returned: the max_result_items cap does not apply,
+// since the messages have already been removed from the queue.
+type DrainResult struct {
+	Count    int                   `json:"count"`
+	Messages []queue.QueuedMessage `json:"messages"`
+}
+
+func toolDrainMessages(q *queue.Queue, r resolve.ChannelResolver, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_drain_messages"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Remove and return every message currently in the queue at once, for batch processing. Unlike discord_poll_messages it has no limit and never waits."),
+		mcp.WithString("channel",
+			mcp.Description("Only drain messages from this channel name or ID; others stay queued (optional)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: \"json\" (default) or \"text\" for one \"[#channel] @user: text\" line per message"),
+			mcp.Enum("json", "text"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		channel := req.GetString("channel", "")
+		format := req.GetString("format", "json")
+		params := map[string]any{
+			"channel": channel,
+			"format":  format,
+		}
+		if format != "json" && format != "text" {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, tools.WithCode(tools.CodeInvalidArgument, fmt.Errorf("invalid format %q (want json or text)", format)), start), nil
+		}
+
+		var channelFilter string
+		if channel != "" {
+			resolved, err := resolve.ResolveChannelParam(r, channel)
+			if err != nil {
+				return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+			}
+			channelFilter = resolved
+			logger.DebugContext(ctx, "resolved channel", "input", channel, "channelID", channelFilter)
+		}
+
+		msgs := q.Drain(channelFilter)
+		if len(msgs) == 0 {
+			tools.LogAuditOutcome(ctx, audit, toolName, params, safety.OutcomeNoMessages, "no messages", start)
+			if format == "text" {
+				return mcp.NewToolResultText("No new messages"), nil
+			}
+			return tools.JSONResult(DrainResult{Messages: []queue.QueuedMessage{}}), nil
+		}
+
+		logger.DebugContext(ctx, "queue drained", "count", len(msgs))
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d messages", len(msgs)), start)
+		if format == "text" {
+			return mcp.NewToolResultText(formatMessages(msgs)), nil
+		}
+		return tools.JSONResult(DrainResult{Count: len(msgs), Messages: msgs}), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
@@ -2,9 +2,11 @@ package message
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/discord"
 	"github.com/jamesprial/claudebot-mcp/internal/resolve"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
@@ -13,11 +15,11 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func toolEditMessage(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+func toolEditMessage(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, mentions MentionPolicy, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
 	const toolName = "discord_edit_message"
 
 	tool := mcp.NewTool(toolName,
-		mcp.WithDescription("Edit an existing Discord message."),
+		mcp.WithDescription("Edit an existing Discord message's text, embed, or both."),
 		mcp.WithString("channel",
 			mcp.Required(),
 			mcp.Description("Channel name or ID"),
@@ -27,33 +29,85 @@ func toolEditMessage(dg discord.DiscordClient, r resolve.ChannelResolver, filter
 			mcp.Description("ID of the message to edit"),
 		),
 		mcp.WithString("content",
-			mcp.Required(),
-			mcp.Description("New message content"),
+			mcp.Description("New message content (omit to leave the text unchanged)"),
+		),
+		mcp.WithObject("embed",
+			mcp.Description("New embed using Discord's field names (title, description, url, color, fields, footer, image, thumbnail, author, timestamp). Pass null or {} to remove the embed; omit to leave it unchanged."),
+		),
+		mcp.WithString("expected_content",
+			mcp.Description("Only edit if the message's current text equals this, e.g. the content you last read; otherwise fail with a CONFLICT error showing the current text (optional)"),
 		),
 	)
 
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		start := time.Now()
+		args := req.GetArguments()
 		channel := req.GetString("channel", "")
 		messageID := req.GetString("message_id", "")
-		content := req.GetString("content", "")
+		content, hasContent := args["content"].(string)
+		embedArg, hasEmbed := args["embed"]
+		expected, hasExpected := args["expected_content"].(string)
 		params := map[string]any{
 			"channel":    channel,
 			"message_id": messageID,
 			"content":    content,
 		}
+		if hasEmbed {
+			params["embed"] = embedArg
+		}
+		if hasExpected {
+			params["expected_content"] = expected
+		}
 
-		channelID, _, errResult := tools.ResolveAndFilterChannel(r, filter, audit, logger, toolName, channel, params, start)
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, channel, params, start)
 		if errResult != nil {
 			return errResult, nil
 		}
+		if !hasContent && !hasEmbed {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("content or embed is required"), start), nil
+		}
+		if err := checkContentLength(content); err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		edit := discordgo.NewMessageEdit(channelID, messageID)
+		if hasContent {
+			edit.SetContent(content)
+			edit.AllowedMentions = mentions.apply(content, nil)
+		}
+		if hasEmbed {
+			embed, err := parseEmbed(embedArg)
+			if err != nil {
+				return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+			}
+			embeds := []*discordgo.MessageEmbed{}
+			if embed != nil {
+				embeds = append(embeds, embed)
+			}
+			edit.Embeds = &embeds
+		}
+
+		if hasExpected {
+			// Compare-and-set: another client editing between this read and
+			// the edit below still wins, but stale overwrites of an earlier
+			// change are refused.
+			current, err := dg.ChannelMessage(channelID, messageID, discordgo.WithContext(ctx))
+			if err != nil {
+				return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+			}
+			if current.Content != expected {
+				err := fmt.Errorf("message %s has changed; current content: %q", messageID, current.Content)
+				return tools.AuditErrorResult(ctx, audit, toolName, params, tools.WithCode(tools.CodeConflict, err), start), nil
+			}
+		}
 
-		if _, err := dg.ChannelMessageEdit(channelID, messageID, content); err != nil {
-			return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+		msg, err := dg.ChannelMessageEditComplex(edit, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
 		}
 
-		tools.LogAudit(audit, toolName, params, "ok", start)
-		return mcp.NewToolResultText("Message edited successfully"), nil
+		tools.LogAudit(ctx, audit, toolName, params, "ok", start)
+		return tools.JSONResultWithText("Message edited successfully", summarizeMessage(msg)), nil
 	}
 
 	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
@@ -0,0 +1,56 @@
+package message
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func toolGetMessage(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_get_message"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Retrieve the current state of a single Discord message by ID, including its attachments and reactions."),
+		mcp.WithString("channel",
+			mcp.Required(),
+			mcp.Description("Channel name or ID"),
+		),
+		mcp.WithString("message_id",
+			mcp.Required(),
+			mcp.Description("ID of the message to retrieve"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		channel := req.GetString("channel", "")
+		messageID := req.GetString("message_id", "")
+		params := map[string]any{
+			"channel":    channel,
+			"message_id": messageID,
+		}
+
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpRead, audit, logger, toolName, channel, params, start)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		msg, err := dg.ChannelMessage(channelID, messageID, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, "ok", start)
+		return tools.JSONResult(summarizeMessage(msg)), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/discord"
 	"github.com/jamesprial/claudebot-mcp/internal/resolve"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
@@ -14,7 +18,7 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func toolGetMessages(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+func toolGetMessages(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, results tools.ResultLimit, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
 	const toolName = "discord_get_messages"
 
 	tool := mcp.NewTool(toolName,
@@ -29,6 +33,21 @@ func toolGetMessages(dg discord.DiscordClient, r resolve.ChannelResolver, filter
 		mcp.WithString("before",
 			mcp.Description("Retrieve messages before this message ID (optional)"),
 		),
+		mcp.WithString("since",
+			mcp.Description("Only return messages sent at or after this time: an RFC 3339 timestamp (e.g. 2025-01-02T15:04:05Z) or a duration ago such as '30m' or '2h' (optional). If more than limit messages match, the newest are returned."),
+		),
+		mcp.WithString("until",
+			mcp.Description("Only return messages sent before this time: an RFC 3339 timestamp or a duration ago (optional)"),
+		),
+		mcp.WithString("author",
+			mcp.Description("Only return messages from this user ID or username (optional). Filtering happens after fetching limit messages, so fewer than limit may be returned; page with before to see older ones."),
+		),
+		mcp.WithBoolean("skip_system",
+			mcp.Description("Leave out system messages such as member joins, pin notices, boosts and thread creation notices (default: true). Ordinary messages, replies and slash command responses are kept. As with author, fewer than limit messages may be returned."),
+		),
+		mcp.WithBoolean("pinned_only",
+			mcp.Description("Return only the channel's pinned messages, newest pin first (default: false). before and limit are ignored; since, until and author still apply."),
+		),
 	)
 
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -36,6 +55,11 @@ func toolGetMessages(dg discord.DiscordClient, r resolve.ChannelResolver, filter
 		channel := req.GetString("channel", "")
 		limit := req.GetInt("limit", 50)
 		before := req.GetString("before", "")
+		sinceParam := req.GetString("since", "")
+		untilParam := req.GetString("until", "")
+		author := strings.TrimSpace(req.GetString("author", ""))
+		pinnedOnly := req.GetBool("pinned_only", false)
+		skipSystem := req.GetBool("skip_system", true)
 
 		if limit <= 0 {
 			limit = 50
@@ -48,38 +72,140 @@ func toolGetMessages(dg discord.DiscordClient, r resolve.ChannelResolver, filter
 			"channel": channel,
 			"limit":   limit,
 			"before":  before,
+			"since":   sinceParam,
+			"until":   untilParam,
+			"author":  author,
+		}
+		if pinnedOnly {
+			params["pinned_only"] = true
+		}
+		if !skipSystem {
+			params["skip_system"] = false
 		}
 
-		channelID, _, errResult := tools.ResolveAndFilterChannel(r, filter, audit, logger, toolName, channel, params, start)
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpRead, audit, logger, toolName, channel, params, start)
 		if errResult != nil {
 			return errResult, nil
 		}
 
-		rawMsgs, err := dg.ChannelMessages(channelID, limit, before, "", "")
+		since, err := parseTimeBound("since", sinceParam, start)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+		until, err := parseTimeBound("until", untilParam, start)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+		if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("since must be before until"), start), nil
+		}
+		keep := func(m MessageSummary) bool {
+			if !since.IsZero() && m.Timestamp.Before(since) {
+				return false
+			}
+			if !until.IsZero() && !m.Timestamp.Before(until) {
+				return false
+			}
+			return author == "" || m.AuthorID == author || strings.EqualFold(m.AuthorUsername, author)
+		}
+
+		if pinnedOnly {
+			pins, err := pinnedMessages(ctx, dg, channelID, maxPinnedMessages)
+			if err != nil {
+				return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+			}
+			summaries := slices.DeleteFunc(pins, func(m MessageSummary) bool { return !keep(m) })
+			tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d pinned messages", len(summaries)), start)
+			result := results.JSONResult(summaries)
+			if ignored := ignoredWithPinnedOnly(req); len(ignored) > 0 {
+				result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf("pinned_only ignores %s", strings.Join(ignored, " and "))))
+			}
+			return result, nil
+		}
+
+		// Discord pages by message ID, not time, so until becomes the ID of
+		// a message sent at that instant.
+		if before == "" && !until.IsZero() {
+			before = snowflakeAt(until)
+		}
+
+		rawMsgs, err := dg.ChannelMessages(channelID, limit, before, "", "", discordgo.WithContext(ctx))
 		if err != nil {
-			return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
 		}
 
 		summaries := make([]MessageSummary, 0, len(rawMsgs))
 		for _, m := range rawMsgs {
-			s := MessageSummary{
-				ID:        m.ID,
-				Content:   m.Content,
-				Timestamp: m.Timestamp,
-			}
-			if m.Author != nil {
-				s.AuthorID = m.Author.ID
-				s.AuthorUsername = m.Author.Username
+			if skipSystem && isSystemMessage(m) {
+				continue
 			}
-			if m.MessageReference != nil {
-				s.ReplyTo = m.MessageReference.MessageID
+			if s := summarizeMessage(m); keep(s) {
+				summaries = append(summaries, s)
 			}
-			summaries = append(summaries, s)
 		}
 
-		tools.LogAudit(audit, toolName, params, fmt.Sprintf("ok: %d messages", len(summaries)), start)
-		return tools.JSONResult(summaries), nil
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d messages", len(summaries)), start)
+		return results.JSONResult(summaries), nil
 	}
 
 	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
 }
+
+// userMessageTypes are the message types that carry content someone wrote:
+// ordinary messages, replies and slash or context menu command responses.
+// Every other type is a system message, such as a member join or pin notice.
+var userMessageTypes = []discordgo.MessageType{
+	discordgo.MessageTypeDefault,
+	discordgo.MessageTypeReply,
+	discordgo.MessageTypeChatInputCommand,
+	discordgo.MessageTypeContextMenuCommand,
+}
+
+// isSystemMessage reports whether m is a system message rather than one in
+// userMessageTypes.
+func isSystemMessage(m *discordgo.Message) bool {
+	return !slices.Contains(userMessageTypes, m.Type)
+}
+
+// ignoredWithPinnedOnly lists the arguments req passed that pinned_only
+// ignores, in a fixed order.
+func ignoredWithPinnedOnly(req mcp.CallToolRequest) []string {
+	args := req.GetArguments()
+	var ignored []string
+	for _, name := range []string{"before", "limit"} {
+		if _, ok := args[name]; ok {
+			ignored = append(ignored, name)
+		}
+	}
+	return ignored
+}
+
+// discordEpoch is the start of Discord's snowflake epoch, 2015-01-01 UTC, in
+// Unix milliseconds.
+const discordEpoch = 1420070400000
+
+// snowflakeAt returns the smallest snowflake ID Discord could assign at t, for
+// use as a before/after cursor.
+func snowflakeAt(t time.Time) string {
+	ms := t.UnixMilli() - discordEpoch
+	if ms < 0 {
+		ms = 0
+	}
+	return strconv.FormatInt(ms<<22, 10)
+}
+
+// parseTimeBound parses a since/until parameter: an RFC 3339 timestamp, or a
+// positive duration such as "30m" measured back from now. Empty returns the
+// zero time.
+func parseTimeBound(name, v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s %q: want an RFC 3339 timestamp or a duration such as 30m", name, v)
+}
@@ -0,0 +1,80 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func toolGetPinnedMessages(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, results tools.ResultLimit, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_get_pinned_messages"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Retrieve the pinned messages of a Discord channel, such as its rules or FAQ."),
+		mcp.WithString("channel",
+			mcp.Required(),
+			mcp.Description("Channel name or ID"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of pinned messages to retrieve (default: 50, max: 50)"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		channel := req.GetString("channel", "")
+		limit := req.GetInt("limit", maxPinnedMessages)
+		if limit <= 0 || limit > maxPinnedMessages {
+			limit = maxPinnedMessages
+		}
+
+		params := map[string]any{
+			"channel": channel,
+			"limit":   limit,
+		}
+
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpRead, audit, logger, toolName, channel, params, start)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		summaries, err := pinnedMessages(ctx, dg, channelID, limit)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d messages", len(summaries)), start)
+		return results.JSONResult(summaries), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// maxPinnedMessages is the most pinned messages Discord returns per request.
+const maxPinnedMessages = 50
+
+// pinnedMessages fetches up to limit of channelID's pinned messages, newest
+// pin first.
+func pinnedMessages(ctx context.Context, dg discord.DiscordClient, channelID string, limit int) ([]MessageSummary, error) {
+	pins, err := dg.ChannelMessagesPinned(channelID, nil, limit, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]MessageSummary, 0, len(pins.Items))
+	for _, pin := range pins.Items {
+		if pin == nil || pin.Message == nil {
+			continue
+		}
+		summaries = append(summaries, summarizeMessage(pin.Message))
+	}
+	return summaries, nil
+}
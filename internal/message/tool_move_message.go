@@ -0,0 +1,155 @@
+package message
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func toolMoveMessage(dg discord.DiscordClient, attachments AttachmentConfig, r resolve.ChannelResolver, filter *safety.Filter, confirm *safety.ConfirmationTracker, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_move_message"
+
+	attachments = attachments.withDefaults()
+	client := attachments.httpClient()
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription(fmt.Sprintf("Move a Discord message to another channel by reposting it with author attribution and deleting the original. Attachments are downloaded and re-uploaded; a message with an attachment over %d bytes is not moved. Requires confirmation.", attachments.MaxBytes)),
+		mcp.WithString("source_channel",
+			mcp.Required(),
+			mcp.Description("Channel name or ID the message is currently in"),
+		),
+		mcp.WithString("message_id",
+			mcp.Required(),
+			mcp.Description("ID of the message to move"),
+		),
+		mcp.WithString("target_channel",
+			mcp.Required(),
+			mcp.Description("Channel name or ID to move the message to"),
+		),
+		tools.ConfirmationParam(confirm),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		source := req.GetString("source_channel", "")
+		messageID := req.GetString("message_id", "")
+		target := req.GetString("target_channel", "")
+		params := map[string]any{
+			"source_channel": source,
+			"message_id":     messageID,
+			"target_channel": target,
+		}
+
+		sourceID, sourceName, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, source, params, start)
+		if errResult != nil {
+			return errResult, nil
+		}
+		targetID, targetName, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, target, params, start)
+		if errResult != nil {
+			return errResult, nil
+		}
+		if sourceID == targetID {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("source and target channel are both %q", sourceName), start), nil
+		}
+
+		// Bind the token to the target too, so it cannot confirm a move of
+		// the same message elsewhere.
+		resource := messageID + " -> " + targetID
+		if ok, token, reason := tools.Approve(confirm, req, toolName, resource); !ok {
+			logger.DebugContext(ctx, "confirmation required", "tool", toolName, "reason", reason)
+			desc := fmt.Sprintf("This will repost message %q from channel %q to channel %q and permanently delete the original.", messageID, sourceName, targetName)
+			return tools.ConfirmPrompt(confirm, toolName, resource, tools.WithRejection(desc, token, reason)), nil
+		}
+
+		original, err := dg.ChannelMessage(sourceID, messageID, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		// Download every file before posting anything: the original's
+		// attachment URLs stop working once it is deleted.
+		files, err := downloadFiles(ctx, client, attachments, original.Attachments)
+		if err != nil {
+			err = fmt.Errorf("original kept: %w", err)
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		var sent []*discordgo.Message
+		chunks := splitContent(repostContent(original, sourceName), maxMessageLength)
+		for i, chunk := range chunks {
+			data := &discordgo.MessageSend{
+				Content:         chunk,
+				AllowedMentions: allowedMentions(false, false, true),
+			}
+			if i == len(chunks)-1 {
+				data.Files = files
+			}
+			msg, _, err := sendWithRetry(ctx, dg, targetID, data, logger)
+			if err != nil {
+				if len(sent) > 0 {
+					err = fmt.Errorf("reposted %d of %d parts (IDs: %s), original kept: %w", len(sent), len(chunks), strings.Join(messageIDs(sent), ", "), err)
+				}
+				return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+			}
+			sent = append(sent, msg)
+		}
+
+		ids := strings.Join(messageIDs(sent), ", ")
+		if err := dg.ChannelMessageDelete(sourceID, messageID, discordgo.WithContext(ctx)); err != nil {
+			err = fmt.Errorf("reposted as %s but failed to delete original: %w", ids, err)
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, "ok: "+ids, start)
+		return tools.JSONResultWithText(fmt.Sprintf("Message moved to #%s (ID: %s)", targetName, ids), summarizeMessage(sent[0])), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// downloadFiles downloads attachments so they can be uploaded again. Each
+// URL must be on one of cfg.Hosts, and each file at most cfg.MaxBytes.
+func downloadFiles(ctx context.Context, client *http.Client, cfg AttachmentConfig, attachments []*discordgo.MessageAttachment) ([]*discordgo.File, error) {
+	files := make([]*discordgo.File, 0, len(attachments))
+	for _, a := range attachments {
+		u, _, _, err := parseAttachmentURL(a.URL, cfg.Hosts)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %q: %w", a.Filename, err)
+		}
+		data, contentType, err := downloadAttachment(ctx, client, u, cfg.MaxBytes)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %q: %w", a.Filename, err)
+		}
+		if a.ContentType != "" {
+			contentType = a.ContentType
+		}
+		files = append(files, &discordgo.File{
+			Name:        a.Filename,
+			ContentType: contentType,
+			Reader:      bytes.NewReader(data),
+		})
+	}
+	return files, nil
+}
+
+// repostContent renders m for reposting in another channel, prefixed with its
+// author and original channel. Attachments are re-uploaded separately.
+func repostContent(m *discordgo.Message, sourceName string) string {
+	author := "unknown"
+	if m.Author != nil {
+		author = m.Author.Username
+	}
+	return fmt.Sprintf("**%s** (moved from #%s):\n%s", author, sourceName, m.Content)
+}
@@ -0,0 +1,118 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxThreadNameLength is the longest thread name Discord accepts.
+const maxThreadNameLength = 100
+
+// ThreadResult is the response of discord_move_to_thread. StarterMessageID
+// is set when starter_content was posted.
+type ThreadResult struct {
+	ThreadID         string `json:"thread_id"`
+	Name             string `json:"name"`
+	ParentID         string `json:"parent_id"`
+	MessageID        string `json:"message_id"`
+	StarterMessageID string `json:"starter_message_id,omitempty"`
+}
+
+func toolMoveToThread(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, mentions MentionPolicy, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_move_to_thread"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Start a thread from an existing message, e.g. to take a support question out of a busy channel, and optionally post a first message in it. Returns the new thread's ID, which can be used as a channel with the other tools."),
+		mcp.WithString("channel",
+			mcp.Required(),
+			mcp.Description("Channel name or ID the message is in"),
+		),
+		mcp.WithString("message_id",
+			mcp.Required(),
+			mcp.Description("ID of the message to start the thread from"),
+		),
+		mcp.WithString("thread_name",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Name of the new thread, up to %d characters", maxThreadNameLength)),
+		),
+		mcp.WithString("starter_content",
+			mcp.Description("Message to post in the new thread (optional)"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		channel := req.GetString("channel", "")
+		messageID := req.GetString("message_id", "")
+		name := strings.TrimSpace(req.GetString("thread_name", ""))
+		starter := req.GetString("starter_content", "")
+		params := map[string]any{
+			"channel":         channel,
+			"message_id":      messageID,
+			"thread_name":     name,
+			"starter_content": starter,
+		}
+
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, channel, params, start)
+		if errResult != nil {
+			return errResult, nil
+		}
+		if messageID == "" {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, tools.WithCode(tools.CodeInvalidArgument, fmt.Errorf("message_id must not be empty")), start), nil
+		}
+		if err := validateThreadName(name); err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, tools.WithCode(tools.CodeInvalidArgument, err), start), nil
+		}
+		if err := checkContentLength(starter); err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, tools.WithCode(tools.CodeInvalidArgument, err), start), nil
+		}
+
+		thread, err := dg.MessageThreadStartComplex(channelID, messageID, &discordgo.ThreadStart{Name: name}, discordgo.WithContext(ctx))
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("starting thread: %w", err), start), nil
+		}
+		logger.DebugContext(ctx, "thread started", "channelID", channelID, "messageID", messageID, "threadID", thread.ID)
+
+		out := ThreadResult{ThreadID: thread.ID, Name: thread.Name, ParentID: channelID, MessageID: messageID}
+		if starter != "" {
+			data := &discordgo.MessageSend{
+				Content:         starter,
+				AllowedMentions: mentions.apply(starter, allowedMentions(false, false, false)),
+			}
+			msg, _, err := sendWithRetry(ctx, dg, thread.ID, data, logger)
+			if err != nil {
+				err = fmt.Errorf("thread %s was created but the starter message was not sent: %w", thread.ID, err)
+				return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+			}
+			out.StarterMessageID = msg.ID
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, "ok: "+thread.ID, start)
+		return tools.JSONResultWithText(fmt.Sprintf("Thread %q started (ID: %s)", out.Name, out.ThreadID), out), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// validateThreadName checks a thread name against Discord's limits.
+func validateThreadName(name string) error {
+	if name == "" {
+		return fmt.Errorf("thread_name must not be empty")
+	}
+	if n := utf8.RuneCountInString(name); n > maxThreadNameLength {
+		return fmt.Errorf("thread_name is %d characters; the maximum is %d", n, maxThreadNameLength)
+	}
+	return nil
+}
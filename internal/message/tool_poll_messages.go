@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/jamesprial/claudebot-mcp/internal/auth"
 	"github.com/jamesprial/claudebot-mcp/internal/queue"
 	"github.com/jamesprial/claudebot-mcp/internal/resolve"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
@@ -14,43 +17,92 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func toolPollMessages(q *queue.Queue, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+// Bounds of the retry_after_seconds hint returned with an empty poll. The
+// hint starts at minEmptyPollBackoff and doubles with each consecutive empty
+// poll from the same client, up to maxEmptyPollBackoff.
+const (
+	minEmptyPollBackoff = 1 * time.Second
+	maxEmptyPollBackoff = 60 * time.Second
+)
+
+// defaultPollLimit is the number of messages a poll returns when the client
+// omits limit.
+const defaultPollLimit = 50
+
+// pollLimitDescription describes the limit parameter, naming the cap when
+// there is one.
+func pollLimitDescription(results tools.ResultLimit) string {
+	if max := results.Max(); max >= 0 {
+		return fmt.Sprintf("Maximum number of messages to return (default: %d, max: %d)", min(defaultPollLimit, max), max)
+	}
+	return fmt.Sprintf("Maximum number of messages to return (default: %d)", defaultPollLimit)
+}
+
+// EmptyPollResult is the JSON shape returned alongside "No new messages".
+// RetryAfterSeconds suggests how long the client should wait before polling
+// again; it grows while the queue stays empty.
+type EmptyPollResult struct {
+	RetryAfterSeconds int `json:"retry_after_seconds"`
+}
+
+func toolPollMessages(shutdown context.Context, q *queue.Queue, poll PollConfig, keepalive pollKeepalive, results tools.ResultLimit, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
 	const toolName = "discord_poll_messages"
 
 	tool := mcp.NewTool(toolName,
-		mcp.WithDescription("Long-poll the message queue for incoming Discord messages."),
+		mcp.WithDescription("Long-poll the message queue for incoming Discord messages. An empty result carries a retry_after_seconds hint that grows while the queue stays empty."),
 		mcp.WithNumber("timeout_seconds",
-			mcp.Description("Seconds to wait for messages (default: 30, max: 300)"),
+			mcp.Description(fmt.Sprintf("Seconds to wait for messages (default: %d, max: %d)", poll.DefaultTimeoutSec, poll.MaxTimeoutSec)),
 		),
 		mcp.WithNumber("limit",
-			mcp.Description("Maximum number of messages to return (default: 50)"),
+			mcp.Description(pollLimitDescription(results)),
 		),
 		mcp.WithString("channel",
 			mcp.Description("Channel name or ID to filter messages (optional)"),
 		),
+		mcp.WithBoolean("no_wait",
+			mcp.Description("Return immediately with whatever is queued instead of waiting (default: false)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: \"json\" (default) or \"text\" for one \"[#channel] @user: text\" line per message"),
+			mcp.Enum("json", "text"),
+		),
 	)
 
+	streaks := newEmptyPollStreaks()
+
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		start := time.Now()
 
-		timeoutSec := req.GetInt("timeout_seconds", 30)
+		timeoutSec := req.GetInt("timeout_seconds", poll.DefaultTimeoutSec)
 		if timeoutSec <= 0 {
-			timeoutSec = 30
+			timeoutSec = poll.DefaultTimeoutSec
 		}
-		if timeoutSec > 300 {
-			timeoutSec = 300
+		if timeoutSec > poll.MaxTimeoutSec {
+			timeoutSec = poll.MaxTimeoutSec
 		}
 
-		limit := req.GetInt("limit", 50)
+		limit := req.GetInt("limit", defaultPollLimit)
 		if limit <= 0 {
-			limit = 50
+			limit = defaultPollLimit
+		}
+		// Polled messages leave the queue, so never take more than the
+		// result cap would return.
+		if max := results.Max(); max >= 0 && limit > max {
+			limit = max
 		}
 
+		noWait := req.GetBool("no_wait", false)
 		channel := req.GetString("channel", "")
+		format := req.GetString("format", "json")
 		params := map[string]any{
 			"timeout_seconds": timeoutSec,
 			"limit":           limit,
 			"channel":         channel,
+			"no_wait":         noWait,
+			"format":          format,
+		}
+		if format != "json" && format != "text" {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("invalid format %q (want json or text)", format), start), nil
 		}
 
 		// Resolve channel filter if provided.
@@ -58,21 +110,89 @@ func toolPollMessages(q *queue.Queue, r resolve.ChannelResolver, filter *safety.
 		if channel != "" {
 			resolved, err := resolve.ResolveChannelParam(r, channel)
 			if err != nil {
-				return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+				return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
 			}
 			channelFilter = resolved
-			logger.Debug("resolved channel", "input", channel, "channelID", channelFilter)
+			logger.DebugContext(ctx, "resolved channel", "input", channel, "channelID", channelFilter)
 		}
 
-		msgs := q.Poll(ctx, time.Duration(timeoutSec)*time.Second, limit, channelFilter)
+		pollCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(shutdown, cancel)
+		defer stop()
+
+		timeout := time.Duration(timeoutSec) * time.Second
+		if noWait {
+			timeout = 0
+		}
+		stopKeepalive := tools.StartKeepalive(pollCtx, keepalive.notifier, req, keepalive.interval)
+		msgs := q.Poll(pollCtx, timeout, limit, channelFilter)
+		stopKeepalive()
+		if len(msgs) == 0 && shutdown.Err() != nil {
+			tools.LogAuditOutcome(ctx, audit, toolName, params, safety.OutcomeError, "error: server shutting down", start)
+			return tools.ErrorResult("server shutting down"), nil
+		}
+		client := auth.ClientFromContext(ctx)
 		if len(msgs) == 0 {
-			tools.LogAudit(audit, toolName, params, "no messages", start)
-			return mcp.NewToolResultText("No new messages"), nil
+			retryAfter := streaks.empty(client)
+			tools.LogAuditOutcome(ctx, audit, toolName, params, safety.OutcomeNoMessages, "no messages", start)
+			return tools.JSONResultWithText("No new messages", EmptyPollResult{RetryAfterSeconds: int(retryAfter / time.Second)}), nil
 		}
+		streaks.reset(client)
 
-		tools.LogAudit(audit, toolName, params, fmt.Sprintf("ok: %d messages", len(msgs)), start)
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d messages", len(msgs)), start)
+		if format == "text" {
+			return mcp.NewToolResultText(formatMessages(msgs)), nil
+		}
 		return tools.JSONResult(msgs), nil
 	}
 
 	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
 }
+
+// formatMessages renders msgs one per line using QueuedMessage.Formatted,
+// followed by the delivery token in acknowledgment mode.
+func formatMessages(msgs []queue.QueuedMessage) string {
+	lines := make([]string, len(msgs))
+	for i, m := range msgs {
+		lines[i] = m.Formatted()
+		if m.DeliveryToken != "" {
+			lines[i] += " [delivery_token=" + m.DeliveryToken + "]"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// emptyPollStreaks counts consecutive empty polls per client to compute the
+// retry_after_seconds hint. It is safe for concurrent use.
+type emptyPollStreaks struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// newEmptyPollStreaks returns an emptyPollStreaks with no recorded polls.
+func newEmptyPollStreaks() *emptyPollStreaks {
+	return &emptyPollStreaks{counts: make(map[string]int)}
+}
+
+// empty records an empty poll from client and returns the suggested wait
+// before its next poll.
+func (e *emptyPollStreaks) empty(client string) time.Duration {
+	e.mu.Lock()
+	e.counts[client]++
+	n := e.counts[client]
+	e.mu.Unlock()
+
+	backoff := minEmptyPollBackoff
+	for i := 1; i < n && backoff < maxEmptyPollBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxEmptyPollBackoff)
+}
+
+// reset ends client's streak after a poll that returned messages.
+func (e *emptyPollStreaks) reset(client string) {
+	e.mu.Lock()
+	delete(e.counts, client)
+	e.mu.Unlock()
+}
@@ -0,0 +1,50 @@
+package message
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/queue"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// QueueInfo is the response shape returned by discord_queue_info. InFlight
+// counts polled messages awaiting acknowledgment and is only reported when
+// the queue is in acknowledgment mode.
+type QueueInfo struct {
+	Length      int     `json:"length"`
+	Capacity    int     `json:"capacity"`
+	PercentFull float64 `json:"percent_full"`
+	InFlight    int     `json:"in_flight,omitempty"`
+}
+
+func toolQueueInfo(q *queue.Queue, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_queue_info"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Report how many messages are waiting in the queue, its capacity, and how full it is."),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		info := QueueInfo{
+			Length:   q.Len(),
+			Capacity: q.Cap(),
+			InFlight: q.InFlight(),
+		}
+		if info.Capacity > 0 {
+			info.PercentFull = float64(info.Length) * 100 / float64(info.Capacity)
+		}
+		logger.DebugContext(ctx, "queue info", "length", info.Length, "capacity", info.Capacity)
+
+		tools.LogAudit(ctx, audit, toolName, map[string]any{}, "ok", start)
+		return tools.JSONResult(info), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
@@ -0,0 +1,149 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func toolScheduleMessage(sched *scheduler, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = scheduledToolName
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Schedule a message to be sent to a Discord channel later. Scheduled messages are kept in memory and lost if the server restarts."),
+		mcp.WithString("channel",
+			mcp.Required(),
+			mcp.Description("Channel name or ID"),
+		),
+		mcp.WithString("content",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Message content to send; content over %d characters is sent as several messages", maxMessageLength)),
+		),
+		mcp.WithString("send_at",
+			mcp.Description("When to send, as an RFC 3339 timestamp (e.g. 2025-01-02T15:04:05Z). Give either send_at or delay_seconds."),
+		),
+		mcp.WithNumber("delay_seconds",
+			mcp.Description("Seconds from now to send. Give either send_at or delay_seconds."),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		channel := req.GetString("channel", "")
+		content := req.GetString("content", "")
+		sendAtParam := req.GetString("send_at", "")
+		delay := req.GetFloat("delay_seconds", 0)
+		params := map[string]any{
+			"channel":       channel,
+			"content":       content,
+			"send_at":       sendAtParam,
+			"delay_seconds": delay,
+		}
+
+		channelID, channelName, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, channel, params, start)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		sendAt, err := scheduleTime(sendAtParam, delay, start)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+		if content == "" {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("content is empty"), start), nil
+		}
+
+		scheduled, err := sched.schedule(ctx, channelID, channelName, content, sendAt)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		logger.DebugContext(ctx, "message scheduled", "id", scheduled.ID, "channelID", channelID, "send_at", scheduled.SendAt)
+		tools.LogAudit(ctx, audit, toolName, params, "ok: "+scheduled.ID, start)
+		return tools.JSONResultWithText(fmt.Sprintf("Message scheduled (ID: %s) for %s", scheduled.ID, scheduled.SendAt.Format(time.RFC3339)), scheduled), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+func toolListScheduled(sched *scheduler, results tools.ResultLimit, audit *safety.AuditLogger) tools.Registration {
+	const toolName = "discord_list_scheduled"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("List messages scheduled with discord_schedule_message that have not been sent yet."),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		pending := sched.list()
+		tools.LogAudit(ctx, audit, toolName, nil, fmt.Sprintf("ok: %d scheduled", len(pending)), start)
+		return results.JSONResult(pending), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+func toolCancelScheduled(sched *scheduler, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_cancel_scheduled"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Cancel a message scheduled with discord_schedule_message before it is sent."),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("ID returned by discord_schedule_message"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		id := req.GetString("id", "")
+		params := map[string]any{"id": id}
+
+		cancelled, ok := sched.cancel(id)
+		if !ok {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("no scheduled message %q (already sent or cancelled?)", id), start), nil
+		}
+
+		logger.DebugContext(ctx, "scheduled message cancelled", "id", id)
+		tools.LogAudit(ctx, audit, toolName, params, "ok", start)
+		return tools.JSONResultWithText(fmt.Sprintf("Scheduled message %s cancelled", id), cancelled), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// scheduleTime returns the send time given by exactly one of sendAt (RFC
+// 3339) or delaySec, measured from now. The time must be in the future and
+// no more than maxScheduleDelay ahead.
+func scheduleTime(sendAt string, delaySec float64, now time.Time) (time.Time, error) {
+	var at time.Time
+	switch {
+	case sendAt != "" && delaySec != 0:
+		return time.Time{}, fmt.Errorf("give either send_at or delay_seconds, not both")
+	case sendAt != "":
+		t, err := time.Parse(time.RFC3339, sendAt)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid send_at %q: want an RFC 3339 timestamp", sendAt)
+		}
+		at = t
+	case delaySec > 0:
+		at = now.Add(time.Duration(delaySec * float64(time.Second)))
+	default:
+		return time.Time{}, fmt.Errorf("send_at or a positive delay_seconds is required")
+	}
+
+	if !at.After(now) {
+		return time.Time{}, fmt.Errorf("send time %s is in the past", at.UTC().Format(time.RFC3339))
+	}
+	if at.Sub(now) > maxScheduleDelay {
+		return time.Time{}, fmt.Errorf("send time %s is more than %s ahead", at.UTC().Format(time.RFC3339), maxScheduleDelay)
+	}
+	return at, nil
+}
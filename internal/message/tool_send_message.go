@@ -2,9 +2,15 @@ package message
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/discord"
@@ -15,7 +21,25 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func toolSendMessage(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+// maxMessageLength is the largest message content Discord accepts, in
+// characters.
+const maxMessageLength = 2000
+
+// maxSendRetryWait caps how long a send waits on a rate limit before
+// retrying, even when the request context has no deadline. Slowmode can ask
+// for hours; longer waits are reported as rate-limit errors instead.
+const maxSendRetryWait = 30 * time.Second
+
+// checkContentLength returns a descriptive error when content is longer than
+// Discord accepts, so the caller can fail before making a request.
+func checkContentLength(content string) error {
+	if n := utf8.RuneCountInString(content); n > maxMessageLength {
+		return fmt.Errorf("content is %d characters; the maximum is %d", n, maxMessageLength)
+	}
+	return nil
+}
+
+func toolSendMessage(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, mentions MentionPolicy, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
 	const toolName = "discord_send_message"
 
 	tool := mcp.NewTool(toolName,
@@ -31,6 +55,21 @@ func toolSendMessage(dg discord.DiscordClient, r resolve.ChannelResolver, filter
 		mcp.WithString("reply_to",
 			mcp.Description("Message ID to reply to (optional)"),
 		),
+		mcp.WithBoolean("mention_reply",
+			mcp.Description("When replying, whether to ping the author of the original message (default: false)"),
+		),
+		mcp.WithBoolean("suppress_mentions",
+			mcp.Description("Disable all pings from this message, including @everyone, roles, users and the reply author (default: false)"),
+		),
+		mcp.WithBoolean("auto_split",
+			mcp.Description(fmt.Sprintf("Split content longer than %d characters on line boundaries and send it as several messages (default: false)", maxMessageLength)),
+		),
+		mcp.WithBoolean("silent",
+			mcp.Description("Send without push or desktop notifications; mentions still highlight but do not ping (default: false)"),
+		),
+		mcp.WithBoolean("suppress_embeds",
+			mcp.Description("Disable link previews for URLs in the content (default: false)"),
+		),
 	)
 
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -38,32 +77,220 @@ func toolSendMessage(dg discord.DiscordClient, r resolve.ChannelResolver, filter
 		channel := req.GetString("channel", "")
 		content := req.GetString("content", "")
 		replyTo := req.GetString("reply_to", "")
+		mentionReply := req.GetBool("mention_reply", false)
+		suppressMentions := req.GetBool("suppress_mentions", false)
+		autoSplit := req.GetBool("auto_split", false)
+		silent := req.GetBool("silent", false)
+		suppressEmbeds := req.GetBool("suppress_embeds", false)
 		params := map[string]any{
-			"channel":  channel,
-			"content":  content,
-			"reply_to": replyTo,
+			"channel":           channel,
+			"content":           content,
+			"reply_to":          replyTo,
+			"mention_reply":     mentionReply,
+			"suppress_mentions": suppressMentions,
+			"auto_split":        autoSplit,
+			"silent":            silent,
+			"suppress_embeds":   suppressEmbeds,
 		}
 
-		channelID, _, errResult := tools.ResolveAndFilterChannel(r, filter, audit, logger, toolName, channel, params, start)
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, channel, params, start)
 		if errResult != nil {
 			return errResult, nil
 		}
 
-		data := &discordgo.MessageSend{
-			Content: content,
+		if !autoSplit {
+			if err := checkContentLength(content); err != nil {
+				return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("%w (set auto_split to send it as several messages)", err), start), nil
+			}
 		}
-		if replyTo != "" {
-			data.Reference = &discordgo.MessageReference{MessageID: replyTo}
+
+		var flags discordgo.MessageFlags
+		if silent {
+			flags |= discordgo.MessageFlagsSuppressNotifications
+		}
+		if suppressEmbeds {
+			flags |= discordgo.MessageFlagsSuppressEmbeds
 		}
 
-		msg, err := dg.ChannelMessageSendComplex(channelID, data)
-		if err != nil {
-			return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+		chunks := []string{content}
+		if autoSplit {
+			chunks = splitContent(content, maxMessageLength)
 		}
 
-		tools.LogAudit(audit, toolName, params, "ok: "+msg.ID, start)
-		return mcp.NewToolResultText(fmt.Sprintf("Message sent (ID: %s)", msg.ID)), nil
+		var sent []*discordgo.Message
+		var waited time.Duration
+		for i, chunk := range chunks {
+			isReply := replyTo != "" && i == 0
+			data := &discordgo.MessageSend{
+				Content:         chunk,
+				AllowedMentions: mentions.apply(chunk, allowedMentions(isReply, mentionReply, suppressMentions)),
+				Flags:           flags,
+			}
+			if isReply {
+				data.Reference = &discordgo.MessageReference{MessageID: replyTo}
+			}
+
+			msg, wait, err := sendWithRetry(ctx, dg, channelID, data, logger)
+			if wait > 0 {
+				waited += wait
+				params["rate_limit_wait_ms"] = waited.Milliseconds()
+			}
+			if err != nil {
+				if len(sent) > 0 {
+					err = fmt.Errorf("sent %d of %d parts (IDs: %s): %w", len(sent), len(chunks), strings.Join(messageIDs(sent), ", "), err)
+				}
+				return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+			}
+			sent = append(sent, msg)
+		}
+
+		if len(sent) == 1 {
+			msg := sent[0]
+			tools.LogAudit(ctx, audit, toolName, params, "ok: "+msg.ID, start)
+			return tools.JSONResultWithText(fmt.Sprintf("Message sent (ID: %s)", msg.ID), summarizeMessage(msg)), nil
+		}
+
+		ids := strings.Join(messageIDs(sent), ", ")
+		summaries := make([]MessageSummary, 0, len(sent))
+		for _, msg := range sent {
+			summaries = append(summaries, summarizeMessage(msg))
+		}
+		tools.LogAudit(ctx, audit, toolName, params, "ok: "+ids, start)
+		return tools.JSONResultWithText(fmt.Sprintf("Message sent in %d parts (IDs: %s)", len(sent), ids), summaries), nil
 	}
 
 	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
 }
+
+// allowedMentions builds the AllowedMentions for an outgoing message. With
+// suppress set, every mention type is disabled. Otherwise replies parse all
+// mention types as usual but only ping the replied-to author when
+// mentionReply is set. Non-reply messages without suppression return nil so
+// Discord's default parsing applies.
+func allowedMentions(isReply, mentionReply, suppress bool) *discordgo.MessageAllowedMentions {
+	if suppress {
+		return &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}}
+	}
+	if !isReply {
+		return nil
+	}
+	return &discordgo.MessageAllowedMentions{
+		Parse: []discordgo.AllowedMentionType{
+			discordgo.AllowedMentionTypeUsers,
+			discordgo.AllowedMentionTypeRoles,
+			discordgo.AllowedMentionTypeEveryone,
+		},
+		RepliedUser: mentionReply,
+	}
+}
+
+// sendWithRetry sends data to channelID. If Discord responds with a rate
+// limit (e.g. slowmode) whose retry_after fits within ctx's deadline and
+// maxSendRetryWait, it waits and retries once, rewinding any seekable file
+// readers first. It returns the time spent waiting.
+func sendWithRetry(ctx context.Context, dg discord.DiscordClient, channelID string, data *discordgo.MessageSend, logger *slog.Logger) (*discordgo.Message, time.Duration, error) {
+	msg, err := dg.ChannelMessageSendComplex(channelID, data, discordgo.WithContext(ctx))
+	wait, ok := retryAfter(err)
+	if !ok || !fitsDeadline(ctx, wait) {
+		return msg, 0, err
+	}
+
+	logger.DebugContext(ctx, "send rate limited, retrying", "channelID", channelID, "retry_after", wait)
+	select {
+	case <-time.After(wait):
+		if err = rewindFiles(data.Files); err != nil {
+			return nil, wait, err
+		}
+		msg, err = dg.ChannelMessageSendComplex(channelID, data, discordgo.WithContext(ctx))
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return msg, wait, err
+}
+
+// rewindFiles seeks each file's reader back to its start so a failed send
+// can upload it again.
+func rewindFiles(files []*discordgo.File) error {
+	for _, f := range files {
+		if s, ok := f.Reader.(io.Seeker); ok {
+			if _, err := s.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("rewinding %s: %w", f.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// splitContent splits content into chunks of at most max characters,
+// breaking on line boundaries where possible. A single line longer than max
+// is split mid-line. Content that already fits is returned unchanged.
+func splitContent(content string, max int) []string {
+	if utf8.RuneCountInString(content) <= max {
+		return []string{content}
+	}
+
+	var chunks []string
+	var cur strings.Builder
+	curLen := 0
+	flush := func() {
+		if chunk := strings.TrimRight(cur.String(), "\n"); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		cur.Reset()
+		curLen = 0
+	}
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineLen := utf8.RuneCountInString(line)
+		if curLen+lineLen > max {
+			flush()
+		}
+		for lineLen > max {
+			runes := []rune(line)
+			chunks = append(chunks, string(runes[:max]))
+			line = string(runes[max:])
+			lineLen -= max
+		}
+		cur.WriteString(line)
+		curLen += lineLen
+	}
+	flush()
+	return chunks
+}
+
+// messageIDs returns the IDs of msgs in order.
+func messageIDs(msgs []*discordgo.Message) []string {
+	ids := make([]string, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+// retryAfter reports how long to wait before retrying when err is an HTTP 429
+// from Discord, as returned for channels in slowmode.
+func retryAfter(err error) (time.Duration, bool) {
+	var rateErr *discordgo.RateLimitError
+	if errors.As(err, &rateErr) && rateErr.RateLimit != nil && rateErr.TooManyRequests != nil {
+		return rateErr.RetryAfter, true
+	}
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil || restErr.Response.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	var body discordgo.TooManyRequests
+	if err := json.Unmarshal(restErr.ResponseBody, &body); err != nil {
+		return 0, false
+	}
+	return body.RetryAfter, true
+}
+
+// fitsDeadline reports whether waiting d stays within maxSendRetryWait and
+// still leaves ctx within its deadline, if it has one.
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	if d > maxSendRetryWait {
+		return false
+	}
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > d
+}
@@ -0,0 +1,130 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxWebhookUsernameLength is the longest username Discord accepts for a
+// webhook message.
+const maxWebhookUsernameLength = 80
+
+func toolSendWebhook(hooks *webhookCache, r resolve.ChannelResolver, filter *safety.Filter, mentions MentionPolicy, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_send_webhook"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Send a message to a Discord channel through a bot-owned webhook, so it appears under a custom name and avatar. The webhook is created on first use and requires the Manage Webhooks permission."),
+		mcp.WithString("channel",
+			mcp.Required(),
+			mcp.Description("Channel name or ID"),
+		),
+		mcp.WithString("content",
+			mcp.Required(),
+			mcp.Description("Message content to send"),
+		),
+		mcp.WithString("username",
+			mcp.Description(fmt.Sprintf("Display name for this message, up to %d characters (default: the webhook's name)", maxWebhookUsernameLength)),
+		),
+		mcp.WithString("avatar_url",
+			mcp.Description("http(s) URL of the avatar image for this message (default: the webhook's avatar)"),
+		),
+		mcp.WithBoolean("suppress_mentions",
+			mcp.Description("Disable all pings from this message, including @everyone, roles and users (default: false)"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		channel := req.GetString("channel", "")
+		content := req.GetString("content", "")
+		username := req.GetString("username", "")
+		avatarURL := req.GetString("avatar_url", "")
+		suppressMentions := req.GetBool("suppress_mentions", false)
+		params := map[string]any{
+			"channel":           channel,
+			"content":           content,
+			"username":          username,
+			"avatar_url":        avatarURL,
+			"suppress_mentions": suppressMentions,
+		}
+
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, channel, params, start)
+		if errResult != nil {
+			return errResult, nil
+		}
+		if err := validateWebhookParams(content, username, avatarURL); err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		data := &discordgo.WebhookParams{
+			Content:         content,
+			Username:        username,
+			AvatarURL:       avatarURL,
+			AllowedMentions: mentions.apply(content, allowedMentions(false, false, suppressMentions)),
+		}
+
+		msg, err := executeWebhook(ctx, hooks, channelID, data)
+		if err != nil && restStatus(err) == http.StatusNotFound {
+			// The cached webhook was deleted in Discord; create a new one.
+			logger.DebugContext(ctx, "webhook gone, recreating", "channelID", channelID)
+			hooks.forget(channelID)
+			msg, err = executeWebhook(ctx, hooks, channelID, data)
+		}
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		logger.DebugContext(ctx, "webhook message sent", "channelID", channelID, "messageID", msg.ID)
+		tools.LogAudit(ctx, audit, toolName, params, "ok: "+msg.ID, start)
+		return tools.JSONResultWithText(fmt.Sprintf("Message sent via webhook (ID: %s)", msg.ID), summarizeMessage(msg)), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// executeWebhook sends data through the bot's webhook for channelID and waits
+// for the created message.
+func executeWebhook(ctx context.Context, hooks *webhookCache, channelID string, data *discordgo.WebhookParams) (*discordgo.Message, error) {
+	wh, err := hooks.get(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return hooks.dg.WebhookExecute(wh.ID, wh.Token, true, data, discordgo.WithContext(ctx))
+}
+
+// validateWebhookParams checks the arguments of discord_send_webhook against
+// Discord's limits so that bad input fails with a clear message.
+func validateWebhookParams(content, username, avatarURL string) error {
+	if content == "" {
+		return fmt.Errorf("content must not be empty")
+	}
+	if err := checkContentLength(content); err != nil {
+		return err
+	}
+	if n := utf8.RuneCountInString(username); n > maxWebhookUsernameLength {
+		return fmt.Errorf("username is %d characters; the maximum is %d", n, maxWebhookUsernameLength)
+	}
+	if lower := strings.ToLower(username); strings.Contains(lower, "discord") || strings.Contains(lower, "clyde") {
+		return fmt.Errorf("username must not contain \"discord\" or \"clyde\"")
+	}
+	if avatarURL != "" {
+		u, err := url.Parse(avatarURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("avatar_url must be an http or https URL")
+		}
+	}
+	return nil
+}
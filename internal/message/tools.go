@@ -2,9 +2,11 @@
 package message
 
 import (
+	"context"
 	"log/slog"
 	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/discord"
 	"github.com/jamesprial/claudebot-mcp/internal/queue"
 	"github.com/jamesprial/claudebot-mcp/internal/resolve"
@@ -14,7 +16,7 @@ import (
 
 // destructiveTools lists the tool names in this package that require
 // confirmation before executing.
-var destructiveTools = []string{"discord_delete_message"}
+var destructiveTools = []string{"discord_delete_message", "discord_move_message", "discord_clear_queue"}
 
 // DestructiveToolNames returns a copy of the destructive tool names list.
 func DestructiveToolNames() []string {
@@ -23,32 +25,203 @@ func DestructiveToolNames() []string {
 	return out
 }
 
-// MessageSummary is the response shape returned by discord_get_messages.
+// MessageSummary is the response shape returned by discord_get_messages and
+// discord_get_message, and by discord_send_message and discord_edit_message
+// for the resulting message. Timestamp is always in UTC. EditedTimestamp is
+// set, also in UTC, only for messages that have been edited.
 type MessageSummary struct {
-	ID             string    `json:"id"`
-	AuthorID       string    `json:"author_id"`
-	AuthorUsername string    `json:"author_username"`
-	Content        string    `json:"content"`
-	Timestamp      time.Time `json:"timestamp"`
-	ReplyTo        string    `json:"reply_to,omitempty"`
+	ID              string     `json:"id"`
+	AuthorID        string     `json:"author_id"`
+	AuthorUsername  string     `json:"author_username"`
+	Content         string     `json:"content"`
+	Timestamp       time.Time  `json:"timestamp"`
+	EditedTimestamp *time.Time `json:"edited_timestamp,omitempty"`
+	ReplyTo         string     `json:"reply_to,omitempty"`
+
+	Attachments []AttachmentSummary `json:"attachments,omitempty"`
+	Reactions   []ReactionSummary   `json:"reactions,omitempty"`
+}
+
+// AttachmentSummary describes a file attached to a message.
+type AttachmentSummary struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int    `json:"size"`
+}
+
+// ReactionSummary describes one emoji reaction on a message. Emoji is the
+// unicode character or, for a custom emoji, its "name:id" form. Me reports
+// whether the bot itself reacted.
+type ReactionSummary struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+	Me    bool   `json:"me"`
+}
+
+// summarizeMessage converts a Discord message into a MessageSummary.
+func summarizeMessage(m *discordgo.Message) MessageSummary {
+	s := MessageSummary{
+		ID:        m.ID,
+		Content:   m.Content,
+		Timestamp: m.Timestamp.UTC(),
+	}
+	if m.Author != nil {
+		s.AuthorID = m.Author.ID
+		s.AuthorUsername = m.Author.Username
+	}
+	if m.EditedTimestamp != nil && !m.EditedTimestamp.IsZero() {
+		edited := m.EditedTimestamp.UTC()
+		s.EditedTimestamp = &edited
+	}
+	if m.MessageReference != nil {
+		s.ReplyTo = m.MessageReference.MessageID
+	}
+	for _, a := range m.Attachments {
+		s.Attachments = append(s.Attachments, AttachmentSummary{
+			ID:          a.ID,
+			Filename:    a.Filename,
+			URL:         a.URL,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+		})
+	}
+	for _, r := range m.Reactions {
+		if r == nil || r.Emoji == nil {
+			continue
+		}
+		s.Reactions = append(s.Reactions, ReactionSummary{
+			Emoji: r.Emoji.APIName(),
+			Count: r.Count,
+			Me:    r.Me,
+		})
+	}
+	return s
+}
+
+// Default long-poll durations used when PollConfig fields are unset.
+const (
+	defaultPollTimeoutSec    = 30
+	defaultMaxPollTimeoutSec = 300
+)
+
+// PollConfig tunes discord_poll_messages. DefaultTimeoutSec applies when the
+// client omits timeout_seconds and MaxTimeoutSec caps any requested value.
+// Zero or negative fields fall back to 30 and 300 seconds respectively.
+type PollConfig struct {
+	DefaultTimeoutSec int
+	MaxTimeoutSec     int
+}
+
+// withDefaults returns a copy of pc with unset fields filled in and the
+// default timeout clamped to the maximum.
+func (pc PollConfig) withDefaults() PollConfig {
+	if pc.MaxTimeoutSec <= 0 {
+		pc.MaxTimeoutSec = defaultMaxPollTimeoutSec
+	}
+	if pc.DefaultTimeoutSec <= 0 {
+		pc.DefaultTimeoutSec = defaultPollTimeoutSec
+	}
+	if pc.DefaultTimeoutSec > pc.MaxTimeoutSec {
+		pc.DefaultTimeoutSec = pc.MaxTimeoutSec
+	}
+	return pc
+}
+
+// Option configures optional behaviour of the tools returned by MessageTools.
+type Option func(*options)
+
+// options holds the settings applied by Option values.
+type options struct {
+	mentions    MentionPolicy
+	attachments AttachmentConfig
+	keepalive   pollKeepalive
+	results     tools.ResultLimit
+}
+
+// pollKeepalive is the notifier and interval set by WithPollKeepalive.
+type pollKeepalive struct {
+	notifier tools.Notifier
+	interval time.Duration
+}
+
+// WithMentionPolicy applies p to every message sent or edited by the tools,
+// including scheduled sends.
+func WithMentionPolicy(p MentionPolicy) Option {
+	return func(o *options) {
+		o.mentions = p
+	}
+}
+
+// WithAttachmentConfig tunes discord_download_attachment and the attachment
+// downloads made by discord_move_message.
+func WithAttachmentConfig(c AttachmentConfig) Option {
+	return func(o *options) {
+		o.attachments = c
+	}
+}
+
+// WithPollKeepalive makes discord_poll_messages send a keepalive
+// notification through n every interval while it waits, so proxies that cut
+// idle HTTP connections do not end a long poll; see tools.StartKeepalive. A
+// nil notifier or non-positive interval disables keepalives.
+func WithPollKeepalive(n tools.Notifier, interval time.Duration) Option {
+	return func(o *options) {
+		o.keepalive = pollKeepalive{notifier: n, interval: interval}
+	}
+}
+
+// WithMaxResultItems caps how many messages the list tools return; see
+// tools.ResultLimit. discord_poll_messages clamps its limit to the cap
+// rather than truncating, so no message is removed from the queue without
+// being returned.
+func WithMaxResultItems(n int) Option {
+	return func(o *options) {
+		o.results = tools.ResultLimit(n)
+	}
 }
 
 // MessageTools returns all tool registrations for Discord message operations.
+// Cancelling shutdown makes in-flight long polls return promptly with a
+// "server shutting down" error and drops any scheduled messages not yet sent.
 func MessageTools(
+	shutdown context.Context,
 	dg discord.DiscordClient,
 	q *queue.Queue,
+	poll PollConfig,
 	r resolve.ChannelResolver,
 	filter *safety.Filter,
 	confirm *safety.ConfirmationTracker,
 	audit *safety.AuditLogger,
 	logger *slog.Logger,
+	opts ...Option,
 ) []tools.Registration {
 	logger = tools.DefaultLogger(logger)
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	sched := newScheduler(shutdown, dg, o.mentions, audit, logger)
+	hooks := newWebhookCache(dg)
 	return []tools.Registration{
-		toolPollMessages(q, r, filter, audit, logger),
-		toolSendMessage(dg, r, filter, audit, logger),
-		toolGetMessages(dg, r, filter, audit, logger),
-		toolEditMessage(dg, r, filter, audit, logger),
+		toolPollMessages(shutdown, q, poll.withDefaults(), o.keepalive, o.results, r, filter, audit, logger),
+		toolDrainMessages(q, r, audit, logger),
+		toolQueueInfo(q, audit, logger),
+		toolAckMessages(q, audit, logger),
+		toolClearQueue(q, confirm, audit, logger),
+		toolSendMessage(dg, r, filter, o.mentions, audit, logger),
+		toolSendWebhook(hooks, r, filter, o.mentions, audit, logger),
+		toolGetMessages(dg, r, filter, o.results, audit, logger),
+		toolGetMessage(dg, r, filter, audit, logger),
+		toolGetPinnedMessages(dg, r, filter, o.results, audit, logger),
+		toolDownloadAttachment(o.attachments, r, filter, audit, logger),
+		toolEditMessage(dg, r, filter, o.mentions, audit, logger),
 		toolDeleteMessage(dg, r, filter, confirm, audit, logger),
+		toolMoveMessage(dg, o.attachments, r, filter, confirm, audit, logger),
+		toolMoveToThread(dg, r, filter, o.mentions, audit, logger),
+		toolScheduleMessage(sched, r, filter, audit, logger),
+		toolListScheduled(sched, o.results, audit),
+		toolCancelScheduled(sched, audit, logger),
 	}
 }
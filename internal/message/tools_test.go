@@ -1,17 +1,55 @@
 package message_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/auth"
+	"github.com/jamesprial/claudebot-mcp/internal/config"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
 	"github.com/jamesprial/claudebot-mcp/internal/message"
 	"github.com/jamesprial/claudebot-mcp/internal/queue"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
 	"github.com/jamesprial/claudebot-mcp/internal/testutil"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
+// messageSummaryFromResult decodes the JSON MessageSummary carried as the
+// second content item of a send or edit result.
+func messageSummaryFromResult(t *testing.T, result *mcp.CallToolResult) message.MessageSummary {
+	t.Helper()
+	if len(result.Content) < 2 {
+		t.Fatalf("result has %d content items, want text plus JSON", len(result.Content))
+	}
+	tc, ok := result.Content[1].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("result content[1] is %T, want mcp.TextContent", result.Content[1])
+	}
+	var summary message.MessageSummary
+	if err := json.Unmarshal([]byte(tc.Text), &summary); err != nil {
+		t.Fatalf("content[1] is not a MessageSummary: %v", err)
+	}
+	return summary
+}
+
 // ---------------------------------------------------------------------------
 // Tool Registration
 // ---------------------------------------------------------------------------
@@ -25,14 +63,27 @@ func Test_MessageTools_Registration(t *testing.T) {
 	filter := safety.NewFilter(nil, nil)
 	confirm := safety.NewConfirmationTracker([]string{"discord_delete_message"})
 
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
 
 	testutil.AssertRegistrations(t, regs, []string{
 		"discord_poll_messages",
+		"discord_drain_messages",
+		"discord_queue_info",
+		"discord_ack_messages",
+		"discord_clear_queue",
 		"discord_send_message",
+		"discord_send_webhook",
 		"discord_get_messages",
+		"discord_get_message",
+		"discord_get_pinned_messages",
+		"discord_download_attachment",
 		"discord_edit_message",
 		"discord_delete_message",
+		"discord_move_message",
+		"discord_move_to_thread",
+		"discord_schedule_message",
+		"discord_list_scheduled",
+		"discord_cancel_scheduled",
 	})
 }
 
@@ -49,7 +100,7 @@ func Test_PollMessages_EmptyQueue_ShortTimeout(t *testing.T) {
 	filter := safety.NewFilter(nil, nil)
 	confirm := safety.NewConfirmationTracker(nil)
 
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
 	handler := testutil.FindHandler(t, regs, "discord_poll_messages")
 
 	req := testutil.NewCallToolRequest("discord_poll_messages", map[string]any{
@@ -90,7 +141,7 @@ func Test_PollMessages_QueueHasMessages(t *testing.T) {
 		Timestamp:      time.Now(),
 	})
 
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
 	handler := testutil.FindHandler(t, regs, "discord_poll_messages")
 
 	req := testutil.NewCallToolRequest("discord_poll_messages", map[string]any{
@@ -111,6 +162,180 @@ func Test_PollMessages_QueueHasMessages(t *testing.T) {
 	}
 }
 
+func Test_PollMessages_LimitClampedToResultCap(t *testing.T) {
+	t.Parallel()
+
+	q := queue.New()
+	for i := range 5 {
+		q.Enqueue(queue.QueuedMessage{ID: fmt.Sprintf("msg-%d", i), ChannelID: "ch-001", Content: "hi", Timestamp: time.Now()})
+	}
+
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, q, message.PollConfig{},
+		testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil,
+		message.WithMaxResultItems(2),
+	)
+	handler := testutil.FindHandler(t, regs, "discord_poll_messages")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_poll_messages", map[string]any{
+		"limit":   float64(10),
+		"no_wait": true,
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	var got []queue.QueuedMessage
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(got) != 2 || len(result.Content) != 1 {
+		t.Errorf("got %d messages in %d content items, want 2 in 1 (no truncation note)", len(got), len(result.Content))
+	}
+	// Messages past the cap stay queued for the next poll.
+	if n := q.Len(); n != 3 {
+		t.Errorf("queue length = %d, want 3", n)
+	}
+}
+
+func Test_PollMessages_Format_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		format    string
+		want      string
+		wantError bool
+	}{
+		{name: "default is json", format: "", want: `"author_username": "alice"`},
+		{name: "json", format: "json", want: `"author_username": "alice"`},
+		{name: "text", format: "text", want: "[#general] @alice: hello world\n[#random] @bob: second"},
+		{name: "invalid", format: "yaml", wantError: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := queue.New()
+			q.Enqueue(queue.QueuedMessage{ID: "msg-1", ChannelID: "ch-001", ChannelName: "general", AuthorUsername: "alice", Content: "hello world"})
+			q.Enqueue(queue.QueuedMessage{ID: "msg-2", ChannelID: "ch-002", ChannelName: "random", AuthorUsername: "bob", Content: "second"})
+
+			regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, q, message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_poll_messages")
+
+			args := map[string]any{"no_wait": true}
+			if tc.format != "" {
+				args["format"] = tc.format
+			}
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_poll_messages", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+
+			if tc.wantError {
+				if !result.IsError {
+					t.Errorf("expected error result, got: %s", testutil.ExtractText(t, result))
+				}
+				return
+			}
+			text := testutil.ExtractText(t, result)
+			if tc.format == "text" {
+				if text != tc.want {
+					t.Errorf("text = %q, want %q", text, tc.want)
+				}
+				return
+			}
+			if !strings.Contains(text, tc.want) {
+				t.Errorf("expected JSON containing %s, got: %s", tc.want, text)
+			}
+		})
+	}
+}
+
+func Test_PollMessages_NoWait_EmptyQueueReturnsInstantly(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_poll_messages")
+
+	req := testutil.NewCallToolRequest("discord_poll_messages", map[string]any{
+		"no_wait": true,
+	})
+
+	start := time.Now()
+	result, err := handler(context.Background(), req)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("handler returned unexpected error: %v", err)
+	}
+
+	if text := testutil.ExtractText(t, result); text != "No new messages" {
+		t.Errorf("result = %q, want %q", text, "No new messages")
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("no_wait poll took %v, want an immediate return", elapsed)
+	}
+}
+
+func Test_PollMessages_EmptyPollBackoffHint(t *testing.T) {
+	t.Parallel()
+
+	q := queue.New()
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, q, message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_poll_messages")
+
+	poll := func(client string) (string, int) {
+		t.Helper()
+		req := testutil.NewCallToolRequest("discord_poll_messages", map[string]any{"no_wait": true})
+		result, err := handler(auth.ContextWithClient(context.Background(), client), req)
+		if err != nil {
+			t.Fatalf("handler returned unexpected error: %v", err)
+		}
+		text := testutil.ExtractText(t, result)
+		if len(result.Content) < 2 {
+			return text, -1
+		}
+		var hint message.EmptyPollResult
+		if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &hint); err != nil {
+			t.Fatalf("content[1] is not an EmptyPollResult: %v", err)
+		}
+		return text, hint.RetryAfterSeconds
+	}
+
+	var hints []int
+	for i := 0; i < 8; i++ {
+		text, hint := poll("client-a")
+		if text != "No new messages" {
+			t.Fatalf("poll %d text = %q, want %q", i, text, "No new messages")
+		}
+		hints = append(hints, hint)
+	}
+	if want := []int{1, 2, 4, 8, 16, 32, 60, 60}; !slices.Equal(hints, want) {
+		t.Errorf("retry_after_seconds = %v, want %v", hints, want)
+	}
+
+	// Another client has its own streak.
+	if _, hint := poll("client-b"); hint != 1 {
+		t.Errorf("other client's first hint = %d, want 1", hint)
+	}
+
+	// A poll that returns messages ends the streak.
+	if err := q.Enqueue(queue.QueuedMessage{ID: "m1", ChannelID: "ch-001"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, hint := poll("client-a"); hint != -1 {
+		t.Errorf("poll with messages returned a hint of %d", hint)
+	}
+	if _, hint := poll("client-a"); hint != 1 {
+		t.Errorf("hint after a non-empty poll = %d, want 1", hint)
+	}
+}
+
 func Test_PollMessages_TimeoutClamping(t *testing.T) {
 	t.Parallel()
 
@@ -120,7 +345,7 @@ func Test_PollMessages_TimeoutClamping(t *testing.T) {
 	filter := safety.NewFilter(nil, nil)
 	confirm := safety.NewConfirmationTracker(nil)
 
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
 	handler := testutil.FindHandler(t, regs, "discord_poll_messages")
 
 	tests := []struct {
@@ -156,320 +381,3370 @@ func Test_PollMessages_TimeoutClamping(t *testing.T) {
 	}
 }
 
-// ---------------------------------------------------------------------------
-// discord_send_message handler
-// ---------------------------------------------------------------------------
-
-func Test_SendMessage_Valid(t *testing.T) {
+func Test_PollMessages_ConfiguredTimeouts(t *testing.T) {
 	t.Parallel()
 
-	client := &testutil.MockDiscordClient{}
-	q := queue.New()
-	r := testutil.NewMockChannelResolver()
-	filter := safety.NewFilter(nil, nil)
-	confirm := safety.NewConfirmationTracker(nil)
+	tests := []struct {
+		name string
+		poll message.PollConfig
+		args map[string]any
+		want float64
+	}{
+		{
+			name: "configured default applied when parameter absent",
+			poll: message.PollConfig{DefaultTimeoutSec: 1},
+			args: map[string]any{},
+			want: 1,
+		},
+		{
+			name: "built-in default applied when unconfigured",
+			poll: message.PollConfig{},
+			args: map[string]any{},
+			want: 30,
+		},
+		{
+			name: "requested timeout capped at configured max",
+			poll: message.PollConfig{MaxTimeoutSec: 1},
+			args: map[string]any{"timeout_seconds": float64(500)},
+			want: 1,
+		},
+		{
+			name: "configured default clamped to configured max",
+			poll: message.PollConfig{DefaultTimeoutSec: 60, MaxTimeoutSec: 1},
+			args: map[string]any{},
+			want: 1,
+		},
+	}
 
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
-	handler := testutil.FindHandler(t, regs, "discord_send_message")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 
-	req := testutil.NewCallToolRequest("discord_send_message", map[string]any{
-		"channel": "123456789012345678",
-		"content": "test message",
-	})
+			var buf bytes.Buffer
+			audit := safety.NewAuditLogger(&buf)
+			r := testutil.NewMockChannelResolver()
+			filter := safety.NewFilter(nil, nil)
+			confirm := safety.NewConfirmationTracker(nil)
 
-	result, err := handler(context.Background(), req)
-	if err != nil {
-		t.Fatalf("handler error: %v", err)
-	}
+			regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, queue.New(), tt.poll, r, filter, confirm, audit, nil)
+			handler := testutil.FindHandler(t, regs, "discord_poll_messages")
 
-	text := testutil.ExtractText(t, result)
-	lower := strings.ToLower(text)
-	if !strings.Contains(lower, "sent") && !strings.Contains(text, "mock-msg-001") {
-		t.Errorf("expected success response with message ID, got: %s", text)
+			// Bound the wait so the built-in default case does not block for 30s.
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			if _, err := handler(ctx, testutil.NewCallToolRequest("discord_poll_messages", tt.args)); err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+
+			var entry struct {
+				Params map[string]any `json:"params"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("audit entry is not valid JSON: %v\n%s", err, buf.String())
+			}
+			if got := entry.Params["timeout_seconds"]; got != tt.want {
+				t.Errorf("timeout_seconds = %v, want %v", got, tt.want)
+			}
+		})
 	}
 }
 
-func Test_SendMessage_DeniedChannel(t *testing.T) {
+func Test_PollMessages_ShutdownCancelsPoll(t *testing.T) {
 	t.Parallel()
 
-	client := &testutil.MockDiscordClient{}
-	q := queue.New()
+	shutdown, cancelShutdown := context.WithCancel(context.Background())
 	r := testutil.NewMockChannelResolver()
-	filter := safety.NewFilter(nil, []string{"general"})
+	filter := safety.NewFilter(nil, nil)
 	confirm := safety.NewConfirmationTracker(nil)
 
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
-	handler := testutil.FindHandler(t, regs, "discord_send_message")
+	regs := message.MessageTools(shutdown, &testutil.MockDiscordClient{}, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_poll_messages")
 
-	req := testutil.NewCallToolRequest("discord_send_message", map[string]any{
-		"channel": "general",
-		"content": "should be blocked",
-	})
+	time.AfterFunc(50*time.Millisecond, cancelShutdown)
 
-	result, err := handler(context.Background(), req)
+	start := time.Now()
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_poll_messages", map[string]any{
+		"timeout_seconds": float64(30),
+	}))
 	if err != nil {
 		t.Fatalf("handler error: %v", err)
 	}
-
-	text := testutil.ExtractText(t, result)
-	lower := strings.ToLower(text)
-	if !strings.Contains(lower, "not allowed") && !strings.Contains(lower, "denied") {
-		t.Errorf("expected error about channel not allowed, got: %s", text)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("poll returned after %v, want prompt return on shutdown", elapsed)
 	}
+	if !result.IsError {
+		t.Error("expected error result after shutdown")
+	}
+	testutil.AssertTextContains(t, result, "server shutting down")
 }
 
-func Test_SendMessage_WithReplyTo(t *testing.T) {
+// recordingNotifier is a tools.Notifier that sends each notification's
+// method on a channel.
+type recordingNotifier struct {
+	methods chan string
+}
+
+func (n *recordingNotifier) SendNotificationToClient(_ context.Context, method string, _ map[string]any) error {
+	n.methods <- method
+	return nil
+}
+
+func Test_PollMessages_KeepaliveWhileBlocked(t *testing.T) {
 	t.Parallel()
 
-	client := &testutil.MockDiscordClient{}
 	q := queue.New()
-	r := testutil.NewMockChannelResolver()
-	filter := safety.NewFilter(nil, nil)
-	confirm := safety.NewConfirmationTracker(nil)
-
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
-	handler := testutil.FindHandler(t, regs, "discord_send_message")
+	notifier := &recordingNotifier{methods: make(chan string, 100)}
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, q, message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil,
+		message.WithPollKeepalive(notifier, 10*time.Millisecond),
+	)
+	handler := testutil.FindHandler(t, regs, "discord_poll_messages")
 
-	req := testutil.NewCallToolRequest("discord_send_message", map[string]any{
-		"channel":  "123456789012345678",
-		"content":  "replying to something",
-		"reply_to": "original-msg-id",
-	})
+	type outcome struct {
+		result *mcp.CallToolResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_poll_messages", map[string]any{
+			"timeout_seconds": float64(30),
+		}))
+		done <- outcome{result, err}
+	}()
+
+	// The poll is blocked on an empty queue; keepalives should keep coming.
+	for i := 0; i < 3; i++ {
+		select {
+		case method := <-notifier.methods:
+			if method != tools.KeepaliveMethod {
+				t.Errorf("notification %d method = %q, want %q", i, method, tools.KeepaliveMethod)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d keepalives before timing out, want 3", i)
+		}
+	}
 
-	result, err := handler(context.Background(), req)
-	if err != nil {
-		t.Fatalf("handler error: %v", err)
+	_ = q.Enqueue(queue.QueuedMessage{ID: "m1", ChannelID: "ch-001", ChannelName: "general"})
+	got := <-done
+	if got.err != nil {
+		t.Fatalf("handler error: %v", got.err)
 	}
+	testutil.AssertTextContains(t, got.result, "m1")
 
-	text := testutil.ExtractText(t, result)
-	lower := strings.ToLower(text)
-	if strings.HasPrefix(lower, "error") {
-		t.Errorf("expected success with reply_to, got: %s", text)
+	// Keepalives stop once the poll returns.
+	for len(notifier.methods) > 0 {
+		<-notifier.methods
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := len(notifier.methods); n != 0 {
+		t.Errorf("%d keepalives sent after the poll returned", n)
 	}
 }
 
 // ---------------------------------------------------------------------------
-// discord_get_messages handler
+// discord_drain_messages handler
 // ---------------------------------------------------------------------------
 
-func Test_GetMessages_Valid(t *testing.T) {
+func Test_DrainMessages_ReturnsAllWithoutCap(t *testing.T) {
 	t.Parallel()
 
-	client := &testutil.MockDiscordClient{}
-	q := queue.New()
-	r := testutil.NewMockChannelResolver()
-	filter := safety.NewFilter(nil, nil)
-	confirm := safety.NewConfirmationTracker(nil)
-
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
-	handler := testutil.FindHandler(t, regs, "discord_get_messages")
-
-	req := testutil.NewCallToolRequest("discord_get_messages", map[string]any{
-		"channel": "123456789012345678",
-	})
+	q := queue.New(queue.WithMaxSize(200))
+	for i := 0; i < 120; i++ {
+		_ = q.Enqueue(queue.QueuedMessage{ID: fmt.Sprintf("m-%d", i), ChannelID: "ch-001", ChannelName: "general"})
+	}
+	_ = q.Enqueue(queue.QueuedMessage{ID: "other", ChannelID: "ch-002", ChannelName: "random"})
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, q, message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_drain_messages")
 
-	result, err := handler(context.Background(), req)
+	start := time.Now()
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_drain_messages", map[string]any{
+		"channel": "general",
+	}))
 	if err != nil {
 		t.Fatalf("handler error: %v", err)
 	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("drain took %v, want it to return immediately", elapsed)
+	}
 
-	text := testutil.ExtractText(t, result)
-	if !strings.Contains(text, "Hello from mock") {
-		t.Errorf("expected mock message content, got: %s", text)
+	var got message.DrainResult
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+		t.Fatalf("result is not DrainResult JSON: %v", err)
+	}
+	if got.Count != 120 || len(got.Messages) != 120 {
+		t.Errorf("drained count = %d with %d messages, want 120 (past the poll default of 50)", got.Count, len(got.Messages))
+	}
+	if q.Len() != 1 {
+		t.Errorf("queue length after drain = %d, want 1 (the other channel's message)", q.Len())
 	}
 }
 
-func Test_GetMessages_DeniedChannel(t *testing.T) {
+func Test_DrainMessages_Empty(t *testing.T) {
 	t.Parallel()
 
-	client := &testutil.MockDiscordClient{}
-	q := queue.New()
-	r := testutil.NewMockChannelResolver()
-	filter := safety.NewFilter(nil, []string{"general"})
-	confirm := safety.NewConfirmationTracker(nil)
-
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
-	handler := testutil.FindHandler(t, regs, "discord_get_messages")
-
-	req := testutil.NewCallToolRequest("discord_get_messages", map[string]any{
-		"channel": "general",
-	})
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_drain_messages")
 
-	result, err := handler(context.Background(), req)
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_drain_messages", nil))
 	if err != nil {
 		t.Fatalf("handler error: %v", err)
 	}
-
-	text := testutil.ExtractText(t, result)
-	lower := strings.ToLower(text)
-	if !strings.Contains(lower, "not allowed") && !strings.Contains(lower, "denied") {
-		t.Errorf("expected denied error, got: %s", text)
-	}
+	testutil.AssertNotError(t, result)
+	testutil.AssertTextContains(t, result, `"count": 0`)
 }
 
 // ---------------------------------------------------------------------------
-// discord_edit_message handler
+// discord_queue_info handler
 // ---------------------------------------------------------------------------
 
-func Test_EditMessage_Valid(t *testing.T) {
+func Test_QueueInfo_ReportsLengthAndCapacity(t *testing.T) {
 	t.Parallel()
 
 	client := &testutil.MockDiscordClient{}
-	q := queue.New()
+	q := queue.New(queue.WithMaxSize(8))
+	for i := 0; i < 2; i++ {
+		_ = q.Enqueue(queue.QueuedMessage{Content: "hi"})
+	}
 	r := testutil.NewMockChannelResolver()
 	filter := safety.NewFilter(nil, nil)
 	confirm := safety.NewConfirmationTracker(nil)
 
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
-	handler := testutil.FindHandler(t, regs, "discord_edit_message")
-
-	req := testutil.NewCallToolRequest("discord_edit_message", map[string]any{
-		"channel":    "123456789012345678",
-		"message_id": "msg-100",
-		"content":    "updated text",
-	})
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_queue_info")
 
-	result, err := handler(context.Background(), req)
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_queue_info", nil))
 	if err != nil {
-		t.Fatalf("handler error: %v", err)
+		t.Fatalf("handler returned unexpected error: %v", err)
 	}
 
-	text := testutil.ExtractText(t, result)
-	lower := strings.ToLower(text)
-	// A successful edit should not start with "error:".
-	if strings.HasPrefix(lower, "error:") {
-		t.Errorf("expected success for edit, got: %s", text)
+	var info message.QueueInfo
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &info); err != nil {
+		t.Fatalf("result is not QueueInfo JSON: %v", err)
+	}
+	want := message.QueueInfo{Length: 2, Capacity: 8, PercentFull: 25}
+	if info != want {
+		t.Errorf("queue info = %+v, want %+v", info, want)
 	}
 }
 
 // ---------------------------------------------------------------------------
-// discord_delete_message handler
+// discord_ack_messages handler
 // ---------------------------------------------------------------------------
 
-func Test_DeleteMessage_NoConfirmationToken(t *testing.T) {
+func Test_AckMessages_AcksAndRequeues(t *testing.T) {
 	t.Parallel()
 
-	client := &testutil.MockDiscordClient{}
-	q := queue.New()
-	r := testutil.NewMockChannelResolver()
-	filter := safety.NewFilter(nil, nil)
-	confirm := safety.NewConfirmationTracker([]string{"discord_delete_message"})
-
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
-	handler := testutil.FindHandler(t, regs, "discord_delete_message")
-
-	req := testutil.NewCallToolRequest("discord_delete_message", map[string]any{
-		"channel":    "123456789012345678",
-		"message_id": "msg-100",
-	})
+	q := queue.New(queue.WithAckTimeout(time.Hour))
+	for _, id := range []string{"m1", "m2", "m3"} {
+		_ = q.Enqueue(queue.QueuedMessage{ID: id})
+	}
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, q, message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	poll := testutil.FindHandler(t, regs, "discord_poll_messages")
+	ack := testutil.FindHandler(t, regs, "discord_ack_messages")
 
-	result, err := handler(context.Background(), req)
+	result, err := poll(context.Background(), testutil.NewCallToolRequest("discord_poll_messages", map[string]any{"no_wait": true}))
 	if err != nil {
-		t.Fatalf("handler error: %v", err)
+		t.Fatalf("poll error: %v", err)
+	}
+	var polled []queue.QueuedMessage
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &polled); err != nil {
+		t.Fatalf("poll result is not valid JSON: %v", err)
+	}
+	if len(polled) != 3 || polled[0].DeliveryToken == "" {
+		t.Fatalf("polled = %+v, want 3 messages with delivery tokens", polled)
 	}
 
-	text := testutil.ExtractText(t, result)
-	lower := strings.ToLower(text)
-	if !strings.Contains(lower, "confirmation") {
-		t.Errorf("expected confirmation prompt, got: %s", text)
+	result, err = ack(context.Background(), testutil.NewCallToolRequest("discord_ack_messages", map[string]any{
+		"delivery_tokens": []any{polled[0].DeliveryToken, polled[1].DeliveryToken, "stale"},
+	}))
+	if err != nil {
+		t.Fatalf("ack error: %v", err)
 	}
-	if !strings.Contains(text, "confirmation_token=") {
-		t.Errorf("expected confirmation_token in response, got: %s", text)
+	testutil.AssertNotError(t, result)
+	var got message.AckResult
+	if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &got); err != nil {
+		t.Fatalf("ack result is not valid JSON: %v", err)
+	}
+	if got.Acked != 2 || len(got.Unknown) != 1 || got.Unknown[0] != "stale" {
+		t.Errorf("ack result = %+v, want 2 acked and [stale] unknown", got)
+	}
+
+	result, err = ack(context.Background(), testutil.NewCallToolRequest("discord_ack_messages", map[string]any{
+		"delivery_tokens": []any{polled[2].DeliveryToken},
+		"requeue":         true,
+	}))
+	if err != nil {
+		t.Fatalf("requeue error: %v", err)
+	}
+	testutil.AssertTextContains(t, result, "Requeued 1 of 1")
+	if got := q.Drain(""); len(got) != 1 || got[0].ID != "m3" || got[0].Deliveries != 2 {
+		t.Errorf("queue after requeue = %+v, want m3 redelivered", got)
 	}
 }
 
-func Test_DeleteMessage_WithValidConfirmationToken(t *testing.T) {
+func Test_AckMessages_RequiresAckMode(t *testing.T) {
+	t.Parallel()
+
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_ack_messages")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_ack_messages", map[string]any{
+		"delivery_tokens": []any{"abc"},
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatalf("expected error result, got: %s", testutil.ExtractText(t, result))
+	}
+	testutil.AssertTextContains(t, result, "ack_timeout_sec")
+}
+
+// ---------------------------------------------------------------------------
+// discord_clear_queue handler
+// ---------------------------------------------------------------------------
+
+func Test_ClearQueue_RequiresConfirmation(t *testing.T) {
 	t.Parallel()
 
-	client := &testutil.MockDiscordClient{}
 	q := queue.New()
+	for _, id := range []string{"m1", "m2", "m3"} {
+		_ = q.Enqueue(queue.QueuedMessage{ID: id, Content: "hi"})
+	}
 	r := testutil.NewMockChannelResolver()
 	filter := safety.NewFilter(nil, nil)
-	confirm := safety.NewConfirmationTracker([]string{"discord_delete_message"})
-
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
-	handler := testutil.FindHandler(t, regs, "discord_delete_message")
+	confirm := safety.NewConfirmationTracker(message.DestructiveToolNames())
 
-	// First call: get the confirmation token.
-	req1 := testutil.NewCallToolRequest("discord_delete_message", map[string]any{
-		"channel":    "123456789012345678",
-		"message_id": "msg-100",
-	})
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_clear_queue")
 
-	result1, err := handler(context.Background(), req1)
+	// First call: no token, should prompt and leave the queue alone.
+	result1, err := handler(context.Background(), testutil.NewCallToolRequest("discord_clear_queue", nil))
 	if err != nil {
 		t.Fatalf("first call error: %v", err)
 	}
-
 	text1 := testutil.ExtractText(t, result1)
-	token := extractConfirmationToken(t, text1)
-
-	// Second call: provide the confirmation token.
-	req2 := testutil.NewCallToolRequest("discord_delete_message", map[string]any{
-		"channel":            "123456789012345678",
-		"message_id":         "msg-100",
-		"confirmation_token": token,
-	})
+	if !strings.Contains(strings.ToLower(text1), "confirmation required") {
+		t.Fatalf("expected confirmation prompt, got: %s", text1)
+	}
+	if got := q.Len(); got != 3 {
+		t.Fatalf("Len() before confirmation = %d, want 3", got)
+	}
 
-	result2, err := handler(context.Background(), req2)
+	// Second call: with the token, should clear.
+	result2, err := handler(context.Background(), testutil.NewCallToolRequest("discord_clear_queue", map[string]any{
+		"confirmation_token": extractConfirmationToken(t, result1),
+	}))
 	if err != nil {
 		t.Fatalf("second call error: %v", err)
 	}
-
-	text2 := testutil.ExtractText(t, result2)
-	lower := strings.ToLower(text2)
-	if strings.Contains(lower, "confirmation required") {
-		t.Errorf("expected deletion success after confirmation, got another prompt: %s", text2)
+	testutil.AssertTextContains(t, result2, "3 messages discarded")
+	if got := q.Len(); got != 0 {
+		t.Errorf("Len() after clear = %d, want 0", got)
 	}
 }
 
 // ---------------------------------------------------------------------------
-// Benchmarks
+// discord_send_message handler
 // ---------------------------------------------------------------------------
 
-func Benchmark_PollMessages_EmptyQueue(b *testing.B) {
+func Test_SendMessage_Valid(t *testing.T) {
+	t.Parallel()
+
 	client := &testutil.MockDiscordClient{}
 	q := queue.New()
 	r := testutil.NewMockChannelResolver()
 	filter := safety.NewFilter(nil, nil)
 	confirm := safety.NewConfirmationTracker(nil)
 
-	regs := message.MessageTools(client, q, r, filter, confirm, nil, nil)
-	handler := testutil.FindHandler(&testing.T{}, regs, "discord_poll_messages")
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_message")
 
-	req := testutil.NewCallToolRequest("discord_poll_messages", map[string]any{
-		"timeout_seconds": float64(1),
+	req := testutil.NewCallToolRequest("discord_send_message", map[string]any{
+		"channel": "123456789012345678",
+		"content": "test message",
 	})
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		_, _ = handler(ctx, req)
-		cancel()
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	text := testutil.ExtractText(t, result)
+	lower := strings.ToLower(text)
+	if !strings.Contains(lower, "sent") && !strings.Contains(text, "mock-msg-001") {
+		t.Errorf("expected success response with message ID, got: %s", text)
+	}
+
+	summary := messageSummaryFromResult(t, result)
+	if summary.ID != "mock-msg-001" {
+		t.Errorf("summary ID = %q, want mock-msg-001", summary.ID)
 	}
 }
 
-func extractConfirmationToken(t *testing.T, text string) string {
-	t.Helper()
-	const prefix = `confirmation_token="`
-	idx := strings.Index(text, prefix)
-	if idx < 0 {
-		const altPrefix = `confirmation_token=`
-		idx = strings.Index(text, altPrefix)
-		if idx < 0 {
-			t.Fatalf("could not find confirmation_token in text: %s", text)
-		}
-		after := text[idx+len(altPrefix):]
-		endIdx := strings.IndexAny(after, `". `+"\n")
-		if endIdx < 0 {
-			return after
-		}
-		return after[:endIdx]
+func Test_SendMessage_RateLimited_RetriesOnce(t *testing.T) {
+	t.Parallel()
+
+	rateLimited := &discordgo.RESTError{
+		Response:     &http.Response{StatusCode: http.StatusTooManyRequests},
+		ResponseBody: []byte(`{"message": "You are being rate limited.", "retry_after": 0.05}`),
+	}
+
+	tests := []struct {
+		name      string
+		failures  int
+		wantCalls int
+		wantError bool
+	}{
+		{name: "429 then success", failures: 1, wantCalls: 2, wantError: false},
+		{name: "429 twice gives up", failures: 2, wantCalls: 2, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			client := &testutil.MockDiscordClient{
+				ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+					calls++
+					if calls <= tt.failures {
+						return nil, rateLimited
+					}
+					return &discordgo.Message{ID: "sent-after-wait", ChannelID: channelID}, nil
+				},
+			}
+			var buf bytes.Buffer
+			audit := safety.NewAuditLogger(&buf)
+			r := testutil.NewMockChannelResolver()
+
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), audit, nil)
+			handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+			start := time.Now()
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_message", map[string]any{
+				"channel": "general",
+				"content": "hello",
+			}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("send called %d times, want %d", calls, tt.wantCalls)
+			}
+			if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+				t.Errorf("handler returned after %v, want it to wait retry_after", elapsed)
+			}
+			if result.IsError != tt.wantError {
+				t.Errorf("IsError = %v, want %v (%s)", result.IsError, tt.wantError, testutil.ExtractText(t, result))
+			}
+			if !strings.Contains(buf.String(), `"rate_limit_wait_ms":50`) {
+				t.Errorf("audit log should record the wait, got: %s", buf.String())
+			}
+		})
+	}
+}
+
+func Test_SendMessage_RateLimited_PastDeadlineNoRetry(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	client := &testutil.MockDiscordClient{
+		ChannelMessageSendComplexFunc: func(string, *discordgo.MessageSend, ...discordgo.RequestOption) (*discordgo.Message, error) {
+			calls++
+			return nil, &discordgo.RESTError{
+				Response:     &http.Response{StatusCode: http.StatusTooManyRequests},
+				ResponseBody: []byte(`{"retry_after": 10}`),
+			}
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := handler(ctx, testutil.NewCallToolRequest("discord_send_message", map[string]any{
+		"channel": "general",
+		"content": "hello",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("send called %d times, want 1 when retry_after exceeds the deadline", calls)
+	}
+	if !result.IsError {
+		t.Error("expected an error result")
+	}
+	if te := testutil.ToolError(t, result); te.Code != tools.CodeRateLimited {
+		t.Errorf("error code = %s, want %s", te.Code, tools.CodeRateLimited)
+	}
+}
+
+func Test_SendMessage_RateLimited_LongWaitNoDeadlineNoRetry(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	client := &testutil.MockDiscordClient{
+		ChannelMessageSendComplexFunc: func(string, *discordgo.MessageSend, ...discordgo.RequestOption) (*discordgo.Message, error) {
+			calls++
+			return nil, &discordgo.RESTError{
+				Response:     &http.Response{StatusCode: http.StatusTooManyRequests},
+				ResponseBody: []byte(`{"retry_after": 21600}`),
+			}
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+	start := time.Now()
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_message", map[string]any{
+		"channel": "general",
+		"content": "hello",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("handler returned after %v, want it not to wait", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("send called %d times, want 1 when retry_after exceeds the retry ceiling", calls)
+	}
+	if te := testutil.ToolError(t, result); te.Code != tools.CodeRateLimited {
+		t.Errorf("error code = %s, want %s", te.Code, tools.CodeRateLimited)
+	}
+}
+
+func Test_SendMessage_MissingPermissionsCode(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		ChannelMessageSendComplexFunc: func(string, *discordgo.MessageSend, ...discordgo.RequestOption) (*discordgo.Message, error) {
+			return nil, &discordgo.RESTError{
+				Response: &http.Response{StatusCode: http.StatusForbidden},
+				Message:  &discordgo.APIErrorMessage{Code: discordgo.ErrCodeMissingPermissions, Message: "Missing Permissions"},
+			}
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_message", map[string]any{
+		"channel": "general",
+		"content": "hello",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	te := testutil.ToolError(t, result)
+	if te.Code != tools.CodePermissionDenied {
+		t.Errorf("error code = %s, want %s", te.Code, tools.CodePermissionDenied)
+	}
+	if te.DiscordCode != discordgo.ErrCodeMissingPermissions {
+		t.Errorf("discord_code = %d, want %d", te.DiscordCode, discordgo.ErrCodeMissingPermissions)
+	}
+}
+
+func Test_SendMessage_AutoSplit(t *testing.T) {
+	t.Parallel()
+
+	// 50 lines of 99 characters plus a newline: 5000 characters in total.
+	line := strings.Repeat("x", 99) + "\n"
+	content := strings.Repeat(line, 50)
+
+	tests := []struct {
+		name      string
+		content   string
+		autoSplit bool
+		wantSends []int // rune length of each sent chunk
+	}{
+		{name: "5000 chars split into three", content: content, autoSplit: true, wantSends: []int{1999, 1999, 999}},
+		{name: "short content unchanged", content: "hello", autoSplit: true, wantSends: []int{5}},
+		{name: "single long line hard-split", content: strings.Repeat("y", 4500), autoSplit: true, wantSends: []int{2000, 2000, 500}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sends []*discordgo.MessageSend
+			client := &testutil.MockDiscordClient{
+				ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+					sends = append(sends, data)
+					return &discordgo.Message{ID: fmt.Sprintf("part-%d", len(sends)), ChannelID: channelID, Content: data.Content}, nil
+				},
+			}
+			r := testutil.NewMockChannelResolver()
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_message", map[string]any{
+				"channel":    "general",
+				"content":    tt.content,
+				"reply_to":   "orig-1",
+				"auto_split": tt.autoSplit,
+			}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("unexpected error result: %s", testutil.ExtractText(t, result))
+			}
+
+			if len(sends) != len(tt.wantSends) {
+				t.Fatalf("sent %d messages, want %d", len(sends), len(tt.wantSends))
+			}
+			for i, want := range tt.wantSends {
+				if got := len([]rune(sends[i].Content)); got != want {
+					t.Errorf("chunk %d length = %d, want %d", i, got, want)
+				}
+				if hasRef := sends[i].Reference != nil; hasRef != (i == 0) {
+					t.Errorf("chunk %d has reply reference = %v, want %v", i, hasRef, i == 0)
+				}
+			}
+			if len(sends) > 1 {
+				testutil.AssertTextContains(t, result, fmt.Sprintf("sent in %d parts", len(sends)))
+				testutil.AssertTextContains(t, result, "part-1, part-2, part-3")
+			}
+		})
+	}
+}
+
+func Test_SendAndEdit_ContentTooLong(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		tool      string
+		args      map[string]any
+		wantInErr []string
+	}{
+		{
+			name:      "send 2001 characters",
+			tool:      "discord_send_message",
+			args:      map[string]any{"channel": "general", "content": strings.Repeat("x", 2001)},
+			wantInErr: []string{"content is 2001 characters; the maximum is 2000", "auto_split"},
+		},
+		{
+			name:      "edit 2001 characters",
+			tool:      "discord_edit_message",
+			args:      map[string]any{"channel": "general", "message_id": "msg-1", "content": strings.Repeat("x", 2001)},
+			wantInErr: []string{"content is 2001 characters; the maximum is 2000"},
+		},
+		{
+			name:      "multi-byte characters are counted as characters",
+			tool:      "discord_send_message",
+			args:      map[string]any{"channel": "general", "content": strings.Repeat("é", 2001)},
+			wantInErr: []string{"content is 2001 characters"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &testutil.MockDiscordClient{
+				ChannelMessageSendComplexFunc: func(string, *discordgo.MessageSend, ...discordgo.RequestOption) (*discordgo.Message, error) {
+					t.Error("Discord should not be called for oversized content")
+					return nil, fmt.Errorf("unexpected")
+				},
+				ChannelMessageEditComplexFunc: func(*discordgo.MessageEdit, ...discordgo.RequestOption) (*discordgo.Message, error) {
+					t.Error("Discord should not be called for oversized content")
+					return nil, fmt.Errorf("unexpected")
+				},
+			}
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, tt.tool)
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest(tt.tool, tt.args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+			for _, want := range tt.wantInErr {
+				testutil.AssertTextContains(t, result, want)
+			}
+		})
+	}
+}
+
+func Test_SendMessage_ExactlyMaxLengthSent(t *testing.T) {
+	t.Parallel()
+
+	sends := 0
+	client := &testutil.MockDiscordClient{
+		ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+			sends++
+			return &discordgo.Message{ID: "m1", ChannelID: channelID}, nil
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_message", map[string]any{
+		"channel": "general",
+		"content": strings.Repeat("x", 2000),
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+	if sends != 1 {
+		t.Errorf("sent %d messages, want 1", sends)
+	}
+}
+
+func Test_SendMessage_CancelledContext_ReturnsPromptly(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			// Simulate a hung Discord API that only gives up when the
+			// request context is done.
+			ctx := discord.RequestContext(options...)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(5 * time.Second):
+				return &discordgo.Message{ID: "too-late"}, nil
+			}
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	result, err := handler(ctx, testutil.NewCallToolRequest("discord_send_message", map[string]any{
+		"channel": "general",
+		"content": "hello",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("handler took %v with a cancelled context, want a prompt return", elapsed)
 	}
-	after := text[idx+len(prefix):]
-	endIdx := strings.Index(after, `"`)
-	if endIdx < 0 {
-		t.Fatalf("could not find closing quote for token: %s", text)
+	if !result.IsError {
+		t.Errorf("expected an error result, got: %s", testutil.ExtractText(t, result))
+	}
+	testutil.AssertTextContains(t, result, "context canceled")
+}
+
+func Test_SendMessage_DeniedChannel(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, []string{"general"})
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+	req := testutil.NewCallToolRequest("discord_send_message", map[string]any{
+		"channel": "general",
+		"content": "should be blocked",
+	})
+
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	text := testutil.ExtractText(t, result)
+	lower := strings.ToLower(text)
+	if !strings.Contains(lower, "not allowed") && !strings.Contains(lower, "denied") {
+		t.Errorf("expected error about channel not allowed, got: %s", text)
+	}
+}
+
+func Test_SendMessage_WithReplyTo(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+	req := testutil.NewCallToolRequest("discord_send_message", map[string]any{
+		"channel":  "123456789012345678",
+		"content":  "replying to something",
+		"reply_to": "original-msg-id",
+	})
+
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	text := testutil.ExtractText(t, result)
+	lower := strings.ToLower(text)
+	if strings.HasPrefix(lower, "error") {
+		t.Errorf("expected success with reply_to, got: %s", text)
+	}
+}
+
+func Test_SendMessage_AllowedMentions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantNil bool
+		want    discordgo.MessageAllowedMentions
+	}{
+		{
+			name:    "plain message leaves default mention parsing",
+			args:    map[string]any{},
+			wantNil: true,
+		},
+		{
+			name: "reply does not ping author by default",
+			args: map[string]any{"reply_to": "original-msg-id"},
+			want: discordgo.MessageAllowedMentions{
+				Parse: []discordgo.AllowedMentionType{
+					discordgo.AllowedMentionTypeUsers,
+					discordgo.AllowedMentionTypeRoles,
+					discordgo.AllowedMentionTypeEveryone,
+				},
+				RepliedUser: false,
+			},
+		},
+		{
+			name: "reply with mention_reply pings author",
+			args: map[string]any{"reply_to": "original-msg-id", "mention_reply": true},
+			want: discordgo.MessageAllowedMentions{
+				Parse: []discordgo.AllowedMentionType{
+					discordgo.AllowedMentionTypeUsers,
+					discordgo.AllowedMentionTypeRoles,
+					discordgo.AllowedMentionTypeEveryone,
+				},
+				RepliedUser: true,
+			},
+		},
+		{
+			name: "suppress_mentions zeroes allowed mentions",
+			args: map[string]any{"reply_to": "original-msg-id", "mention_reply": true, "suppress_mentions": true},
+			want: discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sent *discordgo.MessageSend
+			client := &testutil.MockDiscordClient{
+				ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+					sent = data
+					return &discordgo.Message{ID: "mock-msg-001", ChannelID: channelID}, nil
+				},
+			}
+			r := testutil.NewMockChannelResolver()
+			filter := safety.NewFilter(nil, nil)
+			confirm := safety.NewConfirmationTracker(nil)
+
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+			args := map[string]any{
+				"channel": "general",
+				"content": "@everyone hello",
+			}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_message", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			testutil.AssertNotError(t, result)
+
+			if sent == nil {
+				t.Fatal("ChannelMessageSendComplex was not called")
+			}
+			if tt.wantNil {
+				if sent.AllowedMentions != nil {
+					t.Errorf("AllowedMentions = %+v, want nil", sent.AllowedMentions)
+				}
+				return
+			}
+			if sent.AllowedMentions == nil {
+				t.Fatal("AllowedMentions is nil, want non-nil")
+			}
+			if !reflect.DeepEqual(*sent.AllowedMentions, tt.want) {
+				t.Errorf("AllowedMentions = %+v, want %+v", *sent.AllowedMentions, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SendMessage_DefaultChannel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		defaultChan string
+		filter      *safety.Filter
+		wantChannel string
+		wantErr     string
+	}{
+		{name: "omitted channel uses default", defaultChan: "random", wantChannel: "ch-002"},
+		{name: "no default is an error", wantErr: "not found"},
+		{name: "default is filtered", defaultChan: "random", filter: safety.NewFilter(nil, []string{"random"}), wantErr: "not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotChannel string
+			client := &testutil.MockDiscordClient{
+				ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+					gotChannel = channelID
+					return &discordgo.Message{ID: "mock-msg-001", ChannelID: channelID}, nil
+				},
+			}
+			r := testutil.NewMockChannelResolver()
+			filter := tt.filter
+			if filter == nil {
+				filter = safety.NewFilter(nil, nil)
+			}
+			confirm := safety.NewConfirmationTracker(nil)
+
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+			if tt.defaultChan != "" {
+				regs = tools.OptionalChannel(regs, tools.DefaultChannelTools, tt.defaultChan)
+			}
+			handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_message", map[string]any{
+				"content": "hello",
+			}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if tt.wantErr != "" {
+				if !result.IsError {
+					t.Fatalf("expected error result, got: %s", testutil.ExtractText(t, result))
+				}
+				testutil.AssertTextContains(t, result, tt.wantErr)
+				if gotChannel != "" {
+					t.Errorf("message sent to %q, want no send", gotChannel)
+				}
+				return
+			}
+			testutil.AssertNotError(t, result)
+			if gotChannel != tt.wantChannel {
+				t.Errorf("sent to channel %q, want %q", gotChannel, tt.wantChannel)
+			}
+		})
+	}
+}
+
+func Test_SendMessage_Flags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want discordgo.MessageFlags
+	}{
+		{name: "no flags by default", args: map[string]any{}, want: 0},
+		{name: "silent", args: map[string]any{"silent": true}, want: discordgo.MessageFlagsSuppressNotifications},
+		{name: "suppress_embeds", args: map[string]any{"suppress_embeds": true}, want: discordgo.MessageFlagsSuppressEmbeds},
+		{
+			name: "silent and suppress_embeds",
+			args: map[string]any{"silent": true, "suppress_embeds": true},
+			want: discordgo.MessageFlagsSuppressNotifications | discordgo.MessageFlagsSuppressEmbeds,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sent []*discordgo.MessageSend
+			client := &testutil.MockDiscordClient{
+				ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+					sent = append(sent, data)
+					return &discordgo.Message{ID: fmt.Sprintf("mock-msg-%d", len(sent)), ChannelID: channelID}, nil
+				},
+			}
+			r := testutil.NewMockChannelResolver()
+			filter := safety.NewFilter(nil, nil)
+			confirm := safety.NewConfirmationTracker(nil)
+
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+			// Split content so the flags are checked on every part.
+			args := map[string]any{
+				"channel":    "general",
+				"content":    strings.Repeat("a", 1500) + "\n" + strings.Repeat("b", 1500) + " https://example.com",
+				"auto_split": true,
+			}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_message", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			testutil.AssertNotError(t, result)
+
+			if len(sent) != 2 {
+				t.Fatalf("sent %d messages, want 2", len(sent))
+			}
+			for i, data := range sent {
+				if data.Flags != tt.want {
+					t.Errorf("part %d Flags = %d, want %d", i+1, data.Flags, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_get_messages handler
+// ---------------------------------------------------------------------------
+
+func Test_GetMessages_Valid(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_messages")
+
+	req := testutil.NewCallToolRequest("discord_get_messages", map[string]any{
+		"channel": "123456789012345678",
+	})
+
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	text := testutil.ExtractText(t, result)
+	if !strings.Contains(text, "Hello from mock") {
+		t.Errorf("expected mock message content, got: %s", text)
+	}
+}
+
+func Test_GetMessages_TimestampUTC(t *testing.T) {
+	t.Parallel()
+
+	local := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	client := &testutil.MockDiscordClient{
+		ChannelMessagesFunc: func(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+			return []*discordgo.Message{{ID: "m-1", Content: "hi", Timestamp: local}}, nil
+		},
+	}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_messages")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_messages", map[string]any{
+		"channel": "general",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	testutil.AssertTextContains(t, result, `"timestamp": "2024-03-01T14:30:00Z"`)
+}
+
+func Test_GetMessages_SkipSystem(t *testing.T) {
+	t.Parallel()
+
+	msgs := []*discordgo.Message{
+		{ID: "m-6", Type: discordgo.MessageTypeChatInputCommand, Content: "/roll result: 4"},
+		{ID: "m-5", Type: discordgo.MessageTypeUserPremiumGuildSubscription},
+		{ID: "m-4", Type: discordgo.MessageTypeReply, Content: "agreed"},
+		{ID: "m-3", Type: discordgo.MessageTypeChannelPinnedMessage},
+		{ID: "m-2", Type: discordgo.MessageTypeGuildMemberJoin},
+		{ID: "m-1", Type: discordgo.MessageTypeDefault, Content: "hello"},
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantIDs []string
+	}{
+		{name: "system messages skipped by default", args: map[string]any{}, wantIDs: []string{"m-6", "m-4", "m-1"}},
+		{name: "skip_system true", args: map[string]any{"skip_system": true}, wantIDs: []string{"m-6", "m-4", "m-1"}},
+		{name: "skip_system false keeps everything", args: map[string]any{"skip_system": false}, wantIDs: []string{"m-6", "m-5", "m-4", "m-3", "m-2", "m-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &testutil.MockDiscordClient{
+				ChannelMessagesFunc: func(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+					return msgs, nil
+				},
+			}
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_get_messages")
+
+			args := map[string]any{"channel": "general"}
+			maps.Copy(args, tt.args)
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_messages", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			var got []message.MessageSummary
+			if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+				t.Fatalf("result is not valid JSON: %v", err)
+			}
+			var gotIDs []string
+			for _, m := range got {
+				gotIDs = append(gotIDs, m.ID)
+			}
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("IDs = %v, want %v", gotIDs, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func Test_GetMessages_EditedTimestamp(t *testing.T) {
+	t.Parallel()
+
+	posted := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	edited := time.Date(2024, 3, 1, 10, 15, 0, 0, time.FixedZone("EST", -5*60*60))
+	client := &testutil.MockDiscordClient{
+		ChannelMessagesFunc: func(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+			return []*discordgo.Message{
+				{ID: "m-2", Content: "fixed typo", Timestamp: posted, EditedTimestamp: &edited},
+				{ID: "m-1", Content: "untouched", Timestamp: posted},
+			}, nil
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_messages")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_messages", map[string]any{
+		"channel": "general",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+
+	var raw []map[string]any
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &raw); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	if len(raw) != 2 {
+		t.Fatalf("got %d messages, want 2", len(raw))
+	}
+	if got := raw[0]["edited_timestamp"]; got != "2024-03-01T15:15:00Z" {
+		t.Errorf("edited message edited_timestamp = %v, want 2024-03-01T15:15:00Z", got)
+	}
+	if _, ok := raw[1]["edited_timestamp"]; ok {
+		t.Errorf("unedited message should omit edited_timestamp, got %v", raw[1])
+	}
+}
+
+func Test_GetMessages_TimeRange(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	msgs := []*discordgo.Message{
+		{ID: "m-4", Content: "four", Timestamp: base.Add(3 * time.Hour)},
+		{ID: "m-3", Content: "three", Timestamp: base.Add(2 * time.Hour)},
+		{ID: "m-2", Content: "two", Timestamp: base.Add(1 * time.Hour)},
+		{ID: "m-1", Content: "one", Timestamp: base},
+	}
+
+	tests := []struct {
+		name       string
+		args       map[string]any
+		wantIDs    []string
+		wantBefore string
+		wantErr    string
+	}{
+		{name: "no range returns all", args: map[string]any{}, wantIDs: []string{"m-4", "m-3", "m-2", "m-1"}},
+		{name: "since is inclusive", args: map[string]any{"since": "2024-03-01T11:00:00Z"}, wantIDs: []string{"m-4", "m-3", "m-2"}},
+		{
+			name:       "until becomes before cursor and is exclusive",
+			args:       map[string]any{"until": "2024-03-01T12:00:00Z"},
+			wantIDs:    []string{"m-2", "m-1"},
+			wantBefore: "1213093380096000000",
+		},
+		{
+			name:       "since and until",
+			args:       map[string]any{"since": "2024-03-01T11:00:00Z", "until": "2024-03-01T12:30:00+00:00"},
+			wantIDs:    []string{"m-3", "m-2"},
+			wantBefore: "1213100929843200000",
+		},
+		{
+			name:       "explicit before kept with until",
+			args:       map[string]any{"before": "m-5", "until": "2024-03-01T12:00:00Z"},
+			wantIDs:    []string{"m-2", "m-1"},
+			wantBefore: "m-5",
+		},
+		{name: "invalid since", args: map[string]any{"since": "yesterday"}, wantErr: "invalid since"},
+		{name: "since after until", args: map[string]any{"since": "2024-03-01T12:00:00Z", "until": "2024-03-01T11:00:00Z"}, wantErr: "since must be before until"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotBefore string
+			client := &testutil.MockDiscordClient{
+				ChannelMessagesFunc: func(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+					gotBefore = beforeID
+					return msgs, nil
+				},
+			}
+			r := testutil.NewMockChannelResolver()
+			filter := safety.NewFilter(nil, nil)
+			confirm := safety.NewConfirmationTracker(nil)
+
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_get_messages")
+
+			args := map[string]any{"channel": "general"}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_messages", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if tt.wantErr != "" {
+				if !result.IsError {
+					t.Fatalf("expected error result, got: %s", testutil.ExtractText(t, result))
+				}
+				testutil.AssertTextContains(t, result, tt.wantErr)
+				return
+			}
+
+			var got []message.MessageSummary
+			if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			ids := make([]string, len(got))
+			for i, m := range got {
+				ids[i] = m.ID
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("message IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if gotBefore != tt.wantBefore {
+				t.Errorf("beforeID = %q, want %q", gotBefore, tt.wantBefore)
+			}
+		})
+	}
+}
+
+func Test_GetMessages_SinceDuration(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	client := &testutil.MockDiscordClient{
+		ChannelMessagesFunc: func(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+			return []*discordgo.Message{
+				{ID: "recent", Content: "recent", Timestamp: now.Add(-5 * time.Minute)},
+				{ID: "old", Content: "old", Timestamp: now.Add(-2 * time.Hour)},
+			}, nil
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_messages")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_messages", map[string]any{
+		"channel": "general",
+		"since":   "30m",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertTextContains(t, result, `"id": "recent"`)
+	testutil.AssertTextNotContains(t, result, `"id": "old"`)
+}
+
+func Test_GetMessages_AuthorFilter(t *testing.T) {
+	t.Parallel()
+
+	alice := &discordgo.User{ID: "user-1", Username: "alice"}
+	bob := &discordgo.User{ID: "user-2", Username: "bob"}
+	msgs := []*discordgo.Message{
+		{ID: "m-4", Content: "four", Author: alice},
+		{ID: "m-3", Content: "three", Author: bob},
+		{ID: "m-2", Content: "two"},
+		{ID: "m-1", Content: "one", Author: alice},
+	}
+
+	tests := []struct {
+		name    string
+		author  string
+		wantIDs []string
+	}{
+		{"by user ID", "user-1", []string{"m-4", "m-1"}},
+		{"by username ignoring case", "BOB", []string{"m-3"}},
+		{"unknown author", "user-9", []string{}},
+		{"empty returns all", "", []string{"m-4", "m-3", "m-2", "m-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotLimit int
+			client := &testutil.MockDiscordClient{
+				ChannelMessagesFunc: func(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+					gotLimit = limit
+					return msgs, nil
+				},
+			}
+			r := testutil.NewMockChannelResolver()
+			filter := safety.NewFilter(nil, nil)
+			confirm := safety.NewConfirmationTracker(nil)
+
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_get_messages")
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_messages", map[string]any{
+				"channel": "general",
+				"limit":   4,
+				"author":  tt.author,
+			}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			testutil.AssertNotError(t, result)
+
+			var got []message.MessageSummary
+			if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			ids := make([]string, len(got))
+			for i, m := range got {
+				ids[i] = m.ID
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("message IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if gotLimit != 4 {
+				t.Errorf("fetch limit = %d, want 4", gotLimit)
+			}
+		})
+	}
+}
+
+func Test_GetMessages_PinnedOnly(t *testing.T) {
+	t.Parallel()
+
+	alice := &discordgo.User{ID: "user-1", Username: "alice"}
+	pins := &discordgo.ChannelMessagePinsList{Items: []*discordgo.MessagePin{
+		{Message: &discordgo.Message{ID: "m-7", Content: "rules", Author: alice}},
+		nil,
+		{Message: &discordgo.Message{ID: "m-3", Content: "faq", Author: &discordgo.User{ID: "user-2", Username: "bob"}}},
+	}}
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		wantIDs  []string
+		wantNote string
+	}{
+		{"all pins", map[string]any{}, []string{"m-7", "m-3"}, ""},
+		{"author still applies", map[string]any{"author": "alice"}, []string{"m-7"}, ""},
+		{"before and limit ignored", map[string]any{"before": "m-9", "limit": 1}, []string{"m-7", "m-3"}, "pinned_only ignores before and limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotChannel string
+			var gotLimit int
+			client := &testutil.MockDiscordClient{
+				ChannelMessagesFunc: func(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+					t.Error("ChannelMessages should not be called with pinned_only")
+					return nil, nil
+				},
+				ChannelMessagesPinnedFunc: func(channelID string, before *time.Time, limit int, _ ...discordgo.RequestOption) (*discordgo.ChannelMessagePinsList, error) {
+					gotChannel = channelID
+					gotLimit = limit
+					return pins, nil
+				},
+			}
+			r := testutil.NewMockChannelResolver()
+			filter := safety.NewFilter(nil, nil)
+			confirm := safety.NewConfirmationTracker(nil)
+
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_get_messages")
+
+			args := map[string]any{"channel": "general", "pinned_only": true}
+			maps.Copy(args, tt.args)
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_messages", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			testutil.AssertNotError(t, result)
+
+			if gotChannel != "ch-001" {
+				t.Errorf("ChannelMessagesPinned channel = %q, want %q", gotChannel, "ch-001")
+			}
+			if gotLimit != 50 {
+				t.Errorf("ChannelMessagesPinned limit = %d, want 50", gotLimit)
+			}
+
+			var got []message.MessageSummary
+			if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			ids := make([]string, len(got))
+			for i, m := range got {
+				ids[i] = m.ID
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("message IDs = %v, want %v", ids, tt.wantIDs)
+			}
+
+			if tt.wantNote == "" {
+				if len(result.Content) != 1 {
+					t.Errorf("len(Content) = %d, want 1", len(result.Content))
+				}
+				return
+			}
+			if len(result.Content) != 2 {
+				t.Fatalf("len(Content) = %d, want 2", len(result.Content))
+			}
+			note, ok := result.Content[1].(mcp.TextContent)
+			if !ok || note.Text != tt.wantNote {
+				t.Errorf("note = %v, want %q", result.Content[1], tt.wantNote)
+			}
+		})
+	}
+}
+
+func Test_GetMessages_PinnedOnly_DiscordError(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		ChannelMessagesPinnedFunc: func(channelID string, before *time.Time, limit int, _ ...discordgo.RequestOption) (*discordgo.ChannelMessagePinsList, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_messages")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_messages", map[string]any{
+		"channel":     "general",
+		"pinned_only": true,
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected error result")
+	}
+	testutil.AssertTextContains(t, result, "boom")
+}
+
+func Test_GetMessages_DeniedChannel(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, []string{"general"})
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_messages")
+
+	req := testutil.NewCallToolRequest("discord_get_messages", map[string]any{
+		"channel": "general",
+	})
+
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	text := testutil.ExtractText(t, result)
+	lower := strings.ToLower(text)
+	if !strings.Contains(lower, "not allowed") && !strings.Contains(lower, "denied") {
+		t.Errorf("expected denied error, got: %s", text)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_get_message handler
+// ---------------------------------------------------------------------------
+
+func Test_GetMessage_Valid(t *testing.T) {
+	t.Parallel()
+
+	var gotChannel, gotID string
+	client := &testutil.MockDiscordClient{
+		ChannelMessageFunc: func(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			gotChannel, gotID = channelID, messageID
+			return &discordgo.Message{
+				ID:      messageID,
+				Content: "status: green",
+				Author:  &discordgo.User{ID: "user-1", Username: "alice"},
+				Attachments: []*discordgo.MessageAttachment{
+					{ID: "att-1", Filename: "log.txt", URL: "https://cdn.example/log.txt", ContentType: "text/plain", Size: 42},
+				},
+				Reactions: []*discordgo.MessageReactions{
+					{Emoji: &discordgo.Emoji{Name: "👍"}, Count: 3, Me: true},
+					{Emoji: &discordgo.Emoji{ID: "111", Name: "party"}, Count: 1},
+				},
+			}, nil
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_message")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_message", map[string]any{
+		"channel":    "general",
+		"message_id": "msg-42",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if gotChannel != "ch-001" || gotID != "msg-42" {
+		t.Errorf("ChannelMessage(%q, %q), want (ch-001, msg-42)", gotChannel, gotID)
+	}
+
+	var got message.MessageSummary
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &got); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	wantAttachments := []message.AttachmentSummary{
+		{ID: "att-1", Filename: "log.txt", URL: "https://cdn.example/log.txt", ContentType: "text/plain", Size: 42},
+	}
+	wantReactions := []message.ReactionSummary{
+		{Emoji: "👍", Count: 3, Me: true},
+		{Emoji: "party:111", Count: 1},
+	}
+	if got.Content != "status: green" || got.AuthorUsername != "alice" {
+		t.Errorf("summary = %+v, want content and author from the message", got)
+	}
+	if !reflect.DeepEqual(got.Attachments, wantAttachments) {
+		t.Errorf("Attachments = %+v, want %+v", got.Attachments, wantAttachments)
+	}
+	if !reflect.DeepEqual(got.Reactions, wantReactions) {
+		t.Errorf("Reactions = %+v, want %+v", got.Reactions, wantReactions)
+	}
+}
+
+func Test_GetMessage_NotFound(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		ChannelMessageFunc: func(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			return nil, fmt.Errorf("HTTP 404 Not Found, {\"message\": \"Unknown Message\", \"code\": 10008}")
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_message")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_message", map[string]any{
+		"channel":    "general",
+		"message_id": "gone",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected error result, got: %s", testutil.ExtractText(t, result))
+	}
+}
+
+func Test_GetMessage_DeniedChannel(t *testing.T) {
+	t.Parallel()
+
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, []string{"general"}), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_message")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_message", map[string]any{
+		"channel":    "general",
+		"message_id": "msg-1",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected denied result, got: %s", testutil.ExtractText(t, result))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_get_pinned_messages handler
+// ---------------------------------------------------------------------------
+
+func Test_GetPinnedMessages_Valid(t *testing.T) {
+	t.Parallel()
+
+	var gotChannel string
+	var gotLimit int
+	client := &testutil.MockDiscordClient{
+		ChannelMessagesPinnedFunc: func(channelID string, before *time.Time, limit int, _ ...discordgo.RequestOption) (*discordgo.ChannelMessagePinsList, error) {
+			gotChannel, gotLimit = channelID, limit
+			return &discordgo.ChannelMessagePinsList{
+				Items: []*discordgo.MessagePin{
+					{Message: &discordgo.Message{ID: "pin-1", Content: "Rule 1: be kind", Author: &discordgo.User{ID: "u1", Username: "mod"}}},
+					{Message: nil},
+				},
+			}, nil
+		},
+	}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_pinned_messages")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_pinned_messages", map[string]any{
+		"channel": "general",
+		"limit":   float64(500),
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	if gotChannel != "ch-001" {
+		t.Errorf("ChannelMessagesPinned channelID = %q, want ch-001", gotChannel)
+	}
+	if gotLimit != 50 {
+		t.Errorf("ChannelMessagesPinned limit = %d, want 50 (clamped)", gotLimit)
+	}
+
+	var summaries []message.MessageSummary
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &summaries); err != nil {
+		t.Fatalf("result is not a MessageSummary list: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].ID != "pin-1" || summaries[0].AuthorUsername != "mod" {
+		t.Errorf("summaries = %+v, want the single pinned message", summaries)
+	}
+}
+
+func Test_GetPinnedMessages_DeniedChannel(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, []string{"general"})
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_get_pinned_messages")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_pinned_messages", map[string]any{
+		"channel": "general",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertTextContains(t, result, "not allowed")
+}
+
+// ---------------------------------------------------------------------------
+// discord_download_attachment handler
+// ---------------------------------------------------------------------------
+
+// attachmentCDN starts a TLS server standing in for Discord's CDN and returns
+// the download tool's handler configured to trust it, with channel 111 named
+// "general" and 222 named "secret". maxBytes of zero uses the default cap.
+func attachmentCDN(t *testing.T, mux *http.ServeMux, filter *safety.Filter, maxBytes int64) (*httptest.Server, server.ToolHandlerFunc) {
+	t.Helper()
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+
+	r := testutil.NewMockChannelResolver()
+	r.IDToName["111"] = "general"
+	r.IDToName["222"] = "secret"
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, queue.New(), message.PollConfig{}, r, filter, safety.NewConfirmationTracker(nil), nil, nil,
+		message.WithAttachmentConfig(message.AttachmentConfig{
+			MaxBytes: maxBytes,
+			Hosts:    []string{srv.Listener.Addr().String()},
+			Client:   srv.Client(),
+		}),
+	)
+	return srv, testutil.FindHandler(t, regs, "discord_download_attachment")
+}
+
+func Test_DownloadAttachment_Valid(t *testing.T) {
+	t.Parallel()
+
+	body := []byte("\x89PNG\r\n\x1a\nnot really a png")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/attachments/111/999/cat.png", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(body)
+	})
+	mux.HandleFunc("/attachments/111/998/notes", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header()["Content-Type"] = nil // suppress net/http's sniffing
+		w.Write([]byte("plain notes"))
+	})
+	srv, handler := attachmentCDN(t, mux, safety.NewFilter(nil, nil), 0)
+
+	tests := []struct {
+		name            string
+		path            string
+		wantFilename    string
+		wantContentType string
+		wantData        []byte
+	}{
+		{"content type from response", "/attachments/111/999/cat.png?ex=abc&is=def", "cat.png", "image/png", body},
+		{"content type sniffed", "/attachments/111/998/notes", "notes", "text/plain; charset=utf-8", []byte("plain notes")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_download_attachment", map[string]any{
+				"url": srv.URL + tt.path,
+			}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			testutil.AssertNotError(t, result)
+			testutil.AssertTextContains(t, result, tt.wantFilename)
+
+			var got message.AttachmentContent
+			if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if got.ChannelID != "111" || got.Filename != tt.wantFilename || got.ContentType != tt.wantContentType {
+				t.Errorf("got channel %q, filename %q, content type %q; want 111, %q, %q", got.ChannelID, got.Filename, got.ContentType, tt.wantFilename, tt.wantContentType)
+			}
+			data, err := base64.StdEncoding.DecodeString(got.Data)
+			if err != nil {
+				t.Fatalf("decoding data: %v", err)
+			}
+			if !bytes.Equal(data, tt.wantData) || got.Size != len(tt.wantData) {
+				t.Errorf("data = %q (size %d), want %q", data, got.Size, tt.wantData)
+			}
+		})
+	}
+}
+
+func Test_DownloadAttachment_Rejected(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/attachments/111/1/big.bin":
+			w.Write(bytes.Repeat([]byte("x"), 64))
+		case "/attachments/111/2/chunked.bin":
+			// Flushing forces a chunked response with no Content-Length.
+			w.Write(bytes.Repeat([]byte("x"), 40))
+			w.(http.Flusher).Flush()
+			w.Write(bytes.Repeat([]byte("x"), 40))
+		case "/attachments/111/3/redirect.png":
+			http.Redirect(w, r, "https://evil.example/attachments/111/3/x.png", http.StatusFound)
+		case "/attachments/111/4/error.png":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	filter := safety.NewFilter(nil, []string{"secret"})
+	srv, handler := attachmentCDN(t, mux, filter, 32)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode tools.ErrorCode
+		wantText string
+		wantHit  bool
+	}{
+		{"empty url", "", tools.CodeInvalidArgument, "must not be empty", false},
+		{"other host", "https://example.com/attachments/111/1/a.png", tools.CodeInvalidArgument, "https URL on", false},
+		{"plain http", "http://" + srv.Listener.Addr().String() + "/attachments/111/1/a.png", tools.CodeInvalidArgument, "https URL on", false},
+		{"not an attachment path", srv.URL + "/avatars/111/abc.png", tools.CodeInvalidArgument, "not a Discord attachment URL", false},
+		{"denied channel", srv.URL + "/attachments/222/1/a.png", tools.CodeChannelNotAllowed, "not allowed", false},
+		{"too large", srv.URL + "/attachments/111/1/big.bin", tools.CodeInvalidArgument, "maximum is 32", true},
+		{"too large without length", srv.URL + "/attachments/111/2/chunked.bin", tools.CodeInvalidArgument, "maximum of 32", true},
+		{"redirect off the CDN", srv.URL + "/attachments/111/3/redirect.png", tools.CodeDiscordUnavailable, "not a Discord CDN host", true},
+		{"missing file", srv.URL + "/attachments/111/5/gone.png", tools.CodeNotFound, "may have expired", true},
+		{"CDN error", srv.URL + "/attachments/111/4/error.png", tools.CodeDiscordUnavailable, "HTTP 502", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := hits.Load()
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_download_attachment", map[string]any{
+				"url": tt.url,
+			}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("expected error result")
+			}
+			testutil.AssertTextContains(t, result, tt.wantText)
+			if got := testutil.ToolError(t, result).Code; got != tt.wantCode {
+				t.Errorf("code = %s, want %s", got, tt.wantCode)
+			}
+			if hit := hits.Load() != before; hit != tt.wantHit {
+				t.Errorf("CDN contacted = %v, want %v", hit, tt.wantHit)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_edit_message handler
+// ---------------------------------------------------------------------------
+
+func Test_EditMessage_Valid(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_edit_message")
+
+	req := testutil.NewCallToolRequest("discord_edit_message", map[string]any{
+		"channel":    "123456789012345678",
+		"message_id": "msg-100",
+		"content":    "updated text",
+	})
+
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	text := testutil.ExtractText(t, result)
+	lower := strings.ToLower(text)
+	// A successful edit should not start with "error:".
+	if strings.HasPrefix(lower, "error:") {
+		t.Errorf("expected success for edit, got: %s", text)
+	}
+
+	summary := messageSummaryFromResult(t, result)
+	if summary.ID != "msg-100" || summary.Content != "edited content" {
+		t.Errorf("summary = %+v, want ID msg-100 with the edited content", summary)
+	}
+}
+
+func Test_EditMessage_ExpectedContent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		expected   string
+		wantEdited bool
+	}{
+		{name: "matching content is edited", expected: "draft v1", wantEdited: true},
+		{name: "changed content is a conflict", expected: "draft v0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var fetched, edited bool
+			client := &testutil.MockDiscordClient{
+				ChannelMessageFunc: func(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+					fetched = true
+					if channelID != "ch-001" || messageID != "msg-100" {
+						t.Errorf("ChannelMessage(%q, %q), want (ch-001, msg-100)", channelID, messageID)
+					}
+					return &discordgo.Message{ID: messageID, ChannelID: channelID, Content: "draft v1"}, nil
+				},
+				ChannelMessageEditComplexFunc: func(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+					edited = true
+					return &discordgo.Message{ID: m.ID, ChannelID: m.Channel, Content: *m.Content}, nil
+				},
+			}
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_edit_message")
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_edit_message", map[string]any{
+				"channel":          "general",
+				"message_id":       "msg-100",
+				"content":          "draft v2",
+				"expected_content": tt.expected,
+			}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if !fetched {
+				t.Error("expected the current message to be fetched")
+			}
+			if edited != tt.wantEdited {
+				t.Errorf("edited = %v, want %v", edited, tt.wantEdited)
+			}
+
+			if tt.wantEdited {
+				testutil.AssertNotError(t, result)
+				if got := messageSummaryFromResult(t, result); got.Content != "draft v2" {
+					t.Errorf("content = %q, want draft v2", got.Content)
+				}
+				return
+			}
+			te := testutil.ToolError(t, result)
+			if te.Code != tools.CodeConflict || !strings.Contains(te.Message, `"draft v1"`) {
+				t.Errorf("error = %+v, want CONFLICT with the current content", te)
+			}
+		})
+	}
+}
+
+func Test_EditMessage_WithoutExpectedContent_SkipsFetch(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		ChannelMessageFunc: func(string, string, ...discordgo.RequestOption) (*discordgo.Message, error) {
+			t.Error("ChannelMessage called without expected_content")
+			return nil, errors.New("unexpected")
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_edit_message")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_edit_message", map[string]any{
+		"channel":    "general",
+		"message_id": "msg-100",
+		"content":    "new",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+}
+
+func Test_EditMessage_EmbedCases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		wantErr     string
+		wantContent *string
+		wantEmbeds  *[]*discordgo.MessageEmbed
+	}{
+		{
+			name: "set embed only",
+			args: map[string]any{"embed": map[string]any{
+				"title":  "Status",
+				"color":  float64(0x00ff00),
+				"fields": []any{map[string]any{"name": "build", "value": "passing", "inline": true}},
+			}},
+			wantEmbeds: &[]*discordgo.MessageEmbed{{
+				Title:  "Status",
+				Color:  0x00ff00,
+				Fields: []*discordgo.MessageEmbedField{{Name: "build", Value: "passing", Inline: true}},
+			}},
+		},
+		{
+			name:        "content and embed",
+			args:        map[string]any{"content": "hi", "embed": map[string]any{"description": "body"}},
+			wantContent: ptr("hi"),
+			wantEmbeds:  &[]*discordgo.MessageEmbed{{Description: "body"}},
+		},
+		{
+			name:       "null clears embed",
+			args:       map[string]any{"embed": nil},
+			wantEmbeds: &[]*discordgo.MessageEmbed{},
+		},
+		{
+			name:       "empty object clears embed",
+			args:       map[string]any{"embed": map[string]any{}},
+			wantEmbeds: &[]*discordgo.MessageEmbed{},
+		},
+		{
+			name:        "content only leaves embed alone",
+			args:        map[string]any{"content": "text"},
+			wantContent: ptr("text"),
+		},
+		{
+			name:    "title too long",
+			args:    map[string]any{"embed": map[string]any{"title": strings.Repeat("x", 257)}},
+			wantErr: "title",
+		},
+		{
+			name: "too many fields",
+			args: map[string]any{"embed": map[string]any{"fields": func() []any {
+				fields := make([]any, 26)
+				for i := range fields {
+					fields[i] = map[string]any{"name": "n", "value": "v"}
+				}
+				return fields
+			}()}},
+			wantErr: "fields",
+		},
+		{
+			name: "total too long",
+			args: map[string]any{"embed": map[string]any{
+				"description": strings.Repeat("x", 4000),
+				"footer":      map[string]any{"text": strings.Repeat("y", 2001)},
+			}},
+			wantErr: "totals",
+		},
+		{
+			name:    "embed not an object",
+			args:    map[string]any{"embed": "nope"},
+			wantErr: "object",
+		},
+		{
+			name:    "nothing to edit",
+			args:    map[string]any{},
+			wantErr: "content or embed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got *discordgo.MessageEdit
+			client := &testutil.MockDiscordClient{
+				ChannelMessageEditComplexFunc: func(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+					got = m
+					return &discordgo.Message{ID: m.ID, ChannelID: m.Channel}, nil
+				},
+			}
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_edit_message")
+
+			args := map[string]any{"channel": "general", "message_id": "msg-1"}
+			for k, v := range tc.args {
+				args[k] = v
+			}
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_edit_message", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if !result.IsError {
+					t.Fatalf("expected error result, got: %s", testutil.ExtractText(t, result))
+				}
+				testutil.AssertTextContains(t, result, tc.wantErr)
+				if got != nil {
+					t.Error("edit sent despite validation error")
+				}
+				return
+			}
+			testutil.AssertNotError(t, result)
+			if !reflect.DeepEqual(got.Content, tc.wantContent) {
+				t.Errorf("Content = %v, want %v", got.Content, tc.wantContent)
+			}
+			if !reflect.DeepEqual(got.Embeds, tc.wantEmbeds) {
+				t.Errorf("Embeds = %+v, want %+v", got.Embeds, tc.wantEmbeds)
+			}
+		})
+	}
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T { return &v }
+
+// ---------------------------------------------------------------------------
+// discord_delete_message handler
+// ---------------------------------------------------------------------------
+
+func Test_DeleteMessage_NoConfirmationToken(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker([]string{"discord_delete_message"})
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_delete_message")
+
+	req := testutil.NewCallToolRequest("discord_delete_message", map[string]any{
+		"channel":    "123456789012345678",
+		"message_id": "msg-100",
+	})
+
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	text := testutil.ExtractText(t, result)
+	lower := strings.ToLower(text)
+	if !strings.Contains(lower, "confirmation") {
+		t.Errorf("expected confirmation prompt, got: %s", text)
+	}
+	if !strings.Contains(text, "confirmation_token=") {
+		t.Errorf("expected confirmation_token in response, got: %s", text)
+	}
+}
+
+func Test_DeleteMessage_WithValidConfirmationToken(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker([]string{"discord_delete_message"})
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_delete_message")
+
+	// First call: get the confirmation token.
+	req1 := testutil.NewCallToolRequest("discord_delete_message", map[string]any{
+		"channel":    "123456789012345678",
+		"message_id": "msg-100",
+	})
+
+	result1, err := handler(context.Background(), req1)
+	if err != nil {
+		t.Fatalf("first call error: %v", err)
+	}
+
+	prompt := testutil.ConfirmationRequest(t, result1)
+	if prompt.Tool != "discord_delete_message" || prompt.Resource != "msg-100" {
+		t.Errorf("confirmation prompt = %+v, want tool discord_delete_message on msg-100", prompt)
+	}
+	token := prompt.ConfirmationToken
+
+	// Second call: provide the confirmation token.
+	req2 := testutil.NewCallToolRequest("discord_delete_message", map[string]any{
+		"channel":            "123456789012345678",
+		"message_id":         "msg-100",
+		"confirmation_token": token,
+	})
+
+	result2, err := handler(context.Background(), req2)
+	if err != nil {
+		t.Fatalf("second call error: %v", err)
+	}
+
+	text2 := testutil.ExtractText(t, result2)
+	lower := strings.ToLower(text2)
+	if strings.Contains(lower, "confirmation required") {
+		t.Errorf("expected deletion success after confirmation, got another prompt: %s", text2)
+	}
+}
+
+func Test_DeleteMessage_TokenForOtherMessageRejected(t *testing.T) {
+	t.Parallel()
+
+	deletes := 0
+	client := &testutil.MockDiscordClient{
+		ChannelMessageDeleteFunc: func(string, string, ...discordgo.RequestOption) error {
+			deletes++
+			return nil
+		},
+	}
+	confirm := safety.NewConfirmationTracker([]string{"discord_delete_message"})
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_delete_message")
+
+	// Ask to delete message A, then try to use its token on message B.
+	result1, err := handler(context.Background(), testutil.NewCallToolRequest("discord_delete_message", map[string]any{
+		"channel":    "general",
+		"message_id": "msg-A",
+	}))
+	if err != nil {
+		t.Fatalf("first call error: %v", err)
+	}
+	result2, err := handler(context.Background(), testutil.NewCallToolRequest("discord_delete_message", map[string]any{
+		"channel":            "general",
+		"message_id":         "msg-B",
+		"confirmation_token": extractConfirmationToken(t, result1),
+	}))
+	if err != nil {
+		t.Fatalf("second call error: %v", err)
+	}
+
+	if deletes != 0 {
+		t.Fatalf("message deleted %d times with a token issued for another message", deletes)
+	}
+	testutil.AssertTextContains(t, result2, `issued for "msg-A", not "msg-B"`)
+	if prompt := testutil.ConfirmationRequest(t, result2); prompt.Resource != "msg-B" {
+		t.Errorf("new prompt resource = %q, want msg-B", prompt.Resource)
+	}
+}
+
+func Test_DeleteMessage_ConfirmationModes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		mode        safety.ConfirmationMode
+		args        map[string]any
+		wantParam   string // confirmation parameter in the schema, "" for none
+		wantDeleted bool
+		wantText    string
+	}{
+		{name: "token mode prompts for a token", mode: safety.ConfirmToken, wantParam: "confirmation_token", wantText: "confirmation_token="},
+		{name: "token mode ignores confirm", mode: safety.ConfirmToken, args: map[string]any{"confirm": true}, wantParam: "confirmation_token", wantText: "confirmation_token="},
+		{name: "boolean mode prompts for confirm", mode: safety.ConfirmBoolean, wantParam: "confirm", wantText: "confirm=true"},
+		{name: "boolean mode rejects confirm false", mode: safety.ConfirmBoolean, args: map[string]any{"confirm": false}, wantParam: "confirm", wantText: "confirm=true"},
+		{name: "boolean mode deletes with confirm", mode: safety.ConfirmBoolean, args: map[string]any{"confirm": true}, wantParam: "confirm", wantDeleted: true, wantText: "deleted"},
+		{name: "off mode deletes immediately", mode: safety.ConfirmOff, wantDeleted: true, wantText: "deleted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			deletes := 0
+			client := &testutil.MockDiscordClient{
+				ChannelMessageDeleteFunc: func(string, string, ...discordgo.RequestOption) error {
+					deletes++
+					return nil
+				},
+			}
+			confirm := safety.NewConfirmationTracker([]string{"discord_delete_message"}, safety.WithConfirmationMode(tt.mode))
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), confirm, nil, nil)
+
+			var props map[string]any
+			for _, reg := range regs {
+				if reg.Tool.Name == "discord_delete_message" {
+					props = reg.Tool.InputSchema.Properties
+				}
+			}
+			for _, param := range []string{"confirmation_token", "confirm"} {
+				if _, ok := props[param]; ok != (param == tt.wantParam) {
+					t.Errorf("schema declares %s = %v, want %v", param, ok, param == tt.wantParam)
+				}
+			}
+
+			args := map[string]any{"channel": "general", "message_id": "msg-100"}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			handler := testutil.FindHandler(t, regs, "discord_delete_message")
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_delete_message", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			testutil.AssertNotError(t, result)
+			testutil.AssertTextContains(t, result, tt.wantText)
+			if deleted := deletes > 0; deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_move_message handler
+// ---------------------------------------------------------------------------
+
+func Test_MoveMessage_RepostsThenDeletes(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	var reposted *discordgo.MessageSend
+	client := &testutil.MockDiscordClient{
+		ChannelMessageFunc: func(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			calls = append(calls, "get:"+channelID)
+			return &discordgo.Message{
+				ID:      messageID,
+				Content: "wrong channel, sorry",
+				Author:  &discordgo.User{ID: "user-001", Username: "alice"},
+			}, nil
+		},
+		ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			calls = append(calls, "send:"+channelID)
+			reposted = data
+			return &discordgo.Message{ID: "new-msg-001", ChannelID: channelID, Content: data.Content}, nil
+		},
+		ChannelMessageDeleteFunc: func(channelID, messageID string, options ...discordgo.RequestOption) error {
+			calls = append(calls, "delete:"+channelID+"/"+messageID)
+			return nil
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	confirm := safety.NewConfirmationTracker(message.DestructiveToolNames())
+
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, safety.NewFilter(nil, nil), confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_move_message")
+
+	args := map[string]any{
+		"source_channel": "general",
+		"message_id":     "msg-100",
+		"target_channel": "random",
+	}
+
+	// First call: no token, should prompt without touching Discord.
+	result1, err := handler(context.Background(), testutil.NewCallToolRequest("discord_move_message", args))
+	if err != nil {
+		t.Fatalf("first call error: %v", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("Discord called before confirmation: %v", calls)
+	}
+
+	// Second call: with the token, should fetch, repost and delete in order.
+	args["confirmation_token"] = extractConfirmationToken(t, result1)
+	result2, err := handler(context.Background(), testutil.NewCallToolRequest("discord_move_message", args))
+	if err != nil {
+		t.Fatalf("second call error: %v", err)
+	}
+	testutil.AssertTextContains(t, result2, "new-msg-001")
+
+	want := []string{"get:ch-001", "send:ch-002", "delete:ch-001/msg-100"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if !strings.Contains(reposted.Content, "alice") || !strings.Contains(reposted.Content, "wrong channel, sorry") {
+		t.Errorf("repost content = %q, want author and original content", reposted.Content)
+	}
+	if reposted.AllowedMentions == nil || len(reposted.AllowedMentions.Parse) != 0 {
+		t.Errorf("repost AllowedMentions = %+v, want all pings suppressed", reposted.AllowedMentions)
+	}
+}
+
+func Test_MoveMessage_SendFailureKeepsOriginal(t *testing.T) {
+	t.Parallel()
+
+	deleted := false
+	client := &testutil.MockDiscordClient{
+		ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			return nil, fmt.Errorf("missing access")
+		},
+		ChannelMessageDeleteFunc: func(channelID, messageID string, options ...discordgo.RequestOption) error {
+			deleted = true
+			return nil
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	confirm := safety.NewConfirmationTracker(message.DestructiveToolNames())
+
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, safety.NewFilter(nil, nil), confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_move_message")
+
+	token := confirm.RequestConfirmation("discord_move_message", "msg-100 -> ch-002", "test")
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_move_message", map[string]any{
+		"source_channel":     "general",
+		"message_id":         "msg-100",
+		"target_channel":     "random",
+		"confirmation_token": token,
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected error result, got: %s", testutil.ExtractText(t, result))
+	}
+	if deleted {
+		t.Error("original deleted even though the repost failed")
+	}
+}
+
+// moveWithAttachment runs a confirmed discord_move_message of a message
+// whose one attachment is served by mux under /attachments/111/1/notes.txt,
+// returning the result, the repost sent and whether the original was deleted.
+func moveWithAttachment(t *testing.T, mux *http.ServeMux, maxBytes int64) (*mcp.CallToolResult, *discordgo.MessageSend, bool) {
+	t.Helper()
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+
+	var reposted *discordgo.MessageSend
+	deleted := false
+	client := &testutil.MockDiscordClient{
+		ChannelMessageFunc: func(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			return &discordgo.Message{
+				ID:      messageID,
+				Content: "see attached",
+				Author:  &discordgo.User{ID: "user-001", Username: "alice"},
+				Attachments: []*discordgo.MessageAttachment{{
+					Filename: "notes.txt",
+					URL:      srv.URL + "/attachments/111/1/notes.txt?ex=abc",
+				}},
+			}, nil
+		},
+		ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			reposted = data
+			return &discordgo.Message{ID: "new-msg-001", ChannelID: channelID, Content: data.Content}, nil
+		},
+		ChannelMessageDeleteFunc: func(channelID, messageID string, options ...discordgo.RequestOption) error {
+			deleted = true
+			return nil
+		},
+	}
+	confirm := safety.NewConfirmationTracker(message.DestructiveToolNames())
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), confirm, nil, nil,
+		message.WithAttachmentConfig(message.AttachmentConfig{
+			MaxBytes: maxBytes,
+			Hosts:    []string{srv.Listener.Addr().String()},
+			Client:   srv.Client(),
+		}),
+	)
+	handler := testutil.FindHandler(t, regs, "discord_move_message")
+
+	token := confirm.RequestConfirmation("discord_move_message", "msg-100 -> ch-002", "test")
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_move_message", map[string]any{
+		"source_channel":     "general",
+		"message_id":         "msg-100",
+		"target_channel":     "random",
+		"confirmation_token": token,
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	return result, reposted, deleted
+}
+
+func Test_MoveMessage_ReuploadsAttachments(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/attachments/111/1/notes.txt", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("file body"))
+	})
+	result, reposted, deleted := moveWithAttachment(t, mux, 0)
+
+	testutil.AssertNotError(t, result)
+	if !deleted {
+		t.Error("original not deleted after a successful move")
+	}
+	if strings.Contains(reposted.Content, "/attachments/") {
+		t.Errorf("repost content = %q, want no attachment URL", reposted.Content)
+	}
+	if len(reposted.Files) != 1 {
+		t.Fatalf("repost Files = %d, want 1", len(reposted.Files))
+	}
+	f := reposted.Files[0]
+	data, err := io.ReadAll(f.Reader)
+	if err != nil {
+		t.Fatalf("reading uploaded file: %v", err)
+	}
+	if f.Name != "notes.txt" || f.ContentType != "text/plain" || string(data) != "file body" {
+		t.Errorf("uploaded file = %s (%s) %q, want notes.txt (text/plain) \"file body\"", f.Name, f.ContentType, data)
+	}
+}
+
+func Test_MoveMessage_AttachmentTooLargeKeepsOriginal(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/attachments/111/1/notes.txt", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 64)))
+	})
+	result, reposted, deleted := moveWithAttachment(t, mux, 32)
+
+	if !result.IsError {
+		t.Errorf("expected error result, got: %s", testutil.ExtractText(t, result))
+	}
+	testutil.AssertTextContains(t, result, "original kept")
+	if reposted != nil || deleted {
+		t.Errorf("reposted = %v, deleted = %v; want nothing sent or deleted", reposted != nil, deleted)
+	}
+}
+
+func Test_MoveMessage_DeniedTarget(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{}
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, []string{"random"})
+	confirm := safety.NewConfirmationTracker(message.DestructiveToolNames())
+
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_move_message")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_move_message", map[string]any{
+		"source_channel": "general",
+		"message_id":     "msg-100",
+		"target_channel": "random",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !tools.IsDenied(result) {
+		t.Errorf("expected denied result, got: %s", testutil.ExtractText(t, result))
+	}
+}
+
+func Test_EditMessage_ConfiguredDestructiveTool(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.LoadConfig(filepath.Join("..", "..", "testdata", "config", "destructive_tools.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+
+	edits := 0
+	client := &testutil.MockDiscordClient{
+		ChannelMessageEditFunc: func(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			edits++
+			return &discordgo.Message{ID: messageID, ChannelID: channelID, Content: content}, nil
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker(
+		append(message.DestructiveToolNames(), cfg.Safety.DestructiveTools...),
+	)
+
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, r, filter, confirm, nil, nil)
+	if unknown := tools.UnknownToolNames(regs, cfg.Safety.DestructiveTools); len(unknown) != 0 {
+		t.Fatalf("UnknownToolNames = %v, want none", unknown)
+	}
+	regs = tools.WithConfirmation(confirm, regs)
+	handler := testutil.FindHandler(t, regs, "discord_edit_message")
+
+	args := map[string]any{
+		"channel":    "general",
+		"message_id": "msg-100",
+		"content":    "new content",
+	}
+
+	// First call: no token, should prompt and not edit.
+	result1, err := handler(context.Background(), testutil.NewCallToolRequest("discord_edit_message", args))
+	if err != nil {
+		t.Fatalf("first call error: %v", err)
+	}
+	text1 := testutil.ExtractText(t, result1)
+	if !strings.Contains(strings.ToLower(text1), "confirmation required") {
+		t.Fatalf("expected confirmation prompt, got: %s", text1)
+	}
+	if edits != 0 {
+		t.Fatalf("edit called %d times before confirmation, want 0", edits)
+	}
+
+	// Second call: with the token, should edit.
+	args["confirmation_token"] = extractConfirmationToken(t, result1)
+	result2, err := handler(context.Background(), testutil.NewCallToolRequest("discord_edit_message", args))
+	if err != nil {
+		t.Fatalf("second call error: %v", err)
+	}
+	testutil.AssertTextContains(t, result2, "edited successfully")
+	if edits != 1 {
+		t.Errorf("edit called %d times after confirmation, want 1", edits)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Benchmarks
+// ---------------------------------------------------------------------------
+
+func Benchmark_PollMessages_EmptyQueue(b *testing.B) {
+	client := &testutil.MockDiscordClient{}
+	q := queue.New()
+	r := testutil.NewMockChannelResolver()
+	filter := safety.NewFilter(nil, nil)
+	confirm := safety.NewConfirmationTracker(nil)
+
+	regs := message.MessageTools(context.Background(), client, q, message.PollConfig{}, r, filter, confirm, nil, nil)
+	handler := testutil.FindHandler(&testing.T{}, regs, "discord_poll_messages")
+
+	req := testutil.NewCallToolRequest("discord_poll_messages", map[string]any{
+		"timeout_seconds": float64(1),
+	})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, _ = handler(ctx, req)
+		cancel()
+	}
+}
+
+// extractConfirmationToken returns the token from the structured confirmation
+// prompt in result.
+func extractConfirmationToken(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	return testutil.ConfirmationRequest(t, result).ConfirmationToken
+}
+
+// ---------------------------------------------------------------------------
+// discord_schedule_message / discord_list_scheduled / discord_cancel_scheduled
+// ---------------------------------------------------------------------------
+
+func Test_ScheduleMessage_FiresAtDueTime(t *testing.T) {
+	t.Parallel()
+
+	sent := make(chan string, 1)
+	client := &testutil.MockDiscordClient{
+		ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			sent <- channelID + ":" + data.Content
+			return &discordgo.Message{ID: "sent-1", ChannelID: channelID, Content: data.Content}, nil
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	schedule := testutil.FindHandler(t, regs, "discord_schedule_message")
+	list := testutil.FindHandler(t, regs, "discord_list_scheduled")
+
+	result, err := schedule(context.Background(), testutil.NewCallToolRequest("discord_schedule_message", map[string]any{
+		"channel":       "general",
+		"content":       "later",
+		"delay_seconds": 0.05,
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+
+	select {
+	case got := <-sent:
+		if got != "ch-001:later" {
+			t.Errorf("sent %q, want %q", got, "ch-001:later")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("scheduled message was not sent")
+	}
+
+	result, err = list(context.Background(), testutil.NewCallToolRequest("discord_list_scheduled", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if text := testutil.ExtractText(t, result); text != "[]" {
+		t.Errorf("list after send = %s, want []", text)
+	}
+}
+
+func Test_ScheduleMessage_ListAndCancel(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			t.Errorf("cancelled message was sent: %q", data.Content)
+			return &discordgo.Message{ID: "sent-1"}, nil
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	schedule := testutil.FindHandler(t, regs, "discord_schedule_message")
+	list := testutil.FindHandler(t, regs, "discord_list_scheduled")
+	cancel := testutil.FindHandler(t, regs, "discord_cancel_scheduled")
+
+	sendAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	result, err := schedule(context.Background(), testutil.NewCallToolRequest("discord_schedule_message", map[string]any{
+		"channel": "random",
+		"content": "in an hour",
+		"send_at": sendAt.Format(time.RFC3339),
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+
+	result, err = list(context.Background(), testutil.NewCallToolRequest("discord_list_scheduled", map[string]any{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	var pending []message.ScheduledMessage
+	if err := json.Unmarshal([]byte(testutil.ExtractText(t, result)), &pending); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ChannelID != "ch-002" || !pending[0].SendAt.Equal(sendAt) {
+		t.Fatalf("pending = %+v, want one message for ch-002 at %s", pending, sendAt)
+	}
+
+	result, err = cancel(context.Background(), testutil.NewCallToolRequest("discord_cancel_scheduled", map[string]any{"id": pending[0].ID}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+
+	result, err = cancel(context.Background(), testutil.NewCallToolRequest("discord_cancel_scheduled", map[string]any{"id": pending[0].ID}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("second cancel should fail, got: %s", testutil.ExtractText(t, result))
+	}
+}
+
+func Test_ScheduleMessage_InvalidParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args map[string]any
+	}{
+		{name: "no time", args: map[string]any{"channel": "general", "content": "x"}},
+		{name: "both times", args: map[string]any{"channel": "general", "content": "x", "send_at": "2099-01-01T00:00:00Z", "delay_seconds": float64(5)}},
+		{name: "past send_at", args: map[string]any{"channel": "general", "content": "x", "send_at": "2001-01-01T00:00:00Z"}},
+		{name: "bad send_at", args: map[string]any{"channel": "general", "content": "x", "send_at": "tomorrow"}},
+		{name: "too far ahead", args: map[string]any{"channel": "general", "content": "x", "delay_seconds": float64(30 * 24 * 3600)}},
+		{name: "empty content", args: map[string]any{"channel": "general", "content": "", "delay_seconds": float64(5)}},
+		{name: "denied channel", args: map[string]any{"channel": "random", "content": "x", "delay_seconds": float64(5)}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, []string{"random"}), safety.NewConfirmationTracker(nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_schedule_message")
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_schedule_message", tc.args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if !result.IsError {
+				t.Errorf("expected error result, got: %s", testutil.ExtractText(t, result))
+			}
+		})
+	}
+}
+
+func Test_ScheduleMessage_ShutdownDropsPending(t *testing.T) {
+	t.Parallel()
+
+	shutdown, cancel := context.WithCancel(context.Background())
+	regs := message.MessageTools(shutdown, &testutil.MockDiscordClient{}, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	schedule := testutil.FindHandler(t, regs, "discord_schedule_message")
+	list := testutil.FindHandler(t, regs, "discord_list_scheduled")
+
+	result, err := schedule(context.Background(), testutil.NewCallToolRequest("discord_schedule_message", map[string]any{
+		"channel":       "general",
+		"content":       "never",
+		"delay_seconds": float64(3600),
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for {
+		result, err = list(context.Background(), testutil.NewCallToolRequest("discord_list_scheduled", map[string]any{}))
+		if err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		if testutil.ExtractText(t, result) == "[]" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pending messages not dropped on shutdown: %s", testutil.ExtractText(t, result))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	result, err = schedule(context.Background(), testutil.NewCallToolRequest("discord_schedule_message", map[string]any{
+		"channel":       "general",
+		"content":       "too late",
+		"delay_seconds": float64(5),
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected error scheduling after shutdown, got: %s", testutil.ExtractText(t, result))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Mention policy
+// ---------------------------------------------------------------------------
+
+func Test_MentionPolicy_SendCases(t *testing.T) {
+	t.Parallel()
+
+	allTypes := []discordgo.AllowedMentionType{
+		discordgo.AllowedMentionTypeUsers,
+		discordgo.AllowedMentionTypeRoles,
+		discordgo.AllowedMentionTypeEveryone,
+	}
+
+	tests := []struct {
+		name    string
+		policy  message.MentionPolicy
+		args    map[string]any
+		want    *discordgo.MessageAllowedMentions
+		wantNil bool
+	}{
+		{
+			name:    "no policy leaves defaults",
+			args:    map[string]any{"content": "@everyone hi"},
+			wantNil: true,
+		},
+		{
+			name:   "deny everyone strips @everyone",
+			policy: message.MentionPolicy{DenyEveryone: true},
+			args:   map[string]any{"content": "@everyone hi"},
+			want: &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{
+				discordgo.AllowedMentionTypeUsers,
+				discordgo.AllowedMentionTypeRoles,
+			}},
+		},
+		{
+			name:   "role allowlist keeps only listed roles",
+			policy: message.MentionPolicy{AllowedRoles: []string{"111"}},
+			args:   map[string]any{"content": "<@&111> and <@&222>"},
+			want: &discordgo.MessageAllowedMentions{
+				Parse: []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeUsers, discordgo.AllowedMentionTypeEveryone},
+				Roles: []string{"111"},
+			},
+		},
+		{
+			name:   "user cap keeps first users",
+			policy: message.MentionPolicy{MaxUserMentions: 2},
+			args:   map[string]any{"content": "<@1> <@!2> <@1> <@3>"},
+			want: &discordgo.MessageAllowedMentions{
+				Parse: []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeRoles, discordgo.AllowedMentionTypeEveryone},
+				Users: []string{"1", "2"},
+			},
+		},
+		{
+			name:   "user cap not reached",
+			policy: message.MentionPolicy{MaxUserMentions: 2},
+			args:   map[string]any{"content": "<@1>"},
+			want:   &discordgo.MessageAllowedMentions{Parse: allTypes},
+		},
+		{
+			name:   "reply settings preserved",
+			policy: message.MentionPolicy{DenyEveryone: true},
+			args:   map[string]any{"content": "@here", "reply_to": "m-1", "mention_reply": true},
+			want: &discordgo.MessageAllowedMentions{
+				Parse:       []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeUsers, discordgo.AllowedMentionTypeRoles},
+				RepliedUser: true,
+			},
+		},
+		{
+			name:   "suppress still suppresses",
+			policy: message.MentionPolicy{DenyEveryone: true, AllowedRoles: []string{"111"}},
+			args:   map[string]any{"content": "<@&111>", "suppress_mentions": true},
+			want:   &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got *discordgo.MessageAllowedMentions
+			client := &testutil.MockDiscordClient{
+				ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+					got = data.AllowedMentions
+					return &discordgo.Message{ID: "sent-1", ChannelID: channelID}, nil
+				},
+			}
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil, message.WithMentionPolicy(tc.policy))
+			handler := testutil.FindHandler(t, regs, "discord_send_message")
+
+			args := map[string]any{"channel": "general"}
+			for k, v := range tc.args {
+				args[k] = v
+			}
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_message", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			testutil.AssertNotError(t, result)
+
+			if tc.wantNil {
+				if got != nil {
+					t.Errorf("AllowedMentions = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("AllowedMentions = nil")
+			}
+			if !reflect.DeepEqual(got.Parse, tc.want.Parse) {
+				t.Errorf("Parse = %v, want %v", got.Parse, tc.want.Parse)
+			}
+			if len(got.Roles) != 0 || len(tc.want.Roles) != 0 {
+				if !reflect.DeepEqual(got.Roles, tc.want.Roles) {
+					t.Errorf("Roles = %v, want %v", got.Roles, tc.want.Roles)
+				}
+			}
+			if len(got.Users) != 0 || len(tc.want.Users) != 0 {
+				if !reflect.DeepEqual(got.Users, tc.want.Users) {
+					t.Errorf("Users = %v, want %v", got.Users, tc.want.Users)
+				}
+			}
+			if got.RepliedUser != tc.want.RepliedUser {
+				t.Errorf("RepliedUser = %v, want %v", got.RepliedUser, tc.want.RepliedUser)
+			}
+		})
+	}
+}
+
+func Test_MentionPolicy_EditStripsEveryone(t *testing.T) {
+	t.Parallel()
+
+	var got *discordgo.MessageEdit
+	client := &testutil.MockDiscordClient{
+		ChannelMessageEditComplexFunc: func(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+			got = m
+			return &discordgo.Message{ID: m.ID, ChannelID: m.Channel, Content: *m.Content}, nil
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil,
+		message.WithMentionPolicy(message.MentionPolicy{DenyEveryone: true}))
+	handler := testutil.FindHandler(t, regs, "discord_edit_message")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_edit_message", map[string]any{
+		"channel":    "general",
+		"message_id": "m-1",
+		"content":    "@everyone update",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+
+	if got == nil || got.AllowedMentions == nil {
+		t.Fatal("edit sent without AllowedMentions")
+	}
+	if slices.Contains(got.AllowedMentions.Parse, discordgo.AllowedMentionTypeEveryone) {
+		t.Errorf("Parse = %v, want @everyone stripped", got.AllowedMentions.Parse)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Read/write channel filtering
+// ---------------------------------------------------------------------------
+
+func Test_ReadOnlyChannel_AllowsGetRejectsSend(t *testing.T) {
+	t.Parallel()
+
+	filter := safety.NewFilter(nil, nil,
+		safety.WithOperationFilter(safety.OpWrite, safety.NewFilter([]string{"random"}, nil)),
+	)
+	regs := message.MessageTools(context.Background(), &testutil.MockDiscordClient{}, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), filter, safety.NewConfirmationTracker(nil), nil, nil)
+
+	get := testutil.FindHandler(t, regs, "discord_get_messages")
+	result, err := get(context.Background(), testutil.NewCallToolRequest("discord_get_messages", map[string]any{"channel": "general"}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+
+	send := testutil.FindHandler(t, regs, "discord_send_message")
+	result, err = send(context.Background(), testutil.NewCallToolRequest("discord_send_message", map[string]any{"channel": "general", "content": "hi"}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected send to read-only channel to be denied, got: %s", testutil.ExtractText(t, result))
+	}
+
+	result, err = send(context.Background(), testutil.NewCallToolRequest("discord_send_message", map[string]any{"channel": "random", "content": "hi"}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+}
+
+// ---------------------------------------------------------------------------
+// discord_send_webhook handler
+// ---------------------------------------------------------------------------
+
+func Test_SendWebhook_CreatesAndCachesWebhook(t *testing.T) {
+	t.Parallel()
+
+	var lists, creates int
+	var executed []*discordgo.WebhookParams
+	client := &testutil.MockDiscordClient{
+		ChannelWebhooksFunc: func(channelID string, _ ...discordgo.RequestOption) ([]*discordgo.Webhook, error) {
+			lists++
+			// A webhook owned by someone else is not reused.
+			return []*discordgo.Webhook{{ID: "other", Name: "Other", Type: discordgo.WebhookTypeIncoming, Token: "t"}}, nil
+		},
+		WebhookCreateFunc: func(channelID, name, avatar string, _ ...discordgo.RequestOption) (*discordgo.Webhook, error) {
+			creates++
+			if channelID != "ch-001" {
+				t.Errorf("WebhookCreate channelID = %q, want ch-001", channelID)
+			}
+			return &discordgo.Webhook{ID: "wh-1", Name: name, Type: discordgo.WebhookTypeIncoming, Token: "secret"}, nil
+		},
+		WebhookExecuteFunc: func(webhookID, token string, wait bool, data *discordgo.WebhookParams, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+			if webhookID != "wh-1" || token != "secret" || !wait {
+				t.Errorf("WebhookExecute(%q, %q, %v), want (wh-1, secret, true)", webhookID, token, wait)
+			}
+			executed = append(executed, data)
+			return &discordgo.Message{ID: fmt.Sprintf("msg-%d", len(executed)), Content: data.Content, Author: &discordgo.User{ID: webhookID, Username: data.Username}}, nil
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_webhook")
+
+	for i := 0; i < 2; i++ {
+		result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_webhook", map[string]any{
+			"channel":    "general",
+			"content":    "hello",
+			"username":   "Narrator",
+			"avatar_url": "https://example.com/a.png",
+		}))
+		if err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		testutil.AssertNotError(t, result)
+	}
+
+	if lists != 1 || creates != 1 {
+		t.Errorf("ChannelWebhooks called %d times and WebhookCreate %d times, want 1 each", lists, creates)
+	}
+	if len(executed) != 2 {
+		t.Fatalf("WebhookExecute called %d times, want 2", len(executed))
+	}
+	if got := executed[0]; got.Username != "Narrator" || got.AvatarURL != "https://example.com/a.png" || got.Content != "hello" {
+		t.Errorf("WebhookParams = %+v", got)
+	}
+}
+
+func Test_SendWebhook_ConcurrentLookups(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	var creates atomic.Int32
+	client := &testutil.MockDiscordClient{
+		ChannelWebhooksFunc: func(channelID string, _ ...discordgo.RequestOption) ([]*discordgo.Webhook, error) {
+			if channelID == "ch-001" {
+				<-release
+			}
+			return nil, nil
+		},
+		WebhookCreateFunc: func(channelID, name, _ string, _ ...discordgo.RequestOption) (*discordgo.Webhook, error) {
+			creates.Add(1)
+			return &discordgo.Webhook{ID: "wh-" + channelID, Name: name, Type: discordgo.WebhookTypeIncoming, Token: "tok"}, nil
+		},
+		WebhookExecuteFunc: func(webhookID, _ string, _ bool, data *discordgo.WebhookParams, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+			return &discordgo.Message{ID: "msg-" + webhookID, Content: data.Content}, nil
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_webhook")
+	send := func(channel string) *mcp.CallToolResult {
+		result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_webhook", map[string]any{
+			"channel": channel,
+			"content": "hello",
+		}))
+		if err != nil {
+			t.Errorf("handler error: %v", err)
+		}
+		return result
+	}
+
+	// Two sends to general wait on one blocked lookup.
+	results := make(chan *mcp.CallToolResult, 2)
+	for range 2 {
+		go func() { results <- send("general") }()
+	}
+
+	// A send to another channel is not held up by it.
+	testutil.AssertNotError(t, send("random"))
+
+	close(release)
+	for range 2 {
+		testutil.AssertNotError(t, <-results)
+	}
+	if n := creates.Load(); n != 2 {
+		t.Errorf("WebhookCreate called %d times, want once per channel", n)
+	}
+}
+
+func Test_SendWebhook_ReusesExistingWebhook(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		ChannelWebhooksFunc: func(string, ...discordgo.RequestOption) ([]*discordgo.Webhook, error) {
+			return []*discordgo.Webhook{{ID: "wh-existing", Name: "claudebot-mcp", Type: discordgo.WebhookTypeIncoming, Token: "tok"}}, nil
+		},
+		WebhookCreateFunc: func(string, string, string, ...discordgo.RequestOption) (*discordgo.Webhook, error) {
+			t.Error("WebhookCreate should not be called when the bot's webhook exists")
+			return nil, fmt.Errorf("unexpected")
+		},
+	}
+	var usedID string
+	client.WebhookExecuteFunc = func(webhookID, _ string, _ bool, data *discordgo.WebhookParams, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+		usedID = webhookID
+		return &discordgo.Message{ID: "msg-1", Content: data.Content}, nil
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_webhook")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_webhook", map[string]any{
+		"channel": "general",
+		"content": "hello",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+	if usedID != "wh-existing" {
+		t.Errorf("webhook used = %q, want wh-existing", usedID)
+	}
+}
+
+func Test_SendWebhook_RecreatesDeletedWebhook(t *testing.T) {
+	t.Parallel()
+
+	creates, executes := 0, 0
+	client := &testutil.MockDiscordClient{
+		WebhookCreateFunc: func(channelID, name, _ string, _ ...discordgo.RequestOption) (*discordgo.Webhook, error) {
+			creates++
+			return &discordgo.Webhook{ID: fmt.Sprintf("wh-%d", creates), Name: name, Type: discordgo.WebhookTypeIncoming, Token: "tok"}, nil
+		},
+		WebhookExecuteFunc: func(webhookID, _ string, _ bool, _ *discordgo.WebhookParams, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+			executes++
+			if webhookID == "wh-1" {
+				return nil, &discordgo.RESTError{Response: &http.Response{StatusCode: http.StatusNotFound}}
+			}
+			return &discordgo.Message{ID: "msg-1"}, nil
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_send_webhook")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_webhook", map[string]any{
+		"channel": "general",
+		"content": "hello",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+	if creates != 2 || executes != 2 {
+		t.Errorf("creates = %d, executes = %d; want 2 each", creates, executes)
+	}
+}
+
+func Test_SendWebhook_Errors(t *testing.T) {
+	t.Parallel()
+
+	forbidden := func(string, ...discordgo.RequestOption) ([]*discordgo.Webhook, error) {
+		return nil, &discordgo.RESTError{Response: &http.Response{StatusCode: http.StatusForbidden}}
+	}
+
+	tests := []struct {
+		name      string
+		args      map[string]any
+		listFunc  func(string, ...discordgo.RequestOption) ([]*discordgo.Webhook, error)
+		filter    *safety.Filter
+		wantInErr string
+	}{
+		{name: "missing permission", args: map[string]any{"content": "hi"}, listFunc: forbidden, wantInErr: "Manage Webhooks"},
+		{name: "denied channel", args: map[string]any{"content": "hi"}, filter: safety.NewFilter(nil, []string{"general"}), wantInErr: "not allowed"},
+		{name: "empty content", args: map[string]any{"content": ""}, wantInErr: "content"},
+		{name: "content too long", args: map[string]any{"content": strings.Repeat("x", 2001)}, wantInErr: "maximum is 2000"},
+		{name: "username too long", args: map[string]any{"content": "hi", "username": strings.Repeat("u", 81)}, wantInErr: "maximum is 80"},
+		{name: "reserved username", args: map[string]any{"content": "hi", "username": "Discord Bot"}, wantInErr: "must not contain"},
+		{name: "bad avatar url", args: map[string]any{"content": "hi", "avatar_url": "ftp://example.com/a.png"}, wantInErr: "avatar_url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &testutil.MockDiscordClient{
+				ChannelWebhooksFunc: tt.listFunc,
+				WebhookExecuteFunc: func(string, string, bool, *discordgo.WebhookParams, ...discordgo.RequestOption) (*discordgo.Message, error) {
+					t.Error("WebhookExecute should not be called")
+					return nil, fmt.Errorf("unexpected")
+				},
+			}
+			filter := tt.filter
+			if filter == nil {
+				filter = safety.NewFilter(nil, nil)
+			}
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), filter, safety.NewConfirmationTracker(nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_send_webhook")
+
+			args := map[string]any{"channel": "general"}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_send_webhook", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+			testutil.AssertTextContains(t, result, tt.wantInErr)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_move_to_thread handler
+// ---------------------------------------------------------------------------
+
+func Test_MoveToThread_WithStarter(t *testing.T) {
+	t.Parallel()
+
+	var gotChannel, gotMessage, gotName, sentTo, sentContent string
+	client := &testutil.MockDiscordClient{
+		MessageThreadStartComplexFunc: func(channelID, messageID string, data *discordgo.ThreadStart, _ ...discordgo.RequestOption) (*discordgo.Channel, error) {
+			gotChannel, gotMessage, gotName = channelID, messageID, data.Name
+			return &discordgo.Channel{ID: "thread-42", Name: data.Name, ParentID: channelID}, nil
+		},
+		ChannelMessageSendComplexFunc: func(channelID string, data *discordgo.MessageSend, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+			sentTo, sentContent = channelID, data.Content
+			return &discordgo.Message{ID: "starter-1", ChannelID: channelID}, nil
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_move_to_thread")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_move_to_thread", map[string]any{
+		"channel":         "general",
+		"message_id":      "msg-100",
+		"thread_name":     "  Login help  ",
+		"starter_content": "Let's continue here.",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+
+	if gotChannel != "ch-001" || gotMessage != "msg-100" || gotName != "Login help" {
+		t.Errorf("thread started with (%q, %q, %q), want (ch-001, msg-100, Login help)", gotChannel, gotMessage, gotName)
+	}
+	if sentTo != "thread-42" || sentContent != "Let's continue here." {
+		t.Errorf("starter sent to %q with %q, want thread-42 with the starter content", sentTo, sentContent)
+	}
+
+	var got message.ThreadResult
+	if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	want := message.ThreadResult{ThreadID: "thread-42", Name: "Login help", ParentID: "ch-001", MessageID: "msg-100", StarterMessageID: "starter-1"}
+	if got != want {
+		t.Errorf("result = %+v, want %+v", got, want)
+	}
+}
+
+func Test_MoveToThread_WithoutStarter(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		ChannelMessageSendComplexFunc: func(string, *discordgo.MessageSend, ...discordgo.RequestOption) (*discordgo.Message, error) {
+			t.Error("no message should be sent without starter_content")
+			return nil, fmt.Errorf("unexpected")
+		},
+	}
+	regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), safety.NewFilter(nil, nil), safety.NewConfirmationTracker(nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_move_to_thread")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_move_to_thread", map[string]any{
+		"channel":     "general",
+		"message_id":  "msg-100",
+		"thread_name": "Question",
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertNotError(t, result)
+	testutil.AssertTextContains(t, result, "thread-001")
+}
+
+func Test_MoveToThread_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		args      map[string]any
+		filter    *safety.Filter
+		startErr  error
+		wantInErr string
+		wantCode  tools.ErrorCode
+	}{
+		{name: "denied channel", args: map[string]any{}, filter: safety.NewFilter(nil, []string{"general"}), wantInErr: "not allowed", wantCode: tools.CodeChannelNotAllowed},
+		{name: "empty thread name", args: map[string]any{"thread_name": " "}, wantInErr: "thread_name", wantCode: tools.CodeInvalidArgument},
+		{name: "thread name too long", args: map[string]any{"thread_name": strings.Repeat("n", 101)}, wantInErr: "maximum is 100", wantCode: tools.CodeInvalidArgument},
+		{name: "starter too long", args: map[string]any{"starter_content": strings.Repeat("x", 2001)}, wantInErr: "maximum is 2000", wantCode: tools.CodeInvalidArgument},
+		{
+			name: "unknown message",
+			args: map[string]any{},
+			startErr: &discordgo.RESTError{
+				Response: &http.Response{StatusCode: http.StatusNotFound},
+				Message:  &discordgo.APIErrorMessage{Code: discordgo.ErrCodeUnknownMessage},
+			},
+			wantInErr: "starting thread",
+			wantCode:  tools.CodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			starts := 0
+			client := &testutil.MockDiscordClient{
+				MessageThreadStartComplexFunc: func(string, string, *discordgo.ThreadStart, ...discordgo.RequestOption) (*discordgo.Channel, error) {
+					starts++
+					if tt.startErr != nil {
+						return nil, tt.startErr
+					}
+					return &discordgo.Channel{ID: "thread-1"}, nil
+				},
+			}
+			filter := tt.filter
+			if filter == nil {
+				filter = safety.NewFilter(nil, nil)
+			}
+			regs := message.MessageTools(context.Background(), client, queue.New(), message.PollConfig{}, testutil.NewMockChannelResolver(), filter, safety.NewConfirmationTracker(nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_move_to_thread")
+
+			args := map[string]any{"channel": "general", "message_id": "msg-100", "thread_name": "Question"}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_move_to_thread", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			testutil.AssertTextContains(t, result, tt.wantInErr)
+			if te := testutil.ToolError(t, result); te.Code != tt.wantCode {
+				t.Errorf("error code = %s, want %s", te.Code, tt.wantCode)
+			}
+			if tt.startErr == nil && starts != 0 {
+				t.Errorf("thread started %d times, want 0 for rejected input", starts)
+			}
+		})
 	}
-	return after[:endIdx]
 }
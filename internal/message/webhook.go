@@ -0,0 +1,133 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+)
+
+// webhookName is the name given to the webhooks the bot creates. Existing
+// webhooks with this name are reused rather than creating a new one per
+// restart.
+const webhookName = "claudebot-mcp"
+
+// errMissingWebhookPermission is returned when the bot may not list or create
+// webhooks in a channel.
+var errMissingWebhookPermission = tools.WithCode(tools.CodePermissionDenied, errors.New("the bot needs the Manage Webhooks permission in this channel to send as a webhook"))
+
+// webhookCache maps channel IDs to the bot-owned webhook used to send in
+// them, so each channel's webhook is looked up or created only once. It is
+// safe for concurrent use.
+type webhookCache struct {
+	dg        discord.DiscordClient
+	mu        sync.Mutex
+	byChannel map[string]*discordgo.Webhook
+	// pending holds the lookup in progress for each channel, so concurrent
+	// sends to a new channel share one and create a single webhook.
+	pending map[string]*webhookLookup
+}
+
+// webhookLookup is a lookup shared by the get calls waiting on it. wh and
+// err are set before done is closed.
+type webhookLookup struct {
+	done chan struct{}
+	wh   *discordgo.Webhook
+	err  error
+}
+
+// newWebhookCache returns an empty webhookCache.
+func newWebhookCache(dg discord.DiscordClient) *webhookCache {
+	return &webhookCache{
+		dg:        dg,
+		byChannel: make(map[string]*discordgo.Webhook),
+		pending:   make(map[string]*webhookLookup),
+	}
+}
+
+// get returns the bot's webhook for channelID, reusing an existing incoming
+// webhook named webhookName or creating one. The Discord calls run without
+// the lock held, so a slow lookup in one channel does not delay sends to
+// others; calls for the same channel wait for the first, or until their ctx
+// is done.
+func (c *webhookCache) get(ctx context.Context, channelID string) (*discordgo.Webhook, error) {
+	c.mu.Lock()
+	if wh, ok := c.byChannel[channelID]; ok {
+		c.mu.Unlock()
+		return wh, nil
+	}
+	if l, ok := c.pending[channelID]; ok {
+		c.mu.Unlock()
+		select {
+		case <-l.done:
+			return l.wh, l.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	l := &webhookLookup{done: make(chan struct{})}
+	c.pending[channelID] = l
+	c.mu.Unlock()
+
+	l.wh, l.err = c.lookup(ctx, channelID)
+
+	c.mu.Lock()
+	if l.err == nil {
+		c.byChannel[channelID] = l.wh
+	}
+	delete(c.pending, channelID)
+	c.mu.Unlock()
+	close(l.done)
+	return l.wh, l.err
+}
+
+// lookup finds the bot's webhook in channelID or creates one.
+func (c *webhookCache) lookup(ctx context.Context, channelID string) (*discordgo.Webhook, error) {
+	hooks, err := c.dg.ChannelWebhooks(channelID, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, webhookError("listing webhooks", err)
+	}
+	for _, wh := range hooks {
+		if wh != nil && wh.Name == webhookName && wh.Type == discordgo.WebhookTypeIncoming && wh.Token != "" {
+			return wh, nil
+		}
+	}
+
+	wh, err := c.dg.WebhookCreate(channelID, webhookName, "", discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, webhookError("creating webhook", err)
+	}
+	return wh, nil
+}
+
+// forget drops the cached webhook for channelID, e.g. after it was deleted
+// in Discord, so the next get looks it up again.
+func (c *webhookCache) forget(channelID string) {
+	c.mu.Lock()
+	delete(c.byChannel, channelID)
+	c.mu.Unlock()
+}
+
+// webhookError wraps err from a webhook management call, replacing a 403
+// response with errMissingWebhookPermission.
+func webhookError(action string, err error) error {
+	if restStatus(err) == http.StatusForbidden {
+		return errMissingWebhookPermission
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// restStatus returns the HTTP status of a Discord REST error, or 0 when err
+// is not one.
+func restStatus(err error) int {
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		return restErr.Response.StatusCode
+	}
+	return 0
+}
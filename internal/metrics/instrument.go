@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Instrument wraps every registration's handler so each call is recorded in
+// m with its outcome and latency. A nil m returns registrations unchanged.
+func Instrument(m *Metrics, registrations []tools.Registration) []tools.Registration {
+	if m == nil {
+		return registrations
+	}
+	out := make([]tools.Registration, 0, len(registrations))
+	for _, reg := range registrations {
+		toolName := reg.Tool.Name
+		next := reg.Handler
+		handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, req)
+			m.ObserveToolCall(toolName, outcome(result, err), time.Since(start))
+			return result, err
+		}
+		out = append(out, tools.Registration{Tool: reg.Tool, Handler: server.ToolHandlerFunc(handler)})
+	}
+	return out
+}
+
+// outcome classifies a handler's return values into an outcome label.
+func outcome(result *mcp.CallToolResult, err error) string {
+	switch {
+	case err != nil:
+		return OutcomeError
+	case tools.IsDenied(result):
+		return OutcomeDenied
+	case result != nil && result.IsError:
+		return OutcomeError
+	default:
+		return OutcomeOK
+	}
+}
@@ -0,0 +1,159 @@
+// Package metrics records operational metrics for claudebot-mcp and exposes
+// them in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Outcome labels recorded for tool calls.
+const (
+	OutcomeOK     = "ok"
+	OutcomeError  = "error"
+	OutcomeDenied = "denied"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the tool call latency
+// histogram. The tail extends to the maximum long-poll duration.
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// QueueStats is the subset of *queue.Queue read at scrape time.
+type QueueStats interface {
+	Len() int
+	Cap() int
+	Enqueued() uint64
+}
+
+// callKey identifies a tool call counter series.
+type callKey struct {
+	tool    string
+	outcome string
+}
+
+// histogram is a cumulative latency histogram for a single tool.
+type histogram struct {
+	counts []uint64 // per-bucket counts, parallel to durationBuckets
+	count  uint64
+	sum    float64
+}
+
+// Metrics collects tool call and queue metrics. It is safe for concurrent use.
+// A nil *Metrics ignores all observations.
+type Metrics struct {
+	queue QueueStats
+
+	mu        sync.Mutex
+	calls     map[callKey]uint64
+	durations map[string]*histogram
+}
+
+// New returns an empty Metrics. When q is non-nil, queue depth, capacity, and
+// enqueued totals are read from it on every scrape.
+func New(q QueueStats) *Metrics {
+	return &Metrics{
+		queue:     q,
+		calls:     make(map[callKey]uint64),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// ObserveToolCall records a completed tool invocation.
+func (m *Metrics) ObserveToolCall(tool, outcome string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	secs := d.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls[callKey{tool: tool, outcome: outcome}]++
+
+	h, ok := m.durations[tool]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(durationBuckets))}
+		m.durations[tool] = h
+	}
+	for i, upper := range durationBuckets {
+		if secs <= upper {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += secs
+}
+
+// Write writes all metrics to w in the Prometheus text exposition format.
+func (m *Metrics) Write(w io.Writer) error {
+	cw := bufio.NewWriter(w)
+
+	if m.queue != nil {
+		writeHeader(cw, "claudebot_messages_enqueued_total", "Total messages received from Discord and enqueued.", "counter")
+		fmt.Fprintf(cw, "claudebot_messages_enqueued_total %d\n", m.queue.Enqueued())
+		writeHeader(cw, "claudebot_queue_depth", "Messages currently waiting in the queue.", "gauge")
+		fmt.Fprintf(cw, "claudebot_queue_depth %d\n", m.queue.Len())
+		writeHeader(cw, "claudebot_queue_capacity", "Maximum number of messages the queue can hold.", "gauge")
+		fmt.Fprintf(cw, "claudebot_queue_capacity %d\n", m.queue.Cap())
+	}
+
+	m.mu.Lock()
+	keys := make([]callKey, 0, len(m.calls))
+	for k := range m.calls {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].tool != keys[j].tool {
+			return keys[i].tool < keys[j].tool
+		}
+		return keys[i].outcome < keys[j].outcome
+	})
+	writeHeader(cw, "claudebot_tool_calls_total", "Tool invocations by tool name and outcome.", "counter")
+	for _, k := range keys {
+		fmt.Fprintf(cw, "claudebot_tool_calls_total{tool=%q,outcome=%q} %d\n", k.tool, k.outcome, m.calls[k])
+	}
+
+	tools := make([]string, 0, len(m.durations))
+	for tool := range m.durations {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	writeHeader(cw, "claudebot_tool_call_duration_seconds", "Tool handler latency in seconds.", "histogram")
+	for _, tool := range tools {
+		h := m.durations[tool]
+		for i, upper := range durationBuckets {
+			fmt.Fprintf(cw, "claudebot_tool_call_duration_seconds_bucket{tool=%q,le=%q} %d\n", tool, formatFloat(upper), h.counts[i])
+		}
+		fmt.Fprintf(cw, "claudebot_tool_call_duration_seconds_bucket{tool=%q,le=\"+Inf\"} %d\n", tool, h.count)
+		fmt.Fprintf(cw, "claudebot_tool_call_duration_seconds_sum{tool=%q} %s\n", tool, formatFloat(h.sum))
+		fmt.Fprintf(cw, "claudebot_tool_call_duration_seconds_count{tool=%q} %d\n", tool, h.count)
+	}
+	m.mu.Unlock()
+
+	return cw.Flush()
+}
+
+// Handler returns an http.Handler that serves the metrics in the Prometheus
+// text exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = m.Write(w)
+	})
+}
+
+// writeHeader writes the HELP and TYPE lines for a metric family.
+func writeHeader(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// formatFloat renders f in the shortest form that round-trips.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
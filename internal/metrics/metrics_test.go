@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fakeQueue is a fixed QueueStats implementation.
+type fakeQueue struct {
+	length, capacity int
+	enqueued         uint64
+}
+
+func (f fakeQueue) Len() int         { return f.length }
+func (f fakeQueue) Cap() int         { return f.capacity }
+func (f fakeQueue) Enqueued() uint64 { return f.enqueued }
+
+// scrape fetches /metrics from an httptest server backed by m.
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	srv := httptest.NewServer(m.Handler())
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	return string(body)
+}
+
+// ---------------------------------------------------------------------------
+// Handler
+// ---------------------------------------------------------------------------
+
+func Test_Handler_ExposesMetrics(t *testing.T) {
+	t.Parallel()
+
+	m := New(fakeQueue{length: 3, capacity: 1000, enqueued: 7})
+	m.ObserveToolCall("discord_send_message", OutcomeOK, 20*time.Millisecond)
+	m.ObserveToolCall("discord_send_message", OutcomeOK, 40*time.Millisecond)
+	m.ObserveToolCall("discord_send_message", OutcomeDenied, time.Millisecond)
+
+	body := scrape(t, m)
+
+	for _, want := range []string{
+		"# TYPE claudebot_messages_enqueued_total counter",
+		"claudebot_messages_enqueued_total 7",
+		"claudebot_queue_depth 3",
+		"claudebot_queue_capacity 1000",
+		"# TYPE claudebot_tool_calls_total counter",
+		`claudebot_tool_calls_total{tool="discord_send_message",outcome="ok"} 2`,
+		`claudebot_tool_calls_total{tool="discord_send_message",outcome="denied"} 1`,
+		"# TYPE claudebot_tool_call_duration_seconds histogram",
+		`claudebot_tool_call_duration_seconds_bucket{tool="discord_send_message",le="0.025"} 2`,
+		`claudebot_tool_call_duration_seconds_bucket{tool="discord_send_message",le="+Inf"} 3`,
+		`claudebot_tool_call_duration_seconds_count{tool="discord_send_message"} 3`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape output missing %q\n%s", want, body)
+		}
+	}
+}
+
+func Test_Handler_NilQueue(t *testing.T) {
+	t.Parallel()
+
+	body := scrape(t, New(nil))
+	if strings.Contains(body, "claudebot_queue_depth") {
+		t.Errorf("queue metrics should be omitted without a queue:\n%s", body)
+	}
+}
+
+func Test_ObserveToolCall_NilReceiver(t *testing.T) {
+	t.Parallel()
+	var m *Metrics
+	m.ObserveToolCall("tool", OutcomeOK, time.Second) // must not panic
+}
+
+// ---------------------------------------------------------------------------
+// Instrument
+// ---------------------------------------------------------------------------
+
+func Test_Instrument_RecordsOutcomes(t *testing.T) {
+	t.Parallel()
+
+	results := map[string]func() (*mcp.CallToolResult, error){
+		"ok_tool":     func() (*mcp.CallToolResult, error) { return mcp.NewToolResultText("fine"), nil },
+		"error_tool":  func() (*mcp.CallToolResult, error) { return tools.ErrorResult("boom"), nil },
+		"denied_tool": func() (*mcp.CallToolResult, error) { return tools.DeniedResult("secret"), nil },
+		"failed_tool": func() (*mcp.CallToolResult, error) { return nil, errors.New("transport") },
+	}
+
+	var regs []tools.Registration
+	for name, fn := range results {
+		regs = append(regs, tools.Registration{
+			Tool: mcp.NewTool(name),
+			Handler: server.ToolHandlerFunc(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return fn()
+			}),
+		})
+	}
+
+	m := New(nil)
+	for _, reg := range Instrument(m, regs) {
+		_, _ = reg.Handler(context.Background(), mcp.CallToolRequest{})
+	}
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`claudebot_tool_calls_total{tool="ok_tool",outcome="ok"} 1`,
+		`claudebot_tool_calls_total{tool="error_tool",outcome="error"} 1`,
+		`claudebot_tool_calls_total{tool="denied_tool",outcome="denied"} 1`,
+		`claudebot_tool_calls_total{tool="failed_tool",outcome="error"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape output missing %q\n%s", want, body)
+		}
+	}
+}
+
+func Test_Instrument_NilMetrics(t *testing.T) {
+	t.Parallel()
+
+	regs := []tools.Registration{{Tool: mcp.NewTool("t")}}
+	if got := Instrument(nil, regs); &got[0] != &regs[0] {
+		t.Error("Instrument(nil, regs) should return regs unchanged")
+	}
+}
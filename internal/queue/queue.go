@@ -3,15 +3,62 @@
 package queue
 
 import (
+	"cmp"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 )
 
-// QueuedMessage represents a single Discord message captured from a guild channel.
+// ErrQueueFull is returned by Enqueue under OverflowBlock when no space frees
+// up within the block timeout. The message is discarded.
+var ErrQueueFull = errors.New("queue: full")
+
+// ErrUnknownDelivery is returned by Ack and Nack for a delivery token that is
+// not in flight: it was never issued, was already acknowledged, or its
+// visibility timeout expired and the message became visible again.
+var ErrUnknownDelivery = errors.New("queue: unknown or expired delivery token")
+
+// OverflowPolicy controls what Enqueue does when the queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowDrop discards the oldest queued message to make room. This is
+	// the default.
+	OverflowDrop OverflowPolicy = "drop"
+	// OverflowBlock makes Enqueue wait for a Poll to free space, up to the
+	// block timeout, and discard the new message if none frees up.
+	OverflowBlock OverflowPolicy = "block"
+)
+
+// defaultBlockTimeout bounds how long Enqueue waits under OverflowBlock.
+const defaultBlockTimeout = 2 * time.Second
+
+// EventType distinguishes the kinds of gateway event delivered through the
+// queue. Ordinary messages have an empty EventType so their JSON shape is
+// unchanged.
+type EventType string
+
+const (
+	// EventMessage is a message posted in a channel.
+	EventMessage EventType = ""
+	// EventTyping reports that a user started typing in a channel. It has no
+	// ID or Content.
+	EventTyping EventType = "typing"
+)
+
+// QueuedMessage represents a single Discord message, or another channel
+// event such as a user starting to type, captured from a guild channel.
+// Timestamp is normalized to UTC at ingestion so it serializes as RFC 3339
+// with a Z suffix.
 type QueuedMessage struct {
+	Type             EventType `json:"type,omitempty"`
 	ID               string    `json:"id"`
+	GuildID          string    `json:"guild_id"`
 	ChannelID        string    `json:"channel_id"`
 	ChannelName      string    `json:"channel_name"`
 	AuthorID         string    `json:"author_id"`
@@ -19,11 +66,48 @@ type QueuedMessage struct {
 	Content          string    `json:"content"`
 	Timestamp        time.Time `json:"timestamp"`
 	MessageReference string    `json:"message_reference,omitempty"`
+	// RawContent is the content as Discord sent it, set only when mention
+	// expansion at ingestion rewrote Content.
+	RawContent string `json:"raw_content,omitempty"`
+	// ReplyToAuthor and ReplyToContent summarize the message this one replies
+	// to when reply context is enabled at ingestion. Both are empty if the
+	// referenced message was deleted or is unavailable.
+	ReplyToAuthor  string `json:"reply_to_author,omitempty"`
+	ReplyToContent string `json:"reply_to_content,omitempty"`
+	// Command and Args hold a bot-style command parsed from Content, such as
+	// "say" and ["hello", "world"] for "!say hello world", when a command
+	// prefix is configured at ingestion. Both are empty for other messages.
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	// Priority selects the delivery lane when the queue has priority lanes
+	// enabled; it is ignored otherwise.
+	Priority Priority `json:"priority,omitempty"`
+	// DeliveryToken identifies this delivery when the queue is in
+	// acknowledgment mode; pass it to Ack once the message is processed.
+	// Deliveries counts how many times the message has been handed out, so
+	// a value above one marks a redelivery. Both are empty otherwise.
+	DeliveryToken string `json:"delivery_token,omitempty"`
+	Deliveries    int    `json:"deliveries,omitempty"`
 }
 
+// Priority is the delivery priority of a queued message.
+type Priority int
+
+const (
+	// PriorityNormal is the default priority for ordinary chatter.
+	PriorityNormal Priority = iota
+	// PriorityHigh marks messages, such as ones that mention the bot, that
+	// are delivered before any normal-priority message.
+	PriorityHigh
+)
+
 // Formatted returns a human-readable representation of the message in the
-// form "[#channel] @user: text".
+// form "[#channel] @user: text", or "[#channel] @user is typing" for a typing
+// event.
 func (m QueuedMessage) Formatted() string {
+	if m.Type == EventTyping {
+		return fmt.Sprintf("[#%s] @%s is typing", m.ChannelName, m.AuthorUsername)
+	}
 	return fmt.Sprintf("[#%s] @%s: %s", m.ChannelName, m.AuthorUsername, m.Content)
 }
 
@@ -40,108 +124,473 @@ func WithMaxSize(n int) Option {
 	}
 }
 
+// WithDedup drops a message whose ID matches one of the last window message
+// IDs enqueued, guarding against duplicate MessageCreate events delivered
+// during gateway reconnects. Memory use is bounded by window. Values of zero
+// or less leave deduplication disabled.
+func WithDedup(window int) Option {
+	return func(q *Queue) {
+		if window > 0 {
+			q.dedupWindow = window
+		}
+	}
+}
+
+// WithOverflowPolicy sets the behaviour of Enqueue when the queue is full.
+// An empty policy is ignored and OverflowDrop is used.
+//
+// OverflowBlock applies backpressure instead of losing old messages, but the
+// Discord gateway delivers events on a single goroutine, so a blocked Enqueue
+// stalls all event handling until space frees or the block timeout expires.
+// Keep the timeout short; see WithBlockTimeout.
+func WithOverflowPolicy(p OverflowPolicy) Option {
+	return func(q *Queue) {
+		if p != "" {
+			q.overflow = p
+		}
+	}
+}
+
+// WithBlockTimeout sets the maximum time Enqueue waits for space under
+// OverflowBlock. Values of zero or less are ignored; the default of two
+// seconds is used instead.
+func WithBlockTimeout(d time.Duration) Option {
+	return func(q *Queue) {
+		if d > 0 {
+			q.blockTimeout = d
+		}
+	}
+}
+
+// WithPriorityLanes splits the queue into a high- and a normal-priority lane.
+// Poll drains PriorityHigh messages first while preserving FIFO order within
+// each lane. When disabled (the default), Priority is ignored and the queue
+// is a single FIFO.
+func WithPriorityLanes(enabled bool) Option {
+	return func(q *Queue) {
+		q.priorityLanes = enabled
+	}
+}
+
+// WithAckTimeout enables acknowledgment mode. Poll and Drain then hand out
+// each message with a DeliveryToken and keep it in flight rather than
+// forgetting it: Ack removes it for good and Nack makes it visible again at
+// once. A message neither acked nor nacked within d becomes visible again at
+// the head of its lane and is redelivered with a new token. Values of zero or
+// less leave acknowledgment mode disabled, so each message is delivered at
+// most once.
+func WithAckTimeout(d time.Duration) Option {
+	return func(q *Queue) {
+		if d > 0 {
+			q.ackTimeout = d
+		}
+	}
+}
+
+// WithPerChannelMax caps how many queued messages any one channel may hold,
+// so a flood in a busy channel cannot evict other channels' messages. A
+// message arriving in a channel at its cap discards that channel's oldest
+// queued message instead, whatever the overflow policy, and never blocks.
+// Messages are counted by ChannelID. Values of zero or less leave channels
+// limited only by the queue's maximum size.
+func WithPerChannelMax(n int) Option {
+	return func(q *Queue) {
+		if n > 0 {
+			q.perChannelMax = n
+		}
+	}
+}
+
 // Queue is a thread-safe, bounded FIFO ring-buffer queue. When the buffer is
 // full, the oldest message is silently dropped to make room for the new one.
 // Callers waiting in Poll are notified via a broadcast channel whenever a new
-// message is enqueued.
+// message is enqueued. The channel is only replaced while a Poll is waiting,
+// so enqueueing with no pollers blocked allocates nothing for notification.
+//
+// With priority lanes enabled the queue holds one ring per lane, ordered from
+// highest priority to lowest. The maximum size applies to all lanes combined,
+// and on overflow the oldest message of the lowest non-empty lane is dropped.
 type Queue struct {
-	mu      sync.Mutex
-	buf     []QueuedMessage
-	head    int
-	count   int
-	maxSize int
+	mu            sync.Mutex
+	lanes         []*ring
+	count         int
+	maxSize       int
+	priorityLanes bool
+	// notify is closed and replaced to wake every Poll blocked on it.
+	// waiters counts those Polls; with none, Enqueue leaves notify alone.
 	notify  chan struct{}
+	waiters int
+	// overflow and blockTimeout configure Enqueue on a full queue. freed is
+	// closed and replaced whenever Poll removes messages, waking blocked
+	// Enqueue calls.
+	overflow     OverflowPolicy
+	blockTimeout time.Duration
+	freed        chan struct{}
+	// enqueued counts every message ever enqueued, including ones later
+	// dropped due to overflow.
+	enqueued uint64
+
+	// dedupWindow is the number of recent message IDs remembered for
+	// deduplication; zero disables it. recentIDs is a ring of those IDs and
+	// seen indexes them for lookup.
+	dedupWindow int
+	recentIDs   []string
+	recentPos   int
+	seen        map[string]struct{}
+
+	// perChannelMax caps the queued messages of one channel when positive;
+	// perChannel counts them by ChannelID and is nil when there is no cap.
+	perChannelMax int
+	perChannel    map[string]int
+
+	// ackTimeout enables acknowledgment mode when positive. inflight holds
+	// messages delivered but not yet acked, keyed by delivery token;
+	// deliveries numbers them so expired messages are requeued in the order
+	// they were first handed out. now is the clock, replaced by tests.
+	ackTimeout time.Duration
+	inflight   map[string]*delivery
+	deliveries uint64
+	now        func() time.Time
+}
+
+// delivery is a message handed out in acknowledgment mode and not yet acked.
+type delivery struct {
+	msg      QueuedMessage
+	seq      uint64
+	deadline time.Time
 }
 
 // New constructs a Queue with the provided options applied. The default
 // maximum size is 1000 messages.
 func New(opts ...Option) *Queue {
 	q := &Queue{
-		maxSize: 1000,
-		notify:  make(chan struct{}),
+		maxSize:      1000,
+		notify:       make(chan struct{}),
+		overflow:     OverflowDrop,
+		blockTimeout: defaultBlockTimeout,
+		freed:        make(chan struct{}),
+		now:          time.Now,
 	}
 	for _, opt := range opts {
 		opt(q)
 	}
-	q.buf = make([]QueuedMessage, q.maxSize)
+	q.lanes = []*ring{newRing(q.maxSize)}
+	if q.priorityLanes {
+		q.lanes = append(q.lanes, newRing(q.maxSize))
+	}
+	if q.dedupWindow > 0 {
+		q.recentIDs = make([]string, q.dedupWindow)
+		q.seen = make(map[string]struct{}, q.dedupWindow)
+	}
+	if q.ackTimeout > 0 {
+		q.inflight = make(map[string]*delivery)
+	}
+	if q.perChannelMax > 0 {
+		q.perChannel = make(map[string]int)
+	}
 	return q
 }
 
-// Enqueue adds msg to the tail of the queue. If the queue is full, the oldest
-// message (at head) is discarded to accommodate the new one. Enqueue never
-// blocks and wakes all goroutines currently blocked in Poll.
-func (q *Queue) Enqueue(msg QueuedMessage) {
+// Enqueue adds msg to the tail of its lane and wakes all goroutines currently
+// blocked in Poll. With WithDedup, a message whose ID was seen recently is
+// silently discarded.
+//
+// When the queue is full, the policy set by WithOverflowPolicy applies. Under
+// OverflowDrop (the default) the oldest message of the lowest-priority
+// non-empty lane is discarded and Enqueue never blocks. Under OverflowBlock,
+// Enqueue waits up to the block timeout for space and returns ErrQueueFull,
+// discarding msg, if none frees up. With WithPerChannelMax, a message whose
+// channel is at its cap instead discards that channel's oldest message.
+func (q *Queue) Enqueue(msg QueuedMessage) error {
 	q.mu.Lock()
 
-	if q.count == q.maxSize {
-		// Drop the oldest message by advancing head.
-		q.head = (q.head + 1) % q.maxSize
-		q.count--
+	if q.count == q.maxSize && q.overflow == OverflowBlock && !q.atChannelCap(msg.ChannelID) {
+		if err := q.waitForSpace(); err != nil {
+			q.mu.Unlock()
+			return err
+		}
+	}
+
+	if q.isDuplicate(msg.ID) {
+		q.mu.Unlock()
+		return nil
+	}
+
+	switch {
+	case q.atChannelCap(msg.ChannelID):
+		q.dropOldestIn(msg.ChannelID)
+	case q.count == q.maxSize:
+		q.dropOldest()
 	}
 
-	tail := (q.head + q.count) % q.maxSize
-	q.buf[tail] = msg
+	q.laneFor(msg.Priority).push(msg)
 	q.count++
+	q.countChannel(msg.ChannelID, 1)
+	q.enqueued++
 
 	// Broadcast to all waiters: close the old channel and replace it.
-	oldNotify := q.notify
-	q.notify = make(chan struct{})
+	var oldNotify chan struct{}
+	if q.waiters > 0 {
+		oldNotify = q.notify
+		q.notify = make(chan struct{})
+	}
 
 	q.mu.Unlock()
 
-	close(oldNotify)
+	if oldNotify != nil {
+		close(oldNotify)
+	}
+	return nil
+}
+
+// waitForSpace blocks until the queue has room or the block timeout expires.
+// The caller must hold q.mu; it is released while waiting and held again on
+// return.
+func (q *Queue) waitForSpace() error {
+	timer := time.NewTimer(q.blockTimeout)
+	defer timer.Stop()
+
+	for q.count == q.maxSize {
+		freed := q.freed
+		q.mu.Unlock()
+		select {
+		case <-freed:
+			q.mu.Lock()
+		case <-timer.C:
+			q.mu.Lock()
+			if q.count == q.maxSize {
+				return ErrQueueFull
+			}
+		}
+	}
+	return nil
+}
+
+// isDuplicate reports whether id was among the last dedupWindow IDs and, if
+// not, records it, evicting the oldest remembered ID. Empty IDs are never
+// treated as duplicates. The caller must hold q.mu.
+func (q *Queue) isDuplicate(id string) bool {
+	if q.seen == nil || id == "" {
+		return false
+	}
+	if _, ok := q.seen[id]; ok {
+		return true
+	}
+	if old := q.recentIDs[q.recentPos]; old != "" {
+		delete(q.seen, old)
+	}
+	q.recentIDs[q.recentPos] = id
+	q.recentPos = (q.recentPos + 1) % q.dedupWindow
+	q.seen[id] = struct{}{}
+	return false
+}
+
+// laneFor returns the ring that holds messages of priority p. The caller must
+// hold q.mu.
+func (q *Queue) laneFor(p Priority) *ring {
+	if len(q.lanes) == 1 || p == PriorityHigh {
+		return q.lanes[0]
+	}
+	return q.lanes[1]
+}
+
+// dropOldest discards the oldest message from the lowest-priority non-empty
+// lane. The caller must hold q.mu.
+func (q *Queue) dropOldest() {
+	for i := len(q.lanes) - 1; i >= 0; i-- {
+		if msg, ok := q.lanes[i].dropOldest(); ok {
+			q.count--
+			q.countChannel(msg.ChannelID, -1)
+			return
+		}
+	}
+}
+
+// atChannelCap reports whether channelID already holds the most queued
+// messages WithPerChannelMax allows. The caller must hold q.mu.
+func (q *Queue) atChannelCap(channelID string) bool {
+	return q.perChannel != nil && q.perChannel[channelID] >= q.perChannelMax
+}
+
+// dropOldestIn discards the oldest message of channelID, looking in the
+// lowest-priority lane first. The caller must hold q.mu.
+func (q *Queue) dropOldestIn(channelID string) {
+	for i := len(q.lanes) - 1; i >= 0; i-- {
+		if q.lanes[i].dropOldestIn(channelID) {
+			q.count--
+			q.countChannel(channelID, -1)
+			return
+		}
+	}
 }
 
-// poll collects up to limit messages from the queue into dst, applying an
-// optional channelFilter. When channelFilter is non-empty only messages whose
-// ChannelID or ChannelName matches it are returned; non-matching messages
-// remain in the ring buffer. The caller must hold q.mu.
+// countChannel adjusts the per-channel count of channelID by delta when a
+// per-channel cap is set. The caller must hold q.mu.
+func (q *Queue) countChannel(channelID string, delta int) {
+	if q.perChannel == nil {
+		return
+	}
+	if n := q.perChannel[channelID] + delta; n > 0 {
+		q.perChannel[channelID] = n
+	} else {
+		delete(q.perChannel, channelID)
+	}
+}
+
+// poll collects up to limit messages from the queue, draining lanes in
+// priority order and applying an optional channelFilter. When channelFilter
+// is non-empty only messages whose ChannelID or ChannelName matches it are
+// returned; non-matching messages remain queued. In acknowledgment mode
+// expired deliveries are requeued first and the returned messages are put in
+// flight. The caller must hold q.mu.
 func (q *Queue) poll(channelFilter string, limit int) []QueuedMessage {
+	q.requeueExpired()
 	if q.count == 0 {
 		return nil
 	}
 
-	if channelFilter == "" {
-		// Fast path: collect up to limit messages from the head.
-		n := q.count
-		if limit > 0 && n > limit {
-			n = limit
+	var out []QueuedMessage
+	for _, lane := range q.lanes {
+		remaining := 0
+		if limit > 0 {
+			remaining = limit - len(out)
+			if remaining == 0 {
+				break
+			}
 		}
-		out := make([]QueuedMessage, n)
-		for i := 0; i < n; i++ {
-			idx := (q.head + i) % q.maxSize
-			out[i] = q.buf[idx]
-			q.buf[idx] = QueuedMessage{}
+		msgs := lane.take(channelFilter, remaining)
+		q.count -= len(msgs)
+		for _, m := range msgs {
+			q.countChannel(m.ChannelID, -1)
 		}
-		q.head = (q.head + n) % q.maxSize
-		q.count -= n
-		return out
+		out = append(out, msgs...)
+	}
+	if len(out) > 0 {
+		// Wake Enqueue calls blocked on a full queue.
+		close(q.freed)
+		q.freed = make(chan struct{})
+		q.deliver(out)
 	}
+	return out
+}
 
-	// Filtered path: scan all messages, collect matching ones, compact buffer.
-	var out []QueuedMessage
-	kept := make([]QueuedMessage, 0, q.count)
+// deliver puts msgs in flight with fresh delivery tokens when the queue is
+// in acknowledgment mode, and does nothing otherwise. The caller must hold
+// q.mu.
+func (q *Queue) deliver(msgs []QueuedMessage) {
+	if q.inflight == nil {
+		return
+	}
+	deadline := q.now().Add(q.ackTimeout)
+	for i := range msgs {
+		msgs[i].DeliveryToken = newDeliveryToken()
+		msgs[i].Deliveries++
+		q.inflight[msgs[i].DeliveryToken] = &delivery{msg: msgs[i], seq: q.deliveries, deadline: deadline}
+		q.deliveries++
+	}
+}
 
-	for i := 0; i < q.count; i++ {
-		msg := q.buf[(q.head+i)%q.maxSize]
-		collected := limit <= 0 || len(out) < limit
-		if collected && (msg.ChannelID == channelFilter || msg.ChannelName == channelFilter) {
-			out = append(out, msg)
-		} else {
-			kept = append(kept, msg)
+// newDeliveryToken returns a random token that other clients sharing the
+// queue cannot guess.
+func newDeliveryToken() string {
+	var b [12]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// requeueExpired makes every delivery whose visibility timeout has passed
+// visible again. The caller must hold q.mu.
+func (q *Queue) requeueExpired() {
+	if len(q.inflight) == 0 {
+		return
+	}
+	now := q.now()
+	var expired []*delivery
+	for token, d := range q.inflight {
+		if !now.Before(d.deadline) {
+			expired = append(expired, d)
+			delete(q.inflight, token)
 		}
 	}
+	q.requeue(expired)
+}
 
-	// Rewrite the ring buffer with only the kept messages.
-	q.head = 0
-	q.count = len(kept)
-	copy(q.buf, kept)
-	// Zero out trailing slots to release stale references.
-	for i := len(kept); i < q.maxSize; i++ {
-		q.buf[i] = QueuedMessage{}
+// requeue returns delivered messages to the head of their lanes so they are
+// redelivered before anything that arrived after them, keeping the order in
+// which they were first handed out. A full queue drops its oldest message to
+// make room for each, as for a new arrival under OverflowDrop; per-channel
+// caps are not applied, since the messages were already admitted. The
+// caller must hold q.mu.
+func (q *Queue) requeue(ds []*delivery) {
+	// Push the newest first so the oldest ends up at the head.
+	slices.SortFunc(ds, func(a, b *delivery) int { return cmp.Compare(b.seq, a.seq) })
+	for _, d := range ds {
+		if q.count == q.maxSize {
+			q.dropOldest()
+		}
+		msg := d.msg
+		msg.DeliveryToken = ""
+		q.laneFor(msg.Priority).pushFront(msg)
+		q.count++
+		q.countChannel(msg.ChannelID, 1)
 	}
+}
 
-	return out
+// nextExpiry returns how long until the earliest in-flight delivery expires,
+// and false when nothing is in flight. The caller must hold q.mu.
+func (q *Queue) nextExpiry() (time.Duration, bool) {
+	if len(q.inflight) == 0 {
+		return 0, false
+	}
+	var earliest time.Time
+	for _, d := range q.inflight {
+		if earliest.IsZero() || d.deadline.Before(earliest) {
+			earliest = d.deadline
+		}
+	}
+	return max(earliest.Sub(q.now()), 0), true
+}
+
+// Ack marks the delivery identified by token as processed, removing its
+// message from the queue for good. It returns ErrUnknownDelivery if the token
+// is not in flight, including when its visibility timeout already expired;
+// the message will then be, or has been, delivered again.
+func (q *Queue) Ack(token string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.requeueExpired()
+	if _, ok := q.inflight[token]; !ok {
+		return ErrUnknownDelivery
+	}
+	delete(q.inflight, token)
+	return nil
+}
+
+// Nack gives up the delivery identified by token, making its message visible
+// again at once at the head of its lane and waking blocked Polls. It returns
+// ErrUnknownDelivery if the token is not in flight.
+func (q *Queue) Nack(token string) error {
+	q.mu.Lock()
+	q.requeueExpired()
+	d, ok := q.inflight[token]
+	if !ok {
+		q.mu.Unlock()
+		return ErrUnknownDelivery
+	}
+	delete(q.inflight, token)
+	q.requeue([]*delivery{d})
+
+	var oldNotify chan struct{}
+	if q.waiters > 0 {
+		oldNotify = q.notify
+		q.notify = make(chan struct{})
+	}
+	q.mu.Unlock()
+
+	if oldNotify != nil {
+		close(oldNotify)
+	}
+	return nil
 }
 
 // Poll returns up to limit messages from the queue, blocking until at least
@@ -153,10 +602,13 @@ func (q *Queue) poll(channelFilter string, limit int) []QueuedMessage {
 //
 // A limit of zero or less means return all available matching messages.
 // Messages are returned in FIFO order (oldest first) and are removed from the
-// queue; each message is delivered at most once.
+// queue; each message is delivered at most once. In acknowledgment mode (see
+// WithAckTimeout) they are kept in flight instead, and a Poll waiting for
+// messages also wakes when an unacknowledged delivery becomes visible again.
 //
 // Poll returns nil (not an error) when the timeout elapses or ctx is cancelled
-// with no messages to deliver.
+// with no messages to deliver. A timeout of zero or less returns immediately
+// with whatever is queued.
 func (q *Queue) Poll(ctx context.Context, timeout time.Duration, limit int, channelFilter string) []QueuedMessage {
 	// Try immediately first.
 	q.mu.Lock()
@@ -164,13 +616,31 @@ func (q *Queue) Poll(ctx context.Context, timeout time.Duration, limit int, chan
 		q.mu.Unlock()
 		return msgs
 	}
-	// Capture the current notify channel while still holding the lock so we
-	// don't miss a signal that arrives between the lock release and the select.
+	if timeout <= 0 {
+		q.mu.Unlock()
+		return nil
+	}
+	// Register as a waiter and capture the current notify channel while
+	// still holding the lock so we don't miss a signal that arrives between
+	// the lock release and the select.
+	q.waiters++
 	notifyCh := q.notify
+	expiry, inFlight := q.nextExpiry()
 	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		q.waiters--
+		q.mu.Unlock()
+	}()
 
 	timer := time.NewTimer(timeout)
 	defer timer.Stop()
+	// redeliver fires when the earliest in-flight delivery expires.
+	redeliver := time.NewTimer(expiry)
+	defer redeliver.Stop()
+	if !inFlight {
+		redeliver.Stop()
+	}
 
 	for {
 		select {
@@ -180,21 +650,90 @@ func (q *Queue) Poll(ctx context.Context, timeout time.Duration, limit int, chan
 			return nil
 		case <-notifyCh:
 			// A message was enqueued; try to collect.
-			q.mu.Lock()
-			msgs := q.poll(channelFilter, limit)
-			notifyCh = q.notify
-			q.mu.Unlock()
-			if len(msgs) > 0 {
-				return msgs
-			}
-			// The message may not have matched our filter; keep waiting.
+		case <-redeliver.C:
+			// A delivery expired; its message is visible again.
 		}
+		q.mu.Lock()
+		msgs := q.poll(channelFilter, limit)
+		notifyCh = q.notify
+		expiry, inFlight = q.nextExpiry()
+		q.mu.Unlock()
+		if len(msgs) > 0 {
+			return msgs
+		}
+		// The message may not have matched our filter; keep waiting.
+		if inFlight {
+			redeliver.Reset(expiry)
+		} else {
+			redeliver.Stop()
+		}
+	}
+}
+
+// Drain removes and returns every message currently queued, in the order
+// Poll would return them, without waiting. When channelFilter is non-empty
+// only matching messages are removed, as with Poll. It returns nil when
+// nothing matches.
+func (q *Queue) Drain(channelFilter string) []QueuedMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.poll(channelFilter, 0)
+}
+
+// Clear discards every queued message in all lanes, and every in-flight
+// delivery in acknowledgment mode, and returns how many were discarded.
+// Enqueue calls blocked on a full queue are woken. Deduplication history is
+// kept, so a cleared message is not re-queued if Discord delivers it again.
+func (q *Queue) Clear() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := len(q.inflight)
+	clear(q.inflight)
+	clear(q.perChannel)
+	for _, lane := range q.lanes {
+		n += lane.clear()
+	}
+	q.count = 0
+	if n > 0 {
+		close(q.freed)
+		q.freed = make(chan struct{})
 	}
+	return n
 }
 
-// Len returns the current number of messages in the queue.
+// Len returns the current number of messages in the queue, not counting
+// in-flight deliveries.
 func (q *Queue) Len() int {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	q.requeueExpired()
 	return q.count
 }
+
+// InFlight returns the number of deliveries awaiting Ack in acknowledgment
+// mode. It is always zero otherwise.
+func (q *Queue) InFlight() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.requeueExpired()
+	return len(q.inflight)
+}
+
+// AckTimeout returns the visibility timeout set by WithAckTimeout, or zero
+// when acknowledgment mode is disabled.
+func (q *Queue) AckTimeout() time.Duration {
+	return q.ackTimeout
+}
+
+// Cap returns the maximum number of messages the queue can hold.
+func (q *Queue) Cap() int {
+	return q.maxSize
+}
+
+// Enqueued returns the total number of messages enqueued since the queue was
+// created, including messages that were later dropped or polled.
+func (q *Queue) Enqueued() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.enqueued
+}
@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -150,6 +151,137 @@ func Test_Enqueue_Full_BulkOverflow(t *testing.T) {
 	}
 }
 
+func Test_Enqueued_CountsDroppedAndPolled(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(2))
+	for i := 0; i < 3; i++ {
+		q.Enqueue(QueuedMessage{Content: fmt.Sprintf("msg-%d", i)})
+	}
+	_ = q.Poll(context.Background(), time.Millisecond, 1, "")
+
+	if got := q.Enqueued(); got != 3 {
+		t.Errorf("Enqueued() = %d, want 3", got)
+	}
+	if got := q.Cap(); got != 2 {
+		t.Errorf("Cap() = %d, want 2", got)
+	}
+}
+
+func Test_Enqueue_WithDedup_DropsDuplicateID(t *testing.T) {
+	t.Parallel()
+	q := New(WithDedup(10))
+
+	q.Enqueue(QueuedMessage{ID: "msg-1", Content: "hello"})
+	q.Enqueue(QueuedMessage{ID: "msg-1", Content: "hello"})
+
+	if q.Len() != 1 {
+		t.Errorf("Len() = %d after enqueuing the same ID twice, want 1", q.Len())
+	}
+	if got := q.Enqueued(); got != 1 {
+		t.Errorf("Enqueued() = %d, want 1 (duplicates are not counted)", got)
+	}
+}
+
+func Test_Enqueue_WithDedup_WindowEvictsOldIDs(t *testing.T) {
+	t.Parallel()
+	q := New(WithDedup(2))
+
+	for _, id := range []string{"a", "b", "c", "a"} {
+		q.Enqueue(QueuedMessage{ID: id})
+	}
+
+	// "a" fell out of the two-entry window once "c" arrived, so it is
+	// accepted again.
+	if q.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", q.Len())
+	}
+	if len(q.seen) > 2 {
+		t.Errorf("dedup set holds %d IDs, want <= 2", len(q.seen))
+	}
+}
+
+func Test_Enqueue_WithoutDedup_KeepsDuplicates(t *testing.T) {
+	t.Parallel()
+	q := New()
+
+	q.Enqueue(QueuedMessage{ID: "msg-1"})
+	q.Enqueue(QueuedMessage{ID: "msg-1"})
+
+	if q.Len() != 2 {
+		t.Errorf("Len() = %d, want 2 when dedup is disabled", q.Len())
+	}
+}
+
+func Test_Enqueue_OverflowDrop_ReturnsNil(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(1), WithOverflowPolicy(OverflowDrop))
+
+	if err := q.Enqueue(QueuedMessage{ID: "a"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := q.Enqueue(QueuedMessage{ID: "b"}); err != nil {
+		t.Fatalf("Enqueue() on full queue error = %v, want nil under OverflowDrop", err)
+	}
+	msgs := q.Poll(context.Background(), time.Millisecond, 0, "")
+	if len(msgs) != 1 || msgs[0].ID != "b" {
+		t.Errorf("Poll() = %v, want only the newest message", msgs)
+	}
+}
+
+func Test_Enqueue_OverflowBlock_TimesOut(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(1), WithOverflowPolicy(OverflowBlock), WithBlockTimeout(30*time.Millisecond))
+
+	_ = q.Enqueue(QueuedMessage{ID: "a"})
+
+	start := time.Now()
+	err := q.Enqueue(QueuedMessage{ID: "b"})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Enqueue() error = %v, want ErrQueueFull", err)
+	}
+	if elapsed < 30*time.Millisecond {
+		t.Errorf("Enqueue() returned after %v, want it to wait for the block timeout", elapsed)
+	}
+	msgs := q.Poll(context.Background(), time.Millisecond, 0, "")
+	if len(msgs) != 1 || msgs[0].ID != "a" {
+		t.Errorf("Poll() = %v, want the original message kept", msgs)
+	}
+}
+
+func Test_Enqueue_OverflowBlock_ResumesAfterPoll(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(1), WithOverflowPolicy(OverflowBlock), WithBlockTimeout(2*time.Second))
+
+	_ = q.Enqueue(QueuedMessage{ID: "a"})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- q.Enqueue(QueuedMessage{ID: "b"})
+	}()
+
+	// Give the goroutine time to block, then free a slot.
+	time.Sleep(20 * time.Millisecond)
+	first := q.Poll(context.Background(), time.Millisecond, 1, "")
+	if len(first) != 1 || first[0].ID != "a" {
+		t.Fatalf("first Poll() = %v, want [a]", first)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("blocked Enqueue() error = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue() did not unblock after Poll freed space")
+	}
+	second := q.Poll(context.Background(), time.Millisecond, 0, "")
+	if len(second) != 1 || second[0].ID != "b" {
+		t.Errorf("second Poll() = %v, want [b]", second)
+	}
+}
+
 func Test_Enqueue_Concurrent(t *testing.T) {
 	t.Parallel()
 	q := New(WithMaxSize(50))
@@ -277,6 +409,63 @@ func Test_Poll_WakeUpOnEnqueue(t *testing.T) {
 	}
 }
 
+func Test_Poll_WakeUpAllWaiters(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(10))
+	ctx := context.Background()
+
+	// Two pollers filtered to different channels; one Enqueue per channel
+	// must reach the right poller even though both wait on one channel.
+	results := make(chan string, 2)
+	var wg sync.WaitGroup
+	for _, ch := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(ch string) {
+			defer wg.Done()
+			if msgs := q.Poll(ctx, 5*time.Second, 1, ch); len(msgs) == 1 {
+				results <- msgs[0].ChannelID
+			}
+		}(ch)
+	}
+
+	// Wait until both pollers are registered before enqueueing.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		q.mu.Lock()
+		n := q.waiters
+		q.mu.Unlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	_ = q.Enqueue(QueuedMessage{ChannelID: "b"})
+	_ = q.Enqueue(QueuedMessage{ChannelID: "a"})
+	wg.Wait()
+	close(results)
+
+	got := map[string]bool{}
+	for ch := range results {
+		got[ch] = true
+	}
+	if !got["a"] || !got["b"] {
+		t.Errorf("pollers received %v, want both a and b", got)
+	}
+	if q.waiters != 0 {
+		t.Errorf("waiters = %d after all polls returned, want 0", q.waiters)
+	}
+}
+
+func Test_Enqueue_NoWaiters_KeepsNotifyChannel(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(10))
+	before := q.notify
+	_ = q.Enqueue(QueuedMessage{Content: "x"})
+	if q.notify != before {
+		t.Error("Enqueue with no waiting pollers should not replace the notify channel")
+	}
+}
+
 func Test_Poll_ChannelFilter_NoMatch(t *testing.T) {
 	t.Parallel()
 	q := New(WithMaxSize(10))
@@ -327,6 +516,24 @@ func Test_Poll_NonMatchingRemain(t *testing.T) {
 	}
 }
 
+func Test_Poll_ZeroTimeout_ReturnsImmediately(t *testing.T) {
+	t.Parallel()
+	q := New()
+
+	start := time.Now()
+	if msgs := q.Poll(context.Background(), 0, 0, ""); msgs != nil {
+		t.Errorf("Poll() on empty queue = %v, want nil", msgs)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Poll() with zero timeout took %v", elapsed)
+	}
+
+	q.Enqueue(QueuedMessage{ID: "a"})
+	if msgs := q.Poll(context.Background(), 0, 0, ""); len(msgs) != 1 {
+		t.Errorf("Poll() with zero timeout returned %d messages, want 1", len(msgs))
+	}
+}
+
 func Test_Poll_CancelledContext(t *testing.T) {
 	t.Parallel()
 	q := New(WithMaxSize(10))
@@ -406,6 +613,418 @@ func Test_Poll_Concurrent_NoDuplicates(t *testing.T) {
 	}
 }
 
+func Test_Poll_PriorityLanes_HighFirst(t *testing.T) {
+	t.Parallel()
+	q := New(WithPriorityLanes(true))
+
+	q.Enqueue(QueuedMessage{ID: "n1"})
+	q.Enqueue(QueuedMessage{ID: "n2"})
+	q.Enqueue(QueuedMessage{ID: "h1", Priority: PriorityHigh})
+	q.Enqueue(QueuedMessage{ID: "n3"})
+	q.Enqueue(QueuedMessage{ID: "h2", Priority: PriorityHigh})
+
+	msgs := q.Poll(context.Background(), time.Millisecond, 0, "")
+	want := []string{"h1", "h2", "n1", "n2", "n3"}
+	if len(msgs) != len(want) {
+		t.Fatalf("Poll() returned %d messages, want %d", len(msgs), len(want))
+	}
+	for i, id := range want {
+		if msgs[i].ID != id {
+			t.Errorf("msgs[%d].ID = %q, want %q", i, msgs[i].ID, id)
+		}
+	}
+}
+
+func Test_Poll_PriorityLanes_LimitSpansLanes(t *testing.T) {
+	t.Parallel()
+	q := New(WithPriorityLanes(true))
+
+	q.Enqueue(QueuedMessage{ID: "n1"})
+	q.Enqueue(QueuedMessage{ID: "h1", Priority: PriorityHigh})
+	q.Enqueue(QueuedMessage{ID: "n2"})
+
+	first := q.Poll(context.Background(), time.Millisecond, 2, "")
+	if len(first) != 2 || first[0].ID != "h1" || first[1].ID != "n1" {
+		t.Fatalf("first Poll() = %v, want [h1 n1]", first)
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len() = %d after partial poll, want 1", q.Len())
+	}
+}
+
+func Test_Enqueue_PriorityLanes_OverflowDropsNormalFirst(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(2), WithPriorityLanes(true))
+
+	q.Enqueue(QueuedMessage{ID: "h1", Priority: PriorityHigh})
+	q.Enqueue(QueuedMessage{ID: "n1"})
+	q.Enqueue(QueuedMessage{ID: "h2", Priority: PriorityHigh})
+
+	msgs := q.Poll(context.Background(), time.Millisecond, 0, "")
+	if len(msgs) != 2 || msgs[0].ID != "h1" || msgs[1].ID != "h2" {
+		t.Errorf("Poll() = %v, want [h1 h2] with n1 dropped", msgs)
+	}
+}
+
+func Test_Poll_SingleLane_IgnoresPriority(t *testing.T) {
+	t.Parallel()
+	q := New()
+
+	q.Enqueue(QueuedMessage{ID: "n1"})
+	q.Enqueue(QueuedMessage{ID: "h1", Priority: PriorityHigh})
+
+	msgs := q.Poll(context.Background(), time.Millisecond, 0, "")
+	if len(msgs) != 2 || msgs[0].ID != "n1" || msgs[1].ID != "h1" {
+		t.Errorf("Poll() = %v, want FIFO order [n1 h1]", msgs)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Drain
+// ---------------------------------------------------------------------------
+
+func Test_Drain_PartiallyFull(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(100), WithPriorityLanes(true))
+	for i := 0; i < 60; i++ {
+		q.Enqueue(QueuedMessage{ID: fmt.Sprintf("n%d", i), ChannelID: "ch-1"})
+	}
+	q.Enqueue(QueuedMessage{ID: "h1", ChannelID: "ch-1", Priority: PriorityHigh})
+
+	msgs := q.Drain("")
+	if len(msgs) != 61 {
+		t.Fatalf("Drain() returned %d messages, want all 61", len(msgs))
+	}
+	if msgs[0].ID != "h1" || msgs[1].ID != "n0" || msgs[60].ID != "n59" {
+		t.Errorf("Drain() order = %s, %s ... %s; want h1, n0 ... n59", msgs[0].ID, msgs[1].ID, msgs[60].ID)
+	}
+	if got := q.Len(); got != 0 {
+		t.Errorf("Len() after Drain = %d, want 0", got)
+	}
+	if msgs := q.Drain(""); msgs != nil {
+		t.Errorf("Drain() on empty queue = %v, want nil", msgs)
+	}
+}
+
+func Test_Drain_ChannelFilter(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(10))
+	q.Enqueue(QueuedMessage{ID: "a1", ChannelID: "ch-a"})
+	q.Enqueue(QueuedMessage{ID: "b1", ChannelID: "ch-b"})
+	q.Enqueue(QueuedMessage{ID: "a2", ChannelID: "ch-a"})
+
+	msgs := q.Drain("ch-a")
+	if len(msgs) != 2 || msgs[0].ID != "a1" || msgs[1].ID != "a2" {
+		t.Errorf("Drain(ch-a) = %v, want a1, a2", msgs)
+	}
+	if got := q.Len(); got != 1 {
+		t.Errorf("Len() after filtered Drain = %d, want 1", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Clear
+// ---------------------------------------------------------------------------
+
+func Test_Clear_DiscardsAll(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(10), WithPriorityLanes(true))
+
+	q.Enqueue(QueuedMessage{ID: "n1"})
+	q.Enqueue(QueuedMessage{ID: "h1", Priority: PriorityHigh})
+	q.Enqueue(QueuedMessage{ID: "n2"})
+
+	if got := q.Clear(); got != 3 {
+		t.Errorf("Clear() = %d, want 3", got)
+	}
+	if got := q.Len(); got != 0 {
+		t.Errorf("Len() after Clear = %d, want 0", got)
+	}
+	if msgs := q.Poll(context.Background(), 0, 0, ""); len(msgs) != 0 {
+		t.Errorf("Poll() after Clear = %v, want none", msgs)
+	}
+
+	// The queue keeps working after a clear.
+	q.Enqueue(QueuedMessage{ID: "n3"})
+	msgs := q.Poll(context.Background(), 0, 0, "")
+	if len(msgs) != 1 || msgs[0].ID != "n3" {
+		t.Errorf("Poll() = %v, want [n3]", msgs)
+	}
+}
+
+func Test_Clear_UnblocksEnqueue(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(1), WithOverflowPolicy(OverflowBlock), WithBlockTimeout(5*time.Second))
+	q.Enqueue(QueuedMessage{ID: "m1"})
+
+	done := make(chan error, 1)
+	go func() { done <- q.Enqueue(QueuedMessage{ID: "m2"}) }()
+
+	time.Sleep(20 * time.Millisecond)
+	q.Clear()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Enqueue() error = %v, want nil after Clear", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Enqueue still blocked after Clear")
+	}
+	if got := q.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Per-channel cap
+// ---------------------------------------------------------------------------
+
+func Test_Enqueue_PerChannelMax_FloodKeepsOtherChannels(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(10), WithPerChannelMax(3))
+
+	q.Enqueue(QueuedMessage{ID: "quiet-1", ChannelID: "quiet"})
+	q.Enqueue(QueuedMessage{ID: "quiet-2", ChannelID: "quiet"})
+	for i := range 50 {
+		q.Enqueue(QueuedMessage{ID: fmt.Sprintf("busy-%d", i), ChannelID: "busy"})
+	}
+	q.Enqueue(QueuedMessage{ID: "quiet-3", ChannelID: "quiet"})
+
+	msgs := q.Poll(context.Background(), 0, 0, "")
+	var got []string
+	for _, m := range msgs {
+		got = append(got, m.ID)
+	}
+	want := []string{"quiet-1", "quiet-2", "busy-47", "busy-48", "busy-49", "quiet-3"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Poll() = %v, want %v", got, want)
+	}
+}
+
+func Test_Enqueue_PerChannelMax_CountsFreedByPoll(t *testing.T) {
+	t.Parallel()
+	q := New(WithPerChannelMax(2), WithPriorityLanes(true))
+
+	q.Enqueue(QueuedMessage{ID: "a1", ChannelID: "a", Priority: PriorityHigh})
+	q.Enqueue(QueuedMessage{ID: "a2", ChannelID: "a"})
+	// At the cap, the normal-priority message is dropped before the high one.
+	q.Enqueue(QueuedMessage{ID: "a3", ChannelID: "a"})
+	if msgs := q.Poll(context.Background(), 0, 0, ""); len(msgs) != 2 || msgs[0].ID != "a1" || msgs[1].ID != "a3" {
+		t.Fatalf("Poll() = %v, want [a1 a3]", msgs)
+	}
+
+	// Polling freed the channel's share.
+	q.Enqueue(QueuedMessage{ID: "a4", ChannelID: "a"})
+	q.Enqueue(QueuedMessage{ID: "a5", ChannelID: "a"})
+	if got := q.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+}
+
+func Test_Enqueue_PerChannelMax_DoesNotBlock(t *testing.T) {
+	t.Parallel()
+	q := New(WithMaxSize(2), WithPerChannelMax(1), WithOverflowPolicy(OverflowBlock), WithBlockTimeout(5*time.Second))
+	q.Enqueue(QueuedMessage{ID: "a1", ChannelID: "a"})
+	q.Enqueue(QueuedMessage{ID: "b1", ChannelID: "b"})
+
+	start := time.Now()
+	if err := q.Enqueue(QueuedMessage{ID: "a2", ChannelID: "a"}); err != nil {
+		t.Fatalf("Enqueue() = %v, want nil", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Enqueue() blocked for %v at the channel cap", elapsed)
+	}
+	msgs := q.Poll(context.Background(), 0, 0, "")
+	if len(msgs) != 2 || msgs[0].ID != "b1" || msgs[1].ID != "a2" {
+		t.Errorf("Poll() = %v, want [b1 a2]", msgs)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Acknowledgment mode
+// ---------------------------------------------------------------------------
+
+// fakeClock is a manually advanced clock for visibility timeout tests.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// newAckQueue returns a queue in acknowledgment mode driven by a fake clock.
+func newAckQueue(timeout time.Duration, opts ...Option) (*Queue, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	q := New(append(opts, WithAckTimeout(timeout))...)
+	q.now = clock.Now
+	return q, clock
+}
+
+func Test_Poll_AckMode_AssignsTokens(t *testing.T) {
+	t.Parallel()
+	q, _ := newAckQueue(time.Minute)
+	q.Enqueue(QueuedMessage{ID: "m1"})
+	q.Enqueue(QueuedMessage{ID: "m2"})
+
+	msgs := q.Poll(context.Background(), 0, 0, "")
+	if len(msgs) != 2 {
+		t.Fatalf("Poll() returned %d messages, want 2", len(msgs))
+	}
+	if msgs[0].DeliveryToken == "" || msgs[0].DeliveryToken == msgs[1].DeliveryToken {
+		t.Errorf("delivery tokens = %q, %q, want distinct non-empty tokens", msgs[0].DeliveryToken, msgs[1].DeliveryToken)
+	}
+	for _, m := range msgs {
+		if m.Deliveries != 1 {
+			t.Errorf("%s Deliveries = %d, want 1", m.ID, m.Deliveries)
+		}
+	}
+	if got := q.Len(); got != 0 {
+		t.Errorf("Len() = %d, want 0 while in flight", got)
+	}
+	if got := q.InFlight(); got != 2 {
+		t.Errorf("InFlight() = %d, want 2", got)
+	}
+}
+
+func Test_Poll_AckMode_RedeliversAfterTimeout(t *testing.T) {
+	t.Parallel()
+	q, clock := newAckQueue(30 * time.Second)
+	q.Enqueue(QueuedMessage{ID: "m1"})
+	q.Enqueue(QueuedMessage{ID: "m2"})
+
+	first := q.Poll(context.Background(), 0, 0, "")
+	if len(first) != 2 {
+		t.Fatalf("first Poll() returned %d messages, want 2", len(first))
+	}
+	q.Enqueue(QueuedMessage{ID: "m3"})
+
+	clock.Advance(29 * time.Second)
+	if msgs := q.Poll(context.Background(), 0, 0, ""); len(msgs) != 1 || msgs[0].ID != "m3" {
+		t.Fatalf("Poll() before timeout = %v, want only [m3]", msgs)
+	}
+
+	clock.Advance(time.Second)
+	again := q.Poll(context.Background(), 0, 0, "")
+	if len(again) != 2 || again[0].ID != "m1" || again[1].ID != "m2" {
+		t.Fatalf("Poll() after timeout = %v, want [m1 m2] redelivered in order", again)
+	}
+	for i, m := range again {
+		if m.Deliveries != 2 {
+			t.Errorf("%s Deliveries = %d, want 2", m.ID, m.Deliveries)
+		}
+		if m.DeliveryToken == first[i].DeliveryToken {
+			t.Errorf("%s redelivered with its old token", m.ID)
+		}
+	}
+	if err := q.Ack(first[0].DeliveryToken); !errors.Is(err, ErrUnknownDelivery) {
+		t.Errorf("Ack(expired token) = %v, want ErrUnknownDelivery", err)
+	}
+}
+
+func Test_Ack_RemovesPermanently(t *testing.T) {
+	t.Parallel()
+	q, clock := newAckQueue(30 * time.Second)
+	q.Enqueue(QueuedMessage{ID: "m1"})
+
+	msgs := q.Poll(context.Background(), 0, 0, "")
+	if len(msgs) != 1 {
+		t.Fatalf("Poll() returned %d messages, want 1", len(msgs))
+	}
+	if err := q.Ack(msgs[0].DeliveryToken); err != nil {
+		t.Fatalf("Ack() = %v, want nil", err)
+	}
+	if err := q.Ack(msgs[0].DeliveryToken); !errors.Is(err, ErrUnknownDelivery) {
+		t.Errorf("second Ack() = %v, want ErrUnknownDelivery", err)
+	}
+
+	clock.Advance(time.Hour)
+	if got := q.Poll(context.Background(), 0, 0, ""); len(got) != 0 {
+		t.Errorf("Poll() after Ack and timeout = %v, want none", got)
+	}
+	if got := q.InFlight(); got != 0 {
+		t.Errorf("InFlight() = %d, want 0", got)
+	}
+}
+
+func Test_Nack_RedeliversAtOnce(t *testing.T) {
+	t.Parallel()
+	q, _ := newAckQueue(time.Hour)
+	q.Enqueue(QueuedMessage{ID: "m1"})
+	msgs := q.Poll(context.Background(), 0, 0, "")
+	q.Enqueue(QueuedMessage{ID: "m2"})
+
+	if err := q.Nack(msgs[0].DeliveryToken); err != nil {
+		t.Fatalf("Nack() = %v, want nil", err)
+	}
+	if err := q.Nack("not-a-token"); !errors.Is(err, ErrUnknownDelivery) {
+		t.Errorf("Nack(unknown) = %v, want ErrUnknownDelivery", err)
+	}
+	got := q.Poll(context.Background(), 0, 0, "")
+	if len(got) != 2 || got[0].ID != "m1" || got[1].ID != "m2" {
+		t.Errorf("Poll() after Nack = %v, want [m1 m2]", got)
+	}
+}
+
+func Test_Poll_AckMode_WakesOnRedelivery(t *testing.T) {
+	t.Parallel()
+	// Uses the real clock: a Poll blocked on an empty queue must wake when
+	// an in-flight delivery expires.
+	q := New(WithAckTimeout(50 * time.Millisecond))
+	q.Enqueue(QueuedMessage{ID: "m1"})
+	if msgs := q.Poll(context.Background(), 0, 0, ""); len(msgs) != 1 {
+		t.Fatalf("Poll() returned %d messages, want 1", len(msgs))
+	}
+
+	start := time.Now()
+	msgs := q.Poll(context.Background(), 5*time.Second, 0, "")
+	if len(msgs) != 1 || msgs[0].ID != "m1" || msgs[0].Deliveries != 2 {
+		t.Fatalf("blocked Poll() = %v, want m1 redelivered", msgs)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("redelivery took %v, want about the 50ms ack timeout", elapsed)
+	}
+}
+
+func Test_Clear_AckMode_DiscardsInFlight(t *testing.T) {
+	t.Parallel()
+	q, clock := newAckQueue(time.Second)
+	q.Enqueue(QueuedMessage{ID: "m1"})
+	q.Enqueue(QueuedMessage{ID: "m2"})
+	q.Poll(context.Background(), 0, 1, "")
+
+	if got := q.Clear(); got != 2 {
+		t.Errorf("Clear() = %d, want 2 counting the in-flight message", got)
+	}
+	clock.Advance(time.Minute)
+	if got := q.Poll(context.Background(), 0, 0, ""); len(got) != 0 {
+		t.Errorf("Poll() after Clear = %v, want none", got)
+	}
+}
+
+func Test_Poll_WithoutAckMode_NoTokens(t *testing.T) {
+	t.Parallel()
+	q := New()
+	q.Enqueue(QueuedMessage{ID: "m1"})
+	msgs := q.Poll(context.Background(), 0, 0, "")
+	if len(msgs) != 1 || msgs[0].DeliveryToken != "" || msgs[0].Deliveries != 0 {
+		t.Errorf("Poll() = %+v, want no delivery token", msgs)
+	}
+	if err := q.Ack("anything"); !errors.Is(err, ErrUnknownDelivery) {
+		t.Errorf("Ack() without ack mode = %v, want ErrUnknownDelivery", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // QueuedMessage.Formatted
 // ---------------------------------------------------------------------------
@@ -445,6 +1064,15 @@ func Test_QueuedMessage_Formatted_Cases(t *testing.T) {
 			},
 			want: "[#general] @: msg",
 		},
+		{
+			name: "typing event",
+			msg: QueuedMessage{
+				Type:           EventTyping,
+				ChannelName:    "general",
+				AuthorUsername: "alice",
+			},
+			want: "[#general] @alice is typing",
+		},
 	}
 
 	for _, tt := range tests {
@@ -484,6 +1112,35 @@ func Benchmark_Poll_SingleMessage(b *testing.B) {
 	}
 }
 
+// Benchmark_Enqueue_ConcurrentPoll enqueues from parallel goroutines while
+// pollers drain the queue.
+func Benchmark_Enqueue_ConcurrentPoll(b *testing.B) {
+	q := New(WithMaxSize(1000))
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				q.Poll(ctx, 10*time.Millisecond, 100, "")
+			}
+		}()
+	}
+	msg := QueuedMessage{Content: "bench", ChannelName: "gen", AuthorUsername: "user"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = q.Enqueue(msg)
+		}
+	})
+	b.StopTimer()
+	cancel()
+	wg.Wait()
+}
+
 func Benchmark_QueuedMessage_Formatted(b *testing.B) {
 	msg := QueuedMessage{
 		ChannelName:    "general",
@@ -0,0 +1,123 @@
+package queue
+
+// ring is a fixed-capacity FIFO ring buffer of messages. It is not safe for
+// concurrent use; Queue guards every ring with its mutex.
+type ring struct {
+	buf   []QueuedMessage
+	head  int
+	count int
+}
+
+// newRing returns an empty ring that can hold size messages.
+func newRing(size int) *ring {
+	return &ring{buf: make([]QueuedMessage, size)}
+}
+
+// push appends msg at the tail. The caller must ensure the ring is not full.
+func (r *ring) push(msg QueuedMessage) {
+	r.buf[(r.head+r.count)%len(r.buf)] = msg
+	r.count++
+}
+
+// pushFront inserts msg at the head, ahead of every queued message. The
+// caller must ensure the ring is not full.
+func (r *ring) pushFront(msg QueuedMessage) {
+	r.head = (r.head - 1 + len(r.buf)) % len(r.buf)
+	r.buf[r.head] = msg
+	r.count++
+}
+
+// dropOldest discards the message at the head, if any, and returns it.
+func (r *ring) dropOldest() (QueuedMessage, bool) {
+	if r.count == 0 {
+		return QueuedMessage{}, false
+	}
+	msg := r.buf[r.head]
+	r.buf[r.head] = QueuedMessage{}
+	r.head = (r.head + 1) % len(r.buf)
+	r.count--
+	return msg, true
+}
+
+// dropOldestIn discards the oldest message whose ChannelID is channelID and
+// reports whether there was one. The messages ahead of it move back one slot
+// so order is kept.
+func (r *ring) dropOldestIn(channelID string) bool {
+	size := len(r.buf)
+	for i := 0; i < r.count; i++ {
+		if r.buf[(r.head+i)%size].ChannelID != channelID {
+			continue
+		}
+		for j := i; j > 0; j-- {
+			r.buf[(r.head+j)%size] = r.buf[(r.head+j-1)%size]
+		}
+		r.buf[r.head] = QueuedMessage{}
+		r.head = (r.head + 1) % size
+		r.count--
+		return true
+	}
+	return false
+}
+
+// clear discards every message, zeroing the buffer to release references,
+// and returns how many were discarded.
+func (r *ring) clear() int {
+	n := r.count
+	clear(r.buf)
+	r.head = 0
+	r.count = 0
+	return n
+}
+
+// take removes and returns up to limit messages, applying an optional
+// channelFilter. When channelFilter is non-empty only messages whose
+// ChannelID or ChannelName matches it are returned; non-matching messages
+// remain in the buffer. A limit of zero or less takes every match.
+func (r *ring) take(channelFilter string, limit int) []QueuedMessage {
+	if r.count == 0 {
+		return nil
+	}
+	size := len(r.buf)
+
+	if channelFilter == "" {
+		// Fast path: collect up to limit messages from the head.
+		n := r.count
+		if limit > 0 && n > limit {
+			n = limit
+		}
+		out := make([]QueuedMessage, n)
+		for i := 0; i < n; i++ {
+			idx := (r.head + i) % size
+			out[i] = r.buf[idx]
+			r.buf[idx] = QueuedMessage{}
+		}
+		r.head = (r.head + n) % size
+		r.count -= n
+		return out
+	}
+
+	// Filtered path: scan all messages, collect matching ones, compact buffer.
+	var out []QueuedMessage
+	kept := make([]QueuedMessage, 0, r.count)
+
+	for i := 0; i < r.count; i++ {
+		msg := r.buf[(r.head+i)%size]
+		collected := limit <= 0 || len(out) < limit
+		if collected && (msg.ChannelID == channelFilter || msg.ChannelName == channelFilter) {
+			out = append(out, msg)
+		} else {
+			kept = append(kept, msg)
+		}
+	}
+
+	// Rewrite the ring buffer with only the kept messages.
+	r.head = 0
+	r.count = len(kept)
+	copy(r.buf, kept)
+	// Zero out trailing slots to release stale references.
+	for i := len(kept); i < size; i++ {
+		r.buf[i] = QueuedMessage{}
+	}
+
+	return out
+}
@@ -0,0 +1,164 @@
+package reaction
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+)
+
+var (
+	// mentionEmojiRe matches the message-format form of a custom emoji, e.g.
+	// "<:party:123>" or the animated "<a:party:123>".
+	mentionEmojiRe = regexp.MustCompile(`^<a?:(\w+):(\d+)>$`)
+	// apiEmojiRe matches the API form of a custom emoji, e.g. "party:123".
+	apiEmojiRe = regexp.MustCompile(`^:?(\w+):(\d+)$`)
+	// nameEmojiRe matches a bare custom emoji name, optionally wrapped in
+	// colons, e.g. "party" or ":party:".
+	nameEmojiRe = regexp.MustCompile(`^:?(\w+):?$`)
+)
+
+// emojiRefreshInterval is the minimum time between fetches of a guild's
+// emoji list made for names missing from the cache, so repeated lookups of
+// an unknown name do not each call Discord.
+const emojiRefreshInterval = time.Minute
+
+// emojiCache maintains a lazily populated name -> emoji index of the custom
+// emoji in a single guild. It is safe for concurrent use.
+type emojiCache struct {
+	dg      discord.DiscordClient
+	guildID string
+	now     func() time.Time
+
+	// refreshMu serializes fetches, so concurrent misses share one.
+	refreshMu sync.Mutex
+
+	mu       sync.RWMutex
+	byName   map[string]*discordgo.Emoji
+	loadedAt time.Time
+}
+
+// newEmojiCache returns an empty emojiCache for the given guild. The cache is
+// populated on the first name lookup.
+func newEmojiCache(dg discord.DiscordClient, guildID string) *emojiCache {
+	return &emojiCache{dg: dg, guildID: guildID, now: time.Now}
+}
+
+// refresh fetches the guild's custom emoji from Discord and replaces the cache.
+func (c *emojiCache) refresh(ctx context.Context) error {
+	emojis, err := c.dg.GuildEmojis(c.guildID, discordgo.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to fetch guild emoji: %w", err)
+	}
+
+	byName := make(map[string]*discordgo.Emoji, len(emojis))
+	for _, e := range emojis {
+		if e == nil || e.Name == "" || e.ID == "" {
+			continue
+		}
+		byName[e.Name] = e
+	}
+
+	c.mu.Lock()
+	c.byName = byName
+	c.loadedAt = c.now()
+	c.mu.Unlock()
+	return nil
+}
+
+// cached returns the cached emoji with the given name, whether the cache has
+// been loaded, and whether it was loaded within emojiRefreshInterval.
+func (c *emojiCache) cached(name string) (e *discordgo.Emoji, loaded, fresh bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	loaded = c.byName != nil
+	fresh = loaded && c.now().Sub(c.loadedAt) < emojiRefreshInterval
+	return c.byName[name], loaded, fresh
+}
+
+// lookup returns the custom emoji with the given name. The cache is loaded on
+// first use and reloaded on a miss so newly uploaded emoji are found, but at
+// most once per emojiRefreshInterval.
+func (c *emojiCache) lookup(ctx context.Context, name string) (*discordgo.Emoji, error) {
+	e, _, fresh := c.cached(name)
+	if e != nil {
+		return e, nil
+	}
+	if fresh {
+		return nil, c.notFound(name)
+	}
+
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	// Another caller may have refreshed while this one waited.
+	e, loaded, fresh := c.cached(name)
+	if e != nil {
+		return e, nil
+	}
+	if fresh {
+		return nil, c.notFound(name)
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		if loaded {
+			return nil, c.notFound(name)
+		}
+		return nil, err
+	}
+	if e, _, _ = c.cached(name); e != nil {
+		return e, nil
+	}
+	return nil, c.notFound(name)
+}
+
+// notFound builds an error for an unknown emoji name that lists the custom
+// emoji available in the guild.
+func (c *emojiCache) notFound(name string) error {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.byName))
+	for n := range c.byName {
+		names = append(names, n)
+	}
+	c.mu.RUnlock()
+
+	if len(names) == 0 {
+		return fmt.Errorf("custom emoji %q not found: the guild has no custom emoji", name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("custom emoji %q not found; valid custom emoji: %s", name, strings.Join(names, ", "))
+}
+
+// normalizeEmoji converts a user-supplied emoji to the representation expected
+// by the Discord reactions API. Accepted forms:
+//   - a unicode emoji ("👍"), passed through unchanged
+//   - the mention form of a custom emoji ("<:name:id>" or "<a:name:id>")
+//   - the API form of a custom emoji ("name:id")
+//   - a bare custom emoji name ("name" or ":name:"), resolved via the cache
+func (c *emojiCache) normalizeEmoji(ctx context.Context, emoji string) (string, error) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" {
+		return "", fmt.Errorf("emoji must not be empty")
+	}
+
+	if m := mentionEmojiRe.FindStringSubmatch(emoji); m != nil {
+		return m[1] + ":" + m[2], nil
+	}
+	if m := apiEmojiRe.FindStringSubmatch(emoji); m != nil {
+		return m[1] + ":" + m[2], nil
+	}
+	if m := nameEmojiRe.FindStringSubmatch(emoji); m != nil {
+		e, err := c.lookup(ctx, m[1])
+		if err != nil {
+			return "", err
+		}
+		return e.APIName(), nil
+	}
+
+	return emoji, nil
+}
@@ -0,0 +1,50 @@
+package reaction
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/testutil"
+)
+
+func Test_EmojiCache_MissRefreshesAtMostOncePerInterval(t *testing.T) {
+	t.Parallel()
+
+	fetches := 0
+	emojis := []*discordgo.Emoji{{ID: "emoji-001", Name: "partyparrot"}}
+	client := &testutil.MockDiscordClient{
+		GuildEmojisFunc: func(string, ...discordgo.RequestOption) ([]*discordgo.Emoji, error) {
+			fetches++
+			return emojis, nil
+		},
+	}
+	c := newEmojiCache(client, "guild-1")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	for range 3 {
+		if _, err := c.lookup(context.Background(), "unknown"); err == nil {
+			t.Fatal("lookup(unknown) should fail")
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("fetches = %d after repeated misses, want 1", fetches)
+	}
+	if _, err := c.lookup(context.Background(), "partyparrot"); err != nil {
+		t.Fatalf("lookup(partyparrot): %v", err)
+	}
+
+	// Once the interval has passed, a miss fetches the list again and finds
+	// newly uploaded emoji.
+	emojis = append(emojis, &discordgo.Emoji{ID: "emoji-002", Name: "unknown"})
+	now = now.Add(emojiRefreshInterval)
+	e, err := c.lookup(context.Background(), "unknown")
+	if err != nil || e.ID != "emoji-002" {
+		t.Fatalf("lookup(unknown) after interval = %v, %v; want emoji-002", e, err)
+	}
+	if fetches != 2 {
+		t.Errorf("fetches = %d, want 2", fetches)
+	}
+}
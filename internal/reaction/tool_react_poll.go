@@ -0,0 +1,178 @@
+package reaction
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Limits on a reaction poll. Discord allows at most 20 distinct reactions on
+// a message and returns at most 100 users per MessageReactions page; voters
+// beyond maxPollVoters per option are not counted.
+const (
+	maxPollOptions    = 20
+	reactionsPageSize = 100
+	maxPollVoters     = 1000
+)
+
+// PollOptionTally is the vote count for one option of a reaction poll.
+type PollOptionTally struct {
+	Emoji string `json:"emoji"`
+	Votes int    `json:"votes"`
+	// Truncated is set when more than maxPollVoters reacted with this emoji
+	// and the count stopped early.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// PollTally is the response shape returned by discord_react_poll. Options are
+// sorted by votes, most first; options with equal votes keep the order they
+// were given in. Winners lists every option sharing the highest vote count
+// and is empty when nobody voted.
+type PollTally struct {
+	MessageID  string            `json:"message_id"`
+	Options    []PollOptionTally `json:"options"`
+	Winners    []string          `json:"winners"`
+	TotalVotes int               `json:"total_votes"`
+	Tie        bool              `json:"tie"`
+}
+
+func toolReactPoll(dg discord.DiscordClient, r resolve.ChannelResolver, emojis *emojiCache, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_react_poll"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription("Tally an emoji reaction poll on a Discord message: count the users who reacted with each option emoji and report the winner(s)."),
+		mcp.WithString("channel",
+			mcp.Required(),
+			mcp.Description("Channel name or ID"),
+		),
+		mcp.WithString("message_id",
+			mcp.Required(),
+			mcp.Description("ID of the poll message"),
+		),
+		mcp.WithArray("options",
+			mcp.Required(),
+			mcp.Description("Option emoji to tally (unicode like '👍', or a custom emoji as 'name', 'name:id' or '<:name:id>')"),
+			mcp.WithStringItems(),
+			mcp.MinItems(1),
+			mcp.MaxItems(maxPollOptions),
+		),
+		mcp.WithBoolean("include_bots",
+			mcp.Description("Count reactions from bots, such as the bot's own seed reactions (default: false)"),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		channel := req.GetString("channel", "")
+		messageID := req.GetString("message_id", "")
+		choices := req.GetStringSlice("options", nil)
+		includeBots := req.GetBool("include_bots", false)
+		params := map[string]any{
+			"channel":      channel,
+			"message_id":   messageID,
+			"options":      choices,
+			"include_bots": includeBots,
+		}
+
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpRead, audit, logger, toolName, channel, params, start)
+		if errResult != nil {
+			return errResult, nil
+		}
+		if len(choices) == 0 {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("options must list at least one emoji"), start), nil
+		}
+		if len(choices) > maxPollOptions {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("too many options: %d (max %d)", len(choices), maxPollOptions), start), nil
+		}
+
+		tally := PollTally{MessageID: messageID, Options: make([]PollOptionTally, 0, len(choices))}
+		seen := make(map[string]bool, len(choices))
+		for _, option := range choices {
+			apiEmoji, err := emojis.normalizeEmoji(ctx, option)
+			if err != nil {
+				return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+			}
+			if seen[apiEmoji] {
+				return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("duplicate option %q", option), start), nil
+			}
+			seen[apiEmoji] = true
+
+			votes, truncated, err := countReactions(ctx, dg, channelID, messageID, apiEmoji, includeBots)
+			if err != nil {
+				return tools.AuditErrorResult(ctx, audit, toolName, params, fmt.Errorf("counting %q: %w", option, err), start), nil
+			}
+			tally.Options = append(tally.Options, PollOptionTally{Emoji: option, Votes: votes, Truncated: truncated})
+			tally.TotalVotes += votes
+		}
+
+		sort.SliceStable(tally.Options, func(i, j int) bool {
+			return tally.Options[i].Votes > tally.Options[j].Votes
+		})
+		tally.Winners = []string{}
+		if top := tally.Options[0].Votes; top > 0 {
+			for _, o := range tally.Options {
+				if o.Votes != top {
+					break
+				}
+				tally.Winners = append(tally.Winners, o.Emoji)
+			}
+		}
+		tally.Tie = len(tally.Winners) > 1
+
+		logger.DebugContext(ctx, "poll tallied", "channelID", channelID, "messageID", messageID, "total_votes", tally.TotalVotes, "winners", tally.Winners)
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d votes", tally.TotalVotes), start)
+		return tools.JSONResultWithText(pollSummary(tally), tally), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// countReactions counts the users who reacted to a message with apiEmoji,
+// paging through MessageReactions. Bot users are skipped unless includeBots
+// is set. It stops after maxPollVoters users and reports whether it did.
+func countReactions(ctx context.Context, dg discord.DiscordClient, channelID, messageID, apiEmoji string, includeBots bool) (int, bool, error) {
+	count, fetched := 0, 0
+	after := ""
+	for {
+		users, err := dg.MessageReactions(channelID, messageID, apiEmoji, reactionsPageSize, "", after, discordgo.WithContext(ctx))
+		if err != nil {
+			return 0, false, err
+		}
+		for _, u := range users {
+			if u != nil && (includeBots || !u.Bot) {
+				count++
+			}
+		}
+		fetched += len(users)
+		if len(users) < reactionsPageSize {
+			return count, false, nil
+		}
+		if fetched >= maxPollVoters {
+			return count, true, nil
+		}
+		after = users[len(users)-1].ID
+	}
+}
+
+// pollSummary returns a one-line description of the result of a poll.
+func pollSummary(t PollTally) string {
+	switch len(t.Winners) {
+	case 0:
+		return "No votes yet"
+	case 1:
+		return fmt.Sprintf("Winner: %s with %d of %d votes", t.Winners[0], t.Options[0].Votes, t.TotalVotes)
+	default:
+		return fmt.Sprintf("Tie between %s with %d votes each (%d total)", strings.Join(t.Winners, ", "), t.Options[0].Votes, t.TotalVotes)
+	}
+}
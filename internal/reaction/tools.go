@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/discord"
 	"github.com/jamesprial/claudebot-mcp/internal/resolve"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
@@ -15,22 +16,46 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// Option configures optional behaviour of the tools returned by ReactionTools.
+type Option func(*options)
+
+// options holds the settings applied by Option values.
+type options struct {
+	moderation bool
+}
+
+// WithModeration lets discord_remove_reaction remove other users' reactions.
+// It is off by default, so the tool can only remove the bot's own reactions.
+func WithModeration(enabled bool) Option {
+	return func(o *options) {
+		o.moderation = enabled
+	}
+}
+
 // ReactionTools returns all tool registrations for Discord reaction operations.
 func ReactionTools(
 	dg discord.DiscordClient,
 	r resolve.ChannelResolver,
+	defaultGuildID string,
 	filter *safety.Filter,
 	audit *safety.AuditLogger,
 	logger *slog.Logger,
+	opts ...Option,
 ) []tools.Registration {
 	logger = tools.DefaultLogger(logger)
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	emojis := newEmojiCache(dg, defaultGuildID)
 	return []tools.Registration{
-		toolAddReaction(dg, r, filter, audit, logger),
-		toolRemoveReaction(dg, r, filter, audit, logger),
+		toolAddReaction(dg, r, emojis, filter, audit, logger),
+		toolRemoveReaction(dg, r, emojis, filter, o.moderation, audit, logger),
+		toolReactPoll(dg, r, emojis, filter, audit, logger),
 	}
 }
 
-func toolAddReaction(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+func toolAddReaction(dg discord.DiscordClient, r resolve.ChannelResolver, emojis *emojiCache, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
 	const toolName = "discord_add_reaction"
 
 	tool := mcp.NewTool(toolName,
@@ -45,7 +70,7 @@ func toolAddReaction(dg discord.DiscordClient, r resolve.ChannelResolver, filter
 		),
 		mcp.WithString("emoji",
 			mcp.Required(),
-			mcp.Description("Emoji to add as a reaction (e.g. '👍' or 'custom_emoji:123456')"),
+			mcp.Description("Emoji to add as a reaction (unicode like '👍', or a custom emoji as 'name', 'name:id' or '<:name:id>')"),
 		),
 	)
 
@@ -60,23 +85,29 @@ func toolAddReaction(dg discord.DiscordClient, r resolve.ChannelResolver, filter
 			"emoji":      emoji,
 		}
 
-		channelID, _, errResult := tools.ResolveAndFilterChannel(r, filter, audit, logger, toolName, channel, params, start)
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, channel, params, start)
 		if errResult != nil {
 			return errResult, nil
 		}
 
-		if err := dg.MessageReactionAdd(channelID, messageID, emoji); err != nil {
-			return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+		apiEmoji, err := emojis.normalizeEmoji(ctx, emoji)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
 		}
+		logger.DebugContext(ctx, "normalized emoji", "input", emoji, "emoji", apiEmoji)
 
-		tools.LogAudit(audit, toolName, params, "ok", start)
+		if err := dg.MessageReactionAdd(channelID, messageID, apiEmoji, discordgo.WithContext(ctx)); err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, "ok", start)
 		return mcp.NewToolResultText(fmt.Sprintf("Reaction %q added successfully", emoji)), nil
 	}
 
 	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
 }
 
-func toolRemoveReaction(dg discord.DiscordClient, r resolve.ChannelResolver, filter *safety.Filter, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+func toolRemoveReaction(dg discord.DiscordClient, r resolve.ChannelResolver, emojis *emojiCache, filter *safety.Filter, moderation bool, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
 	const toolName = "discord_remove_reaction"
 
 	tool := mcp.NewTool(toolName,
@@ -91,7 +122,10 @@ func toolRemoveReaction(dg discord.DiscordClient, r resolve.ChannelResolver, fil
 		),
 		mcp.WithString("emoji",
 			mcp.Required(),
-			mcp.Description("Emoji to remove (e.g. '👍' or 'custom_emoji:123456')"),
+			mcp.Description("Emoji to remove (unicode like '👍', or a custom emoji as 'name', 'name:id' or '<:name:id>')"),
+		),
+		mcp.WithString("user_id",
+			mcp.Description("ID of the user whose reaction to remove (default: the bot's own). Other users' reactions can only be removed when moderation is enabled."),
 		),
 	)
 
@@ -100,22 +134,37 @@ func toolRemoveReaction(dg discord.DiscordClient, r resolve.ChannelResolver, fil
 		channel := req.GetString("channel", "")
 		messageID := req.GetString("message_id", "")
 		emoji := req.GetString("emoji", "")
+		userID := req.GetString("user_id", "")
+		if userID == "" {
+			userID = "@me"
+		}
 		params := map[string]any{
 			"channel":    channel,
 			"message_id": messageID,
 			"emoji":      emoji,
+			"user_id":    userID,
 		}
 
-		channelID, _, errResult := tools.ResolveAndFilterChannel(r, filter, audit, logger, toolName, channel, params, start)
+		channelID, _, errResult := tools.ResolveAndFilterChannel(ctx, r, filter, safety.OpWrite, audit, logger, toolName, channel, params, start)
 		if errResult != nil {
 			return errResult, nil
 		}
+		if userID != "@me" && !moderation {
+			err := fmt.Errorf("removing another user's reaction requires safety.allow_moderation")
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+
+		apiEmoji, err := emojis.normalizeEmoji(ctx, emoji)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
+		}
+		logger.DebugContext(ctx, "normalized emoji", "input", emoji, "emoji", apiEmoji)
 
-		if err := dg.MessageReactionRemove(channelID, messageID, emoji, "@me"); err != nil {
-			return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+		if err := dg.MessageReactionRemove(channelID, messageID, apiEmoji, userID, discordgo.WithContext(ctx)); err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
 		}
 
-		tools.LogAudit(audit, toolName, params, "ok", start)
+		tools.LogAudit(ctx, audit, toolName, params, "ok", start)
 		return mcp.NewToolResultText(fmt.Sprintf("Reaction %q removed successfully", emoji)), nil
 	}
 
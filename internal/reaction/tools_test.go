@@ -2,12 +2,16 @@ package reaction_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/reaction"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
 	"github.com/jamesprial/claudebot-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // ---------------------------------------------------------------------------
@@ -20,11 +24,12 @@ func Test_ReactionTools_Registration(t *testing.T) {
 	r := testutil.NewMockChannelResolver()
 	filter := safety.NewFilter(nil, nil)
 
-	regs := reaction.ReactionTools(client, r, filter, nil, nil)
+	regs := reaction.ReactionTools(client, r, "guild-1", filter, nil, nil)
 
 	testutil.AssertRegistrations(t, regs, []string{
 		"discord_add_reaction",
 		"discord_remove_reaction",
+		"discord_react_poll",
 	})
 }
 
@@ -38,13 +43,13 @@ func Test_AddReaction_Valid(t *testing.T) {
 	r := testutil.NewMockChannelResolver()
 	filter := safety.NewFilter(nil, nil)
 
-	regs := reaction.ReactionTools(client, r, filter, nil, nil)
+	regs := reaction.ReactionTools(client, r, "guild-1", filter, nil, nil)
 	handler := testutil.FindHandler(t, regs, "discord_add_reaction")
 
 	req := testutil.NewCallToolRequest("discord_add_reaction", map[string]any{
 		"channel":    "123456789012345678",
 		"message_id": "msg-100",
-		"emoji":      "thumbsup",
+		"emoji":      "👍",
 	})
 
 	result, err := handler(context.Background(), req)
@@ -66,13 +71,13 @@ func Test_AddReaction_DeniedChannel(t *testing.T) {
 	r := testutil.NewMockChannelResolver()
 	filter := safety.NewFilter(nil, []string{"general"})
 
-	regs := reaction.ReactionTools(client, r, filter, nil, nil)
+	regs := reaction.ReactionTools(client, r, "guild-1", filter, nil, nil)
 	handler := testutil.FindHandler(t, regs, "discord_add_reaction")
 
 	req := testutil.NewCallToolRequest("discord_add_reaction", map[string]any{
 		"channel":    "general",
 		"message_id": "msg-100",
-		"emoji":      "thumbsup",
+		"emoji":      "👍",
 	})
 
 	result, err := handler(context.Background(), req)
@@ -97,13 +102,13 @@ func Test_RemoveReaction_Valid(t *testing.T) {
 	r := testutil.NewMockChannelResolver()
 	filter := safety.NewFilter(nil, nil)
 
-	regs := reaction.ReactionTools(client, r, filter, nil, nil)
+	regs := reaction.ReactionTools(client, r, "guild-1", filter, nil, nil)
 	handler := testutil.FindHandler(t, regs, "discord_remove_reaction")
 
 	req := testutil.NewCallToolRequest("discord_remove_reaction", map[string]any{
 		"channel":    "123456789012345678",
 		"message_id": "msg-100",
-		"emoji":      "thumbsup",
+		"emoji":      "👍",
 	})
 
 	result, err := handler(context.Background(), req)
@@ -117,3 +122,366 @@ func Test_RemoveReaction_Valid(t *testing.T) {
 		t.Errorf("expected success for remove_reaction, got: %s", text)
 	}
 }
+
+func Test_RemoveReaction_UserID_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		moderation bool
+		userID     string
+		wantUser   string
+		wantErr    bool
+	}{
+		{name: "default removes own reaction", wantUser: "@me"},
+		{name: "explicit @me without moderation", userID: "@me", wantUser: "@me"},
+		{name: "other user with moderation", moderation: true, userID: "user-42", wantUser: "user-42"},
+		{name: "other user without moderation", userID: "user-42", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotUser string
+			called := false
+			client := &testutil.MockDiscordClient{
+				MessageReactionRemoveFunc: func(_, _, _, userID string, _ ...discordgo.RequestOption) error {
+					called = true
+					gotUser = userID
+					return nil
+				},
+			}
+			r := testutil.NewMockChannelResolver()
+			filter := safety.NewFilter(nil, nil)
+
+			regs := reaction.ReactionTools(client, r, "guild-1", filter, nil, nil, reaction.WithModeration(tt.moderation))
+			handler := testutil.FindHandler(t, regs, "discord_remove_reaction")
+
+			args := map[string]any{
+				"channel":    "123456789012345678",
+				"message_id": "msg-100",
+				"emoji":      "👍",
+			}
+			if tt.userID != "" {
+				args["user_id"] = tt.userID
+			}
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_remove_reaction", args))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+
+			if tt.wantErr {
+				testutil.AssertTextContains(t, result, "allow_moderation")
+				if called {
+					t.Error("MessageReactionRemove should not be called without moderation")
+				}
+				return
+			}
+			if text := testutil.ExtractText(t, result); strings.HasPrefix(strings.ToLower(text), "error:") {
+				t.Fatalf("expected success, got: %s", text)
+			}
+			if gotUser != tt.wantUser {
+				t.Errorf("userID = %q, want %q", gotUser, tt.wantUser)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Emoji normalization
+// ---------------------------------------------------------------------------
+
+func Test_AddReaction_EmojiNormalization(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		emoji string
+		want  string
+	}{
+		{name: "unicode passed through", emoji: "👍", want: "👍"},
+		{name: "mention form", emoji: "<:partyparrot:123456>", want: "partyparrot:123456"},
+		{name: "animated mention form", emoji: "<a:partyparrot:123456>", want: "partyparrot:123456"},
+		{name: "api form", emoji: "partyparrot:123456", want: "partyparrot:123456"},
+		{name: "bare name resolved via cache", emoji: "partyparrot", want: "partyparrot:emoji-001"},
+		{name: "colon-wrapped name resolved via cache", emoji: ":partyparrot:", want: "partyparrot:emoji-001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got string
+			client := &testutil.MockDiscordClient{
+				MessageReactionAddFunc: func(channelID, messageID, emojiID string, options ...discordgo.RequestOption) error {
+					got = emojiID
+					return nil
+				},
+			}
+			r := testutil.NewMockChannelResolver()
+
+			regs := reaction.ReactionTools(client, r, "guild-1", safety.NewFilter(nil, nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_add_reaction")
+
+			req := testutil.NewCallToolRequest("discord_add_reaction", map[string]any{
+				"channel":    "general",
+				"message_id": "msg-100",
+				"emoji":      tt.emoji,
+			})
+
+			result, err := handler(context.Background(), req)
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			testutil.AssertNotError(t, result)
+			if got != tt.want {
+				t.Errorf("emoji sent to Discord = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_RemoveReaction_UnknownCustomEmoji(t *testing.T) {
+	t.Parallel()
+	called := false
+	client := &testutil.MockDiscordClient{
+		MessageReactionRemoveFunc: func(channelID, messageID, emojiID, userID string, options ...discordgo.RequestOption) error {
+			called = true
+			return nil
+		},
+	}
+	r := testutil.NewMockChannelResolver()
+
+	regs := reaction.ReactionTools(client, r, "guild-1", safety.NewFilter(nil, nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_remove_reaction")
+
+	req := testutil.NewCallToolRequest("discord_remove_reaction", map[string]any{
+		"channel":    "general",
+		"message_id": "msg-100",
+		"emoji":      "thumbsup",
+	})
+
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected error result for unknown custom emoji")
+	}
+	testutil.AssertTextContains(t, result, `"thumbsup" not found`)
+	testutil.AssertTextContains(t, result, "partyparrot")
+	if called {
+		t.Error("MessageReactionRemove should not be called for an unresolved emoji")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// discord_react_poll handler
+// ---------------------------------------------------------------------------
+
+// voters returns n users with IDs starting at offset; every user is a bot
+// when bot is set.
+func voters(offset, n int, bot bool) []*discordgo.User {
+	users := make([]*discordgo.User, n)
+	for i := range users {
+		users[i] = &discordgo.User{ID: fmt.Sprintf("user-%d", offset+i), Bot: bot}
+	}
+	return users
+}
+
+func Test_ReactPoll_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		reactions   map[string][]*discordgo.User
+		options     []any
+		includeBots bool
+		wantOrder   []string
+		wantVotes   []int
+		wantWinners []string
+		wantTie     bool
+		wantText    string
+	}{
+		{
+			name: "single winner sorted first",
+			reactions: map[string][]*discordgo.User{
+				"👍": voters(0, 2, false),
+				"👎": voters(10, 5, false),
+				"🤷": voters(20, 1, false),
+			},
+			options:     []any{"👍", "👎", "🤷"},
+			wantOrder:   []string{"👎", "👍", "🤷"},
+			wantVotes:   []int{5, 2, 1},
+			wantWinners: []string{"👎"},
+			wantText:    "Winner: 👎 with 5 of 8 votes",
+		},
+		{
+			name: "tie keeps option order",
+			reactions: map[string][]*discordgo.User{
+				"👍": voters(0, 3, false),
+				"👎": voters(10, 3, false),
+				"🤷": voters(20, 1, false),
+			},
+			options:     []any{"🤷", "👎", "👍"},
+			wantOrder:   []string{"👎", "👍", "🤷"},
+			wantVotes:   []int{3, 3, 1},
+			wantWinners: []string{"👎", "👍"},
+			wantTie:     true,
+			wantText:    "Tie between 👎, 👍",
+		},
+		{
+			name: "bot seed reactions excluded",
+			reactions: map[string][]*discordgo.User{
+				"👍": append(voters(0, 1, true), voters(10, 1, false)...),
+				"👎": voters(20, 1, true),
+			},
+			options:     []any{"👍", "👎"},
+			wantOrder:   []string{"👍", "👎"},
+			wantVotes:   []int{1, 0},
+			wantWinners: []string{"👍"},
+		},
+		{
+			name: "bot reactions included",
+			reactions: map[string][]*discordgo.User{
+				"👍": voters(0, 1, true),
+				"👎": append(voters(10, 1, true), voters(20, 1, false)...),
+			},
+			options:     []any{"👍", "👎"},
+			includeBots: true,
+			wantOrder:   []string{"👎", "👍"},
+			wantVotes:   []int{2, 1},
+			wantWinners: []string{"👎"},
+		},
+		{
+			name:        "no votes has no winner",
+			reactions:   map[string][]*discordgo.User{},
+			options:     []any{"👍", "👎"},
+			wantOrder:   []string{"👍", "👎"},
+			wantVotes:   []int{0, 0},
+			wantWinners: []string{},
+			wantText:    "No votes yet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := &testutil.MockDiscordClient{
+				MessageReactionsFunc: func(_, _, emojiID string, _ int, _, _ string, _ ...discordgo.RequestOption) ([]*discordgo.User, error) {
+					return tt.reactions[emojiID], nil
+				},
+			}
+			regs := reaction.ReactionTools(client, testutil.NewMockChannelResolver(), "guild-1", safety.NewFilter(nil, nil), nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_react_poll")
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_react_poll", map[string]any{
+				"channel":      "general",
+				"message_id":   "msg-100",
+				"options":      tt.options,
+				"include_bots": tt.includeBots,
+			}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("unexpected error: %s", testutil.ExtractText(t, result))
+			}
+			if tt.wantText != "" {
+				testutil.AssertTextContains(t, result, tt.wantText)
+			}
+
+			tc, ok := result.Content[1].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("result content[1] is %T, want mcp.TextContent", result.Content[1])
+			}
+			var tally reaction.PollTally
+			if err := json.Unmarshal([]byte(tc.Text), &tally); err != nil {
+				t.Fatalf("unmarshal tally: %v\n%s", err, tc.Text)
+			}
+			if len(tally.Options) != len(tt.wantOrder) {
+				t.Fatalf("got %d options, want %d", len(tally.Options), len(tt.wantOrder))
+			}
+			for i, o := range tally.Options {
+				if o.Emoji != tt.wantOrder[i] || o.Votes != tt.wantVotes[i] {
+					t.Errorf("options[%d] = %s:%d, want %s:%d", i, o.Emoji, o.Votes, tt.wantOrder[i], tt.wantVotes[i])
+				}
+			}
+			if strings.Join(tally.Winners, ",") != strings.Join(tt.wantWinners, ",") {
+				t.Errorf("winners = %v, want %v", tally.Winners, tt.wantWinners)
+			}
+			if tally.Tie != tt.wantTie {
+				t.Errorf("tie = %v, want %v", tally.Tie, tt.wantTie)
+			}
+		})
+	}
+}
+
+func Test_ReactPoll_Paginates(t *testing.T) {
+	t.Parallel()
+	var afters []string
+	client := &testutil.MockDiscordClient{
+		MessageReactionsFunc: func(_, _, _ string, limit int, _, afterID string, _ ...discordgo.RequestOption) ([]*discordgo.User, error) {
+			afters = append(afters, afterID)
+			if afterID == "" {
+				return voters(0, limit, false), nil
+			}
+			return voters(limit, 30, false), nil
+		},
+	}
+	regs := reaction.ReactionTools(client, testutil.NewMockChannelResolver(), "guild-1", safety.NewFilter(nil, nil), nil, nil)
+	handler := testutil.FindHandler(t, regs, "discord_react_poll")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_react_poll", map[string]any{
+		"channel":    "general",
+		"message_id": "msg-100",
+		"options":    []any{"👍"},
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertTextContains(t, result, "with 130 of 130 votes")
+	if len(afters) != 2 || afters[1] != "user-99" {
+		t.Errorf("afterIDs = %v, want [\"\" user-99]", afters)
+	}
+}
+
+func Test_ReactPoll_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		filter  *safety.Filter
+		options []any
+		want    string
+	}{
+		{name: "denied channel", filter: safety.NewFilter(nil, []string{"general"}), options: []any{"👍"}, want: "not allowed"},
+		{name: "duplicate option", options: []any{"👍", "👍"}, want: "duplicate option"},
+		{name: "no options", options: []any{}, want: "at least one emoji"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			filter := tt.filter
+			if filter == nil {
+				filter = safety.NewFilter(nil, nil)
+			}
+			regs := reaction.ReactionTools(&testutil.MockDiscordClient{}, testutil.NewMockChannelResolver(), "guild-1", filter, nil, nil)
+			handler := testutil.FindHandler(t, regs, "discord_react_poll")
+
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_react_poll", map[string]any{
+				"channel":    "general",
+				"message_id": "msg-100",
+				"options":    tt.options,
+			}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatalf("expected error result, got: %s", testutil.ExtractText(t, result))
+			}
+			testutil.AssertTextContains(t, result, tt.want)
+		})
+	}
+}
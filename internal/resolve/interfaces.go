@@ -5,6 +5,7 @@ package resolve
 type ChannelResolver interface {
 	ChannelName(id string) string
 	ChannelID(name string) (string, error)
+	Snapshot() Snapshot
 }
 
 // Compile-time assertion: *Resolver satisfies ChannelResolver.
@@ -58,6 +58,36 @@ func Test_ResolveChannelParam_Cases(t *testing.T) {
 			wantID:  "",
 			wantErr: true,
 		},
+		{
+			name:    "channel mention",
+			input:   "<#123456789012345678>",
+			wantID:  "123456789012345678",
+			wantErr: false,
+		},
+		{
+			name:    "channel mention with bang",
+			input:   "<#!123456789012345678>",
+			wantID:  "123456789012345678",
+			wantErr: false,
+		},
+		{
+			name:    "mention of a name is not a mention",
+			input:   "<#general>",
+			wantID:  "",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated mention",
+			input:   "<#123",
+			wantID:  "",
+			wantErr: true,
+		},
+		{
+			name:    "user mention is not a channel",
+			input:   "<@123456789012345678>",
+			wantID:  "",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -2,13 +2,40 @@
 package resolve
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+// Errors returned by ChannelID match these with errors.Is, so callers can
+// tell a missing channel from an ambiguous name.
+var (
+	ErrChannelNotFound  = errors.New("resolve: channel not found")
+	ErrAmbiguousChannel = errors.New("resolve: ambiguous channel name")
+)
+
+// channelError carries a descriptive message while matching kind with
+// errors.Is.
+type channelError struct {
+	kind error
+	msg  string
+}
+
+func (e *channelError) Error() string { return e.msg }
+func (e *channelError) Unwrap() error { return e.kind }
+
+// NotFound returns the error for a channel name that matches no channel.
+// ChannelResolver implementations use it so that errors.Is(err,
+// ErrChannelNotFound) holds.
+func NotFound(name string) error {
+	return &channelError{kind: ErrChannelNotFound, msg: fmt.Sprintf("resolve: channel %q not found", name)}
+}
+
 // Resolver maintains an in-memory bidirectional cache of Discord channel IDs
 // and names for a single guild. It is safe for concurrent use.
 type Resolver struct {
@@ -16,18 +43,96 @@ type Resolver struct {
 	guildID string
 	mu      sync.RWMutex
 	byID    map[string]string // channel ID -> name
-	byName  map[string]string // channel name -> ID
+	byName  map[string]string // channel name -> ID, for unique names only
+	// byQualified maps "category/channel" to a channel ID, and collisions
+	// maps a name or qualified name shared by several channels to those
+	// channels.
+	byQualified map[string]string
+	collisions  map[string][]ChannelRef
+	// refreshedAt is when Refresh last succeeded; zero if it never has.
+	refreshedAt time.Time
+	// verifyNumericIDs makes ResolveChannelParam check all-digit input
+	// against the cache; see WithVerifyNumericIDs.
+	verifyNumericIDs bool
+	// missTTL enables refreshing on a cache miss, and missing maps each name
+	// still absent after such a refresh to when that result expires; see
+	// WithRefreshOnMiss. missMu serializes the refreshes, so concurrent
+	// misses wait for one instead of each fetching the channel list.
+	missTTL time.Duration
+	missing map[string]time.Time
+	missMu  sync.Mutex
+	now     func() time.Time
+}
+
+// DefaultMissTTL is how long a name that was not found after a refresh is
+// remembered as missing when WithRefreshOnMiss is given no positive TTL.
+const DefaultMissTTL = 30 * time.Second
+
+// Option is a functional option for configuring a Resolver.
+type Option func(*Resolver)
+
+// WithVerifyNumericIDs controls how ResolveChannelParam treats all-digit
+// input. Discord channel names may consist solely of digits (e.g. "2024"),
+// which by default are taken to be channel IDs. When enabled, an all-digit
+// value that is not a cached channel ID but is a cached channel name resolves
+// to that channel; anything else is still passed through as an ID, since the
+// cache only holds text channels and may be stale.
+func WithVerifyNumericIDs(enabled bool) Option {
+	return func(r *Resolver) {
+		r.verifyNumericIDs = enabled
+	}
+}
+
+// WithRefreshOnMiss makes ChannelID refresh the cache when a name is not in
+// it, so a channel created since the last refresh resolves without waiting
+// for a reconnect. A name still missing afterwards is remembered for ttl and
+// fails without another refresh until then, so repeated lookups of a name
+// that does not exist do not each fetch the channel list. A ttl of zero or
+// less uses DefaultMissTTL.
+func WithRefreshOnMiss(ttl time.Duration) Option {
+	return func(r *Resolver) {
+		if ttl <= 0 {
+			ttl = DefaultMissTTL
+		}
+		r.missTTL = ttl
+	}
+}
+
+// Snapshot is a point-in-time copy of a Resolver's cache, for debugging
+// channel resolution.
+type Snapshot struct {
+	GuildID string            `json:"guild_id"`
+	ByID    map[string]string `json:"by_id"`
+	ByName  map[string]string `json:"by_name"`
+	// RefreshedAt is when the cache was last refreshed successfully; nil if
+	// it never has been.
+	RefreshedAt *time.Time `json:"refreshed_at"`
+	// Collisions lists names shared by more than one channel; such names
+	// are absent from ByName.
+	Collisions map[string][]ChannelRef `json:"collisions,omitempty"`
+}
+
+// ChannelRef identifies one of several channels sharing a name.
+type ChannelRef struct {
+	ID       string `json:"id"`
+	Category string `json:"category,omitempty"`
 }
 
 // New constructs a Resolver for the given guild backed by the provided
-// discordgo session. The cache is empty until Refresh is called.
-func New(session *discordgo.Session, guildID string) *Resolver {
-	return &Resolver{
+// discordgo session with the provided options applied. The cache is empty
+// until Refresh is called.
+func New(session *discordgo.Session, guildID string, opts ...Option) *Resolver {
+	r := &Resolver{
 		session: session,
 		guildID: guildID,
 		byID:    make(map[string]string),
 		byName:  make(map[string]string),
+		now:     time.Now,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // GuildID returns the guild ID this Resolver was constructed with.
@@ -49,33 +154,140 @@ func (r *Resolver) ChannelName(id string) string {
 }
 
 // ChannelID returns the ID for the channel with the given name. A leading "#"
-// is stripped before the lookup. If the name is not present in the cache, an
-// error is returned.
+// is stripped before the lookup. The name may be qualified with its category
+// as "category/channel" to pick one of several channels sharing a name. If
+// the name is not present in the cache, or is shared by several channels, an
+// error is returned; the latter lists the candidates. With WithRefreshOnMiss,
+// a missing name is looked up again after refreshing the cache.
 func (r *Resolver) ChannelID(name string) (string, error) {
 	name = strings.TrimPrefix(name, "#")
+	id, err := r.lookup(name)
+	if r.missTTL <= 0 || name == "" || !errors.Is(err, ErrChannelNotFound) {
+		return id, err
+	}
+	return r.refreshOnMiss(name)
+}
+
+// refreshOnMiss refreshes the cache and looks name up again, unless name was
+// found missing by a refresh within the last missTTL.
+func (r *Resolver) refreshOnMiss(name string) (string, error) {
+	r.missMu.Lock()
+	defer r.missMu.Unlock()
+
+	r.mu.RLock()
+	expires, known := r.missing[name]
+	r.mu.RUnlock()
+	if known && r.now().Before(expires) {
+		return "", NotFound(name)
+	}
+	// A refresh made for another name while this call waited may have
+	// added it.
+	if id, err := r.lookup(name); !errors.Is(err, ErrChannelNotFound) {
+		return id, err
+	}
+
+	// A failed refresh is remembered too, so an outage does not turn every
+	// lookup into a request.
+	if err := r.Refresh(); err == nil {
+		if id, err := r.lookup(name); !errors.Is(err, ErrChannelNotFound) {
+			return id, err
+		}
+	}
+	r.mu.Lock()
+	if r.missing == nil {
+		r.missing = make(map[string]time.Time)
+	}
+	r.missing[name] = r.now().Add(r.missTTL)
+	r.mu.Unlock()
+	return "", NotFound(name)
+}
 
+// lookup returns the cached ID for name, which has no leading "#".
+func (r *Resolver) lookup(name string) (string, error) {
 	r.mu.RLock()
+	refs := r.collisions[name]
 	id, ok := r.byName[name]
+	if !ok {
+		id, ok = r.byQualified[name]
+	}
 	r.mu.RUnlock()
 
+	if len(refs) > 0 {
+		return "", ambiguousError(name, refs)
+	}
 	if !ok {
-		return "", fmt.Errorf("resolve: channel %q not found", name)
+		return "", NotFound(name)
 	}
 	return id, nil
 }
 
+// ambiguousError describes a name shared by the channels in refs.
+func ambiguousError(name string, refs []ChannelRef) error {
+	candidates := make([]string, len(refs))
+	for i, ref := range refs {
+		category := ref.Category
+		if category == "" {
+			category = "no category"
+		}
+		candidates[i] = fmt.Sprintf("%s (%s)", ref.ID, category)
+	}
+	return &channelError{kind: ErrAmbiguousChannel, msg: fmt.Sprintf("resolve: channel name %q is ambiguous, matching %s; use a channel ID or \"category/channel\"",
+		name, strings.Join(candidates, ", "))}
+}
+
+// RefreshedAt returns when Refresh last succeeded, or the zero time if it
+// never has. Until then only channel IDs can be resolved.
+func (r *Resolver) RefreshedAt() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.refreshedAt
+}
+
+// Snapshot returns a copy of the cache contents. The returned maps are not
+// shared with the Resolver and may be modified freely.
+func (r *Resolver) Snapshot() Snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	snap := Snapshot{
+		GuildID: r.guildID,
+		ByID:    maps.Clone(r.byID),
+		ByName:  maps.Clone(r.byName),
+	}
+	if len(r.collisions) > 0 {
+		snap.Collisions = make(map[string][]ChannelRef, len(r.collisions))
+		for name, refs := range r.collisions {
+			snap.Collisions[name] = append([]ChannelRef(nil), refs...)
+		}
+	}
+	if !r.refreshedAt.IsZero() {
+		t := r.refreshedAt
+		snap.RefreshedAt = &t
+	}
+	return snap
+}
+
 // Refresh fetches the current channel list for the guild from Discord and
 // updates the cache. Only text channels (Type == discordgo.ChannelTypeGuildText,
-// numeric value 0) are indexed. A write lock is held only during the map swap,
-// so concurrent reads are not blocked during the network call.
+// numeric value 0) are indexed, by name and by "category/channel". Names
+// shared by several channels are recorded as collisions rather than indexed.
+// A write lock is held only during the map swap, so concurrent reads are not
+// blocked during the network call.
 func (r *Resolver) Refresh() error {
 	channels, err := r.session.GuildChannels(r.guildID)
 	if err != nil {
 		return fmt.Errorf("resolve: failed to fetch guild channels: %w", err)
 	}
 
+	categories := make(map[string]string)
+	for _, ch := range channels {
+		if ch.Type == discordgo.ChannelTypeGuildCategory {
+			categories[ch.ID] = ch.Name
+		}
+	}
+
 	newByID := make(map[string]string, len(channels))
-	newByName := make(map[string]string, len(channels))
+	matches := make(map[string][]ChannelRef, len(channels))
+	qualified := make(map[string]bool)
 
 	for _, ch := range channels {
 		// Only cache text channels (Type == 0).
@@ -83,34 +295,119 @@ func (r *Resolver) Refresh() error {
 			continue
 		}
 		newByID[ch.ID] = ch.Name
-		newByName[ch.Name] = ch.ID
+		ref := ChannelRef{ID: ch.ID, Category: categories[ch.ParentID]}
+		matches[ch.Name] = append(matches[ch.Name], ref)
+		if ref.Category != "" {
+			key := ref.Category + "/" + ch.Name
+			matches[key] = append(matches[key], ref)
+			qualified[key] = true
+		}
+	}
+
+	newByName := make(map[string]string, len(channels))
+	newByQualified := make(map[string]string)
+	newCollisions := make(map[string][]ChannelRef)
+	for key, refs := range matches {
+		switch {
+		case len(refs) > 1:
+			newCollisions[key] = refs
+		case qualified[key]:
+			newByQualified[key] = refs[0].ID
+		default:
+			newByName[key] = refs[0].ID
+		}
 	}
 
 	r.mu.Lock()
 	r.byID = newByID
 	r.byName = newByName
+	r.byQualified = newByQualified
+	r.collisions = newCollisions
+	r.refreshedAt = time.Now()
+	// Names remembered as missing may exist now.
+	r.missing = nil
 	r.mu.Unlock()
 
 	return nil
 }
 
+// resolveNumeric resolves an all-digit channel parameter. See
+// WithVerifyNumericIDs.
+func (r *Resolver) resolveNumeric(channel string) string {
+	if !r.verifyNumericIDs {
+		return channel
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.byID[channel]; ok {
+		return channel
+	}
+	if id, ok := r.byName[channel]; ok {
+		return id
+	}
+	return channel
+}
+
+// numericResolver is implemented by resolvers that can check all-digit
+// channel parameters against their cache.
+type numericResolver interface {
+	resolveNumeric(channel string) string
+}
+
 // ResolveChannelParam resolves a channel parameter that may be a name or ID.
 // All-digit strings are treated as IDs, otherwise looked up via the Resolver.
-// A leading "#" is stripped from names.
+// A leading "#" is stripped from names. A channel mention as Discord writes
+// it, <#id> (or <#!id>), resolves to its ID, so a parameter copied from a
+// message works.
+//
+// All-digit input is ambiguous: it may be an ID or the name of a channel such
+// as "#2024". A *Resolver created with WithVerifyNumericIDs falls back to a
+// name lookup for such input when it is not a known channel ID. A mention is
+// never ambiguous and is always taken as an ID.
 func ResolveChannelParam(r ChannelResolver, channel string) (string, error) {
+	if id, ok := channelMentionID(channel); ok {
+		return id, nil
+	}
 	channel = strings.TrimPrefix(channel, "#")
 
 	// All-digit strings are already IDs.
-	allDigits := len(channel) > 0
-	for _, c := range channel {
-		if c < '0' || c > '9' {
-			allDigits = false
-			break
+	if isAllDigits(channel) {
+		if nr, ok := r.(numericResolver); ok {
+			return nr.resolveNumeric(channel), nil
 		}
-	}
-	if allDigits {
 		return channel, nil
 	}
 
 	return r.ChannelID(channel)
 }
+
+// channelMentionID returns the ID in a channel mention of the form <#id> or
+// <#!id>, and whether s is one.
+func channelMentionID(s string) (string, bool) {
+	inner, ok := strings.CutPrefix(s, "<#")
+	if !ok {
+		return "", false
+	}
+	inner, ok = strings.CutSuffix(inner, ">")
+	if !ok {
+		return "", false
+	}
+	inner = strings.TrimPrefix(inner, "!")
+	if !isAllDigits(inner) {
+		return "", false
+	}
+	return inner, true
+}
+
+// isAllDigits reports whether s is non-empty and made only of ASCII digits.
+func isAllDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -2,9 +2,13 @@ package resolve
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -38,7 +42,7 @@ func testChannels() []*discordgo.Channel {
 
 // newTestResolver sets up a mock Discord API server and returns a Resolver
 // that uses it, plus a cleanup function.
-func newTestResolver(t *testing.T, guildID string, channels []*discordgo.Channel) *Resolver {
+func newTestResolver(t *testing.T, guildID string, channels []*discordgo.Channel, opts ...Option) *Resolver {
 	t.Helper()
 
 	mux := http.NewServeMux()
@@ -72,7 +76,7 @@ func newTestResolver(t *testing.T, guildID string, channels []*discordgo.Channel
 		discordgo.EndpointGuilds = origGuilds
 	})
 
-	return New(session, guildID)
+	return New(session, guildID, opts...)
 }
 
 // ---------------------------------------------------------------------------
@@ -228,6 +232,67 @@ func Test_ChannelID_BeforeRefresh_ReturnsError(t *testing.T) {
 	}
 }
 
+func Test_ChannelID_RefreshOnMiss(t *testing.T) {
+	channels := []*discordgo.Channel{
+		{ID: "111", Name: "general", Type: discordgo.ChannelTypeGuildText},
+	}
+	fetches := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v9/guilds/guild-1/channels", func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(channels); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	session, err := discordgo.New("Bot fake-token")
+	if err != nil {
+		t.Fatalf("failed to create discordgo session: %v", err)
+	}
+	origAPI := discordgo.EndpointAPI
+	origGuilds := discordgo.EndpointGuilds
+	discordgo.EndpointAPI = server.URL + "/api/v9/"
+	discordgo.EndpointGuilds = discordgo.EndpointAPI + "guilds/"
+	t.Cleanup(func() {
+		discordgo.EndpointAPI = origAPI
+		discordgo.EndpointGuilds = origGuilds
+	})
+
+	r := New(session, "guild-1", WithRefreshOnMiss(time.Minute))
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	if err := r.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	// A channel created after the refresh resolves on its first lookup.
+	channels = append(channels, &discordgo.Channel{ID: "222", Name: "new-channel", Type: discordgo.ChannelTypeGuildText})
+	if id, err := r.ChannelID("new-channel"); err != nil || id != "222" {
+		t.Fatalf("ChannelID(new-channel) = %q, %v; want 222", id, err)
+	}
+	if fetches != 2 {
+		t.Fatalf("fetches = %d after miss, want 2", fetches)
+	}
+
+	// A name still missing is remembered until the TTL expires.
+	for range 3 {
+		if _, err := r.ChannelID("nonexistent"); !errors.Is(err, ErrChannelNotFound) {
+			t.Fatalf("ChannelID(nonexistent) error = %v, want ErrChannelNotFound", err)
+		}
+	}
+	if fetches != 3 {
+		t.Errorf("fetches = %d after repeated misses, want 3", fetches)
+	}
+	now = now.Add(time.Minute)
+	_, _ = r.ChannelID("nonexistent")
+	if fetches != 4 {
+		t.Errorf("fetches = %d after the TTL, want 4", fetches)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Edge cases
 // ---------------------------------------------------------------------------
@@ -328,3 +393,183 @@ func Test_Refresh_MultipleCallsOverwriteCache(t *testing.T) {
 		t.Errorf("after second refresh: ChannelName('111') = %q, want %q (cache miss)", name, "111")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Snapshot
+// ---------------------------------------------------------------------------
+
+func Test_RefreshedAt(t *testing.T) {
+	r := newTestResolver(t, "guild-1", testChannels())
+
+	if got := r.RefreshedAt(); !got.IsZero() {
+		t.Errorf("RefreshedAt() = %v before Refresh, want zero", got)
+	}
+	if err := r.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if r.RefreshedAt().IsZero() {
+		t.Error("RefreshedAt() is zero after Refresh")
+	}
+}
+
+func Test_Snapshot_BeforeRefresh(t *testing.T) {
+	r := newTestResolver(t, "guild-1", testChannels())
+
+	snap := r.Snapshot()
+	if snap.GuildID != "guild-1" {
+		t.Errorf("GuildID = %q, want %q", snap.GuildID, "guild-1")
+	}
+	if len(snap.ByID) != 0 || len(snap.ByName) != 0 {
+		t.Errorf("snapshot before Refresh = %+v, want empty maps", snap)
+	}
+	if snap.RefreshedAt != nil {
+		t.Errorf("RefreshedAt = %v before Refresh, want nil", snap.RefreshedAt)
+	}
+}
+
+func Test_Snapshot_AfterRefresh_IsCopy(t *testing.T) {
+	r := newTestResolver(t, "guild-1", testChannels())
+	if err := r.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	snap := r.Snapshot()
+	if len(snap.ByID) != 3 || snap.ByID["111"] != "general" || snap.ByName["announcements"] != "444" {
+		t.Errorf("snapshot = %+v, want the three text channels", snap)
+	}
+	if snap.RefreshedAt == nil {
+		t.Error("RefreshedAt = nil after Refresh, want a time")
+	}
+
+	// Mutating the snapshot must not affect the cache.
+	snap.ByName["general"] = "999"
+	if id, _ := r.ChannelID("general"); id != "111" {
+		t.Errorf("ChannelID('general') = %q after mutating snapshot, want %q", id, "111")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// WithVerifyNumericIDs
+// ---------------------------------------------------------------------------
+
+func Test_ResolveChannelParam_AllDigitName(t *testing.T) {
+	channels := append(testChannels(),
+		&discordgo.Channel{ID: "555", Name: "2024", Type: discordgo.ChannelTypeGuildText},
+	)
+
+	tests := []struct {
+		name   string
+		verify bool
+		input  string
+		wantID string
+	}{
+		{name: "default treats digit name as ID", verify: false, input: "2024", wantID: "2024"},
+		{name: "verify resolves digit name", verify: true, input: "2024", wantID: "555"},
+		{name: "verify resolves hash-prefixed digit name", verify: true, input: "#2024", wantID: "555"},
+		{name: "verify keeps cached ID", verify: true, input: "111", wantID: "111"},
+		{name: "verify passes through unknown ID", verify: true, input: "999", wantID: "999"},
+		{name: "verify takes mention as ID", verify: true, input: "<#2024>", wantID: "2024"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestResolver(t, "guild-1", channels, WithVerifyNumericIDs(tt.verify))
+			if err := r.Refresh(); err != nil {
+				t.Fatalf("Refresh() error = %v", err)
+			}
+
+			id, err := ResolveChannelParam(r, tt.input)
+			if err != nil {
+				t.Fatalf("ResolveChannelParam(%q) error = %v", tt.input, err)
+			}
+			if id != tt.wantID {
+				t.Errorf("ResolveChannelParam(%q) = %q, want %q", tt.input, id, tt.wantID)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Name collisions
+// ---------------------------------------------------------------------------
+
+// collidingChannels returns two "general" channels under different
+// categories plus one uniquely named channel.
+func collidingChannels() []*discordgo.Channel {
+	return []*discordgo.Channel{
+		{ID: "900", Name: "Text", Type: discordgo.ChannelTypeGuildCategory},
+		{ID: "901", Name: "Archive", Type: discordgo.ChannelTypeGuildCategory},
+		{ID: "111", Name: "general", Type: discordgo.ChannelTypeGuildText, ParentID: "900"},
+		{ID: "222", Name: "general", Type: discordgo.ChannelTypeGuildText, ParentID: "901"},
+		{ID: "333", Name: "random", Type: discordgo.ChannelTypeGuildText, ParentID: "900"},
+	}
+}
+
+func Test_ChannelID_NameCollision(t *testing.T) {
+	r := newTestResolver(t, "guild-1", collidingChannels())
+	if err := r.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	_, err := r.ChannelID("general")
+	if err == nil {
+		t.Fatal("ChannelID('general') expected ambiguity error, got nil")
+	}
+	for _, want := range []string{"ambiguous", "111 (Text)", "222 (Archive)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	// Both channels keep their names for ID lookups.
+	if name := r.ChannelName("222"); name != "general" {
+		t.Errorf("ChannelName('222') = %q, want %q", name, "general")
+	}
+}
+
+func Test_ChannelID_CategoryQualified(t *testing.T) {
+	r := newTestResolver(t, "guild-1", collidingChannels())
+	if err := r.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	tests := []struct {
+		input   string
+		wantID  string
+		wantErr bool
+	}{
+		{input: "Text/general", wantID: "111"},
+		{input: "#Archive/general", wantID: "222"},
+		{input: "Text/random", wantID: "333"},
+		{input: "random", wantID: "333"},
+		{input: "Archive/random", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			id, err := r.ChannelID(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChannelID(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if id != tt.wantID {
+				t.Errorf("ChannelID(%q) = %q, want %q", tt.input, id, tt.wantID)
+			}
+		})
+	}
+}
+
+func Test_Snapshot_ListsCollisions(t *testing.T) {
+	r := newTestResolver(t, "guild-1", collidingChannels())
+	if err := r.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	snap := r.Snapshot()
+	if _, ok := snap.ByName["general"]; ok {
+		t.Error("ByName contains colliding name 'general'")
+	}
+	want := []ChannelRef{{ID: "111", Category: "Text"}, {ID: "222", Category: "Archive"}}
+	if got := snap.Collisions["general"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Collisions['general'] = %v, want %v", got, want)
+	}
+}
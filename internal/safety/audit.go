@@ -3,6 +3,7 @@ package safety
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"sync"
 	"time"
@@ -12,40 +13,121 @@ import (
 // with a nil writer.
 var ErrNilWriter = errors.New("audit logger: writer is nil")
 
+// Outcome categorises the result of a tool invocation so audit logs can be
+// filtered without parsing the freeform Result string.
+type Outcome string
+
+// Outcome values recorded in AuditEntry.Outcome.
+const (
+	OutcomeSuccess    Outcome = "success"
+	OutcomeDenied     Outcome = "denied"
+	OutcomeError      Outcome = "error"
+	OutcomeNoMessages Outcome = "no_messages"
+)
+
 // AuditEntry captures a single tool invocation for the audit log.
+//
+// RequestID identifies the tool call that produced the entry and matches the
+// request_id field of the server's log lines for that call. Client is the
+// label of the authenticated HTTP client that made the call, empty when
+// authentication is disabled or over stdio.
 type AuditEntry struct {
 	Timestamp time.Time      `json:"timestamp"`
+	RequestID string         `json:"request_id,omitempty"`
+	Client    string         `json:"client,omitempty"`
 	Tool      string         `json:"tool"`
 	Params    map[string]any `json:"params"`
+	Outcome   Outcome        `json:"outcome"`
 	Result    string         `json:"result"`
 	Duration  time.Duration  `json:"duration_ns"`
 }
 
-// AuditLogger writes AuditEntry records as newline-delimited JSON to an
-// io.Writer. It is safe for concurrent use.
+// AuditSink receives every audit entry in addition to the NDJSON writer, for
+// example to export it to a tracing backend. Record must be safe for
+// concurrent use.
+type AuditSink interface {
+	Record(entry AuditEntry)
+}
+
+// AuditFormat selects how AuditLogger encodes each entry.
+type AuditFormat string
+
+// AuditFormat values accepted by ParseAuditFormat.
+const (
+	// AuditFormatJSON writes one JSON object per line (NDJSON).
+	AuditFormatJSON AuditFormat = "json"
+	// AuditFormatLogfmt writes one logfmt line of key=value pairs per
+	// entry; see encodeLogfmt.
+	AuditFormatLogfmt AuditFormat = "logfmt"
+)
+
+// ParseAuditFormat returns the AuditFormat named by s. An empty s means
+// AuditFormatJSON.
+func ParseAuditFormat(s string) (AuditFormat, error) {
+	switch f := AuditFormat(s); f {
+	case "":
+		return AuditFormatJSON, nil
+	case AuditFormatJSON, AuditFormatLogfmt:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown audit format %q (want json or logfmt)", s)
+	}
+}
+
+// AuditLogger writes AuditEntry records, one per line, to an io.Writer and
+// forwards them to any configured sinks. It is safe for concurrent use.
 type AuditLogger struct {
-	mu sync.Mutex
-	w  io.Writer
+	mu     sync.Mutex
+	w      io.Writer
+	format AuditFormat
+	sinks  []AuditSink
+}
+
+// NewAuditLogger returns an AuditLogger that writes JSON lines to w and
+// forwards entries to sinks. If w is nil and no sinks are given the returned
+// logger is also nil; callers must check for nil before use. A nil w with
+// sinks yields a logger that only feeds the sinks.
+func NewAuditLogger(w io.Writer, sinks ...AuditSink) *AuditLogger {
+	return NewAuditLoggerWithFormat(w, AuditFormatJSON, sinks...)
 }
 
-// NewAuditLogger returns an AuditLogger that writes to w. If w is nil the
-// returned logger is also nil; callers must check for nil before use.
-func NewAuditLogger(w io.Writer) *AuditLogger {
-	if w == nil {
+// NewAuditLoggerWithFormat is like NewAuditLogger but encodes entries
+// written to w in format. An empty format means AuditFormatJSON.
+func NewAuditLoggerWithFormat(w io.Writer, format AuditFormat, sinks ...AuditSink) *AuditLogger {
+	if w == nil && len(sinks) == 0 {
 		return nil
 	}
-	return &AuditLogger{w: w}
+	if format == "" {
+		format = AuditFormatJSON
+	}
+	return &AuditLogger{w: w, format: format, sinks: sinks}
 }
 
-// Log serialises entry as a single JSON line and writes it to the underlying
-// writer. It returns an error if the writer is nil or if serialisation or
-// writing fails. Log is safe for concurrent use.
+// Log serialises entry as a single line in the logger's format and writes it
+// to the underlying writer, then forwards it to each sink. Sensitive
+// parameters are redacted first; see RedactParams. It returns an error if the
+// logger is nil or if serialisation or writing fails. Log is safe for
+// concurrent use.
 func (l *AuditLogger) Log(entry AuditEntry) error {
-	if l == nil || l.w == nil {
+	if l == nil || (l.w == nil && len(l.sinks) == 0) {
 		return ErrNilWriter
 	}
+	entry.Params = RedactParams(entry.Params)
 
-	data, err := json.Marshal(entry)
+	for _, sink := range l.sinks {
+		sink.Record(entry)
+	}
+	if l.w == nil {
+		return nil
+	}
+
+	var data []byte
+	var err error
+	if l.format == AuditFormatLogfmt {
+		data, err = encodeLogfmt(entry)
+	} else {
+		data, err = json.Marshal(entry)
+	}
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,87 @@
+package safety
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// encodeLogfmt renders entry as a logfmt line without the trailing newline.
+// Keys match the JSON field names: timestamp (RFC 3339 with nanoseconds),
+// request_id and client (each omitted when empty), tool, outcome, result and duration_ns.
+// Each parameter follows as params.<name>, in name order; strings are
+// written as is and any other value as its JSON encoding, so nested maps
+// and slices stay on one line. Values are quoted when they contain spaces,
+// quotes, '=' or control characters, or are empty.
+func encodeLogfmt(entry AuditEntry) ([]byte, error) {
+	var b strings.Builder
+	writePair := func(key, value string) {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(value))
+	}
+
+	writePair("timestamp", entry.Timestamp.Format(time.RFC3339Nano))
+	if entry.RequestID != "" {
+		writePair("request_id", entry.RequestID)
+	}
+	if entry.Client != "" {
+		writePair("client", entry.Client)
+	}
+	writePair("tool", entry.Tool)
+	writePair("outcome", string(entry.Outcome))
+	writePair("result", entry.Result)
+	writePair("duration_ns", strconv.FormatInt(int64(entry.Duration), 10))
+
+	for _, name := range slices.Sorted(maps.Keys(entry.Params)) {
+		value, err := logfmtParam(entry.Params[name])
+		if err != nil {
+			return nil, err
+		}
+		writePair("params."+logfmtKey(name), value)
+	}
+	return []byte(b.String()), nil
+}
+
+// logfmtParam renders a parameter value: strings unchanged, anything else
+// JSON encoded.
+func logfmtParam(v any) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// logfmtValue quotes s when it would otherwise not read back as one value.
+func logfmtValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || unicode.IsControl(r) || unicode.IsSpace(r)
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// logfmtKey replaces characters that would break a logfmt key with '_'.
+func logfmtKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || unicode.IsControl(r) || unicode.IsSpace(r) {
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,14 @@
+//go:build !windows && !plan9
+
+package safety
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// dialSyslog connects to a syslog daemon. Empty network and addr select the
+// local daemon.
+func dialSyslog(network, addr, tag string) (io.WriteCloser, error) {
+	return syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+}
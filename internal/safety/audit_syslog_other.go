@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package safety
+
+import (
+	"errors"
+	"io"
+)
+
+// dialSyslog reports that syslog is unavailable on this platform.
+func dialSyslog(network, addr, tag string) (io.WriteCloser, error) {
+	return nil, errors.New("audit logger: syslog is not supported on this platform")
+}
@@ -3,6 +3,7 @@ package safety
 import (
 	"bytes"
 	"encoding/json"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -43,6 +44,7 @@ func Test_AuditLogger_Log_ValidEntry(t *testing.T) {
 		Timestamp: time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC),
 		Tool:      "discord_send_message",
 		Params:    map[string]any{"channel": "general", "content": "hello"},
+		Outcome:   OutcomeSuccess,
 		Result:    "success",
 		Duration:  100 * time.Millisecond,
 	}
@@ -71,6 +73,9 @@ func Test_AuditLogger_Log_ValidEntry(t *testing.T) {
 	if decoded["result"] != "success" {
 		t.Errorf("result = %v, want %q", decoded["result"], "success")
 	}
+	if decoded["outcome"] != "success" {
+		t.Errorf("outcome = %v, want %q", decoded["outcome"], "success")
+	}
 }
 
 func Test_AuditLogger_Log_NilParams(t *testing.T) {
@@ -223,3 +228,166 @@ func Benchmark_AuditLogger_Log(b *testing.B) {
 		_ = logger.Log(entry)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Formats
+// ---------------------------------------------------------------------------
+
+// parseLogfmt splits a logfmt line into its pairs, unquoting quoted values.
+func parseLogfmt(t *testing.T, line string) map[string]string {
+	t.Helper()
+	out := make(map[string]string)
+	for line != "" {
+		key, rest, ok := strings.Cut(line, "=")
+		if !ok {
+			t.Fatalf("no '=' in %q", line)
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				t.Fatalf("bad quoted value in %q: %v", rest, err)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+			rest = " " + rest
+		}
+		out[key] = value
+		line = strings.TrimPrefix(rest, " ")
+	}
+	return out
+}
+
+func Test_ParseAuditFormat_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    AuditFormat
+		wantErr bool
+	}{
+		{"", AuditFormatJSON, false},
+		{"json", AuditFormatJSON, false},
+		{"logfmt", AuditFormatLogfmt, false},
+		{"text", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAuditFormat(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAuditFormat(%q) = (%q, %v), want (%q, err %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func Test_AuditLogger_Formats_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	entry := AuditEntry{
+		Timestamp: time.Date(2026, 1, 15, 10, 30, 0, 123, time.UTC),
+		RequestID: "req-7",
+		Client:    "ci-agent",
+		Tool:      "discord_send_message",
+		Params: map[string]any{
+			"channel": "general",
+			"content": `say "hi" = wave`,
+			"limit":   5,
+			"embed":   map[string]any{"title": "T"},
+			"empty":   "",
+		},
+		Outcome:  OutcomeError,
+		Result:   "error: channel not found",
+		Duration: 1500 * time.Microsecond,
+	}
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		if err := NewAuditLoggerWithFormat(&buf, AuditFormatJSON).Log(entry); err != nil {
+			t.Fatalf("Log() error: %v", err)
+		}
+		var got AuditEntry
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if !got.Timestamp.Equal(entry.Timestamp) || got.RequestID != entry.RequestID || got.Client != entry.Client || got.Tool != entry.Tool ||
+			got.Outcome != entry.Outcome || got.Result != entry.Result || got.Duration != entry.Duration {
+			t.Errorf("round trip = %+v, want %+v", got, entry)
+		}
+		if got.Params["content"] != entry.Params["content"] {
+			t.Errorf("params.content = %v, want %v", got.Params["content"], entry.Params["content"])
+		}
+	})
+
+	t.Run("logfmt", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		if err := NewAuditLoggerWithFormat(&buf, AuditFormatLogfmt).Log(entry); err != nil {
+			t.Fatalf("Log() error: %v", err)
+		}
+		line := buf.String()
+		if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+			t.Fatalf("want exactly one line, got %q", line)
+		}
+		got := parseLogfmt(t, strings.TrimSuffix(line, "\n"))
+
+		want := map[string]string{
+			"timestamp":      "2026-01-15T10:30:00.000000123Z",
+			"request_id":     "req-7",
+			"client":         "ci-agent",
+			"tool":           "discord_send_message",
+			"outcome":        "error",
+			"result":         "error: channel not found",
+			"duration_ns":    "1500000",
+			"params.channel": "general",
+			"params.content": `say "hi" = wave`,
+			"params.limit":   "5",
+			"params.embed":   `{"title":"T"}`,
+			"params.empty":   "",
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("%s = %q, want %q", k, got[k], v)
+			}
+		}
+		if len(got) != len(want) {
+			t.Errorf("got %d keys, want %d: %v", len(got), len(want), got)
+		}
+		if !strings.HasPrefix(line, "timestamp=") {
+			t.Errorf("line should start with timestamp: %q", line)
+		}
+	})
+}
+
+func Test_AuditLogger_Logfmt_OmitsEmptyRequestID(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	logger := NewAuditLoggerWithFormat(&buf, AuditFormatLogfmt)
+	if err := logger.Log(AuditEntry{Tool: "discord_get_user", Outcome: OutcomeSuccess, Result: "ok"}); err != nil {
+		t.Fatalf("Log() error: %v", err)
+	}
+	if strings.Contains(buf.String(), "request_id") {
+		t.Errorf("empty request_id should be omitted: %q", buf.String())
+	}
+}
+
+func Test_AuditLogger_Log_RedactsSensitiveParams(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	logger := NewAuditLogger(&buf)
+
+	err := logger.Log(AuditEntry{
+		Timestamp: time.Now(),
+		Tool:      "discord_delete_message",
+		Params:    map[string]any{"message_id": "123", "confirmation_token": "abc"},
+		Outcome:   OutcomeSuccess,
+		Result:    "ok",
+	})
+	if err != nil {
+		t.Fatalf("Log() unexpected error: %v", err)
+	}
+	if out := buf.String(); strings.Contains(out, "abc") || !strings.Contains(out, RedactedValue) {
+		t.Errorf("confirmation_token not redacted: %s", out)
+	}
+}
@@ -0,0 +1,96 @@
+package safety
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Audit log destinations recognised by OpenAuditWriter in addition to file
+// paths.
+const (
+	auditStdout       = "stdout"
+	auditStderr       = "stderr"
+	auditSyslogScheme = "syslog"
+	defaultSyslogTag  = "claudebot-mcp"
+)
+
+// auditTarget describes where audit entries are written, as parsed from the
+// audit.log_path config value.
+type auditTarget struct {
+	kind    string // "file", "stdout", "stderr", or "syslog"
+	path    string // file path for kind "file"
+	network string // syslog network ("" for the local daemon)
+	addr    string // syslog address ("" for the local daemon)
+	tag     string // syslog tag
+}
+
+// parseAuditTarget interprets logPath. "-" and "stdout" select standard
+// output, "stderr" selects standard error, and a syslog:// URI selects syslog:
+//
+//	syslog://                        local syslog daemon
+//	syslog://host:514                remote daemon over UDP
+//	syslog://host:514?network=tcp    remote daemon over TCP
+//	syslog://?tag=mybot              custom tag (default "claudebot-mcp")
+//
+// Any other value is treated as a file path.
+func parseAuditTarget(logPath string) (auditTarget, error) {
+	switch strings.ToLower(logPath) {
+	case "-", auditStdout:
+		return auditTarget{kind: auditStdout}, nil
+	case auditStderr:
+		return auditTarget{kind: auditStderr}, nil
+	}
+
+	if !strings.HasPrefix(strings.ToLower(logPath), auditSyslogScheme+"://") {
+		return auditTarget{kind: "file", path: logPath}, nil
+	}
+
+	u, err := url.Parse(logPath)
+	if err != nil {
+		return auditTarget{}, fmt.Errorf("invalid syslog URI %q: %w", logPath, err)
+	}
+	t := auditTarget{kind: auditSyslogScheme, tag: defaultSyslogTag}
+	if u.Host != "" {
+		t.addr = u.Host
+		t.network = "udp"
+	}
+	q := u.Query()
+	if network := q.Get("network"); network != "" {
+		if u.Host == "" {
+			return auditTarget{}, fmt.Errorf("invalid syslog URI %q: network requires a host", logPath)
+		}
+		t.network = network
+	}
+	if tag := q.Get("tag"); tag != "" {
+		t.tag = tag
+	}
+	return t, nil
+}
+
+// nopWriteCloser wraps a writer that must not be closed, such as os.Stdout.
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// OpenAuditWriter returns the writer for the audit.log_path config value. See
+// parseAuditTarget for the accepted forms. Closing the returned writer closes
+// the underlying file or syslog connection; it is a no-op for stdout/stderr.
+func OpenAuditWriter(logPath string) (io.WriteCloser, error) {
+	t, err := parseAuditTarget(logPath)
+	if err != nil {
+		return nil, err
+	}
+	switch t.kind {
+	case auditStdout:
+		return nopWriteCloser{os.Stdout}, nil
+	case auditStderr:
+		return nopWriteCloser{os.Stderr}, nil
+	case auditSyslogScheme:
+		return dialSyslog(t.network, t.addr, t.tag)
+	default:
+		return os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	}
+}
@@ -0,0 +1,140 @@
+package safety
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// parseAuditTarget
+// ---------------------------------------------------------------------------
+
+func Test_parseAuditTarget_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		logPath string
+		want    auditTarget
+		wantErr bool
+	}{
+		{name: "dash selects stdout", logPath: "-", want: auditTarget{kind: "stdout"}},
+		{name: "stdout keyword", logPath: "stdout", want: auditTarget{kind: "stdout"}},
+		{name: "keyword is case-insensitive", logPath: "STDERR", want: auditTarget{kind: "stderr"}},
+		{name: "file path", logPath: "/var/log/audit.log", want: auditTarget{kind: "file", path: "/var/log/audit.log"}},
+		{name: "relative file path", logPath: "audit.log", want: auditTarget{kind: "file", path: "audit.log"}},
+		{
+			name:    "local syslog",
+			logPath: "syslog://",
+			want:    auditTarget{kind: "syslog", tag: "claudebot-mcp"},
+		},
+		{
+			name:    "remote syslog defaults to udp",
+			logPath: "syslog://logs.example.com:514",
+			want:    auditTarget{kind: "syslog", network: "udp", addr: "logs.example.com:514", tag: "claudebot-mcp"},
+		},
+		{
+			name:    "remote syslog with network and tag",
+			logPath: "syslog://logs.example.com:514?network=tcp&tag=bot",
+			want:    auditTarget{kind: "syslog", network: "tcp", addr: "logs.example.com:514", tag: "bot"},
+		},
+		{name: "network without host", logPath: "syslog://?network=tcp", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseAuditTarget(tt.logPath)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseAuditTarget(%q) expected error, got %+v", tt.logPath, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAuditTarget(%q) unexpected error: %v", tt.logPath, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseAuditTarget(%q) = %+v, want %+v", tt.logPath, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// OpenAuditWriter
+// ---------------------------------------------------------------------------
+
+func Test_OpenAuditWriter_Stdout(t *testing.T) {
+	t.Parallel()
+
+	w, err := OpenAuditWriter("-")
+	if err != nil {
+		t.Fatalf("OpenAuditWriter(\"-\") unexpected error: %v", err)
+	}
+	nop, ok := w.(nopWriteCloser)
+	if !ok || nop.Writer != os.Stdout {
+		t.Fatalf("OpenAuditWriter(\"-\") = %T, want stdout wrapper", w)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() on stdout writer returned error: %v", err)
+	}
+}
+
+func Test_OpenAuditWriter_File(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	w, err := OpenAuditWriter(path)
+	if err != nil {
+		t.Fatalf("OpenAuditWriter(%q) unexpected error: %v", path, err)
+	}
+	if _, err := w.Write([]byte("line\n")); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	_ = w.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	if string(data) != "line\n" {
+		t.Errorf("file contents = %q, want %q", data, "line\n")
+	}
+}
+
+func Test_OpenAuditWriter_Syslog(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on UDP: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	w, err := OpenAuditWriter("syslog://" + conn.LocalAddr().String() + "?tag=audit-test")
+	if err != nil {
+		t.Fatalf("OpenAuditWriter(syslog) unexpected error: %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	logger := NewAuditLogger(w)
+	if err := logger.Log(AuditEntry{Timestamp: time.Now(), Tool: "syslog_tool", Outcome: OutcomeSuccess}); err != nil {
+		t.Fatalf("Log() error: %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("reading syslog packet: %v", err)
+	}
+	got := string(buf[:n])
+	if !strings.Contains(got, "audit-test") || !strings.Contains(got, "syslog_tool") {
+		t.Errorf("syslog packet = %q, want tag and tool name", got)
+	}
+}
@@ -3,6 +3,7 @@ package safety
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -17,35 +18,110 @@ type pendingConfirmation struct {
 	createdAt    time.Time
 }
 
+// ConfirmationMode selects how callers confirm destructive tool calls.
+type ConfirmationMode string
+
+// ConfirmationMode values accepted by ParseConfirmationMode.
+const (
+	// ConfirmToken is the default two-step flow: the first call returns a
+	// single-use token that a second call passes back as confirmation_token.
+	ConfirmToken ConfirmationMode = "token"
+	// ConfirmBoolean accepts a confirm: true argument instead of a token.
+	// It trusts the client to have obtained the user's approval: nothing
+	// stops an agent from setting confirm on its first call, so use it only
+	// with clients that gate destructive calls on an explicit approval step.
+	ConfirmBoolean ConfirmationMode = "boolean"
+	// ConfirmOff runs destructive tools without any confirmation.
+	ConfirmOff ConfirmationMode = "off"
+)
+
+// ParseConfirmationMode returns the ConfirmationMode named by s. An empty s
+// means ConfirmToken.
+func ParseConfirmationMode(s string) (ConfirmationMode, error) {
+	switch m := ConfirmationMode(s); m {
+	case "":
+		return ConfirmToken, nil
+	case ConfirmToken, ConfirmBoolean, ConfirmOff:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown confirmation mode %q (want token, boolean or off)", s)
+	}
+}
+
 // ConfirmationTracker manages single-use, time-limited confirmation tokens for
 // destructive tool invocations.
 type ConfirmationTracker struct {
 	destructive map[string]struct{}
+	mode        ConfirmationMode
 
 	mu     sync.Mutex
 	tokens map[string]*pendingConfirmation
 }
 
+// ConfirmationOption is a functional option for NewConfirmationTracker.
+type ConfirmationOption func(*ConfirmationTracker)
+
+// WithConfirmationMode sets how destructive calls are confirmed. An empty
+// mode keeps the default, ConfirmToken.
+func WithConfirmationMode(mode ConfirmationMode) ConfirmationOption {
+	return func(ct *ConfirmationTracker) {
+		if mode != "" {
+			ct.mode = mode
+		}
+	}
+}
+
 // NewConfirmationTracker returns a ConfirmationTracker whose set of tools
 // requiring explicit confirmation is defined by destructiveTools. A nil or
 // empty slice means no tools require confirmation.
-func NewConfirmationTracker(destructiveTools []string) *ConfirmationTracker {
+func NewConfirmationTracker(destructiveTools []string, opts ...ConfirmationOption) *ConfirmationTracker {
 	ct := &ConfirmationTracker{
 		destructive: make(map[string]struct{}, len(destructiveTools)),
+		mode:        ConfirmToken,
 		tokens:      make(map[string]*pendingConfirmation),
 	}
 	for _, tool := range destructiveTools {
 		ct.destructive[tool] = struct{}{}
 	}
+	for _, opt := range opts {
+		opt(ct)
+	}
 	return ct
 }
 
-// NeedsConfirmation reports whether tool is in the destructive-tools set.
+// Mode returns how destructive calls are confirmed.
+func (ct *ConfirmationTracker) Mode() ConfirmationMode {
+	return ct.mode
+}
+
+// NeedsConfirmation reports whether tool is in the destructive-tools set and
+// confirmation is not turned off.
 func (ct *ConfirmationTracker) NeedsConfirmation(tool string) bool {
+	if ct.mode == ConfirmOff {
+		return false
+	}
 	_, ok := ct.destructive[tool]
 	return ok
 }
 
+// Approve reports whether a call of tool on resourceName is confirmed under
+// the tracker's mode: by a valid token (see Confirm) in token mode, by
+// confirmed in boolean mode, and always when confirmation is off. When it
+// returns false the second result says why.
+func (ct *ConfirmationTracker) Approve(token string, confirmed bool, tool, resourceName string) (bool, string) {
+	switch ct.mode {
+	case ConfirmOff:
+		return true, ""
+	case ConfirmBoolean:
+		if !confirmed {
+			return false, "confirm was not set to true"
+		}
+		return true, ""
+	default:
+		return ct.Confirm(token, tool, resourceName)
+	}
+}
+
 // sweepExpired removes all tokens whose age exceeds tokenTTL. The caller must
 // hold ct.mu.
 func (ct *ConfirmationTracker) sweepExpired() {
@@ -75,11 +151,14 @@ func (ct *ConfirmationTracker) RequestConfirmation(tool, resourceName, descripti
 	return token
 }
 
-// Confirm consumes the given token and returns true if it was valid and
-// unexpired. Subsequent calls with the same token return false.
-func (ct *ConfirmationTracker) Confirm(token string) bool {
+// Confirm consumes the given token and reports whether it was valid,
+// unexpired, and issued for the same tool and resource. When it returns false
+// the second result says why. A token is consumed by any attempt to use it,
+// even one that fails because of a mismatch, so subsequent calls with the
+// same token return false.
+func (ct *ConfirmationTracker) Confirm(token, tool, resourceName string) (bool, string) {
 	if token == "" {
-		return false
+		return false, "no confirmation token given"
 	}
 
 	ct.mu.Lock()
@@ -87,7 +166,7 @@ func (ct *ConfirmationTracker) Confirm(token string) bool {
 
 	pending, ok := ct.tokens[token]
 	if !ok {
-		return false
+		return false, "unknown or already used confirmation token"
 	}
 
 	// Remove the token immediately (single-use).
@@ -95,10 +174,16 @@ func (ct *ConfirmationTracker) Confirm(token string) bool {
 
 	// Check expiry.
 	if time.Since(pending.createdAt) > tokenTTL {
-		return false
+		return false, "confirmation token expired"
+	}
+	if pending.tool != tool {
+		return false, fmt.Sprintf("confirmation token was issued for %s, not %s", pending.tool, tool)
+	}
+	if pending.resourceName != resourceName {
+		return false, fmt.Sprintf("confirmation token was issued for %q, not %q", pending.resourceName, resourceName)
 	}
 
-	return true
+	return true, ""
 }
 
 // generateToken returns a cryptographically random hex-encoded token string.
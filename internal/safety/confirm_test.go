@@ -1,6 +1,7 @@
 package safety
 
 import (
+	"strings"
 	"sync"
 	"testing"
 )
@@ -106,9 +107,10 @@ func Test_Confirm_Cases(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name  string
-		setup func(ct *ConfirmationTracker) string // returns token to confirm
-		want  bool
+		name       string
+		setup      func(ct *ConfirmationTracker) string // returns token to confirm
+		want       bool
+		wantReason string
 	}{
 		{
 			name: "valid unused token returns true",
@@ -121,7 +123,7 @@ func Test_Confirm_Cases(t *testing.T) {
 			name: "already-used token returns false",
 			setup: func(ct *ConfirmationTracker) string {
 				token := ct.RequestConfirmation("tool", "resource", "desc")
-				ct.Confirm(token) // consume it
+				ct.Confirm(token, "tool", "resource") // consume it
 				return token
 			},
 			want: false,
@@ -140,6 +142,22 @@ func Test_Confirm_Cases(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "token for another resource returns false",
+			setup: func(ct *ConfirmationTracker) string {
+				return ct.RequestConfirmation("tool", "other-resource", "desc")
+			},
+			want:       false,
+			wantReason: `issued for "other-resource", not "resource"`,
+		},
+		{
+			name: "token for another tool returns false",
+			setup: func(ct *ConfirmationTracker) string {
+				return ct.RequestConfirmation("other-tool", "resource", "desc")
+			},
+			want:       false,
+			wantReason: "issued for other-tool, not tool",
+		},
 	}
 
 	for _, tt := range tests {
@@ -147,14 +165,94 @@ func Test_Confirm_Cases(t *testing.T) {
 			t.Parallel()
 			ct := NewConfirmationTracker([]string{"tool"})
 			token := tt.setup(ct)
-			got := ct.Confirm(token)
+			got, reason := ct.Confirm(token, "tool", "resource")
 			if got != tt.want {
 				t.Errorf("Confirm(%q) = %v, want %v", token, got, tt.want)
 			}
+			if got && reason != "" {
+				t.Errorf("Confirm(%q) succeeded with reason %q, want none", token, reason)
+			}
+			if !got && reason == "" {
+				t.Errorf("Confirm(%q) failed without a reason", token)
+			}
+			if tt.wantReason != "" && !strings.Contains(reason, tt.wantReason) {
+				t.Errorf("reason = %q, want it to contain %q", reason, tt.wantReason)
+			}
 		})
 	}
 }
 
+func Test_Confirm_MismatchConsumesToken(t *testing.T) {
+	t.Parallel()
+	ct := NewConfirmationTracker([]string{"tool"})
+	token := ct.RequestConfirmation("tool", "message-A", "desc")
+
+	if ok, _ := ct.Confirm(token, "tool", "message-B"); ok {
+		t.Fatal("token issued for message-A should not confirm message-B")
+	}
+	if ok, _ := ct.Confirm(token, "tool", "message-A"); ok {
+		t.Error("token should be consumed by the mismatched attempt")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Confirmation modes
+// ---------------------------------------------------------------------------
+
+func Test_ParseConfirmationMode_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    ConfirmationMode
+		wantErr bool
+	}{
+		{in: "", want: ConfirmToken},
+		{in: "token", want: ConfirmToken},
+		{in: "boolean", want: ConfirmBoolean},
+		{in: "off", want: ConfirmOff},
+		{in: "yes", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseConfirmationMode(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseConfirmationMode(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func Test_Approve_Modes(t *testing.T) {
+	t.Parallel()
+
+	token := NewConfirmationTracker([]string{"tool"})
+	valid := token.RequestConfirmation("tool", "res", "desc")
+	if ok, _ := token.Approve("", true, "tool", "res"); ok {
+		t.Error("token mode should ignore confirmed")
+	}
+	if ok, reason := token.Approve(valid, false, "tool", "res"); !ok {
+		t.Errorf("token mode rejected a valid token: %s", reason)
+	}
+
+	boolean := NewConfirmationTracker([]string{"tool"}, WithConfirmationMode(ConfirmBoolean))
+	if ok, _ := boolean.Approve("", false, "tool", "res"); ok {
+		t.Error("boolean mode approved without confirmed")
+	}
+	if ok, reason := boolean.Approve("", true, "tool", "res"); !ok {
+		t.Errorf("boolean mode rejected confirmed: %s", reason)
+	}
+	if !boolean.NeedsConfirmation("tool") {
+		t.Error("boolean mode should still require confirmation")
+	}
+
+	off := NewConfirmationTracker([]string{"tool"}, WithConfirmationMode(ConfirmOff))
+	if ok, _ := off.Approve("", false, "tool", "res"); !ok {
+		t.Error("off mode should approve every call")
+	}
+	if off.NeedsConfirmation("tool") {
+		t.Error("off mode should not require confirmation")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Concurrency: 100 concurrent RequestConfirmation calls
 // ---------------------------------------------------------------------------
@@ -208,7 +306,7 @@ func Test_Confirm_ConcurrentSingleUse(t *testing.T) {
 	for i := 0; i < numGoroutines; i++ {
 		go func(idx int) {
 			defer wg.Done()
-			results[idx] = ct.Confirm(token)
+			results[idx], _ = ct.Confirm(token, "tool", "resource")
 		}(i)
 	}
 	wg.Wait()
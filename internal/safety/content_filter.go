@@ -0,0 +1,136 @@
+package safety
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ContentFilter decides whether a message body is worth ingesting, based on
+// keyword and regular-expression allowlists and denylists. Plain entries are
+// substring matches (e.g. "!bot"); entries prefixed with "re:" are regular
+// expressions searched anywhere in the content (e.g. "re:^!bot\b").
+//
+// Matching is case-insensitive unless WithContentCaseSensitive(true) is
+// supplied.
+//
+// Rules:
+//   - If both lists are empty (or nil), every message is allowed.
+//   - Denylist always takes priority over the allowlist.
+//   - If a non-empty allowlist is present, content must match at least one
+//     entry to be permitted. With WithAllowMentions(true), a message that
+//     mentions the bot also satisfies the allowlist.
+type ContentFilter struct {
+	allowlist     []contentPattern
+	denylist      []contentPattern
+	caseSensitive bool
+	allowMentions bool
+}
+
+// contentPattern is a single compiled content filter entry: either a keyword
+// substring or, for "re:" entries, a regular expression.
+type contentPattern struct {
+	keyword string
+	re      *regexp.Regexp
+}
+
+// match reports whether content (already normalised) matches p.
+func (p contentPattern) match(content string) bool {
+	if p.re != nil {
+		return p.re.MatchString(content)
+	}
+	return strings.Contains(content, p.keyword)
+}
+
+// ContentFilterOption is a functional option for configuring a ContentFilter.
+type ContentFilterOption func(*ContentFilter)
+
+// WithContentCaseSensitive controls whether keywords and regexes are compared
+// case-sensitively. The default is case-insensitive.
+func WithContentCaseSensitive(caseSensitive bool) ContentFilterOption {
+	return func(f *ContentFilter) {
+		f.caseSensitive = caseSensitive
+	}
+}
+
+// WithAllowMentions lets messages that mention the bot through the allowlist
+// regardless of their content. The denylist still applies.
+func WithAllowMentions(allow bool) ContentFilterOption {
+	return func(f *ContentFilter) {
+		f.allowMentions = allow
+	}
+}
+
+// NewContentFilter constructs a ContentFilter from keyword and "re:" entries.
+// It returns an error naming each regular expression that fails to compile.
+func NewContentFilter(allowlist, denylist []string, opts ...ContentFilterOption) (*ContentFilter, error) {
+	f := &ContentFilter{}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	var invalid []string
+	compile := func(listName string, entries []string) []contentPattern {
+		out := make([]contentPattern, 0, len(entries))
+		for _, entry := range entries {
+			p, err := f.compile(entry)
+			if err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s %q", listName, entry))
+				continue
+			}
+			out = append(out, p)
+		}
+		return out
+	}
+	f.allowlist = compile("allowlist", allowlist)
+	f.denylist = compile("denylist", denylist)
+
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid content filter patterns: %s", strings.Join(invalid, ", "))
+	}
+	return f, nil
+}
+
+// IsAllowed reports whether a message with the given content should be
+// ingested. mentionsBot reports whether the message mentions the bot.
+func (f *ContentFilter) IsAllowed(content string, mentionsBot bool) bool {
+	if !f.caseSensitive {
+		content = strings.ToLower(content)
+	}
+
+	// Denylist wins first.
+	for _, p := range f.denylist {
+		if p.match(content) {
+			return false
+		}
+	}
+
+	if len(f.allowlist) == 0 {
+		return true
+	}
+	if f.allowMentions && mentionsBot {
+		return true
+	}
+
+	for _, p := range f.allowlist {
+		if p.match(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// compile converts a single entry into a contentPattern.
+func (f *ContentFilter) compile(entry string) (contentPattern, error) {
+	if expr, ok := strings.CutPrefix(entry, regexPrefix); ok {
+		if !f.caseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		return contentPattern{re: re}, err
+	}
+	if !f.caseSensitive {
+		entry = strings.ToLower(entry)
+	}
+	return contentPattern{keyword: entry}, nil
+}
@@ -0,0 +1,64 @@
+package safety
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_ContentFilter_IsAllowed_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		allowlist   []string
+		denylist    []string
+		opts        []ContentFilterOption
+		content     string
+		mentionsBot bool
+		want        bool
+	}{
+		{name: "empty lists allow everything", content: "hello", want: true},
+		{name: "keyword allowlist match", allowlist: []string{"!bot"}, content: "hey !bot help", want: true},
+		{name: "keyword allowlist miss", allowlist: []string{"!bot"}, content: "just chatting", want: false},
+		{name: "keyword is case-insensitive by default", allowlist: []string{"!BOT"}, content: "!bot ping", want: true},
+		{name: "case-sensitive keyword miss", allowlist: []string{"!BOT"}, opts: []ContentFilterOption{WithContentCaseSensitive(true)}, content: "!bot ping", want: false},
+		{name: "regex prefix match", allowlist: []string{`re:^!bot\b`}, content: "!bot status", want: true},
+		{name: "regex prefix not at start", allowlist: []string{`re:^!bot\b`}, content: "try !bot status", want: false},
+		{name: "denylist keyword blocks", denylist: []string{"spoiler"}, content: "big SPOILER ahead", want: false},
+		{name: "denylist beats allowlist", allowlist: []string{"!bot"}, denylist: []string{"spoiler"}, content: "!bot spoiler", want: false},
+		{name: "mention ignored without option", allowlist: []string{"!bot"}, content: "hi there", mentionsBot: true, want: false},
+		{name: "mention passes allowlist with option", allowlist: []string{"!bot"}, opts: []ContentFilterOption{WithAllowMentions(true)}, content: "hi there", mentionsBot: true, want: true},
+		{name: "mention does not bypass denylist", denylist: []string{"spoiler"}, opts: []ContentFilterOption{WithAllowMentions(true)}, content: "spoiler", mentionsBot: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := NewContentFilter(tt.allowlist, tt.denylist, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewContentFilter() unexpected error: %v", err)
+			}
+			if got := f.IsAllowed(tt.content, tt.mentionsBot); got != tt.want {
+				t.Errorf("IsAllowed(%q, %v) = %v, want %v", tt.content, tt.mentionsBot, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_NewContentFilter_InvalidRegex(t *testing.T) {
+	t.Parallel()
+
+	f, err := NewContentFilter([]string{"re:(unclosed"}, []string{"ok", "re:[bad"})
+	if err == nil {
+		t.Fatal("NewContentFilter() expected error, got nil")
+	}
+	if f != nil {
+		t.Error("NewContentFilter() should return nil filter on error")
+	}
+	for _, want := range []string{`allowlist "re:(unclosed"`, `denylist "re:[bad"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %s", err, want)
+		}
+	}
+}
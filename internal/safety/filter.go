@@ -2,10 +2,22 @@
 // destructive or sensitive claudebot-mcp operations.
 package safety
 
-import "path/filepath"
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// regexPrefix marks a filter entry as a regular expression rather than a glob.
+const regexPrefix = "re:"
 
 // Filter controls access to named resources using an allowlist and a denylist.
 // Glob patterns (as understood by filepath.Match) are supported in both lists.
+// Entries prefixed with "re:" are regular expressions instead, e.g.
+// "re:^team-.*$" or "re:-logs$".
+//
+// Matching is case-insensitive unless WithCaseSensitive(true) is supplied.
 //
 // Rules:
 //   - If both lists are empty (or nil), every resource is allowed.
@@ -13,25 +25,165 @@ import "path/filepath"
 //   - If a non-empty allowlist is present, a resource must match at least one
 //     allowlist pattern to be permitted (after the denylist check).
 type Filter struct {
-	allowlist []string
-	denylist  []string
+	allowlist     []pattern
+	denylist      []pattern
+	caseSensitive bool
+	// ops holds extra filters that apply only to one kind of operation,
+	// checked by IsAllowedFor after the filter's own lists.
+	ops map[Operation]*Filter
+}
+
+// Operation is the kind of access a tool needs to a resource, letting a
+// channel be readable but not writable.
+type Operation int
+
+const (
+	// OpRead covers tools that only read, such as fetching history.
+	OpRead Operation = iota
+	// OpWrite covers tools that change something, such as sending,
+	// editing, deleting or reacting.
+	OpWrite
+)
+
+// String returns "read" or "write".
+func (o Operation) String() string {
+	if o == OpWrite {
+		return "write"
+	}
+	return "read"
+}
+
+// pattern is a single compiled filter entry: either a glob or, for "re:"
+// entries, a regular expression. A "re:" entry that fails to compile has a
+// nil re and never matches.
+type pattern struct {
+	glob string
+	re   *regexp.Regexp
+	isRe bool
+}
+
+// match reports whether name matches p. Glob errors (malformed patterns) are
+// treated as non-matching.
+func (p pattern) match(name string) bool {
+	if p.isRe {
+		return p.re != nil && p.re.MatchString(name)
+	}
+	return matchGlob(p.glob, name)
+}
+
+// FilterOption is a functional option for configuring a Filter.
+type FilterOption func(*Filter)
+
+// WithCaseSensitive controls whether patterns and names are compared
+// case-sensitively. The default is case-insensitive, so a denylist entry of
+// "general" also blocks a channel named "General".
+func WithCaseSensitive(caseSensitive bool) FilterOption {
+	return func(f *Filter) {
+		f.caseSensitive = caseSensitive
+	}
+}
+
+// WithOperationFilter adds a filter that applies only to op, on top of the
+// filter's own allowlist and denylist. A nil sub filter is ignored.
+func WithOperationFilter(op Operation, sub *Filter) FilterOption {
+	return func(f *Filter) {
+		if sub == nil {
+			return
+		}
+		if f.ops == nil {
+			f.ops = make(map[Operation]*Filter)
+		}
+		f.ops[op] = sub
+	}
 }
 
 // NewFilter constructs a Filter from the provided allowlist and denylist
 // pattern slices. Either or both may be nil or empty.
-func NewFilter(allowlist, denylist []string) *Filter {
-	return &Filter{
-		allowlist: allowlist,
-		denylist:  denylist,
+func NewFilter(allowlist, denylist []string, opts ...FilterOption) *Filter {
+	f := &Filter{}
+	for _, opt := range opts {
+		opt(f)
 	}
+	f.allowlist = f.compileAll(allowlist)
+	f.denylist = f.compileAll(denylist)
+	return f
+}
+
+// NewFilterValidated is like NewFilter but first checks every pattern in
+// both lists and returns an error naming each malformed one, so a bad pattern
+// is reported at startup rather than silently never matching. Invalid "re:"
+// entries are reported the same way as malformed globs.
+func NewFilterValidated(allowlist, denylist []string, opts ...FilterOption) (*Filter, error) {
+	f := NewFilter(allowlist, denylist, opts...)
+	var invalid []string
+	for _, list := range []struct {
+		name     string
+		patterns []string
+	}{
+		{"allowlist", allowlist},
+		{"denylist", denylist},
+	} {
+		for _, entry := range list.patterns {
+			if _, err := f.compile(entry); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s %q", list.name, entry))
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid filter patterns: %s", strings.Join(invalid, ", "))
+	}
+	return f, nil
 }
 
 // IsAllowed reports whether name is permitted by this filter.
 func (f *Filter) IsAllowed(name string) bool {
+	return f.IsAllowedAny(name)
+}
+
+// IsAllowedFor reports whether name is permitted for op: it must pass both
+// the filter's own lists and any filter added for op with
+// WithOperationFilter.
+func (f *Filter) IsAllowedFor(op Operation, name string) bool {
+	return f.IsAllowedForAny(op, name)
+}
+
+// IsAllowedForAny is IsAllowedFor for a resource known by several names,
+// such as a channel's ID and name, matched as by IsAllowedAny.
+func (f *Filter) IsAllowedForAny(op Operation, names ...string) bool {
+	if !f.IsAllowedAny(names...) {
+		return false
+	}
+	sub, ok := f.ops[op]
+	return !ok || sub.IsAllowedAny(names...)
+}
+
+// HasAllowlist reports whether an allowlist applies to op, either the
+// filter's own or one added for op with WithOperationFilter. A resource
+// whose name is unknown cannot be shown to match it.
+func (f *Filter) HasAllowlist(op Operation) bool {
+	if len(f.allowlist) > 0 {
+		return true
+	}
+	sub, ok := f.ops[op]
+	return ok && len(sub.allowlist) > 0
+}
+
+// IsAllowedAny reports whether a resource known by several names (for
+// example a user's ID and username) is permitted. The resource is denied if
+// any name matches the denylist, and with a non-empty allowlist it is allowed
+// only if at least one name matches.
+func (f *Filter) IsAllowedAny(names ...string) bool {
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		normalized[i] = f.normalize(name)
+	}
+
 	// Denylist wins first.
-	for _, pattern := range f.denylist {
-		if matchGlob(pattern, name) {
-			return false
+	for _, p := range f.denylist {
+		for _, name := range normalized {
+			if p.match(name) {
+				return false
+			}
 		}
 	}
 
@@ -41,15 +193,53 @@ func (f *Filter) IsAllowed(name string) bool {
 	}
 
 	// Resource must match at least one allowlist pattern.
-	for _, pattern := range f.allowlist {
-		if matchGlob(pattern, name) {
-			return true
+	for _, p := range f.allowlist {
+		for _, name := range normalized {
+			if p.match(name) {
+				return true
+			}
 		}
 	}
 
 	return false
 }
 
+// normalize folds s to lower case when the filter is case-insensitive.
+func (f *Filter) normalize(s string) string {
+	if f.caseSensitive {
+		return s
+	}
+	return strings.ToLower(s)
+}
+
+// compile converts a single filter entry into a pattern. Regex entries are
+// compiled with the (?i) flag when the filter is case-insensitive; globs are
+// lower-cased instead.
+func (f *Filter) compile(entry string) (pattern, error) {
+	if expr, ok := strings.CutPrefix(entry, regexPrefix); ok {
+		if !f.caseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		return pattern{re: re, isRe: true}, err
+	}
+	glob := f.normalize(entry)
+	return pattern{glob: glob}, validateGlob(glob)
+}
+
+// compileAll compiles every entry. Invalid entries are kept so that they
+// never match. A nil input yields nil.
+func (f *Filter) compileAll(entries []string) []pattern {
+	if entries == nil {
+		return nil
+	}
+	out := make([]pattern, len(entries))
+	for i, entry := range entries {
+		out[i], _ = f.compile(entry)
+	}
+	return out
+}
+
 // matchGlob returns true when name matches the given glob pattern.
 // filepath.Match errors (malformed patterns) are treated as non-matching.
 func matchGlob(pattern, name string) bool {
@@ -59,3 +249,9 @@ func matchGlob(pattern, name string) bool {
 	}
 	return matched
 }
+
+// validateGlob reports whether pattern is a well-formed filepath.Match glob.
+func validateGlob(pattern string) error {
+	_, err := filepath.Match(pattern, "")
+	return err
+}
@@ -1,6 +1,9 @@
 package safety
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func Test_Filter_IsAllowed_Cases(t *testing.T) {
 	t.Parallel()
@@ -124,3 +127,310 @@ func Test_Filter_IsAllowed_GlobPatternInDenylist(t *testing.T) {
 		t.Error("resource not matching deny glob should be allowed")
 	}
 }
+
+func Test_Filter_IsAllowed_CaseSensitivity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		opts      []FilterOption
+		resource  string
+		want      bool
+	}{
+		{
+			name:     "lowercase deny blocks mixed-case name by default",
+			denylist: []string{"general"},
+			resource: "General",
+			want:     false,
+		},
+		{
+			name:     "mixed-case deny blocks lowercase name by default",
+			denylist: []string{"General"},
+			resource: "general",
+			want:     false,
+		},
+		{
+			name:      "glob allow is case-insensitive by default",
+			allowlist: []string{"bot-*"},
+			resource:  "Bot-Commands",
+			want:      true,
+		},
+		{
+			name:     "case-sensitive mode does not fold case",
+			denylist: []string{"general"},
+			opts:     []FilterOption{WithCaseSensitive(true)},
+			resource: "General",
+			want:     true,
+		},
+		{
+			name:      "case-sensitive mode still matches exact case",
+			allowlist: []string{"General"},
+			opts:      []FilterOption{WithCaseSensitive(true)},
+			resource:  "General",
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := NewFilter(tt.allowlist, tt.denylist, tt.opts...)
+			if got := f.IsAllowed(tt.resource); got != tt.want {
+				t.Errorf("IsAllowed(%q) = %v, want %v", tt.resource, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Filter_IsAllowed_RegexEntries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		resource  string
+		want      bool
+	}{
+		{
+			name:      "regex allow matches",
+			allowlist: []string{"re:^team-.*$"},
+			resource:  "team-backend",
+			want:      true,
+		},
+		{
+			name:      "regex allow misses",
+			allowlist: []string{"re:^team-.*$"},
+			resource:  "general",
+			want:      false,
+		},
+		{
+			name:      "glob and regex mixed in allowlist",
+			allowlist: []string{"general", "re:^team-[a-z]+$"},
+			resource:  "general",
+			want:      true,
+		},
+		{
+			name:     "regex deny matches suffix",
+			denylist: []string{"re:-logs$"},
+			resource: "mod-logs",
+			want:     false,
+		},
+		{
+			name:     "regex deny ignores non-matching name",
+			denylist: []string{"re:-logs$"},
+			resource: "logs-archive",
+			want:     true,
+		},
+		{
+			name:      "glob deny wins over regex allow",
+			allowlist: []string{"re:^team-"},
+			denylist:  []string{"team-secret*"},
+			resource:  "team-secret-ops",
+			want:      false,
+		},
+		{
+			name:     "regex is case-insensitive by default",
+			denylist: []string{"re:^Admin$"},
+			resource: "admin",
+			want:     false,
+		},
+		{
+			name:     "invalid regex never matches",
+			denylist: []string{"re:("},
+			resource: "(",
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := NewFilter(tt.allowlist, tt.denylist)
+			if got := f.IsAllowed(tt.resource); got != tt.want {
+				t.Errorf("NewFilter(%v, %v).IsAllowed(%q) = %v, want %v",
+					tt.allowlist, tt.denylist, tt.resource, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_NewFilterValidated_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		wantErr   []string
+	}{
+		{
+			name:      "valid patterns",
+			allowlist: []string{"general", "bot-*"},
+			denylist:  []string{"admin-[0-9]"},
+		},
+		{
+			name: "nil lists",
+		},
+		{
+			name:      "broken allowlist pattern",
+			allowlist: []string{"general", "["},
+			wantErr:   []string{`allowlist "["`},
+		},
+		{
+			name:      "broken patterns in both lists are all reported",
+			allowlist: []string{"team-[a-"},
+			denylist:  []string{"ok-*", `trailing\`},
+			wantErr:   []string{`allowlist "team-[a-"`, `denylist "trailing\\"`},
+		},
+		{
+			name:      "valid regex entries",
+			allowlist: []string{"re:^team-.*$"},
+			denylist:  []string{"re:-logs$", "admin"},
+		},
+		{
+			name:     "invalid regex entry",
+			denylist: []string{"re:(unclosed", "ok-*"},
+			wantErr:  []string{`denylist "re:(unclosed"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := NewFilterValidated(tt.allowlist, tt.denylist)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("NewFilterValidated() unexpected error: %v", err)
+				}
+				if f == nil {
+					t.Fatal("NewFilterValidated() returned nil filter")
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("NewFilterValidated() expected error, got nil")
+			}
+			if f != nil {
+				t.Error("NewFilterValidated() should return nil filter on error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q should mention %s", err, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_Filter_IsAllowedAny(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		names     []string
+		want      bool
+	}{
+		{"no lists", nil, nil, []string{"123", "alice"}, true},
+		{"deny matches first name", nil, []string{"123"}, []string{"123", "alice"}, false},
+		{"deny matches second name", nil, []string{"alice"}, []string{"123", "alice"}, false},
+		{"allow matches either name", []string{"alice"}, nil, []string{"123", "Alice"}, true},
+		{"allow matches neither", []string{"bob"}, nil, []string{"123", "alice"}, false},
+		{"deny beats allow across names", []string{"123"}, []string{"alice"}, []string{"123", "alice"}, false},
+		{"no names with allowlist", []string{"alice"}, nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := NewFilter(tt.allowlist, tt.denylist)
+			if got := f.IsAllowedAny(tt.names...); got != tt.want {
+				t.Errorf("IsAllowedAny(%v) = %v, want %v", tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// IsAllowedFor
+// ---------------------------------------------------------------------------
+
+func Test_IsAllowedFor_Cases(t *testing.T) {
+	t.Parallel()
+
+	f := NewFilter(nil, []string{"admin"},
+		WithOperationFilter(OpWrite, NewFilter([]string{"bot-commands"}, nil)),
+		WithOperationFilter(OpRead, NewFilter(nil, []string{"secret"})),
+	)
+
+	tests := []struct {
+		name    string
+		op      Operation
+		channel string
+		want    bool
+	}{
+		{"read general", OpRead, "general", true},
+		{"write general denied by write allowlist", OpWrite, "general", false},
+		{"write bot-commands", OpWrite, "bot-commands", true},
+		{"read bot-commands", OpRead, "bot-commands", true},
+		{"shared denylist blocks read", OpRead, "admin", false},
+		{"shared denylist blocks write", OpWrite, "admin", false},
+		{"read denylist blocks read", OpRead, "secret", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := f.IsAllowedFor(tt.op, tt.channel); got != tt.want {
+				t.Errorf("IsAllowedFor(%s, %q) = %v, want %v", tt.op, tt.channel, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_IsAllowedForAny_Cases(t *testing.T) {
+	t.Parallel()
+
+	f := NewFilter(nil, []string{"111"},
+		WithOperationFilter(OpRead, NewFilter([]string{"general"}, nil)),
+	)
+
+	tests := []struct {
+		name  string
+		op    Operation
+		names []string
+		want  bool
+	}{
+		{"read allowed by name", OpRead, []string{"222", "general"}, true},
+		{"read denied by ID", OpRead, []string{"111", "general"}, false},
+		{"read not on allowlist", OpRead, []string{"333", "random"}, false},
+		{"write without op allowlist", OpWrite, []string{"333", "random"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := f.IsAllowedForAny(tt.op, tt.names...); got != tt.want {
+				t.Errorf("IsAllowedForAny(%s, %v) = %v, want %v", tt.op, tt.names, got, tt.want)
+			}
+		})
+	}
+
+	if !f.HasAllowlist(OpRead) || f.HasAllowlist(OpWrite) {
+		t.Errorf("HasAllowlist(read, write) = %v, %v; want true, false", f.HasAllowlist(OpRead), f.HasAllowlist(OpWrite))
+	}
+}
+
+func Test_IsAllowedFor_NoOperationFilters(t *testing.T) {
+	t.Parallel()
+	f := NewFilter([]string{"general"}, nil, WithOperationFilter(OpWrite, nil))
+	if !f.IsAllowedFor(OpWrite, "general") || f.IsAllowedFor(OpRead, "random") {
+		t.Error("IsAllowedFor without operation filters should match IsAllowed")
+	}
+}
@@ -0,0 +1,43 @@
+package safety
+
+// GuildFilters holds a channel filter per guild ID, with a fallback for
+// guilds that have no rules of their own. Channel names are only unique
+// within a guild, so a rule such as "general" may need to differ between
+// servers.
+type GuildFilters struct {
+	fallback *Filter
+	byGuild  map[string]*Filter
+}
+
+// NewGuildFilters returns a GuildFilters that uses byGuild[guildID] when
+// present and fallback otherwise. Nil entries in byGuild are ignored. The map
+// is copied, so later changes to byGuild have no effect.
+func NewGuildFilters(fallback *Filter, byGuild map[string]*Filter) *GuildFilters {
+	g := &GuildFilters{fallback: fallback, byGuild: make(map[string]*Filter, len(byGuild))}
+	for id, f := range byGuild {
+		if f != nil {
+			g.byGuild[id] = f
+		}
+	}
+	return g
+}
+
+// For returns the filter for guildID: the guild's own filter if one was
+// configured, otherwise the fallback. A per-guild filter replaces the
+// fallback rather than adding to it. A nil GuildFilters returns nil.
+func (g *GuildFilters) For(guildID string) *Filter {
+	if g == nil {
+		return nil
+	}
+	if f, ok := g.byGuild[guildID]; ok {
+		return f
+	}
+	return g.fallback
+}
+
+// IsAllowedFor reports whether the channel name in guildID is permitted for
+// op by that guild's filter. A nil filter allows everything.
+func (g *GuildFilters) IsAllowedFor(guildID string, op Operation, name string) bool {
+	f := g.For(guildID)
+	return f == nil || f.IsAllowedFor(op, name)
+}
@@ -0,0 +1,62 @@
+package safety
+
+import "testing"
+
+// ---------------------------------------------------------------------------
+// GuildFilters
+// ---------------------------------------------------------------------------
+
+func Test_GuildFilters_DivergentRules(t *testing.T) {
+	t.Parallel()
+
+	g := NewGuildFilters(
+		NewFilter(nil, []string{"admin"}),
+		map[string]*Filter{
+			// In guild-a "general" is off limits; in guild-b only "general"
+			// may be written to.
+			"guild-a": NewFilter(nil, []string{"general"}),
+			"guild-b": NewFilter(nil, nil, WithOperationFilter(OpWrite, NewFilter([]string{"general"}, nil))),
+		},
+	)
+
+	tests := []struct {
+		name    string
+		guildID string
+		op      Operation
+		channel string
+		want    bool
+	}{
+		{"guild-a denies general", "guild-a", OpRead, "general", false},
+		{"guild-a allows random", "guild-a", OpWrite, "random", true},
+		{"guild-a ignores fallback denylist", "guild-a", OpRead, "admin", true},
+		{"guild-b writes general", "guild-b", OpWrite, "general", true},
+		{"guild-b cannot write random", "guild-b", OpWrite, "random", false},
+		{"guild-b reads random", "guild-b", OpRead, "random", true},
+		{"other guild uses fallback", "guild-c", OpRead, "admin", false},
+		{"other guild allows general", "guild-c", OpWrite, "general", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := g.IsAllowedFor(tt.guildID, tt.op, tt.channel); got != tt.want {
+				t.Errorf("IsAllowedFor(%q, %s, %q) = %v, want %v", tt.guildID, tt.op, tt.channel, got, tt.want)
+			}
+			if got := g.For(tt.guildID).IsAllowedFor(tt.op, tt.channel); got != tt.want {
+				t.Errorf("For(%q).IsAllowedFor(%s, %q) = %v, want %v", tt.guildID, tt.op, tt.channel, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_GuildFilters_NilFilters(t *testing.T) {
+	t.Parallel()
+
+	g := NewGuildFilters(nil, map[string]*Filter{"guild-a": nil})
+	if g.For("guild-a") != nil {
+		t.Error("For() with a nil guild entry should return the nil fallback")
+	}
+	if !g.IsAllowedFor("guild-a", OpWrite, "anything") {
+		t.Error("IsAllowedFor() with no filters should allow everything")
+	}
+}
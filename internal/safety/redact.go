@@ -0,0 +1,143 @@
+package safety
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces the value of a sensitive parameter.
+const RedactedValue = "[REDACTED]"
+
+// sensitiveParams names tool parameters and result fields whose values must
+// not be written to the audit log or debug logs: confirmation tokens would
+// let a reader approve the pending destructive call, and delivery tokens
+// settle queued messages. Names are lowercase and matched case-insensitively.
+var sensitiveParams = map[string]bool{
+	"confirmation_token": true,
+	"delivery_token":     true,
+	"delivery_tokens":    true,
+	"token":              true,
+	"auth_token":         true,
+	"password":           true,
+	"secret":             true,
+}
+
+// sensitiveAssignment matches a sensitive name followed by its value in
+// prose or JSON, such as confirmation_token="abc", [delivery_token=abc] or
+// "token": "abc". The value group keeps its quotes.
+var sensitiveAssignment = regexp.MustCompile(`(?i)\b(` + sensitiveNamePattern() + `)("?\s*[:=]\s*)("[^"]*"|[^\s"',\]\)}]+)`)
+
+// sensitiveNamePattern returns the names in sensitiveParams as a regexp
+// alternation, longest first so that delivery_tokens is not matched as
+// delivery_token.
+func sensitiveNamePattern() string {
+	names := make([]string, 0, len(sensitiveParams))
+	for name := range sensitiveParams {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	return strings.Join(names, "|")
+}
+
+// IsSensitiveParam reports whether values of the parameter name are redacted.
+func IsSensitiveParam(name string) bool {
+	return sensitiveParams[strings.ToLower(name)]
+}
+
+// RedactParams returns params with the value of every sensitive parameter,
+// including those in nested maps and slices, replaced by RedactedValue.
+// params itself is never modified: a copy is returned when anything is
+// redacted, and params unchanged otherwise.
+func RedactParams(params map[string]any) map[string]any {
+	if !needsRedaction(params) {
+		return params
+	}
+	return redactValue(params).(map[string]any)
+}
+
+// RedactText returns text with sensitive values replaced by RedactedValue.
+// Text that is a JSON document is decoded and redacted field by field, as by
+// RedactParams, and re-encoded compactly; other text, such as a prompt
+// saying "call again with confirmation_token=...", has any sensitive
+// name=value or "name": value assignment redacted.
+func RedactText(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var v any
+		if err := json.Unmarshal([]byte(trimmed), &v); err == nil {
+			if !needsRedaction(v) {
+				return text
+			}
+			if data, err := json.Marshal(redactValue(v)); err == nil {
+				return string(data)
+			}
+		}
+	}
+	return sensitiveAssignment.ReplaceAllStringFunc(text, func(m string) string {
+		sub := sensitiveAssignment.FindStringSubmatch(m)
+		value := RedactedValue
+		if strings.HasPrefix(sub[3], `"`) {
+			value = `"` + value + `"`
+		}
+		return sub[1] + sub[2] + value
+	})
+}
+
+// redactValue returns a copy of v with sensitive map entries redacted at any
+// depth. Values without maps are returned unchanged.
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for name, nested := range v {
+			if IsSensitiveParam(name) {
+				out[name] = RedactedValue
+				continue
+			}
+			out[name] = redactValue(nested)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, nested := range v {
+			out[i] = redactValue(nested)
+		}
+		return out
+	case []map[string]any:
+		out := make([]map[string]any, len(v))
+		for i, nested := range v {
+			out[i] = redactValue(nested).(map[string]any)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// needsRedaction reports whether v is or contains, at any depth of maps and
+// slices, a map with a sensitive entry.
+func needsRedaction(v any) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		for name, nested := range v {
+			if IsSensitiveParam(name) || needsRedaction(nested) {
+				return true
+			}
+		}
+	case []any:
+		for _, nested := range v {
+			if needsRedaction(nested) {
+				return true
+			}
+		}
+	case []map[string]any:
+		for _, nested := range v {
+			if needsRedaction(nested) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package safety
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// RedactParams
+// ---------------------------------------------------------------------------
+
+func Test_RedactParams_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		params map[string]any
+		want   map[string]any
+	}{
+		{name: "nil", params: nil, want: nil},
+		{
+			name:   "nothing sensitive",
+			params: map[string]any{"channel": "general", "ids": []string{"1"}},
+			want:   map[string]any{"channel": "general", "ids": []string{"1"}},
+		},
+		{
+			name:   "confirmation token",
+			params: map[string]any{"message_id": "1", "confirmation_token": "abc"},
+			want:   map[string]any{"message_id": "1", "confirmation_token": RedactedValue},
+		},
+		{
+			name:   "case-insensitive name",
+			params: map[string]any{"Token": "abc"},
+			want:   map[string]any{"Token": RedactedValue},
+		},
+		{
+			name:   "nested map",
+			params: map[string]any{"embed": map[string]any{"title": "t", "secret": "s"}},
+			want:   map[string]any{"embed": map[string]any{"title": "t", "secret": RedactedValue}},
+		},
+		{
+			name:   "maps in a slice",
+			params: map[string]any{"items": []any{map[string]any{"id": "1", "delivery_token": "d"}, "plain"}},
+			want:   map[string]any{"items": []any{map[string]any{"id": "1", "delivery_token": RedactedValue}, "plain"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := RedactParams(tt.params); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RedactParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_RedactParams_DoesNotModifyInput(t *testing.T) {
+	t.Parallel()
+
+	params := map[string]any{"confirmation_token": "abc"}
+	_ = RedactParams(params)
+	if params["confirmation_token"] != "abc" {
+		t.Errorf("input modified: %v", params)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// RedactText
+// ---------------------------------------------------------------------------
+
+func Test_RedactText_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain text", text: "hello world", want: "hello world"},
+		{name: "quoted assignment", text: `call again with confirmation_token="abc123".`, want: `call again with confirmation_token="[REDACTED]".`},
+		{name: "bare assignment", text: "@alice: hi [delivery_token=abc]", want: "@alice: hi [delivery_token=[REDACTED]]"},
+		{name: "plural name", text: "delivery_tokens=abc", want: "delivery_tokens=[REDACTED]"},
+		{name: "json object", text: `{"tool": "t", "confirmation_token": "abc"}`, want: `{"confirmation_token":"[REDACTED]","tool":"t"}`},
+		{name: "json array", text: `[{"id": "1", "delivery_token": "abc"}]`, want: `[{"delivery_token":"[REDACTED]","id":"1"}]`},
+		{name: "json without secrets unchanged", text: `{"id": "1"}`, want: `{"id": "1"}`},
+		{name: "truncated json", text: `{"id": "1", "delivery_token": "abc", "con`, want: `{"id": "1", "delivery_token": "[REDACTED]", "con`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := RedactText(tt.text); got != tt.want {
+				t.Errorf("RedactText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Package telemetry exports tool invocations as OpenTelemetry spans. It plugs
+// into the audit path as a safety.AuditSink so every audited tool call
+// produces a span without changes to individual handlers.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName identifies this package as the span producer.
+const instrumentationName = "github.com/jamesprial/claudebot-mcp/internal/telemetry"
+
+// Config controls span export.
+type Config struct {
+	// OTLPEndpoint is the host:port of an OTLP/HTTP collector. When empty,
+	// a no-op tracer is used and nothing is exported.
+	OTLPEndpoint string
+	// Insecure disables TLS for the collector connection.
+	Insecure bool
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+}
+
+// SpanSink converts audit entries into spans. It implements safety.AuditSink.
+type SpanSink struct {
+	tracer trace.Tracer
+}
+
+// Compile-time assertion: *SpanSink satisfies safety.AuditSink.
+var _ safety.AuditSink = (*SpanSink)(nil)
+
+// NewSpanSink returns a SpanSink that records spans with tracer.
+func NewSpanSink(tracer trace.Tracer) *SpanSink {
+	return &SpanSink{tracer: tracer}
+}
+
+// Record emits a span named after the tool covering the entry's start time
+// and duration. Failed invocations record the result as an error.
+func (s *SpanSink) Record(entry safety.AuditEntry) {
+	attrs := []attribute.KeyValue{
+		attribute.String("tool.name", entry.Tool),
+		attribute.String("tool.outcome", string(entry.Outcome)),
+		attribute.Int64("tool.duration_ms", entry.Duration.Milliseconds()),
+	}
+	if entry.RequestID != "" {
+		attrs = append(attrs, attribute.String("tool.request_id", entry.RequestID))
+	}
+	if entry.Client != "" {
+		attrs = append(attrs, attribute.String("tool.client", entry.Client))
+	}
+	if ch, ok := entry.Params["channel"].(string); ok && ch != "" {
+		attrs = append(attrs, attribute.String("discord.channel", ch))
+	}
+
+	_, span := s.tracer.Start(context.Background(), entry.Tool,
+		trace.WithTimestamp(entry.Timestamp),
+		trace.WithAttributes(attrs...),
+	)
+	if entry.Outcome == safety.OutcomeError {
+		span.RecordError(errors.New(entry.Result))
+		span.SetStatus(codes.Error, entry.Result)
+	}
+	span.End(trace.WithTimestamp(entry.Timestamp.Add(entry.Duration)))
+}
+
+// Setup builds a SpanSink from cfg. When cfg.OTLPEndpoint is empty the sink
+// uses a no-op tracer. The returned shutdown function flushes pending spans
+// and must be called before exit.
+func Setup(ctx context.Context, cfg Config) (*SpanSink, func(context.Context) error, error) {
+	if cfg.OTLPEndpoint == "" {
+		tracer := noop.NewTracerProvider().Tracer(instrumentationName)
+		return NewSpanSink(tracer), func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("telemetry: failed to create OTLP exporter: %w", err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "claudebot-mcp"
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+
+	return NewSpanSink(tp.Tracer(instrumentationName)), tp.Shutdown, nil
+}
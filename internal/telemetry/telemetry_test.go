@@ -0,0 +1,139 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newRecordingSink returns a SpanSink backed by an in-memory span recorder.
+func newRecordingSink() (*SpanSink, *tracetest.SpanRecorder) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	return NewSpanSink(tp.Tracer("test")), rec
+}
+
+// attrMap flattens span attributes for lookup.
+func attrMap(attrs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, kv := range attrs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+// ---------------------------------------------------------------------------
+// SpanSink.Record
+// ---------------------------------------------------------------------------
+
+func Test_SpanSink_Record_Success(t *testing.T) {
+	t.Parallel()
+
+	sink, rec := newRecordingSink()
+	start := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	sink.Record(safety.AuditEntry{
+		Timestamp: start,
+		Tool:      "discord_send_message",
+		Params:    map[string]any{"channel": "general"},
+		Outcome:   safety.OutcomeSuccess,
+		Result:    "ok",
+		Duration:  250 * time.Millisecond,
+	})
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "discord_send_message" {
+		t.Errorf("span name = %q, want %q", span.Name(), "discord_send_message")
+	}
+	if !span.StartTime().Equal(start) {
+		t.Errorf("start = %v, want %v", span.StartTime(), start)
+	}
+	if got := span.EndTime().Sub(span.StartTime()); got != 250*time.Millisecond {
+		t.Errorf("span duration = %v, want 250ms", got)
+	}
+
+	attrs := attrMap(span.Attributes())
+	if got := attrs["tool.name"].AsString(); got != "discord_send_message" {
+		t.Errorf("tool.name = %q", got)
+	}
+	if got := attrs["tool.outcome"].AsString(); got != "success" {
+		t.Errorf("tool.outcome = %q, want success", got)
+	}
+	if got := attrs["discord.channel"].AsString(); got != "general" {
+		t.Errorf("discord.channel = %q, want general", got)
+	}
+	if span.Status().Code == codes.Error {
+		t.Error("successful call should not have error status")
+	}
+}
+
+func Test_SpanSink_Record_Error(t *testing.T) {
+	t.Parallel()
+
+	sink, rec := newRecordingSink()
+	sink.Record(safety.AuditEntry{
+		Timestamp: time.Now(),
+		Tool:      "discord_get_user",
+		Outcome:   safety.OutcomeError,
+		Result:    "error: unknown user",
+	})
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Status().Code != codes.Error {
+		t.Errorf("status = %v, want Error", span.Status().Code)
+	}
+	if len(span.Events()) == 0 || span.Events()[0].Name != "exception" {
+		t.Error("expected an exception event recording the error")
+	}
+	if _, ok := attrMap(span.Attributes())["discord.channel"]; ok {
+		t.Error("discord.channel should be omitted when no channel param is present")
+	}
+}
+
+func Test_SpanSink_ViaAuditLogger(t *testing.T) {
+	t.Parallel()
+
+	sink, rec := newRecordingSink()
+	logger := safety.NewAuditLogger(nil, sink)
+	if logger == nil {
+		t.Fatal("NewAuditLogger(nil, sink) should return a sink-only logger")
+	}
+	if err := logger.Log(safety.AuditEntry{Timestamp: time.Now(), Tool: "t", Outcome: safety.OutcomeSuccess}); err != nil {
+		t.Fatalf("Log() error: %v", err)
+	}
+	if len(rec.Ended()) != 1 {
+		t.Errorf("got %d spans, want 1", len(rec.Ended()))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+func Test_Setup_Unconfigured_NoOp(t *testing.T) {
+	t.Parallel()
+
+	sink, shutdown, err := Setup(context.Background(), Config{})
+	if err != nil {
+		t.Fatalf("Setup() unexpected error: %v", err)
+	}
+	// Recording through the no-op tracer must not panic.
+	sink.Record(safety.AuditEntry{Timestamp: time.Now(), Tool: "t", Outcome: safety.OutcomeError, Result: "error: x"})
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -108,3 +109,43 @@ func AssertNotError(t *testing.T, result *mcp.CallToolResult) {
 		t.Fatalf("expected non-error result, but got IsError=true with text: %s", text)
 	}
 }
+
+// ConfirmationRequest decodes the structured confirmation prompt carried as
+// the second content item of a result from tools.ConfirmPrompt, failing the
+// test if result is not a confirmation prompt.
+func ConfirmationRequest(t *testing.T, result *mcp.CallToolResult) tools.ConfirmationRequest {
+	t.Helper()
+	if result == nil || len(result.Content) < 2 {
+		t.Fatalf("result is not a confirmation prompt: %+v", result)
+	}
+	tc, ok := result.Content[1].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("result content[1] is %T, want mcp.TextContent", result.Content[1])
+	}
+	var req tools.ConfirmationRequest
+	if err := json.Unmarshal([]byte(tc.Text), &req); err != nil {
+		t.Fatalf("content[1] is not a ConfirmationRequest: %v", err)
+	}
+	if req.ConfirmationToken == "" {
+		t.Fatalf("confirmation prompt has no token: %s", tc.Text)
+	}
+	return req
+}
+
+// ToolError decodes the structured error carried as the second content item
+// of an error result, failing the test if result is not one.
+func ToolError(t *testing.T, result *mcp.CallToolResult) tools.ToolError {
+	t.Helper()
+	if result == nil || !result.IsError || len(result.Content) < 2 {
+		t.Fatalf("result is not a structured error: %+v", result)
+	}
+	tc, ok := result.Content[1].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("result content[1] is %T, want mcp.TextContent", result.Content[1])
+	}
+	var te tools.ToolError
+	if err := json.Unmarshal([]byte(tc.Text), &te); err != nil {
+		t.Fatalf("content[1] is not a ToolError: %v", err)
+	}
+	return te
+}
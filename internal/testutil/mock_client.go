@@ -16,15 +16,30 @@ var _ discord.DiscordClient = (*MockDiscordClient)(nil)
 // produced by NewMockDiscordSession's HTTP handlers.
 type MockDiscordClient struct {
 	ChannelMessageSendComplexFunc func(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageFunc            func(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error)
 	ChannelMessagesFunc           func(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error)
+	ChannelMessagesPinnedFunc     func(channelID string, before *time.Time, limit int, options ...discordgo.RequestOption) (*discordgo.ChannelMessagePinsList, error)
 	ChannelMessageEditFunc        func(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEditComplexFunc func(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
 	ChannelMessageDeleteFunc      func(channelID, messageID string, options ...discordgo.RequestOption) error
 	MessageReactionAddFunc        func(channelID, messageID, emojiID string, options ...discordgo.RequestOption) error
 	MessageReactionRemoveFunc     func(channelID, messageID, emojiID, userID string, options ...discordgo.RequestOption) error
+	MessageReactionsFunc          func(channelID, messageID, emojiID string, limit int, beforeID, afterID string, options ...discordgo.RequestOption) ([]*discordgo.User, error)
 	GuildChannelsFunc             func(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error)
 	GuildFunc                     func(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error)
+	GuildEmojisFunc               func(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Emoji, error)
+	GuildAuditLogFunc             func(guildID, userID, beforeID string, actionType, limit int, options ...discordgo.RequestOption) (*discordgo.GuildAuditLog, error)
+	GuildInvitesFunc              func(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Invite, error)
+	GuildThreadsActiveFunc        func(guildID string, options ...discordgo.RequestOption) (*discordgo.ThreadsList, error)
+	ThreadsArchivedFunc           func(channelID string, before *time.Time, limit int, options ...discordgo.RequestOption) (*discordgo.ThreadsList, error)
+	MessageThreadStartComplexFunc func(channelID, messageID string, data *discordgo.ThreadStart, options ...discordgo.RequestOption) (*discordgo.Channel, error)
 	ChannelTypingFunc             func(channelID string, options ...discordgo.RequestOption) error
+	ChannelInviteCreateFunc       func(channelID string, i discordgo.Invite, options ...discordgo.RequestOption) (*discordgo.Invite, error)
 	UserFunc                      func(userID string, options ...discordgo.RequestOption) (*discordgo.User, error)
+	UserChannelPermissionsFunc    func(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error)
+	ChannelWebhooksFunc           func(channelID string, options ...discordgo.RequestOption) ([]*discordgo.Webhook, error)
+	WebhookCreateFunc             func(channelID, name, avatar string, options ...discordgo.RequestOption) (*discordgo.Webhook, error)
+	WebhookExecuteFunc            func(webhookID, token string, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
 }
 
 func (m *MockDiscordClient) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
@@ -37,6 +52,22 @@ func (m *MockDiscordClient) ChannelMessageSendComplex(channelID string, data *di
 	}, nil
 }
 
+func (m *MockDiscordClient) ChannelMessage(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if m.ChannelMessageFunc != nil {
+		return m.ChannelMessageFunc(channelID, messageID, options...)
+	}
+	return &discordgo.Message{
+		ID:        messageID,
+		ChannelID: channelID,
+		Content:   "Hello from mock",
+		Author: &discordgo.User{
+			ID:       "user-001",
+			Username: "mockuser",
+		},
+		Timestamp: time.Now(),
+	}, nil
+}
+
 func (m *MockDiscordClient) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
 	if m.ChannelMessagesFunc != nil {
 		return m.ChannelMessagesFunc(channelID, limit, beforeID, afterID, aroundID, options...)
@@ -54,6 +85,27 @@ func (m *MockDiscordClient) ChannelMessages(channelID string, limit int, beforeI
 	}, nil
 }
 
+func (m *MockDiscordClient) ChannelMessagesPinned(channelID string, before *time.Time, limit int, options ...discordgo.RequestOption) (*discordgo.ChannelMessagePinsList, error) {
+	if m.ChannelMessagesPinnedFunc != nil {
+		return m.ChannelMessagesPinnedFunc(channelID, before, limit, options...)
+	}
+	return &discordgo.ChannelMessagePinsList{
+		Items: []*discordgo.MessagePin{
+			{
+				PinnedAt: time.Now(),
+				Message: &discordgo.Message{
+					ID:      "mock-pin-001",
+					Content: "Please read the rules",
+					Author: &discordgo.User{
+						ID:       "user-001",
+						Username: "mockuser",
+					},
+				},
+			},
+		},
+	}, nil
+}
+
 func (m *MockDiscordClient) ChannelMessageEdit(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
 	if m.ChannelMessageEditFunc != nil {
 		return m.ChannelMessageEditFunc(channelID, messageID, content, options...)
@@ -65,6 +117,22 @@ func (m *MockDiscordClient) ChannelMessageEdit(channelID, messageID, content str
 	}, nil
 }
 
+// ChannelMessageEditComplex calls ChannelMessageEditComplexFunc when set.
+// Otherwise an edit that sets content is delegated to ChannelMessageEdit, so
+// tests written against ChannelMessageEditFunc keep working.
+func (m *MockDiscordClient) ChannelMessageEditComplex(data *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if m.ChannelMessageEditComplexFunc != nil {
+		return m.ChannelMessageEditComplexFunc(data, options...)
+	}
+	if data.Content != nil {
+		return m.ChannelMessageEdit(data.Channel, data.ID, *data.Content, options...)
+	}
+	return &discordgo.Message{
+		ID:        data.ID,
+		ChannelID: data.Channel,
+	}, nil
+}
+
 func (m *MockDiscordClient) ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error {
 	if m.ChannelMessageDeleteFunc != nil {
 		return m.ChannelMessageDeleteFunc(channelID, messageID, options...)
@@ -86,6 +154,13 @@ func (m *MockDiscordClient) MessageReactionRemove(channelID, messageID, emojiID,
 	return nil
 }
 
+func (m *MockDiscordClient) MessageReactions(channelID, messageID, emojiID string, limit int, beforeID, afterID string, options ...discordgo.RequestOption) ([]*discordgo.User, error) {
+	if m.MessageReactionsFunc != nil {
+		return m.MessageReactionsFunc(channelID, messageID, emojiID, limit, beforeID, afterID, options...)
+	}
+	return []*discordgo.User{}, nil
+}
+
 func (m *MockDiscordClient) GuildChannels(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
 	if m.GuildChannelsFunc != nil {
 		return m.GuildChannelsFunc(guildID, options...)
@@ -115,6 +190,53 @@ func (m *MockDiscordClient) Guild(guildID string, options ...discordgo.RequestOp
 	}, nil
 }
 
+func (m *MockDiscordClient) GuildEmojis(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Emoji, error) {
+	if m.GuildEmojisFunc != nil {
+		return m.GuildEmojisFunc(guildID, options...)
+	}
+	return []*discordgo.Emoji{
+		{
+			ID:   "emoji-001",
+			Name: "partyparrot",
+		},
+	}, nil
+}
+
+func (m *MockDiscordClient) GuildAuditLog(guildID, userID, beforeID string, actionType, limit int, options ...discordgo.RequestOption) (*discordgo.GuildAuditLog, error) {
+	if m.GuildAuditLogFunc != nil {
+		return m.GuildAuditLogFunc(guildID, userID, beforeID, actionType, limit, options...)
+	}
+	return &discordgo.GuildAuditLog{}, nil
+}
+
+func (m *MockDiscordClient) GuildInvites(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Invite, error) {
+	if m.GuildInvitesFunc != nil {
+		return m.GuildInvitesFunc(guildID, options...)
+	}
+	return []*discordgo.Invite{}, nil
+}
+
+func (m *MockDiscordClient) GuildThreadsActive(guildID string, options ...discordgo.RequestOption) (*discordgo.ThreadsList, error) {
+	if m.GuildThreadsActiveFunc != nil {
+		return m.GuildThreadsActiveFunc(guildID, options...)
+	}
+	return &discordgo.ThreadsList{Threads: []*discordgo.Channel{}}, nil
+}
+
+func (m *MockDiscordClient) ThreadsArchived(channelID string, before *time.Time, limit int, options ...discordgo.RequestOption) (*discordgo.ThreadsList, error) {
+	if m.ThreadsArchivedFunc != nil {
+		return m.ThreadsArchivedFunc(channelID, before, limit, options...)
+	}
+	return &discordgo.ThreadsList{Threads: []*discordgo.Channel{}}, nil
+}
+
+func (m *MockDiscordClient) MessageThreadStartComplex(channelID, messageID string, data *discordgo.ThreadStart, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	if m.MessageThreadStartComplexFunc != nil {
+		return m.MessageThreadStartComplexFunc(channelID, messageID, data, options...)
+	}
+	return &discordgo.Channel{ID: "thread-001", Name: data.Name, ParentID: channelID, Type: discordgo.ChannelTypeGuildPublicThread}, nil
+}
+
 func (m *MockDiscordClient) ChannelTyping(channelID string, options ...discordgo.RequestOption) error {
 	if m.ChannelTypingFunc != nil {
 		return m.ChannelTypingFunc(channelID, options...)
@@ -122,6 +244,18 @@ func (m *MockDiscordClient) ChannelTyping(channelID string, options ...discordgo
 	return nil
 }
 
+func (m *MockDiscordClient) ChannelInviteCreate(channelID string, i discordgo.Invite, options ...discordgo.RequestOption) (*discordgo.Invite, error) {
+	if m.ChannelInviteCreateFunc != nil {
+		return m.ChannelInviteCreateFunc(channelID, i, options...)
+	}
+	return &discordgo.Invite{
+		Code:    "mock-invite",
+		Channel: &discordgo.Channel{ID: channelID},
+		MaxAge:  i.MaxAge,
+		MaxUses: i.MaxUses,
+	}, nil
+}
+
 func (m *MockDiscordClient) User(userID string, options ...discordgo.RequestOption) (*discordgo.User, error) {
 	if m.UserFunc != nil {
 		return m.UserFunc(userID, options...)
@@ -131,3 +265,49 @@ func (m *MockDiscordClient) User(userID string, options ...discordgo.RequestOpti
 		Username: "mockuser",
 	}, nil
 }
+
+func (m *MockDiscordClient) UserChannelPermissions(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error) {
+	if m.UserChannelPermissionsFunc != nil {
+		return m.UserChannelPermissionsFunc(userID, channelID, fetchOptions...)
+	}
+	return discordgo.PermissionViewChannel |
+		discordgo.PermissionSendMessages |
+		discordgo.PermissionReadMessageHistory |
+		discordgo.PermissionAddReactions, nil
+}
+
+func (m *MockDiscordClient) ChannelWebhooks(channelID string, options ...discordgo.RequestOption) ([]*discordgo.Webhook, error) {
+	if m.ChannelWebhooksFunc != nil {
+		return m.ChannelWebhooksFunc(channelID, options...)
+	}
+	return []*discordgo.Webhook{}, nil
+}
+
+func (m *MockDiscordClient) WebhookCreate(channelID, name, avatar string, options ...discordgo.RequestOption) (*discordgo.Webhook, error) {
+	if m.WebhookCreateFunc != nil {
+		return m.WebhookCreateFunc(channelID, name, avatar, options...)
+	}
+	return &discordgo.Webhook{
+		ID:        "webhook-001",
+		Type:      discordgo.WebhookTypeIncoming,
+		ChannelID: channelID,
+		Name:      name,
+		Token:     "webhook-token",
+	}, nil
+}
+
+func (m *MockDiscordClient) WebhookExecute(webhookID, token string, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if m.WebhookExecuteFunc != nil {
+		return m.WebhookExecuteFunc(webhookID, token, wait, data, options...)
+	}
+	msg := &discordgo.Message{
+		ID:        "mock-msg-001",
+		WebhookID: webhookID,
+		Author:    &discordgo.User{ID: webhookID, Bot: true},
+	}
+	if data != nil {
+		msg.Content = data.Content
+		msg.Author.Username = data.Username
+	}
+	return msg, nil
+}
@@ -85,6 +85,21 @@ func NewMockDiscordSession(t *testing.T) *MockDiscord {
 			}
 			writeJSON(w, msgs)
 
+		// GET /channels/{id}/messages/pins — pinned messages
+		case r.Method == http.MethodGet && len(parts) == 3 && parts[1] == "messages" && parts[2] == "pins":
+			pins := &discordgo.ChannelMessagePinsList{
+				Items: []*discordgo.MessagePin{
+					{
+						Message: &discordgo.Message{
+							ID:        "msg-pin-1",
+							ChannelID: channelID,
+							Content:   "Pinned rules",
+						},
+					},
+				},
+			}
+			writeJSON(w, pins)
+
 		// PATCH /channels/{id}/messages/{mID} — edit message
 		case r.Method == http.MethodPatch && len(parts) == 3 && parts[1] == "messages":
 			msgID := parts[2]
@@ -146,6 +161,16 @@ func NewMockDiscordSession(t *testing.T) *MockDiscord {
 			}
 			writeJSON(w, channels)
 
+		// GET /guilds/{id}/emojis
+		case r.Method == http.MethodGet && len(parts) == 2 && parts[1] == "emojis":
+			emojis := []*discordgo.Emoji{
+				{
+					ID:   "emoji-001",
+					Name: "partyparrot",
+				},
+			}
+			writeJSON(w, emojis)
+
 		// GET /guilds/{id} — get guild info
 		case r.Method == http.MethodGet && len(parts) == 1:
 			guild := &discordgo.Guild{
@@ -1,7 +1,7 @@
 package testutil
 
 import (
-	"fmt"
+	"maps"
 	"strings"
 
 	"github.com/jamesprial/claudebot-mcp/internal/resolve"
@@ -43,5 +43,15 @@ func (m *MockChannelResolver) ChannelID(name string) (string, error) {
 	if id, ok := m.NameToID[name]; ok {
 		return id, nil
 	}
-	return "", fmt.Errorf("resolve: channel %q not found", name)
+	return "", resolve.NotFound(name)
+}
+
+// Snapshot returns a copy of the mock's maps under the guild ID "mock-guild".
+// RefreshedAt is always nil.
+func (m *MockChannelResolver) Snapshot() resolve.Snapshot {
+	return resolve.Snapshot{
+		GuildID: "mock-guild",
+		ByID:    maps.Clone(m.IDToName),
+		ByName:  maps.Clone(m.NameToID),
+	}
 }
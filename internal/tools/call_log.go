@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxLoggedResultBytes is how much of a result's text withCallLogging logs;
+// the rest, such as the base64 body of a downloaded attachment, is cut.
+const maxLoggedResultBytes = 4096
+
+// withCallLogging returns a copy of reg whose handler logs the arguments and
+// result text of each call at debug level, for debugging agent behaviour.
+// Sensitive arguments and result fields, such as confirmation and delivery
+// tokens, are redacted with safety.RedactParams and safety.RedactText, and
+// the result text is cut to maxLoggedResultBytes. The level is checked on
+// every call, so raising the log level at runtime turns the logging on and
+// off.
+func withCallLogging(logger *slog.Logger, reg Registration) Registration {
+	logger = DefaultLogger(logger)
+	toolName := reg.Tool.Name
+	next := reg.Handler
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !logger.Enabled(ctx, slog.LevelDebug) {
+			return next(ctx, req)
+		}
+		start := time.Now()
+		logger.DebugContext(ctx, "tool request",
+			"tool", toolName,
+			"arguments", safety.RedactParams(req.GetArguments()),
+		)
+		result, err := next(ctx, req)
+		attrs := []any{"tool", toolName, "duration", time.Since(start)}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		}
+		if result != nil {
+			attrs = append(attrs, "is_error", result.IsError, "result", resultText(result))
+		}
+		logger.DebugContext(ctx, "tool response", attrs...)
+		return result, err
+	}
+
+	return Registration{Tool: reg.Tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// resultText joins the redacted text content blocks of result with
+// newlines, cut to maxLoggedResultBytes.
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range result.Content {
+		if tc, ok := c.(mcp.TextContent); ok {
+			parts = append(parts, safety.RedactText(tc.Text))
+		}
+	}
+	return truncateText(strings.Join(parts, "\n"), maxLoggedResultBytes)
+}
+
+// truncateText cuts s to at most max bytes, on a rune boundary, noting how
+// many bytes were dropped.
+func truncateText(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (%d bytes truncated)", s[:cut], len(s)-cut)
+}
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// callLogRecords runs one call with args through withCallLogging and
+// returns the JSON log records written at level.
+func callLogRecords(t *testing.T, level slog.Level, args map[string]any, handler server.ToolHandlerFunc) []map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))
+	reg := withCallLogging(logger, Registration{Tool: mcp.NewTool("sample_tool"), Handler: handler})
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = args
+	_, _ = reg.Handler(context.Background(), req)
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("unmarshal log line %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+// ---------------------------------------------------------------------------
+// withCallLogging
+// ---------------------------------------------------------------------------
+
+func Test_WithCallLogging_LogsRequestAndResponse(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return JSONResultWithText("Sent message", map[string]string{"id": "m1"}), nil
+	}
+	records := callLogRecords(t, slog.LevelDebug, map[string]any{
+		"channel":            "general",
+		"content":            "hello",
+		"confirmation_token": "secret-token",
+	}, handler)
+
+	if len(records) != 2 {
+		t.Fatalf("got %d log records, want 2", len(records))
+	}
+	request, response := records[0], records[1]
+
+	if request["msg"] != "tool request" || request["tool"] != "sample_tool" {
+		t.Errorf("request record = %v", request)
+	}
+	args, _ := request["arguments"].(map[string]any)
+	if args["channel"] != "general" || args["content"] != "hello" {
+		t.Errorf("arguments = %v, want channel and content logged", args)
+	}
+	if args["confirmation_token"] != safety.RedactedValue {
+		t.Errorf("confirmation_token = %v, want %q", args["confirmation_token"], safety.RedactedValue)
+	}
+
+	if response["msg"] != "tool response" || response["is_error"] != false {
+		t.Errorf("response record = %v", response)
+	}
+	text, _ := response["result"].(string)
+	if !strings.Contains(text, "Sent message") || !strings.Contains(text, `"m1"`) {
+		t.Errorf("result = %q, want every text block", text)
+	}
+}
+
+func Test_WithCallLogging_HandlerError(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("boom")
+	}
+	records := callLogRecords(t, slog.LevelDebug, nil, handler)
+
+	if len(records) != 2 || records[1]["error"] != "boom" {
+		t.Errorf("records = %v, want a response record with error boom", records)
+	}
+}
+
+func Test_WithCallLogging_SilentAboveDebug(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	if records := callLogRecords(t, slog.LevelInfo, map[string]any{"content": "hello"}, handler); len(records) != 0 {
+		t.Errorf("got %d log records at info level, want 0", len(records))
+	}
+}
+
+func Test_WithCallLogging_RedactsResult(t *testing.T) {
+	t.Parallel()
+
+	confirm := safety.NewConfirmationTracker([]string{"sample_tool"})
+	tests := []struct {
+		name   string
+		result *mcp.CallToolResult
+	}{
+		{name: "confirmation prompt", result: ConfirmPrompt(confirm, "sample_tool", "msg-1", "Delete it.")},
+		{name: "polled messages", result: JSONResult([]map[string]string{{"id": "m1", "delivery_token": "dt-secret"}})},
+		{name: "text format", result: mcp.NewToolResultText("[#general] @alice: hi [delivery_token=dt-secret]")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var secrets []string
+			for _, c := range tt.result.Content {
+				text := c.(mcp.TextContent).Text
+				if i := strings.Index(text, `"confirmation_token": "`); i >= 0 {
+					secrets = append(secrets, strings.SplitN(text[i+len(`"confirmation_token": "`):], `"`, 2)[0])
+				}
+			}
+			secrets = append(secrets, "dt-secret")
+
+			handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tt.result, nil
+			}
+			records := callLogRecords(t, slog.LevelDebug, nil, handler)
+			text, _ := records[1]["result"].(string)
+			for _, secret := range secrets {
+				if strings.Contains(text, secret) {
+					t.Errorf("result %q leaks %q", text, secret)
+				}
+			}
+			if !strings.Contains(text, safety.RedactedValue) {
+				t.Errorf("result %q, want %q in place of the token", text, safety.RedactedValue)
+			}
+		})
+	}
+}
+
+func Test_WithCallLogging_TruncatesLongResult(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(strings.Repeat("x", 3*maxLoggedResultBytes)), nil
+	}
+	records := callLogRecords(t, slog.LevelDebug, nil, handler)
+	text, _ := records[1]["result"].(string)
+	if len(text) > maxLoggedResultBytes+64 || !strings.HasSuffix(text, "bytes truncated)") {
+		t.Errorf("logged %d bytes ending %q, want at most about %d with a truncation note", len(text), text[len(text)-20:], maxLoggedResultBytes)
+	}
+}
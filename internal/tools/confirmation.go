@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Parameters through which a caller confirms a destructive call:
+// confirmationTokenParam passes back a token in token mode and confirmParam
+// approves the call in boolean mode.
+const (
+	confirmationTokenParam = "confirmation_token"
+	confirmParam           = "confirm"
+)
+
+// ConfirmationParam declares the parameter a destructive tool is confirmed
+// with under confirm's mode: confirmation_token in token mode, confirm in
+// boolean mode and none when confirmation is off.
+func ConfirmationParam(confirm *safety.ConfirmationTracker) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		switch confirm.Mode() {
+		case safety.ConfirmOff:
+		case safety.ConfirmBoolean:
+			mcp.WithBoolean(confirmParam,
+				mcp.Description("Set to true once the user has explicitly approved this action"),
+			)(t)
+		default:
+			mcp.WithString(confirmationTokenParam,
+				mcp.Description("Confirmation token returned by a prior call to this tool"),
+			)(t)
+		}
+	}
+}
+
+// Approve reports whether req confirms a call of toolName on resource under
+// confirm's mode; see safety.ConfirmationTracker.Approve. It also returns
+// the confirmation token passed, if any, for WithRejection.
+func Approve(confirm *safety.ConfirmationTracker, req mcp.CallToolRequest, toolName, resource string) (ok bool, token, reason string) {
+	token = req.GetString(confirmationTokenParam, "")
+	ok, reason = confirm.Approve(token, req.GetBool(confirmParam, false), toolName, resource)
+	return ok, token, reason
+}
+
+// WithConfirmation wraps every registration whose tool is in the tracker's
+// destructive set so that it requires confirmation before running. Tools
+// that already declare a confirmation_token or confirm parameter are assumed
+// to perform their own confirmation and are returned unchanged.
+func WithConfirmation(confirm *safety.ConfirmationTracker, registrations []Registration) []Registration {
+	if confirm == nil {
+		return registrations
+	}
+	out := make([]Registration, 0, len(registrations))
+	for _, reg := range registrations {
+		if !confirm.NeedsConfirmation(reg.Tool.Name) {
+			out = append(out, reg)
+			continue
+		}
+		props := reg.Tool.InputSchema.Properties
+		if _, ok := props[confirmationTokenParam]; ok {
+			out = append(out, reg)
+			continue
+		}
+		if _, ok := props[confirmParam]; ok {
+			out = append(out, reg)
+			continue
+		}
+		out = append(out, requireConfirmation(confirm, reg))
+	}
+	return out
+}
+
+// requireConfirmation returns a copy of reg whose schema declares the
+// confirmation parameter for the tracker's mode and whose handler issues a
+// confirmation prompt unless the call is confirmed.
+func requireConfirmation(confirm *safety.ConfirmationTracker, reg Registration) Registration {
+	toolName := reg.Tool.Name
+
+	tool := reg.Tool
+	props := make(map[string]any, len(tool.InputSchema.Properties)+1)
+	for k, v := range tool.InputSchema.Properties {
+		props[k] = v
+	}
+	tool.InputSchema.Properties = props
+	ConfirmationParam(confirm)(&tool)
+
+	next := reg.Handler
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		resource := confirmationResource(req)
+		if ok, token, reason := Approve(confirm, req, toolName, resource); !ok {
+			desc := fmt.Sprintf("%s is configured to require confirmation before it runs.", toolName)
+			return ConfirmPrompt(confirm, toolName, resource, WithRejection(desc, token, reason)), nil
+		}
+		return next(ctx, req)
+	}
+
+	return Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// confirmationResource picks the most specific identifier from the request to
+// show in a confirmation prompt.
+func confirmationResource(req mcp.CallToolRequest) string {
+	for _, key := range []string{"message_id", "user_id", "channel", "guild_id"} {
+		if v := req.GetString(key, ""); v != "" {
+			return v
+		}
+	}
+	return req.Params.Name
+}
+
+// UnknownToolNames returns the entries of names that do not match any tool in
+// registrations, preserving their order.
+func UnknownToolNames(registrations []Registration, names []string) []string {
+	known := make(map[string]struct{}, len(registrations))
+	for _, reg := range registrations {
+		known[reg.Tool.Name] = struct{}{}
+	}
+	var unknown []string
+	for _, name := range names {
+		if _, ok := known[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
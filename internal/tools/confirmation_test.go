@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stubRegistration returns a Registration for name whose handler reports
+// "ran". When ownToken is true the tool declares its own confirmation_token
+// parameter.
+func stubRegistration(name string, ownToken bool) Registration {
+	opts := []mcp.ToolOption{mcp.WithString("message_id")}
+	if ownToken {
+		opts = append(opts, mcp.WithString(confirmationTokenParam))
+	}
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ran"), nil
+	}
+	return Registration{Tool: mcp.NewTool(name, opts...), Handler: server.ToolHandlerFunc(handler)}
+}
+
+// ---------------------------------------------------------------------------
+// WithConfirmation
+// ---------------------------------------------------------------------------
+
+func Test_WithConfirmation_Cases(t *testing.T) {
+	t.Parallel()
+
+	confirm := safety.NewConfirmationTracker([]string{"wrapped", "self_confirming"})
+	regs := WithConfirmation(confirm, []Registration{
+		stubRegistration("plain", false),
+		stubRegistration("wrapped", false),
+		stubRegistration("self_confirming", true),
+	})
+
+	tests := []struct {
+		name       string
+		tool       string
+		wantPrompt bool
+	}{
+		{name: "non-destructive tool runs directly", tool: "plain", wantPrompt: false},
+		{name: "configured tool prompts for confirmation", tool: "wrapped", wantPrompt: true},
+		{name: "tool with own token param is left alone", tool: "self_confirming", wantPrompt: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var reg Registration
+			for _, r := range regs {
+				if r.Tool.Name == tt.tool {
+					reg = r
+				}
+			}
+			result, err := reg.Handler(context.Background(), mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: tt.tool, Arguments: map[string]any{"message_id": "msg-1"}},
+			})
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			text := extractText(t, result)
+			if got := strings.Contains(text, "Confirmation required"); got != tt.wantPrompt {
+				t.Errorf("prompted = %v, want %v (text: %s)", got, tt.wantPrompt, text)
+			}
+		})
+	}
+}
+
+func Test_WithConfirmation_AddsTokenParam(t *testing.T) {
+	t.Parallel()
+
+	confirm := safety.NewConfirmationTracker([]string{"wrapped"})
+	orig := stubRegistration("wrapped", false)
+	regs := WithConfirmation(confirm, []Registration{orig})
+
+	if _, ok := regs[0].Tool.InputSchema.Properties[confirmationTokenParam]; !ok {
+		t.Error("wrapped tool schema should declare confirmation_token")
+	}
+	if _, ok := orig.Tool.InputSchema.Properties[confirmationTokenParam]; ok {
+		t.Error("original tool schema should not be modified")
+	}
+}
+
+func Test_WithConfirmation_BooleanMode(t *testing.T) {
+	t.Parallel()
+
+	confirm := safety.NewConfirmationTracker([]string{"wrapped"}, safety.WithConfirmationMode(safety.ConfirmBoolean))
+	reg := WithConfirmation(confirm, []Registration{stubRegistration("wrapped", false)})[0]
+
+	props := reg.Tool.InputSchema.Properties
+	if _, ok := props[confirmParam]; !ok {
+		t.Error("wrapped tool schema should declare confirm")
+	}
+	if _, ok := props[confirmationTokenParam]; ok {
+		t.Error("wrapped tool schema should not declare confirmation_token in boolean mode")
+	}
+
+	for _, confirmed := range []bool{false, true} {
+		result, err := reg.Handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "wrapped", Arguments: map[string]any{"message_id": "msg-1", confirmParam: confirmed}},
+		})
+		if err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		if ran := extractText(t, result) == "ran"; ran != confirmed {
+			t.Errorf("confirm=%v: ran = %v, want %v", confirmed, ran, confirmed)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// UnknownToolNames
+// ---------------------------------------------------------------------------
+
+func Test_UnknownToolNames(t *testing.T) {
+	t.Parallel()
+
+	regs := []Registration{stubRegistration("a", false), stubRegistration("b", false)}
+	got := UnknownToolNames(regs, []string{"a", "typo", "b", "other"})
+	want := []string{"typo", "other"}
+	if len(got) != len(want) {
+		t.Fatalf("UnknownToolNames = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("UnknownToolNames[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ErrorCode classifies a failed tool call so that clients can branch on the
+// kind of failure instead of parsing the message.
+type ErrorCode string
+
+const (
+	// CodeInvalidArgument means the call's arguments were rejected, e.g. an
+	// out-of-range limit or an ambiguous channel name.
+	CodeInvalidArgument ErrorCode = "INVALID_ARGUMENT"
+	// CodeNotFound means a channel, message, user or other object does not
+	// exist.
+	CodeNotFound ErrorCode = "NOT_FOUND"
+	// CodeChannelNotAllowed means the server's channel filter blocked the
+	// call; Discord was not contacted.
+	CodeChannelNotAllowed ErrorCode = "CHANNEL_NOT_ALLOWED"
+	// CodePermissionDenied means Discord refused the call because the bot
+	// lacks a permission or access.
+	CodePermissionDenied ErrorCode = "PERMISSION_DENIED"
+	// CodeConflict means the target changed since the caller last read it,
+	// e.g. a guarded edit whose expected content no longer matches.
+	CodeConflict ErrorCode = "CONFLICT"
+	// CodeRateLimited means Discord rate limited the call; retry later.
+	CodeRateLimited ErrorCode = "RATE_LIMITED"
+	// CodeDiscordUnavailable means Discord failed with a 5xx response, or
+	// the circuit breaker is failing calls fast during an outage.
+	CodeDiscordUnavailable ErrorCode = "DISCORD_UNAVAILABLE"
+	// CodeInternal means the server itself failed, e.g. a handler panicked.
+	CodeInternal ErrorCode = "INTERNAL"
+	// CodeUnknown is used for errors that fit none of the other codes.
+	CodeUnknown ErrorCode = "UNKNOWN"
+)
+
+// ToolError is the JSON shape of an error result, sent after the
+// human-readable text. DiscordCode is Discord's JSON error code (e.g. 50013)
+// when the failure came from the Discord API.
+type ToolError struct {
+	Code        ErrorCode `json:"code"`
+	Message     string    `json:"message"`
+	DiscordCode int       `json:"discord_code,omitempty"`
+}
+
+// codedError attaches an ErrorCode to an error; see WithCode.
+type codedError struct {
+	code ErrorCode
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// WithCode returns err annotated with code, which ClassifyError reports in
+// place of the code it would derive. A nil err returns nil.
+func WithCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// ClassifyError returns the ErrorCode for err and, for Discord API errors,
+// Discord's JSON error code. Codes attached with WithCode take precedence;
+// otherwise resolver errors, an open circuit breaker and Discord REST and
+// rate limit errors are mapped, and anything else is CodeUnknown.
+func ClassifyError(err error) (ErrorCode, int) {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code, 0
+	}
+	switch {
+	case errors.Is(err, resolve.ErrChannelNotFound):
+		return CodeNotFound, 0
+	case errors.Is(err, resolve.ErrAmbiguousChannel):
+		return CodeInvalidArgument, 0
+	case errors.Is(err, discord.ErrCircuitOpen):
+		return CodeDiscordUnavailable, 0
+	}
+
+	var rateErr *discordgo.RateLimitError
+	if errors.As(err, &rateErr) {
+		return CodeRateLimited, 0
+	}
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) {
+		return CodeUnknown, 0
+	}
+
+	var discordCode int
+	if restErr.Message != nil {
+		discordCode = restErr.Message.Code
+	}
+	switch discordCode {
+	case discordgo.ErrCodeMissingPermissions, discordgo.ErrCodeMissingAccess:
+		return CodePermissionDenied, discordCode
+	case discordgo.ErrCodeUnknownMessage, discordgo.ErrCodeUnknownChannel,
+		discordgo.ErrCodeUnknownUser, discordgo.ErrCodeUnknownMember,
+		discordgo.ErrCodeUnknownGuild, discordgo.ErrCodeUnknownWebhook:
+		return CodeNotFound, discordCode
+	case discordgo.ErrCodeInvalidFormBody:
+		return CodeInvalidArgument, discordCode
+	}
+
+	if restErr.Response == nil {
+		return CodeUnknown, discordCode
+	}
+	switch status := restErr.Response.StatusCode; {
+	case status == http.StatusTooManyRequests:
+		return CodeRateLimited, discordCode
+	case status == http.StatusForbidden:
+		return CodePermissionDenied, discordCode
+	case status == http.StatusNotFound:
+		return CodeNotFound, discordCode
+	case status == http.StatusBadRequest:
+		return CodeInvalidArgument, discordCode
+	case status >= 500:
+		return CodeDiscordUnavailable, discordCode
+	}
+	return CodeUnknown, discordCode
+}
+
+// CodedErrorResult returns an error result whose first content item is
+// "error: <msg>" and whose second is a ToolError carrying code as JSON.
+func CodedErrorResult(code ErrorCode, msg string) *mcp.CallToolResult {
+	return toolErrorResult(ToolError{Code: code, Message: msg})
+}
+
+// ErrorResultFor returns the error result for err, classified by
+// ClassifyError.
+func ErrorResultFor(err error) *mcp.CallToolResult {
+	code, discordCode := ClassifyError(err)
+	return toolErrorResult(ToolError{Code: code, Message: err.Error(), DiscordCode: discordCode})
+}
+
+// ErrorCodeOf returns the code of an error result built by CodedErrorResult
+// or ErrorResultFor. It reports false for a nil or successful result, or one
+// without a ToolError.
+func ErrorCodeOf(result *mcp.CallToolResult) (ErrorCode, bool) {
+	if result == nil || !result.IsError || len(result.Content) < 2 {
+		return "", false
+	}
+	tc, ok := result.Content[1].(mcp.TextContent)
+	if !ok {
+		return "", false
+	}
+	var te ToolError
+	if err := json.Unmarshal([]byte(tc.Text), &te); err != nil || te.Code == "" {
+		return "", false
+	}
+	return te.Code, true
+}
+
+// toolErrorResult renders te as an error result.
+func toolErrorResult(te ToolError) *mcp.CallToolResult {
+	result := mcp.NewToolResultError(fmt.Sprintf("error: %s", te.Message))
+	data, err := json.MarshalIndent(te, "", "  ")
+	if err != nil {
+		return result
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(string(data)))
+	return result
+}
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/resolve"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// restError returns a Discord REST error with the given HTTP status and JSON
+// error code (0 for none).
+func restError(status, code int) error {
+	err := &discordgo.RESTError{Response: &http.Response{StatusCode: status}}
+	if code != 0 {
+		err.Message = &discordgo.APIErrorMessage{Code: code, Message: "discord says no"}
+	}
+	return err
+}
+
+// ---------------------------------------------------------------------------
+// ClassifyError
+// ---------------------------------------------------------------------------
+
+func Test_ClassifyError_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		err             error
+		wantCode        ErrorCode
+		wantDiscordCode int
+	}{
+		{"missing permissions", restError(http.StatusForbidden, discordgo.ErrCodeMissingPermissions), CodePermissionDenied, 50013},
+		{"missing access", restError(http.StatusForbidden, discordgo.ErrCodeMissingAccess), CodePermissionDenied, 50001},
+		{"unknown message", restError(http.StatusNotFound, discordgo.ErrCodeUnknownMessage), CodeNotFound, 10008},
+		{"wrapped unknown channel", fmt.Errorf("fetching: %w", restError(http.StatusNotFound, discordgo.ErrCodeUnknownChannel)), CodeNotFound, 10003},
+		{"invalid form body", restError(http.StatusBadRequest, discordgo.ErrCodeInvalidFormBody), CodeInvalidArgument, 50035},
+		{"429 response", restError(http.StatusTooManyRequests, 0), CodeRateLimited, 0},
+		{"rate limit error", &discordgo.RateLimitError{RateLimit: &discordgo.RateLimit{URL: "/x"}}, CodeRateLimited, 0},
+		{"bare 403", restError(http.StatusForbidden, 0), CodePermissionDenied, 0},
+		{"5xx", restError(http.StatusBadGateway, 0), CodeDiscordUnavailable, 0},
+		{"circuit open", discord.ErrCircuitOpen, CodeDiscordUnavailable, 0},
+		{"unmapped discord code keeps code", restError(http.StatusConflict, 30001), CodeUnknown, 30001},
+		{"channel not found", resolve.NotFound("nope"), CodeNotFound, 0},
+		{"plain error", errors.New("boom"), CodeUnknown, 0},
+		{"WithCode wins", WithCode(CodeInvalidArgument, restError(http.StatusForbidden, discordgo.ErrCodeMissingPermissions)), CodeInvalidArgument, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			code, discordCode := ClassifyError(tt.err)
+			if code != tt.wantCode || discordCode != tt.wantDiscordCode {
+				t.Errorf("ClassifyError() = (%s, %d), want (%s, %d)", code, discordCode, tt.wantCode, tt.wantDiscordCode)
+			}
+		})
+	}
+}
+
+func Test_WithCode_Nil(t *testing.T) {
+	t.Parallel()
+	if err := WithCode(CodeInternal, nil); err != nil {
+		t.Errorf("WithCode(nil) = %v, want nil", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ErrorResultFor
+// ---------------------------------------------------------------------------
+
+func Test_ErrorResultFor_Structured(t *testing.T) {
+	t.Parallel()
+
+	result := ErrorResultFor(restError(http.StatusForbidden, discordgo.ErrCodeMissingPermissions))
+	if !result.IsError {
+		t.Fatal("ErrorResultFor() should produce a result with IsError=true")
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("got %d content items, want 2", len(result.Content))
+	}
+	var te ToolError
+	if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &te); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if te.Code != CodePermissionDenied || te.DiscordCode != 50013 || te.Message == "" {
+		t.Errorf("ToolError = %+v, want PERMISSION_DENIED with discord_code 50013 and a message", te)
+	}
+}
+
+func Test_DeniedResult_Code(t *testing.T) {
+	t.Parallel()
+
+	result := DeniedResult("admin")
+	var te ToolError
+	if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &te); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if te.Code != CodeChannelNotAllowed {
+		t.Errorf("code = %s, want %s", te.Code, CodeChannelNotAllowed)
+	}
+	if !IsDenied(result) {
+		t.Error("IsDenied() should still recognize DeniedResult")
+	}
+}
+
+func Test_IsDenied_ByCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		result *mcp.CallToolResult
+		want   bool
+	}{
+		{name: "nil", result: nil, want: false},
+		{name: "success", result: mcp.NewToolResultText("access to channel \"x\" is not allowed"), want: false},
+		{name: "denied text without code", result: mcp.NewToolResultError("access to channel \"x\" is not allowed"), want: false},
+		{name: "other code with denied text", result: CodedErrorResult(CodeNotFound, "user is not allowed"), want: false},
+		{name: "channel not allowed code", result: CodedErrorResult(CodeChannelNotAllowed, "blocked"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := IsDenied(tt.result); got != tt.want {
+				t.Errorf("IsDenied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
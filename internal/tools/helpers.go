@@ -2,17 +2,62 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"reflect"
 	"time"
 
+	"github.com/jamesprial/claudebot-mcp/internal/auth"
 	"github.com/jamesprial/claudebot-mcp/internal/resolve"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
+// DefaultMaxResultItems is the number of elements a slice result is
+// truncated to when no limit is configured.
+const DefaultMaxResultItems = 500
+
+// ResultLimit caps how many elements of a slice result are serialized by its
+// JSONResult and JSONResultWithText methods. Zero means
+// DefaultMaxResultItems; a negative value disables the cap. Tool packages
+// take it as an option set from tools.max_result_items.
+type ResultLimit int
+
+// Max returns the number of elements kept, or a negative number when the cap
+// is disabled.
+func (l ResultLimit) Max() int {
+	if l == 0 {
+		return DefaultMaxResultItems
+	}
+	return int(l)
+}
+
+// JSONResult is like the package-level JSONResult, but a slice longer than
+// the limit is cut to its first elements and a second content item notes how
+// many were shown.
+func (l ResultLimit) JSONResult(v any) *mcp.CallToolResult {
+	v, note := truncateSlice(v, l.Max())
+	result := JSONResult(v)
+	if note != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(note))
+	}
+	return result
+}
+
+// JSONResultWithText is like the package-level JSONResultWithText, but a
+// slice v is truncated as by JSONResult, with the note added to text.
+func (l ResultLimit) JSONResultWithText(text string, v any) *mcp.CallToolResult {
+	v, note := truncateSlice(v, l.Max())
+	if note != "" {
+		text += " (" + note + ")"
+	}
+	return JSONResultWithText(text, v)
+}
+
 // JSONResult marshals v to indented JSON and returns an mcp.CallToolResult.
+// Tools that return lists use ResultLimit.JSONResult instead.
 func JSONResult(v any) *mcp.CallToolResult {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
@@ -21,32 +66,126 @@ func JSONResult(v any) *mcp.CallToolResult {
 	return mcp.NewToolResultText(string(data))
 }
 
-// ErrorResult returns an mcp.CallToolResult that describes an error condition.
+// JSONResultWithText returns an mcp.CallToolResult whose first content item
+// is the human-readable text and whose second is v marshaled as indented JSON.
+// Clients that only show the first item still get a readable summary.
+func JSONResultWithText(text string, v any) *mcp.CallToolResult {
+	result := mcp.NewToolResultText(text)
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return result
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(string(data)))
+	return result
+}
+
+// truncateSlice returns the first max elements of v when v is a slice longer
+// than max, together with a note saying so. Any other v, or a negative max,
+// is returned unchanged with an empty note.
+func truncateSlice(v any, max int) (any, string) {
+	rv := reflect.ValueOf(v)
+	if max < 0 || rv.Kind() != reflect.Slice || rv.Len() <= max {
+		return v, ""
+	}
+	return rv.Slice(0, max).Interface(), fmt.Sprintf("showing first %d of %d results", max, rv.Len())
+}
+
+// ErrorResult returns an mcp.CallToolResult that describes an error condition,
+// with code CodeUnknown. Prefer CodedErrorResult or ErrorResultFor when the
+// kind of failure is known.
 func ErrorResult(msg string) *mcp.CallToolResult {
-	return mcp.NewToolResultError(fmt.Sprintf("error: %s", msg))
+	return CodedErrorResult(CodeUnknown, msg)
+}
+
+// DeniedResult returns an error result with code CodeChannelNotAllowed for a
+// request rejected by the channel filter.
+func DeniedResult(channelName string) *mcp.CallToolResult {
+	return CodedErrorResult(CodeChannelNotAllowed, fmt.Sprintf("access to channel %q is not allowed", channelName))
+}
+
+// IsDenied reports whether result is an error with code
+// CodeChannelNotAllowed, as produced by DeniedResult.
+func IsDenied(result *mcp.CallToolResult) bool {
+	code, ok := ErrorCodeOf(result)
+	return ok && code == CodeChannelNotAllowed
+}
+
+// LogAudit logs a successful tool invocation to the audit logger, silently
+// ignoring a nil logger. Failures are logged by AuditErrorResult, or by
+// LogAuditOutcome for any other outcome.
+//
+// The entry carries the request ID stored in ctx, if any.
+func LogAudit(ctx context.Context, audit *safety.AuditLogger, toolName string, params map[string]any, result string, start time.Time) {
+	LogAuditOutcome(ctx, audit, toolName, params, safety.OutcomeSuccess, result, start)
 }
 
-// LogAudit logs a tool invocation to the audit logger, silently ignoring a nil logger.
-func LogAudit(audit *safety.AuditLogger, toolName string, params map[string]any, result string, start time.Time) {
+// LogAuditOutcome is like LogAudit, but records the given outcome.
+func LogAuditOutcome(ctx context.Context, audit *safety.AuditLogger, toolName string, params map[string]any, outcome safety.Outcome, result string, start time.Time) {
 	if audit == nil {
 		return
 	}
 	_ = audit.Log(safety.AuditEntry{
 		Timestamp: start,
+		RequestID: RequestIDFromContext(ctx),
+		Client:    auth.ClientFromContext(ctx),
 		Tool:      toolName,
 		Params:    params,
+		Outcome:   outcome,
 		Result:    result,
 		Duration:  time.Since(start),
 	})
 }
 
-// ConfirmPrompt issues a confirmation request and returns the prompt result.
+// ConfirmationRequest is the JSON shape of a confirmation prompt. In token
+// mode clients pass ConfirmationToken back as the confirmation_token argument
+// of Tool to proceed; in boolean mode Confirm is set and clients call Tool
+// again with confirm: true once the user has approved.
+type ConfirmationRequest struct {
+	Tool              string `json:"tool"`
+	Resource          string `json:"resource"`
+	Description       string `json:"description"`
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+	Confirm           bool   `json:"confirm,omitempty"`
+}
+
+// ConfirmPrompt issues a confirmation request and returns the prompt result:
+// a human-readable explanation followed by the ConfirmationRequest as JSON,
+// so clients can read the token without parsing the prose. In boolean mode
+// no token is issued and the prompt asks for confirm: true instead.
 func ConfirmPrompt(confirm *safety.ConfirmationTracker, toolName, resource, description string) *mcp.CallToolResult {
+	if confirm.Mode() == safety.ConfirmBoolean {
+		text := fmt.Sprintf(
+			"Confirmation required for %s on %q.\n\n%s\n\nAsk the user to approve, then call %s again with confirm=true.",
+			toolName, resource, description, toolName,
+		)
+		return JSONResultWithText(text, ConfirmationRequest{
+			Tool:        toolName,
+			Resource:    resource,
+			Description: description,
+			Confirm:     true,
+		})
+	}
 	token := confirm.RequestConfirmation(toolName, resource, description)
-	return mcp.NewToolResultText(fmt.Sprintf(
+	text := fmt.Sprintf(
 		"Confirmation required for %s on %q.\n\n%s\n\nTo proceed, call %s again with confirmation_token=%q.",
 		toolName, resource, description, toolName, token,
-	))
+	)
+	return JSONResultWithText(text, ConfirmationRequest{
+		Tool:              toolName,
+		Resource:          resource,
+		Description:       description,
+		ConfirmationToken: token,
+	})
+}
+
+// WithRejection prefixes description with the reason a confirmation token
+// was rejected, so a caller that did pass a token learns why it was refused.
+// description is returned unchanged when no token was passed.
+func WithRejection(description, token, reason string) string {
+	if token == "" || reason == "" {
+		return description
+	}
+	return fmt.Sprintf("The confirmation token was rejected: %s. %s", reason, description)
 }
 
 // DefaultLogger returns l if non-nil, otherwise slog.Default().
@@ -57,19 +196,22 @@ func DefaultLogger(l *slog.Logger) *slog.Logger {
 	return l
 }
 
-// AuditErrorResult logs the error to the audit logger and returns an ErrorResult.
-func AuditErrorResult(audit *safety.AuditLogger, toolName string, params map[string]any, err error, start time.Time) *mcp.CallToolResult {
-	LogAudit(audit, toolName, params, "error: "+err.Error(), start)
-	return ErrorResult(err.Error())
+// AuditErrorResult logs the error to the audit logger and returns an error
+// result classified by ClassifyError.
+func AuditErrorResult(ctx context.Context, audit *safety.AuditLogger, toolName string, params map[string]any, err error, start time.Time) *mcp.CallToolResult {
+	LogAuditOutcome(ctx, audit, toolName, params, safety.OutcomeError, "error: "+err.Error(), start)
+	return ErrorResultFor(err)
 }
 
 // ResolveAndFilterChannel resolves a channel parameter to an ID and name, then
-// checks whether the channel is permitted by the filter. On success it returns
+// checks whether the filter permits op on the channel. On success it returns
 // the channelID, channelName, and a nil errResult. On any failure it returns
 // empty strings and a non-nil errResult that should be returned to the caller.
 func ResolveAndFilterChannel(
+	ctx context.Context,
 	r resolve.ChannelResolver,
 	filter *safety.Filter,
+	op safety.Operation,
 	audit *safety.AuditLogger,
 	logger *slog.Logger,
 	toolName string,
@@ -80,16 +222,16 @@ func ResolveAndFilterChannel(
 	var err error
 	channelID, err = resolve.ResolveChannelParam(r, channel)
 	if err != nil {
-		LogAudit(audit, toolName, params, "error: "+err.Error(), start)
-		return "", "", ErrorResult(err.Error())
+		LogAuditOutcome(ctx, audit, toolName, params, safety.OutcomeError, "error: "+err.Error(), start)
+		return "", "", ErrorResultFor(err)
 	}
-	logger.Debug("resolved channel", "input", channel, "channelID", channelID)
+	logger.DebugContext(ctx, "resolved channel", "input", channel, "channelID", channelID)
 
 	name := r.ChannelName(channelID)
-	if filter != nil && !filter.IsAllowed(name) {
-		logger.Debug("channel access denied", "channel", name)
-		LogAudit(audit, toolName, params, "denied", start)
-		return "", "", ErrorResult(fmt.Sprintf("access to channel %q is not allowed", name))
+	if filter != nil && !filter.IsAllowedFor(op, name) {
+		logger.DebugContext(ctx, "channel access denied", "channel", name, "operation", op)
+		LogAuditOutcome(ctx, audit, toolName, params, safety.OutcomeDenied, "denied", start)
+		return "", "", DeniedResult(name)
 	}
 	return channelID, name, nil
 }
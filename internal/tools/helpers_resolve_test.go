@@ -2,6 +2,7 @@ package tools_test
 
 import (
 	"bytes"
+	"context"
 	"log/slog"
 	"strings"
 	"testing"
@@ -108,7 +109,7 @@ func Test_ResolveAndFilterChannel_Cases(t *testing.T) {
 			params := map[string]any{"channel": tt.channel}
 
 			channelID, channelName, errResult := tools.ResolveAndFilterChannel(
-				r, tt.filter, tt.audit, logger,
+				context.Background(), r, tt.filter, safety.OpRead, tt.audit, logger,
 				"test_tool", tt.channel, params, start,
 			)
 
@@ -154,7 +155,7 @@ func Test_ResolveAndFilterChannel_AuditOnResolveError(t *testing.T) {
 	params := map[string]any{"channel": "nonexistent"}
 
 	_, _, errResult := tools.ResolveAndFilterChannel(
-		r, nil, auditLogger, logger,
+		context.Background(), r, nil, safety.OpRead, auditLogger, logger,
 		"test_tool", "nonexistent", params, start,
 	)
 
@@ -179,7 +180,7 @@ func Test_ResolveAndFilterChannel_AuditOnFilterDenial(t *testing.T) {
 	params := map[string]any{"channel": "general"}
 
 	_, _, errResult := tools.ResolveAndFilterChannel(
-		r, filter, auditLogger, logger,
+		context.Background(), r, filter, safety.OpRead, auditLogger, logger,
 		"test_tool", "general", params, start,
 	)
 
@@ -211,7 +212,7 @@ func Test_ResolveAndFilterChannel_NilAuditLoggerNoPanic(t *testing.T) {
 	// Test with resolve error (unknown channel) and nil audit logger.
 	start := time.Now()
 	_, _, errResult := tools.ResolveAndFilterChannel(
-		r, nil, nil, logger,
+		context.Background(), r, nil, safety.OpRead, nil, logger,
 		"test_tool", "nonexistent", map[string]any{"channel": "nonexistent"}, start,
 	)
 	if errResult == nil {
@@ -221,7 +222,7 @@ func Test_ResolveAndFilterChannel_NilAuditLoggerNoPanic(t *testing.T) {
 	// Test with filter denial and nil audit logger.
 	filter := safety.NewFilter(nil, []string{"general"})
 	_, _, errResult2 := tools.ResolveAndFilterChannel(
-		r, filter, nil, logger,
+		context.Background(), r, filter, safety.OpRead, nil, logger,
 		"test_tool", "general", map[string]any{"channel": "general"}, start,
 	)
 	if errResult2 == nil {
@@ -237,7 +238,7 @@ func Test_ResolveAndFilterChannel_NumericIDPassesThrough(t *testing.T) {
 
 	start := time.Now()
 	channelID, _, errResult := tools.ResolveAndFilterChannel(
-		r, nil, nil, logger,
+		context.Background(), r, nil, safety.OpRead, nil, logger,
 		"test_tool", "9999999", map[string]any{"channel": "9999999"}, start,
 	)
 	if errResult != nil {
@@ -257,7 +258,7 @@ func Test_ResolveAndFilterChannel_HashPrefixStripped(t *testing.T) {
 
 	start := time.Now()
 	channelID, channelName, errResult := tools.ResolveAndFilterChannel(
-		r, nil, nil, logger,
+		context.Background(), r, nil, safety.OpRead, nil, logger,
 		"test_tool", "#general", map[string]any{"channel": "#general"}, start,
 	)
 	if errResult != nil {
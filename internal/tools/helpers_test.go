@@ -2,13 +2,18 @@ package tools
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/jamesprial/claudebot-mcp/internal/auth"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -118,6 +123,94 @@ func Test_JSONResult_Cases(t *testing.T) {
 	}
 }
 
+func Test_TruncateSlice_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    any
+		max      int
+		wantLen  int
+		wantNote string
+	}{
+		{name: "under limit", input: []int{1, 2, 3}, max: 5, wantLen: 3},
+		{name: "at limit", input: []int{1, 2, 3}, max: 3, wantLen: 3},
+		{name: "over limit", input: []int{1, 2, 3, 4, 5}, max: 2, wantLen: 2, wantNote: "showing first 2 of 5 results"},
+		{name: "negative disables", input: []int{1, 2, 3}, max: -1, wantLen: 3},
+		{name: "non-slice unchanged", input: map[string]int{"a": 1, "b": 2}, max: 1, wantLen: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, note := truncateSlice(tt.input, tt.max)
+			if note != tt.wantNote {
+				t.Errorf("note = %q, want %q", note, tt.wantNote)
+			}
+			if tt.wantLen < 0 {
+				return
+			}
+			if n := len(got.([]int)); n != tt.wantLen {
+				t.Errorf("len = %d, want %d", n, tt.wantLen)
+			}
+		})
+	}
+}
+
+func Test_ResultLimit_TruncatesPastLimit(t *testing.T) {
+	t.Parallel()
+
+	limit := ResultLimit(3)
+	items := []string{"a", "b", "c", "d", "e"}
+
+	result := limit.JSONResult(items)
+	var got []string
+	if err := json.Unmarshal([]byte(extractText(t, result)), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("got %d items, want 3", len(got))
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("got %d content items, want 2", len(result.Content))
+	}
+	if note := result.Content[1].(mcp.TextContent).Text; note != "showing first 3 of 5 results" {
+		t.Errorf("note = %q", note)
+	}
+
+	withText := limit.JSONResultWithText("5 items", items)
+	if text := extractText(t, withText); text != "5 items (showing first 3 of 5 results)" {
+		t.Errorf("text = %q", text)
+	}
+
+	// Within the limit nothing changes.
+	if n := len(limit.JSONResult(items[:3]).Content); n != 1 {
+		t.Errorf("got %d content items for a short slice, want 1", n)
+	}
+	// The package-level helpers never truncate.
+	if err := json.Unmarshal([]byte(extractText(t, JSONResult(items))), &got); err != nil || len(got) != 5 {
+		t.Errorf("JSONResult kept %d items (err %v), want 5", len(got), err)
+	}
+}
+
+func Test_ResultLimit_Max(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		limit ResultLimit
+		want  int
+	}{
+		{limit: 0, want: DefaultMaxResultItems},
+		{limit: 10, want: 10},
+		{limit: -1, want: -1},
+	}
+	for _, tt := range tests {
+		if got := tt.limit.Max(); got != tt.want {
+			t.Errorf("ResultLimit(%d).Max() = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
 func Test_JSONResult_StructFields(t *testing.T) {
 	t.Parallel()
 
@@ -146,6 +239,25 @@ func Test_JSONResult_IsNotError(t *testing.T) {
 	}
 }
 
+func Test_JSONResultWithText_TextThenJSON(t *testing.T) {
+	t.Parallel()
+
+	result := JSONResultWithText("Message sent (ID: m1)", map[string]string{"id": "m1"})
+	if len(result.Content) != 2 {
+		t.Fatalf("got %d content items, want 2", len(result.Content))
+	}
+	if text := extractText(t, result); text != "Message sent (ID: m1)" {
+		t.Errorf("first content = %q, want the human-readable text", text)
+	}
+	tc, ok := result.Content[1].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("second content is %T, want mcp.TextContent", result.Content[1])
+	}
+	if !strings.Contains(tc.Text, `"id": "m1"`) {
+		t.Errorf("second content = %q, want indented JSON", tc.Text)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ErrorResult
 // ---------------------------------------------------------------------------
@@ -214,7 +326,7 @@ func Test_LogAudit_NilLogger(t *testing.T) {
 		}
 	}()
 
-	LogAudit(nil, "test_tool", map[string]any{"key": "value"}, "ok", time.Now())
+	LogAudit(context.Background(), nil, "test_tool", map[string]any{"key": "value"}, "ok", time.Now())
 }
 
 func Test_LogAudit_WritesToLogger(t *testing.T) {
@@ -231,13 +343,63 @@ func Test_LogAudit_WritesToLogger(t *testing.T) {
 	w := &trackingWriter{}
 	logger := safety.NewAuditLogger(w)
 
-	LogAudit(logger, "test_tool", map[string]any{"key": "val"}, "success", time.Now())
+	LogAudit(context.Background(), logger, "test_tool", map[string]any{"key": "val"}, "success", time.Now())
 
 	if !w.called {
 		t.Error("LogAudit should have written to the audit logger")
 	}
 }
 
+func Test_LogAudit_Outcome(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		log    func(*safety.AuditLogger, string)
+		result string
+		want   safety.Outcome
+	}{
+		{name: "success", log: logSuccess, result: "ok: 3 messages", want: safety.OutcomeSuccess},
+		// The result text does not change the outcome.
+		{name: "success with error text", log: logSuccess, result: "error: looks bad", want: safety.OutcomeSuccess},
+		{name: "explicit denied", log: logOutcome(safety.OutcomeDenied), result: "denied", want: safety.OutcomeDenied},
+		{name: "explicit no messages", log: logOutcome(safety.OutcomeNoMessages), result: "no messages", want: safety.OutcomeNoMessages},
+		{name: "explicit error", log: logOutcome(safety.OutcomeError), result: "boom", want: safety.OutcomeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			tt.log(safety.NewAuditLogger(&buf), tt.result)
+
+			var entry safety.AuditEntry
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("audit entry is not valid JSON: %v", err)
+			}
+			if entry.Outcome != tt.want {
+				t.Errorf("Outcome = %q, want %q", entry.Outcome, tt.want)
+			}
+			if entry.Result != tt.result {
+				t.Errorf("Result = %q, want %q", entry.Result, tt.result)
+			}
+		})
+	}
+}
+
+// logSuccess logs result with LogAudit.
+func logSuccess(audit *safety.AuditLogger, result string) {
+	LogAudit(context.Background(), audit, "test_tool", nil, result, time.Now())
+}
+
+// logOutcome returns a function that logs result with LogAuditOutcome and
+// outcome.
+func logOutcome(outcome safety.Outcome) func(*safety.AuditLogger, string) {
+	return func(audit *safety.AuditLogger, result string) {
+		LogAuditOutcome(context.Background(), audit, "test_tool", nil, outcome, result, time.Now())
+	}
+}
+
 // trackingWriter is a minimal io.Writer that records whether Write was called.
 type trackingWriter struct {
 	called bool
@@ -248,6 +410,60 @@ func (tw *trackingWriter) Write(p []byte) (int, error) {
 	return len(p), nil
 }
 
+func Test_LogAudit_RecordsClient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		header     string
+		wantClient string
+	}{
+		{"labelled client", "Bearer ci-token", "ci-agent"},
+		{"unlabelled token", "Bearer main-token", auth.TokenLabel("main-token")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			audit := safety.NewAuditLogger(&buf)
+			middleware := auth.NewClientAuthMiddleware([]auth.Client{
+				{Token: "main-token"},
+				{Label: "ci-agent", Token: "ci-token"},
+			}, nil)
+			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				LogAudit(r.Context(), audit, "test_tool", nil, "ok", time.Now())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set("Authorization", tt.header)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			var entry safety.AuditEntry
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("audit entry is not JSON: %v (%q)", err, buf.String())
+			}
+			if entry.Client != tt.wantClient {
+				t.Errorf("client = %q, want %q", entry.Client, tt.wantClient)
+			}
+			if strings.Contains(buf.String(), strings.TrimPrefix(tt.header, "Bearer ")) {
+				t.Errorf("audit output leaks the token: %s", buf.String())
+			}
+		})
+	}
+}
+
+func Test_LogAudit_NoClientOmitted(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	LogAudit(context.Background(), safety.NewAuditLogger(&buf), "test_tool", nil, "ok", time.Now())
+	if strings.Contains(buf.String(), `"client"`) {
+		t.Errorf("audit entry without a client should omit the field: %s", buf.String())
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ConfirmPrompt
 // ---------------------------------------------------------------------------
@@ -280,33 +496,32 @@ func Test_ConfirmPrompt_TokenIsConfirmable(t *testing.T) {
 	tracker := safety.NewConfirmationTracker([]string{"discord_delete_message"})
 	result := ConfirmPrompt(tracker, "discord_delete_message", "res", "desc")
 
-	text := extractText(t, result)
-
-	// Extract the token from the text. It appears after confirmation_token="
-	// and before the closing quote.
-	const prefix = `confirmation_token="`
-	idx := strings.Index(text, prefix)
-	if idx < 0 {
-		t.Fatalf("could not find confirmation_token in text: %s", text)
+	if len(result.Content) < 2 {
+		t.Fatalf("ConfirmPrompt returned %d content items, want text plus JSON", len(result.Content))
 	}
-	after := text[idx+len(prefix):]
-	endIdx := strings.Index(after, `"`)
-	if endIdx < 0 {
-		t.Fatalf("could not find closing quote for token in text: %s", text)
+	var prompt ConfirmationRequest
+	if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &prompt); err != nil {
+		t.Fatalf("content[1] is not a ConfirmationRequest: %v", err)
 	}
-	token := after[:endIdx]
-
+	want := ConfirmationRequest{Tool: "discord_delete_message", Resource: "res", Description: "desc", ConfirmationToken: prompt.ConfirmationToken}
+	if prompt != want {
+		t.Errorf("ConfirmationRequest = %+v, want %+v", prompt, want)
+	}
+	token := prompt.ConfirmationToken
 	if token == "" {
-		t.Fatal("extracted token is empty")
+		t.Fatal("confirmation_token is empty")
+	}
+	if text := extractText(t, result); !strings.Contains(text, token) {
+		t.Errorf("text fallback should contain the token, got: %s", text)
 	}
 
 	// The token should be confirmable via the tracker.
-	if !tracker.Confirm(token) {
-		t.Error("token from ConfirmPrompt should be confirmable via the tracker")
+	if ok, reason := tracker.Confirm(token, "discord_delete_message", "res"); !ok {
+		t.Errorf("token from ConfirmPrompt should be confirmable via the tracker: %s", reason)
 	}
 
 	// Second confirm should fail (single-use).
-	if tracker.Confirm(token) {
+	if ok, _ := tracker.Confirm(token, "discord_delete_message", "res"); ok {
 		t.Error("token should be single-use, second Confirm should return false")
 	}
 }
@@ -447,7 +662,7 @@ func Test_AuditErrorResult_Cases(t *testing.T) {
 			}()
 
 			start := time.Now()
-			result := AuditErrorResult(tt.auditLogger, tt.toolName, tt.params, tt.err, start)
+			result := AuditErrorResult(context.Background(), tt.auditLogger, tt.toolName, tt.params, tt.err, start)
 
 			if result == nil {
 				t.Fatal("AuditErrorResult() returned nil, want non-nil")
@@ -473,7 +688,7 @@ func Test_AuditErrorResult_WritesToAuditLog(t *testing.T) {
 	auditLogger := safety.NewAuditLogger(w)
 
 	start := time.Now()
-	_ = AuditErrorResult(auditLogger, "discord_send_message", map[string]any{"channel": "general"}, errors.New("test error"), start)
+	_ = AuditErrorResult(context.Background(), auditLogger, "discord_send_message", map[string]any{"channel": "general"}, errors.New("test error"), start)
 
 	if !w.called {
 		t.Error("AuditErrorResult should write to the audit logger")
@@ -487,12 +702,15 @@ func Test_AuditErrorResult_AuditEntryContainsError(t *testing.T) {
 	auditLogger := safety.NewAuditLogger(&buf)
 
 	start := time.Now()
-	_ = AuditErrorResult(auditLogger, "discord_send_message", map[string]any{"channel": "general"}, errors.New("permission denied"), start)
+	_ = AuditErrorResult(context.Background(), auditLogger, "discord_send_message", map[string]any{"channel": "general"}, errors.New("permission denied"), start)
 
 	logged := buf.String()
 	if !strings.Contains(logged, "error: permission denied") {
 		t.Errorf("audit log entry should contain the error message, got: %s", logged)
 	}
+	if !strings.Contains(logged, `"outcome":"error"`) {
+		t.Errorf("audit log entry should have outcome error, got: %s", logged)
+	}
 	if !strings.Contains(logged, "discord_send_message") {
 		t.Errorf("audit log entry should contain the tool name, got: %s", logged)
 	}
@@ -507,7 +725,7 @@ func Test_AuditErrorResult_NilAuditLoggerNoPanic(t *testing.T) {
 		}
 	}()
 
-	result := AuditErrorResult(nil, "test_tool", map[string]any{"key": "val"}, errors.New("oops"), time.Now())
+	result := AuditErrorResult(context.Background(), nil, "test_tool", map[string]any{"key": "val"}, errors.New("oops"), time.Now())
 	if result == nil {
 		t.Fatal("AuditErrorResult() should return non-nil even with nil audit logger")
 	}
@@ -563,6 +781,6 @@ func Benchmark_AuditErrorResult(b *testing.B) {
 	start := time.Now()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = AuditErrorResult(auditLogger, "discord_send_message", params, err, start)
+		_ = AuditErrorResult(context.Background(), auditLogger, "discord_send_message", params, err, start)
 	}
 }
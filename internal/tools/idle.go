@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// WithIdleReconnect wraps every registration so that each call counts as
+// activity on idle and reopens the gateway first if idle closed it. A failed
+// reconnect is logged and the call still runs, since REST tools do not need
+// the gateway. A nil monitor returns registrations unchanged. A nil logger
+// defaults to slog.Default().
+func WithIdleReconnect(idle *discord.IdleMonitor, logger *slog.Logger, registrations []Registration) []Registration {
+	if idle == nil {
+		return registrations
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	out := make([]Registration, 0, len(registrations))
+	for _, reg := range registrations {
+		out = append(out, idleReconnect(idle, logger, reg))
+	}
+	return out
+}
+
+// idleReconnect returns a copy of reg whose handler runs between idle.Begin
+// and idle.End.
+func idleReconnect(idle *discord.IdleMonitor, logger *slog.Logger, reg Registration) Registration {
+	toolName := reg.Tool.Name
+	next := reg.Handler
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		defer idle.End()
+		if err := idle.Begin(ctx); err != nil {
+			logger.WarnContext(ctx, "gateway unavailable, continuing without it", "tool", toolName, "error", err)
+		}
+		return next(ctx, req)
+	}
+
+	return Registration{Tool: reg.Tool, Handler: server.ToolHandlerFunc(handler)}
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stubGateway is a discord.Gateway that opens and closes without error.
+type stubGateway struct{}
+
+func (stubGateway) Open() error  { return nil }
+func (stubGateway) Close() error { return nil }
+
+// ---------------------------------------------------------------------------
+// WithIdleReconnect
+// ---------------------------------------------------------------------------
+
+func Test_WithIdleReconnect_NilMonitorUnchanged(t *testing.T) {
+	t.Parallel()
+
+	regs := []Registration{{Tool: mcp.NewTool("a_tool")}}
+	if got := WithIdleReconnect(nil, nil, regs); &got[0] != &regs[0] {
+		t.Error("a nil monitor should return the registrations unchanged")
+	}
+}
+
+func Test_WithIdleReconnect_RunsCall(t *testing.T) {
+	t.Parallel()
+
+	idle := discord.NewIdleMonitor(stubGateway{}, time.Minute, nil)
+	var ran bool
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ran = true
+		return mcp.NewToolResultText("ok"), nil
+	}
+	regs := WithIdleReconnect(idle, nil, []Registration{{Tool: mcp.NewTool("a_tool"), Handler: server.ToolHandlerFunc(handler)}})
+
+	result, err := regs[0].Handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result == nil || result.IsError {
+		t.Fatalf("handler = %v, %v; want a successful result", result, err)
+	}
+	if !ran {
+		t.Error("wrapped handler did not run")
+	}
+}
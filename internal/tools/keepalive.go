@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// KeepaliveMethod is the notification sent by StartKeepalive when the
+// client did not ask for progress notifications. Clients ignore
+// notifications they do not recognise; the point is the bytes on the wire.
+const KeepaliveMethod = "notifications/keepalive"
+
+// progressMethod is the MCP progress notification.
+const progressMethod = "notifications/progress"
+
+// Notifier sends a notification to the client of the call carried by ctx.
+// *server.MCPServer implements it.
+type Notifier interface {
+	SendNotificationToClient(ctx context.Context, method string, params map[string]any) error
+}
+
+// StartKeepalive sends a notification to the caller every interval until the
+// returned stop function is called or ctx is done, so that proxies with idle
+// timeouts do not cut a long-running call. Over streamable HTTP the first
+// notification switches the response to an event stream, and each one after
+// that is written to it immediately.
+//
+// When req carries a progress token the notifications are
+// notifications/progress for that token, with progress counting the
+// keepalives sent; otherwise they are KeepaliveMethod. Send errors are
+// ignored. A nil notifier or non-positive interval does nothing. stop waits
+// for the sender to exit and is safe to call more than once.
+func StartKeepalive(ctx context.Context, n Notifier, req mcp.CallToolRequest, interval time.Duration) (stop func()) {
+	if n == nil || interval <= 0 {
+		return func() {}
+	}
+	var progressToken mcp.ProgressToken
+	if req.Params.Meta != nil {
+		progressToken = req.Params.Meta.ProgressToken
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		start := time.Now()
+		for sent := 1; ; sent++ {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if progressToken != nil {
+				_ = n.SendNotificationToClient(ctx, progressMethod, map[string]any{
+					"progressToken": progressToken,
+					"progress":      sent,
+					"message":       "still waiting",
+				})
+				continue
+			}
+			_ = n.SendNotificationToClient(ctx, KeepaliveMethod, map[string]any{
+				"elapsed_seconds": int(time.Since(start) / time.Second),
+			})
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// notification is a call recorded by chanNotifier.
+type notification struct {
+	method string
+	params map[string]any
+}
+
+// chanNotifier is a Notifier that sends each notification on a channel.
+type chanNotifier chan notification
+
+func (n chanNotifier) SendNotificationToClient(_ context.Context, method string, params map[string]any) error {
+	n <- notification{method, params}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// StartKeepalive
+// ---------------------------------------------------------------------------
+
+func Test_StartKeepalive_ProgressToken(t *testing.T) {
+	t.Parallel()
+
+	n := make(chanNotifier, 100)
+	req := mcp.CallToolRequest{}
+	req.Params.Meta = &mcp.Meta{ProgressToken: "tok-1"}
+	stop := StartKeepalive(context.Background(), n, req, 5*time.Millisecond)
+	defer stop()
+
+	for want := 1; want <= 2; want++ {
+		select {
+		case got := <-n:
+			if got.method != progressMethod || got.params["progressToken"] != "tok-1" || got.params["progress"] != want {
+				t.Errorf("notification = %+v, want progress %d for tok-1", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no progress notification sent")
+		}
+	}
+}
+
+func Test_StartKeepalive_StopAndDisabled(t *testing.T) {
+	t.Parallel()
+
+	n := make(chanNotifier, 100)
+	stop := StartKeepalive(context.Background(), n, mcp.CallToolRequest{}, time.Hour)
+	stop()
+	stop()
+	if len(n) != 0 {
+		t.Errorf("%d notifications sent before the first interval", len(n))
+	}
+
+	StartKeepalive(context.Background(), nil, mcp.CallToolRequest{}, time.Millisecond)()
+	StartKeepalive(context.Background(), n, mcp.CallToolRequest{}, 0)()
+}
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Recover returns a copy of reg whose handler recovers from panics. A panic
+// is logged with the tool name and stack trace, recorded in audit as an
+// error, and turned into a generic ErrorResult so one bad call cannot take
+// the server down. A nil logger defaults to slog.Default().
+func Recover(audit *safety.AuditLogger, logger *slog.Logger, reg Registration) Registration {
+	logger = DefaultLogger(logger)
+	toolName := reg.Tool.Name
+	next := reg.Handler
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		start := time.Now()
+		defer func() {
+			if p := recover(); p != nil {
+				logger.ErrorContext(ctx, "tool handler panicked",
+					"tool", toolName,
+					"panic", fmt.Sprint(p),
+					"stack", string(debug.Stack()),
+				)
+				LogAuditOutcome(ctx, audit, toolName, req.GetArguments(), safety.OutcomeError, fmt.Sprintf("error: panic: %v", p), start)
+				result, err = CodedErrorResult(CodeInternal, "internal error"), nil
+			}
+		}()
+		return next(ctx, req)
+	}
+
+	return Registration{Tool: reg.Tool, Handler: server.ToolHandlerFunc(handler)}
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ---------------------------------------------------------------------------
+// Recover
+// ---------------------------------------------------------------------------
+
+func Test_Recover_PanickingHandler(t *testing.T) {
+	t.Parallel()
+
+	panicky := Registration{
+		Tool: mcp.NewTool("panicky"),
+		Handler: server.ToolHandlerFunc(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			panic("unexpected nil response")
+		}),
+	}
+
+	var auditBuf, logBuf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logBuf, nil))
+	reg := Recover(safety.NewAuditLogger(&auditBuf), logger, panicky)
+
+	result, err := reg.Handler(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "panicky", Arguments: map[string]any{"channel": "general"}},
+	})
+	if err != nil {
+		t.Fatalf("handler error = %v, want nil", err)
+	}
+	if !result.IsError {
+		t.Error("expected an error result")
+	}
+	if text := extractText(t, result); text != "error: internal error" {
+		t.Errorf("result = %q, want a generic error", text)
+	}
+
+	if !strings.Contains(logBuf.String(), "tool=panicky") || !strings.Contains(logBuf.String(), "stack=") {
+		t.Errorf("log should include the tool name and stack, got: %s", logBuf.String())
+	}
+	audit := auditBuf.String()
+	if !strings.Contains(audit, `"tool":"panicky"`) || !strings.Contains(audit, `"outcome":"error"`) || !strings.Contains(audit, "panic:") {
+		t.Errorf("audit entry should record the panic, got: %s", audit)
+	}
+}
+
+func Test_Recover_PassesThrough(t *testing.T) {
+	t.Parallel()
+
+	reg := Recover(nil, nil, stubRegistration("ok", false))
+	result, err := reg.Handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if text := extractText(t, result); text != "ran" {
+		t.Errorf("result = %q, want %q", text, "ran")
+	}
+}
@@ -3,6 +3,14 @@
 package tools
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -14,9 +22,121 @@ type Registration struct {
 }
 
 // RegisterAll adds every Registration in the provided slice to the given MCP
-// server.
-func RegisterAll(s *server.MCPServer, registrations []Registration) {
+// server. Each handler is wrapped with Recover, so panics are logged to
+// logger, audited, and reported to the client as errors. Each call also gets
+// a fresh request ID in its context; see RequestIDFromContext. When logger
+// has debug enabled, the arguments and result text of every call are logged,
+// with sensitive arguments redacted.
+//
+// If two registrations share a tool name, RegisterAll registers nothing and
+// returns an error listing every colliding name.
+func RegisterAll(s *server.MCPServer, registrations []Registration, audit *safety.AuditLogger, logger *slog.Logger) error {
+	if dups := duplicateToolNames(registrations); len(dups) > 0 {
+		return fmt.Errorf("duplicate tool names: %s", strings.Join(dups, ", "))
+	}
 	for _, r := range registrations {
+		r = withRequestID(withCallLogging(logger, Recover(audit, logger, r)))
 		s.AddTool(r.Tool, r.Handler)
 	}
+	return nil
+}
+
+// duplicateToolNames returns each tool name registered more than once, in
+// order of first collision.
+func duplicateToolNames(registrations []Registration) []string {
+	seen := make(map[string]int, len(registrations))
+	var dups []string
+	for _, r := range registrations {
+		seen[r.Tool.Name]++
+		if seen[r.Tool.Name] == 2 {
+			dups = append(dups, r.Tool.Name)
+		}
+	}
+	return dups
+}
+
+// SelectTools filters registrations by tool name. When enabled is non-empty
+// only the listed tools are kept; any tool in disabled is then removed. Both
+// lists empty returns registrations unchanged.
+func SelectTools(registrations []Registration, enabled, disabled []string) []Registration {
+	if len(enabled) == 0 && len(disabled) == 0 {
+		return registrations
+	}
+	allow := nameSet(enabled)
+	deny := nameSet(disabled)
+	out := make([]Registration, 0, len(registrations))
+	for _, r := range registrations {
+		if _, ok := allow[r.Tool.Name]; len(allow) > 0 && !ok {
+			continue
+		}
+		if _, ok := deny[r.Tool.Name]; ok {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// DefaultChannelTools are the tools whose channel parameter may be omitted
+// when a default channel is configured.
+var DefaultChannelTools = []string{
+	"discord_send_message",
+	"discord_get_messages",
+	"discord_typing",
+	"discord_add_reaction",
+	"discord_remove_reaction",
+}
+
+// OptionalChannel marks the channel parameter of the named tools as optional,
+// notes defaultChannel in its description, and wraps their handlers so that
+// a call without a channel acts on defaultChannel. Other registrations are
+// returned unchanged, so tools that did not opt in still require a channel.
+func OptionalChannel(registrations []Registration, names []string, defaultChannel string) []Registration {
+	set := nameSet(names)
+	out := make([]Registration, 0, len(registrations))
+	for _, r := range registrations {
+		prop, ok := r.Tool.InputSchema.Properties["channel"].(map[string]any)
+		if _, selected := set[r.Tool.Name]; !selected || !ok {
+			out = append(out, r)
+			continue
+		}
+
+		props := maps.Clone(r.Tool.InputSchema.Properties)
+		channel := maps.Clone(prop)
+		desc, _ := channel["description"].(string)
+		channel["description"] = fmt.Sprintf("%s (default: %s)", desc, defaultChannel)
+		props["channel"] = channel
+		r.Tool.InputSchema.Properties = props
+		r.Tool.InputSchema.Required = slices.DeleteFunc(slices.Clone(r.Tool.InputSchema.Required), func(name string) bool {
+			return name == "channel"
+		})
+		r.Handler = withDefaultChannel(r.Handler, defaultChannel)
+		out = append(out, r)
+	}
+	return out
+}
+
+// withDefaultChannel returns next with the channel argument set to
+// defaultChannel when the call omits it or passes it empty.
+func withDefaultChannel(next server.ToolHandlerFunc, defaultChannel string) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if req.GetString("channel", "") == "" {
+			args := maps.Clone(req.GetArguments())
+			if args == nil {
+				args = make(map[string]any, 1)
+			}
+			args["channel"] = defaultChannel
+			req.Params.Arguments = args
+		}
+		return next(ctx, req)
+	}
+}
+
+// nameSet builds a lookup set from names.
+func nameSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		set[n] = struct{}{}
+	}
+	return set
 }
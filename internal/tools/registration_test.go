@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ---------------------------------------------------------------------------
+// RegisterAll
+// ---------------------------------------------------------------------------
+
+func Test_RegisterAll_DuplicateNames(t *testing.T) {
+	t.Parallel()
+
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(false))
+	err := RegisterAll(s, []Registration{
+		stubRegistration("discord_send_message", false),
+		stubRegistration("discord_get_user", false),
+		stubRegistration("discord_send_message", false),
+		stubRegistration("discord_send_message", false),
+	}, nil, nil)
+	if err == nil {
+		t.Fatal("RegisterAll() expected an error for duplicate names")
+	}
+	if !strings.Contains(err.Error(), "duplicate tool names: discord_send_message") {
+		t.Errorf("error = %q, want it to name the collision once", err)
+	}
+	if strings.Count(err.Error(), "discord_send_message") != 1 {
+		t.Errorf("error = %q lists the same collision more than once", err)
+	}
+	if tools := s.ListTools(); len(tools) != 0 {
+		t.Errorf("registered %d tools, want none when names collide", len(tools))
+	}
+}
+
+func Test_RegisterAll_Unique(t *testing.T) {
+	t.Parallel()
+
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(false))
+	err := RegisterAll(s, []Registration{
+		stubRegistration("a", false),
+		stubRegistration("b", false),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("RegisterAll() error = %v", err)
+	}
+	if tools := s.ListTools(); len(tools) != 2 {
+		t.Errorf("registered %d tools, want 2", len(tools))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// SelectTools
+// ---------------------------------------------------------------------------
+
+func Test_SelectTools_Cases(t *testing.T) {
+	t.Parallel()
+
+	all := []Registration{
+		stubRegistration("discord_send_message", false),
+		stubRegistration("discord_get_messages", false),
+		stubRegistration("discord_delete_message", false),
+	}
+
+	tests := []struct {
+		name     string
+		enabled  []string
+		disabled []string
+		want     []string
+	}{
+		{name: "no lists keeps everything", want: []string{"discord_send_message", "discord_get_messages", "discord_delete_message"}},
+		{name: "disabled tool removed", disabled: []string{"discord_delete_message"}, want: []string{"discord_send_message", "discord_get_messages"}},
+		{name: "enabled keeps only listed", enabled: []string{"discord_get_messages"}, want: []string{"discord_get_messages"}},
+		{name: "disabled wins over enabled", enabled: []string{"discord_get_messages", "discord_send_message"}, disabled: []string{"discord_send_message"}, want: []string{"discord_get_messages"}},
+		{name: "unknown names ignored", disabled: []string{"no_such_tool"}, want: []string{"discord_send_message", "discord_get_messages", "discord_delete_message"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := SelectTools(all, tt.enabled, tt.disabled)
+			names := make([]string, len(got))
+			for i, r := range got {
+				names[i] = r.Tool.Name
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("SelectTools() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func Test_OptionalChannel(t *testing.T) {
+	t.Parallel()
+
+	newTool := func(name string) Registration {
+		return Registration{Tool: mcp.NewTool(name,
+			mcp.WithString("channel", mcp.Required(), mcp.Description("Channel name or ID")),
+			mcp.WithString("content", mcp.Required()),
+		)}
+	}
+	orig := []Registration{newTool("discord_send_message"), newTool("discord_edit_message")}
+	regs := OptionalChannel(orig, []string{"discord_send_message"}, "general")
+
+	send := regs[0].Tool.InputSchema
+	if slices.Contains(send.Required, "channel") {
+		t.Errorf("send Required = %v, want channel optional", send.Required)
+	}
+	if !slices.Contains(send.Required, "content") {
+		t.Errorf("send Required = %v, want content still required", send.Required)
+	}
+	desc := send.Properties["channel"].(map[string]any)["description"]
+	if desc != "Channel name or ID (default: general)" {
+		t.Errorf("channel description = %q", desc)
+	}
+
+	if !slices.Contains(regs[1].Tool.InputSchema.Required, "channel") {
+		t.Error("unselected tool should still require channel")
+	}
+	if !slices.Contains(orig[0].Tool.InputSchema.Required, "channel") {
+		t.Error("original registration was modified")
+	}
+}
+
+func Test_OptionalChannel_InjectsDefault(t *testing.T) {
+	t.Parallel()
+
+	newTool := func(name string, got *string) Registration {
+		return Registration{
+			Tool: mcp.NewTool(name, mcp.WithString("channel", mcp.Required())),
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				*got = req.GetString("channel", "")
+				return mcp.NewToolResultText("ok"), nil
+			},
+		}
+	}
+	var sendChannel, deleteChannel string
+	regs := OptionalChannel([]Registration{
+		newTool("discord_send_message", &sendChannel),
+		newTool("discord_delete_message", &deleteChannel),
+	}, []string{"discord_send_message"}, "general")
+
+	tests := []struct {
+		name string
+		reg  Registration
+		args map[string]any
+		got  *string
+		want string
+	}{
+		{name: "omitted channel uses default", reg: regs[0], args: map[string]any{"content": "hi"}, got: &sendChannel, want: "general"},
+		{name: "empty channel uses default", reg: regs[0], args: map[string]any{"channel": ""}, got: &sendChannel, want: "general"},
+		{name: "nil arguments use default", reg: regs[0], got: &sendChannel, want: "general"},
+		{name: "explicit channel wins", reg: regs[0], args: map[string]any{"channel": "random"}, got: &sendChannel, want: "random"},
+		{name: "tool not opted in gets no default", reg: regs[1], args: map[string]any{}, got: &deleteChannel, want: ""},
+	}
+	for _, tt := range tests {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = tt.args
+		if _, err := tt.reg.Handler(context.Background(), req); err != nil {
+			t.Fatalf("%s: handler error: %v", tt.name, err)
+		}
+		if *tt.got != tt.want {
+			t.Errorf("%s: channel = %q, want %q", tt.name, *tt.got, tt.want)
+		}
+		if tt.args != nil && tt.args["channel"] == "general" {
+			t.Errorf("%s: caller's arguments were modified", tt.name)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// requestIDKey is the context key under which a call's request ID is stored.
+type requestIDKey struct{}
+
+// NewRequestID returns a short random identifier for a tool call.
+func NewRequestID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ContextWithRequestID returns a copy of ctx carrying id.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or "" if none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID returns a copy of reg whose handler stores a fresh request ID
+// in the context before calling the original handler.
+func withRequestID(reg Registration) Registration {
+	next := reg.Handler
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return next(ContextWithRequestID(ctx, NewRequestID()), req)
+	}
+	return Registration{Tool: reg.Tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// ContextHandler is a slog.Handler that adds a request_id attribute to
+// records logged with a context carrying a request ID, so that log lines can
+// be matched with the call's audit entry.
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps next in a ContextHandler.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: next}
+}
+
+// Handle adds the request ID from ctx, if any, and passes r to the wrapped
+// handler.
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r = r.Clone()
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a ContextHandler wrapping the result of the wrapped
+// handler's WithAttrs.
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a ContextHandler wrapping the result of the wrapped
+// handler's WithGroup.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ---------------------------------------------------------------------------
+// Request ID correlation
+// ---------------------------------------------------------------------------
+
+func Test_RequestID_AppearsInLogAndAudit(t *testing.T) {
+	t.Parallel()
+
+	var logBuf, auditBuf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(&logBuf, nil)))
+	audit := safety.NewAuditLogger(&auditBuf)
+
+	reg := withRequestID(Registration{
+		Tool: mcp.NewTool("test_tool"),
+		Handler: server.ToolHandlerFunc(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			logger.InfoContext(ctx, "handling")
+			LogAudit(ctx, audit, "test_tool", nil, "ok", time.Now())
+			return mcp.NewToolResultText("done"), nil
+		}),
+	})
+
+	if _, err := reg.Handler(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	var line struct {
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(logBuf.Bytes(), &line); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	var entry safety.AuditEntry
+	if err := json.Unmarshal(auditBuf.Bytes(), &entry); err != nil {
+		t.Fatalf("audit entry is not JSON: %v", err)
+	}
+
+	if line.RequestID == "" {
+		t.Fatalf("log line has no request_id: %s", logBuf.String())
+	}
+	if entry.RequestID != line.RequestID {
+		t.Errorf("audit request_id = %q, log request_id = %q, want equal", entry.RequestID, line.RequestID)
+	}
+}
+
+func Test_RequestID_UniquePerCall(t *testing.T) {
+	t.Parallel()
+
+	var ids []string
+	reg := withRequestID(Registration{
+		Tool: mcp.NewTool("test_tool"),
+		Handler: server.ToolHandlerFunc(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ids = append(ids, RequestIDFromContext(ctx))
+			return mcp.NewToolResultText("done"), nil
+		}),
+	})
+
+	for range 2 {
+		if _, err := reg.Handler(context.Background(), mcp.CallToolRequest{}); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+	}
+	if ids[0] == "" || ids[0] == ids[1] {
+		t.Errorf("request IDs = %v, want two distinct non-empty IDs", ids)
+	}
+}
+
+func Test_ContextHandler_NoRequestID(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewTextHandler(&buf, nil)))
+	logger.With("tool", "x").InfoContext(context.Background(), "outside a call")
+
+	if strings.Contains(buf.String(), "request_id") {
+		t.Errorf("log line has request_id outside a tool call: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "tool=x") {
+		t.Errorf("log line lost WithAttrs attributes: %s", buf.String())
+	}
+}
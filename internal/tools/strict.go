@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// WithStrictArguments wraps every registration so that a call passing an
+// argument the tool does not declare is rejected with an error listing the
+// unexpected and the valid parameter names, instead of the argument being
+// silently ignored. Rejections are written to audit.
+//
+// Apply it after any wrapper that adds parameters (such as WithConfirmation)
+// so those parameters are accepted.
+func WithStrictArguments(audit *safety.AuditLogger, registrations []Registration) []Registration {
+	out := make([]Registration, 0, len(registrations))
+	for _, reg := range registrations {
+		out = append(out, strictArguments(audit, reg))
+	}
+	return out
+}
+
+// strictArguments returns a copy of reg whose handler validates argument
+// names against the tool's input schema before calling the original handler.
+func strictArguments(audit *safety.AuditLogger, reg Registration) Registration {
+	toolName := reg.Tool.Name
+	declared := reg.Tool.InputSchema.Properties
+
+	valid := make([]string, 0, len(declared))
+	for name := range declared {
+		valid = append(valid, name)
+	}
+	sort.Strings(valid)
+
+	next := reg.Handler
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		var unexpected []string
+		for name := range args {
+			if _, ok := declared[name]; !ok {
+				unexpected = append(unexpected, name)
+			}
+		}
+		if len(unexpected) == 0 {
+			return next(ctx, req)
+		}
+
+		sort.Strings(unexpected)
+		err := WithCode(CodeInvalidArgument, fmt.Errorf("unexpected arguments: %s (valid: %s)", strings.Join(unexpected, ", "), strings.Join(valid, ", ")))
+		return AuditErrorResult(ctx, audit, toolName, args, err, time.Now()), nil
+	}
+
+	return Registration{Tool: reg.Tool, Handler: server.ToolHandlerFunc(handler)}
+}
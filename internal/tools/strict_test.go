@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ---------------------------------------------------------------------------
+// WithStrictArguments
+// ---------------------------------------------------------------------------
+
+func Test_WithStrictArguments_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantRan bool
+		wantErr []string
+	}{
+		{name: "declared argument passes", args: map[string]any{"message_id": "m1"}, wantRan: true},
+		{name: "no arguments pass", args: nil, wantRan: true},
+		{name: "typo is rejected", args: map[string]any{"mesage_id": "m1"}, wantErr: []string{"unexpected arguments: mesage_id", "valid: message_id"}},
+		{name: "all unexpected keys listed", args: map[string]any{"message_id": "m1", "zeta": 1, "alpha": 2}, wantErr: []string{"unexpected arguments: alpha, zeta"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			regs := WithStrictArguments(safety.NewAuditLogger(&buf), []Registration{stubRegistration("tool", false)})
+			result, err := regs[0].Handler(context.Background(), mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "tool", Arguments: tt.args},
+			})
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+
+			text := extractText(t, result)
+			if tt.wantRan {
+				if text != "ran" {
+					t.Errorf("result = %q, want the wrapped handler to run", text)
+				}
+				return
+			}
+			if !result.IsError {
+				t.Errorf("expected an error result, got %q", text)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(text, want) {
+					t.Errorf("result %q should contain %q", text, want)
+				}
+			}
+			if !strings.Contains(buf.String(), `"outcome":"error"`) {
+				t.Errorf("rejection should be audited, got: %s", buf.String())
+			}
+		})
+	}
+}
+
+func Test_WithStrictArguments_AcceptsConfirmationToken(t *testing.T) {
+	t.Parallel()
+
+	confirm := safety.NewConfirmationTracker([]string{"tool"})
+	regs := WithStrictArguments(nil, WithConfirmation(confirm, []Registration{stubRegistration("tool", false)}))
+
+	result, err := regs[0].Handler(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "tool", Arguments: map[string]any{"message_id": "m1", "confirmation_token": "bogus"}},
+	})
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if strings.Contains(extractText(t, result), "unexpected arguments") {
+		t.Error("confirmation_token added by WithConfirmation should be accepted")
+	}
+}
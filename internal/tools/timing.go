@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// WithTimingMeta wraps every registration so that each result carries a
+// _meta block with the tool name and how long the call took, e.g.
+// {"tool": "discord_send_message", "duration_ms": 212}. Metadata the handler
+// already set is kept.
+//
+// Apply it last so the duration covers the other wrappers too.
+func WithTimingMeta(registrations []Registration) []Registration {
+	out := make([]Registration, 0, len(registrations))
+	for _, reg := range registrations {
+		out = append(out, timingMeta(reg))
+	}
+	return out
+}
+
+// timingMeta returns a copy of reg whose handler records its duration in the
+// result's _meta.
+func timingMeta(reg Registration) Registration {
+	toolName := reg.Tool.Name
+	next := reg.Handler
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, req)
+		if result == nil {
+			return result, err
+		}
+		if result.Meta == nil {
+			result.Meta = &mcp.Meta{}
+		}
+		if result.Meta.AdditionalFields == nil {
+			result.Meta.AdditionalFields = make(map[string]any, 2)
+		}
+		result.Meta.AdditionalFields["tool"] = toolName
+		result.Meta.AdditionalFields["duration_ms"] = time.Since(start).Milliseconds()
+		return result, err
+	}
+
+	return Registration{Tool: reg.Tool, Handler: server.ToolHandlerFunc(handler)}
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ---------------------------------------------------------------------------
+// WithTimingMeta
+// ---------------------------------------------------------------------------
+
+func Test_WithTimingMeta_AddsMeta(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		time.Sleep(5 * time.Millisecond)
+		return JSONResult(map[string]string{"id": "m1"}), nil
+	}
+	regs := WithTimingMeta([]Registration{{Tool: mcp.NewTool("slow_tool"), Handler: server.ToolHandlerFunc(handler)}})
+
+	result, err := regs[0].Handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+	var got struct {
+		Meta struct {
+			Tool       string `json:"tool"`
+			DurationMs int64  `json:"duration_ms"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	if got.Meta.Tool != "slow_tool" {
+		t.Errorf("_meta.tool = %q, want %q", got.Meta.Tool, "slow_tool")
+	}
+	if got.Meta.DurationMs < 5 {
+		t.Errorf("_meta.duration_ms = %d, want at least 5", got.Meta.DurationMs)
+	}
+	if text := extractText(t, result); text == "" {
+		t.Error("result content should be unchanged")
+	}
+}
+
+func Test_WithTimingMeta_KeepsExistingMeta(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultText("ran")
+		result.Meta = mcp.NewMetaFromMap(map[string]any{"cursor": "abc"})
+		return result, nil
+	}
+	regs := WithTimingMeta([]Registration{{Tool: mcp.NewTool("tool"), Handler: server.ToolHandlerFunc(handler)}})
+
+	result, err := regs[0].Handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	fields := result.Meta.AdditionalFields
+	if fields["cursor"] != "abc" {
+		t.Errorf("_meta.cursor = %v, want abc", fields["cursor"])
+	}
+	if fields["tool"] != "tool" {
+		t.Errorf("_meta.tool = %v, want tool", fields["tool"])
+	}
+	if _, ok := fields["duration_ms"]; !ok {
+		t.Error("_meta.duration_ms missing")
+	}
+}
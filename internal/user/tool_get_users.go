@@ -0,0 +1,140 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamesprial/claudebot-mcp/internal/discord"
+	"github.com/jamesprial/claudebot-mcp/internal/safety"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Bounds on discord_get_users: the most IDs per call, and the most user
+// fetches in flight at once.
+const (
+	maxUserIDs       = 50
+	userFetchWorkers = 8
+)
+
+// UserResult is one entry in the response of discord_get_users. When the
+// user could not be fetched only ID is set among the summary fields, and
+// Error and ErrorCode say why.
+type UserResult struct {
+	UserSummary
+	Error     string          `json:"error,omitempty"`
+	ErrorCode tools.ErrorCode `json:"error_code,omitempty"`
+}
+
+func toolGetUsers(dg discord.DiscordClient, audit *safety.AuditLogger, logger *slog.Logger) tools.Registration {
+	const toolName = "discord_get_users"
+
+	tool := mcp.NewTool(toolName,
+		mcp.WithDescription(fmt.Sprintf("Retrieve several Discord users by ID in one call, e.g. the authors of a batch of polled messages. Results are in the order requested; a user that cannot be fetched gets an error entry instead of failing the whole call. At most %d IDs.", maxUserIDs)),
+		mcp.WithArray("user_ids",
+			mcp.Required(),
+			mcp.Description("Discord user IDs; duplicates are fetched once"),
+			mcp.WithStringItems(),
+			mcp.MinItems(1),
+			mcp.MaxItems(maxUserIDs),
+		),
+	)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		userIDs := req.GetStringSlice("user_ids", nil)
+		params := map[string]any{"user_ids": userIDs}
+
+		ids, err := uniqueUserIDs(userIDs)
+		if err != nil {
+			return tools.AuditErrorResult(ctx, audit, toolName, params, tools.WithCode(tools.CodeInvalidArgument, err), start), nil
+		}
+		logger.DebugContext(ctx, "fetching users", "count", len(ids))
+
+		results := fetchUsers(ctx, dg, ids)
+		failed := 0
+		for _, r := range results {
+			if r.Error != "" {
+				failed++
+			}
+		}
+
+		tools.LogAudit(ctx, audit, toolName, params, fmt.Sprintf("ok: %d users, %d failed", len(results)-failed, failed), start)
+		return tools.JSONResultWithText(fmt.Sprintf("Fetched %d of %d users", len(results)-failed, len(results)), results), nil
+	}
+
+	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
+}
+
+// uniqueUserIDs trims ids and drops repeats, keeping first-seen order. It
+// rejects an empty list, an empty ID and more than maxUserIDs distinct IDs.
+func uniqueUserIDs(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("user_ids must list at least one user ID")
+	}
+	out := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return nil, fmt.Errorf("user_ids must not contain an empty ID")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	if len(out) > maxUserIDs {
+		return nil, fmt.Errorf("too many user_ids: %d (max %d)", len(out), maxUserIDs)
+	}
+	return out, nil
+}
+
+// fetchUsers fetches ids with up to userFetchWorkers requests in flight and
+// returns one result per ID, in order. IDs not yet started when ctx ends
+// fail with ctx's error.
+func fetchUsers(ctx context.Context, dg discord.DiscordClient, ids []string) []UserResult {
+	results := make([]UserResult, len(ids))
+	sem := make(chan struct{}, userFetchWorkers)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		results[i].ID = id
+		if err := ctx.Err(); err != nil {
+			setUserError(&results[i], err)
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			setUserError(&results[i], ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			u, err := dg.User(id, discordgo.WithContext(ctx))
+			if err != nil {
+				setUserError(&results[i], err)
+				return
+			}
+			results[i].UserSummary = summarizeUser(u)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// setUserError records err on r, classified as for a tool error result.
+func setUserError(r *UserResult, err error) {
+	code, _ := tools.ClassifyError(err)
+	r.Error = err.Error()
+	r.ErrorCode = code
+}
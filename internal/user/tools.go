@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/discord"
 	"github.com/jamesprial/claudebot-mcp/internal/safety"
 	"github.com/jamesprial/claudebot-mcp/internal/tools"
@@ -13,7 +14,8 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// UserSummary is the response shape returned by discord_get_user.
+// UserSummary is the response shape returned by discord_get_user and, per
+// user, by discord_get_users.
 type UserSummary struct {
 	ID            string `json:"id"`
 	Username      string `json:"username"`
@@ -31,6 +33,7 @@ func UserTools(
 	logger = tools.DefaultLogger(logger)
 	return []tools.Registration{
 		toolGetUser(dg, audit, logger),
+		toolGetUsers(dg, audit, logger),
 	}
 }
 
@@ -50,24 +53,27 @@ func toolGetUser(dg discord.DiscordClient, audit *safety.AuditLogger, logger *sl
 		userID := req.GetString("user_id", "")
 		params := map[string]any{"user_id": userID}
 
-		logger.Debug("fetching user info", "userID", userID)
+		logger.DebugContext(ctx, "fetching user info", "userID", userID)
 
-		u, err := dg.User(userID)
+		u, err := dg.User(userID, discordgo.WithContext(ctx))
 		if err != nil {
-			return tools.AuditErrorResult(audit, toolName, params, err, start), nil
+			return tools.AuditErrorResult(ctx, audit, toolName, params, err, start), nil
 		}
 
-		summary := UserSummary{
-			ID:            u.ID,
-			Username:      u.Username,
-			Discriminator: u.Discriminator,
-			Bot:           u.Bot,
-			AvatarURL:     u.AvatarURL(""),
-		}
-
-		tools.LogAudit(audit, toolName, params, "ok", start)
-		return tools.JSONResult(summary), nil
+		tools.LogAudit(ctx, audit, toolName, params, "ok", start)
+		return tools.JSONResult(summarizeUser(u)), nil
 	}
 
 	return tools.Registration{Tool: tool, Handler: server.ToolHandlerFunc(handler)}
 }
+
+// summarizeUser converts a Discord user into a UserSummary.
+func summarizeUser(u *discordgo.User) UserSummary {
+	return UserSummary{
+		ID:            u.ID,
+		Username:      u.Username,
+		Discriminator: u.Discriminator,
+		Bot:           u.Bot,
+		AvatarURL:     u.AvatarURL(""),
+	}
+}
@@ -2,11 +2,19 @@ package user_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jamesprial/claudebot-mcp/internal/testutil"
+	"github.com/jamesprial/claudebot-mcp/internal/tools"
 	"github.com/jamesprial/claudebot-mcp/internal/user"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // ---------------------------------------------------------------------------
@@ -20,6 +28,7 @@ func Test_UserTools_Registration(t *testing.T) {
 
 	testutil.AssertRegistrations(t, regs, []string{
 		"discord_get_user",
+		"discord_get_users",
 	})
 }
 
@@ -95,3 +104,170 @@ func Test_GetUser_JSONFormat(t *testing.T) {
 		t.Errorf("expected JSON-formatted result, got: %s", text)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// discord_get_users handler
+// ---------------------------------------------------------------------------
+
+// userResults decodes the JSON array carried as the second content item of
+// a discord_get_users result.
+func userResults(t *testing.T, result *mcp.CallToolResult) []user.UserResult {
+	t.Helper()
+	testutil.AssertNotError(t, result)
+	if len(result.Content) < 2 {
+		t.Fatalf("expected 2 content items, got %d", len(result.Content))
+	}
+	var got []user.UserResult
+	if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return got
+}
+
+func Test_GetUsers_MixedResults(t *testing.T) {
+	t.Parallel()
+
+	client := &testutil.MockDiscordClient{
+		UserFunc: func(userID string, _ ...discordgo.RequestOption) (*discordgo.User, error) {
+			if strings.HasPrefix(userID, "gone") {
+				return nil, &discordgo.RESTError{
+					Response: &http.Response{StatusCode: http.StatusNotFound},
+					Message:  &discordgo.APIErrorMessage{Code: discordgo.ErrCodeUnknownUser, Message: "Unknown User"},
+				}
+			}
+			return &discordgo.User{ID: userID, Username: "name-" + userID}, nil
+		},
+	}
+	handler := testutil.FindHandler(t, user.UserTools(client, nil, nil), "discord_get_users")
+
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_users", map[string]any{
+		"user_ids": []any{"u1", "gone-1", "u2", "u1"},
+	}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	testutil.AssertTextContains(t, result, "Fetched 2 of 3 users")
+
+	got := userResults(t, result)
+	if len(got) != 3 {
+		t.Fatalf("got %d results, want 3 (duplicates fetched once): %+v", len(got), got)
+	}
+	for i, want := range []string{"u1", "gone-1", "u2"} {
+		if got[i].ID != want {
+			t.Errorf("results[%d].ID = %q, want %q", i, got[i].ID, want)
+		}
+	}
+	if got[0].Username != "name-u1" || got[0].Error != "" {
+		t.Errorf("results[0] = %+v, want a found user", got[0])
+	}
+	if got[1].Error == "" || got[1].ErrorCode != tools.CodeNotFound || got[1].Username != "" {
+		t.Errorf("results[1] = %+v, want a NOT_FOUND error entry", got[1])
+	}
+}
+
+func Test_GetUsers_BoundedConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, peak atomic.Int32
+	client := &testutil.MockDiscordClient{
+		UserFunc: func(userID string, _ ...discordgo.RequestOption) (*discordgo.User, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return &discordgo.User{ID: userID}, nil
+		},
+	}
+	handler := testutil.FindHandler(t, user.UserTools(client, nil, nil), "discord_get_users")
+
+	ids := make([]any, 50)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("u%d", i)
+	}
+	result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_users", map[string]any{"user_ids": ids}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if got := userResults(t, result); len(got) != 50 {
+		t.Errorf("got %d results, want 50", len(got))
+	}
+	if p := peak.Load(); p < 2 || p > 8 {
+		t.Errorf("peak concurrent fetches = %d, want between 2 and 8", p)
+	}
+}
+
+func Test_GetUsers_CancelledContext(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	client := &testutil.MockDiscordClient{
+		UserFunc: func(userID string, options ...discordgo.RequestOption) (*discordgo.User, error) {
+			calls.Add(1)
+			return &discordgo.User{ID: userID}, nil
+		},
+	}
+	handler := testutil.FindHandler(t, user.UserTools(client, nil, nil), "discord_get_users")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ids := make([]any, 20)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("u%d", i)
+	}
+	result, err := handler(ctx, testutil.NewCallToolRequest("discord_get_users", map[string]any{"user_ids": ids}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	failed := 0
+	for _, r := range userResults(t, result) {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	if calls.Load() != 0 || failed != 20 {
+		t.Errorf("calls = %d, failed = %d; want every ID failed without a fetch", calls.Load(), failed)
+	}
+}
+
+func Test_GetUsers_InvalidIDs(t *testing.T) {
+	t.Parallel()
+
+	tooMany := make([]any, 51)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("u%d", i)
+	}
+
+	tests := []struct {
+		name     string
+		ids      []any
+		wantText string
+	}{
+		{"empty list", []any{}, "at least one"},
+		{"blank ID", []any{"u1", " "}, "empty ID"},
+		{"too many", tooMany, "too many user_ids: 51"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := testutil.FindHandler(t, user.UserTools(&testutil.MockDiscordClient{}, nil, nil), "discord_get_users")
+			result, err := handler(context.Background(), testutil.NewCallToolRequest("discord_get_users", map[string]any{"user_ids": tt.ids}))
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("expected error result")
+			}
+			testutil.AssertTextContains(t, result, tt.wantText)
+			if got := testutil.ToolError(t, result).Code; got != tools.CodeInvalidArgument {
+				t.Errorf("code = %s, want %s", got, tools.CodeInvalidArgument)
+			}
+		})
+	}
+}